@@ -28,8 +28,11 @@ type Handler struct {
 	*ErrorHandler
 	pluginAPI *pluginapi.Client
 	APIRouter *mux.Router
-	root      *mux.Router
-	config    config.Service
+	// ExternalRouter serves requests of external tools, which authenticate with a token instead
+	// of a Mattermost session.
+	ExternalRouter *mux.Router
+	root           *mux.Router
+	config         config.Service
 }
 
 // NewHandler constructs a new handler.
@@ -48,7 +51,14 @@ func NewHandler(pluginAPI *pluginapi.Client, config config.Service) *Handler {
 	api.Handle("{anything:.*}", http.NotFoundHandler())
 	api.NotFoundHandler = http.NotFoundHandler()
 
+	external := root.PathPrefix("/external/v0").Subrouter()
+	external.Use(LogRequest)
+
+	external.Handle("{anything:.*}", http.NotFoundHandler())
+	external.NotFoundHandler = http.NotFoundHandler()
+
 	handler.APIRouter = api
+	handler.ExternalRouter = external
 	handler.root = root
 	handler.config = config
 
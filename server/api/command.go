@@ -0,0 +1,134 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/jwilander/mattermost-plugin-properties/server/app"
+	"github.com/jwilander/mattermost-plugin-properties/server/command"
+	"github.com/jwilander/mattermost-plugin-properties/server/config"
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/pluginapi"
+	"github.com/pkg/errors"
+)
+
+// CommandHandler serves the dynamic autocomplete lists of the slash command.
+type CommandHandler struct {
+	*ErrorHandler
+	propertyFieldService app.PropertyFieldService
+	viewService          app.ViewService
+	pluginAPI            *pluginapi.Client
+	config               config.Service
+}
+
+// NewCommandHandler returns a new command autocomplete api handler
+func NewCommandHandler(router *mux.Router, propertyFieldService app.PropertyFieldService, viewService app.ViewService, api *pluginapi.Client, configService config.Service) *CommandHandler {
+	handler := &CommandHandler{
+		ErrorHandler:         &ErrorHandler{},
+		propertyFieldService: propertyFieldService,
+		viewService:          viewService,
+		pluginAPI:            api,
+		config:               configService,
+	}
+
+	commandRouter := router.PathPrefix("/command").Subrouter()
+
+	commandRouter.HandleFunc("/autocomplete/fields", withContext(handler.getFieldsAutocomplete)).Methods(http.MethodGet)
+	commandRouter.HandleFunc("/autocomplete/values", withContext(handler.getValuesAutocomplete)).Methods(http.MethodGet)
+	commandRouter.HandleFunc("/autocomplete/views", withContext(handler.getViewsAutocomplete)).Methods(http.MethodGet)
+
+	return handler
+}
+
+func (h *CommandHandler) getFieldsAutocomplete(c *Context, w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	fields, err := h.propertyFieldService.GetFields(app.PropertyFieldFilterOptions{
+		TeamID:     query.Get("team_id"),
+		SearchTerm: query.Get("user_input"),
+		PerPage:    maxPropertyFieldsToAutoComplete,
+	})
+	if err != nil {
+		h.HandleError(w, c.logger, err)
+		return
+	}
+
+	list := make([]model.AutocompleteListItem, 0, len(fields))
+	for _, field := range fields {
+		list = append(list, model.AutocompleteListItem{
+			Item:     command.QuoteArg(field.Name),
+			HelpText: field.Type,
+		})
+	}
+
+	ReturnJSON(w, list, http.StatusOK)
+}
+
+// getValuesAutocomplete lists the options of the field named in the already parsed part of the command.
+func (h *CommandHandler) getValuesAutocomplete(c *Context, w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	list := []model.AutocompleteListItem{}
+
+	// parsed looks like "property set <field> "
+	args := command.SplitArgs(query.Get("parsed"))
+	if len(args) < 3 {
+		ReturnJSON(w, list, http.StatusOK)
+		return
+	}
+	fieldName := args[2]
+
+	fields, err := h.propertyFieldService.GetFields(app.PropertyFieldFilterOptions{
+		TeamID:     query.Get("team_id"),
+		SearchTerm: fieldName,
+		PerPage:    maxPropertyFieldsToAutoComplete,
+	})
+	if err != nil {
+		h.HandleError(w, c.logger, err)
+		return
+	}
+
+	userInput := strings.ToLower(query.Get("user_input"))
+	for _, field := range fields {
+		if !strings.EqualFold(field.Name, fieldName) {
+			continue
+		}
+
+		for _, v := range field.Values {
+			option := fmt.Sprint(v)
+			if !strings.HasPrefix(strings.ToLower(option), userInput) {
+				continue
+			}
+			list = append(list, model.AutocompleteListItem{
+				Item: command.QuoteArg(option),
+			})
+		}
+	}
+
+	ReturnJSON(w, list, http.StatusOK)
+}
+
+func (h *CommandHandler) getViewsAutocomplete(c *Context, w http.ResponseWriter, r *http.Request) {
+	userID := r.Header.Get("Mattermost-User-ID")
+	userInput := strings.ToLower(r.URL.Query().Get("user_input"))
+
+	views, err := h.viewService.GetForUser(userID)
+	if err != nil && !errors.Is(err, app.ErrNotFound) {
+		h.HandleError(w, c.logger, err)
+		return
+	}
+
+	list := []model.AutocompleteListItem{}
+	for _, view := range views {
+		if !strings.HasPrefix(strings.ToLower(view.Title), userInput) {
+			continue
+		}
+		list = append(list, model.AutocompleteListItem{
+			Item:     view.Title,
+			HelpText: view.Type,
+		})
+	}
+
+	ReturnJSON(w, list, http.StatusOK)
+}
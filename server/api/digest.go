@@ -0,0 +1,176 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/jwilander/mattermost-plugin-properties/server/app"
+	"github.com/jwilander/mattermost-plugin-properties/server/config"
+	"github.com/mattermost/mattermost/server/public/pluginapi"
+	"github.com/pkg/errors"
+)
+
+// DigestHandler is the API handler.
+type DigestHandler struct {
+	*ErrorHandler
+	digestService app.DigestService
+	pluginAPI     *pluginapi.Client
+	config        config.Service
+	permissions   *app.PermissionsService
+}
+
+// NewDigestHandler returns a new digest api handler
+func NewDigestHandler(router *mux.Router, digestService app.DigestService, api *pluginapi.Client, configService config.Service, permissions *app.PermissionsService) *DigestHandler {
+	handler := &DigestHandler{
+		ErrorHandler:  &ErrorHandler{},
+		digestService: digestService,
+		pluginAPI:     api,
+		config:        configService,
+		permissions:   permissions,
+	}
+
+	digestRouter := router.PathPrefix("/digest").Subrouter()
+
+	digestRouter.HandleFunc("", withContext(handler.createDigest)).Methods(http.MethodPost)
+	digestRouter.HandleFunc("", withContext(handler.getDigests)).Methods(http.MethodGet)
+	digestRouter.HandleFunc("/{id}", withContext(handler.getDigest)).Methods(http.MethodGet)
+	digestRouter.HandleFunc("/{id}", withContext(handler.updateDigest)).Methods(http.MethodPut)
+	digestRouter.HandleFunc("/{id}", withContext(handler.deleteDigest)).Methods(http.MethodDelete)
+
+	return handler
+}
+
+func (h *DigestHandler) createDigest(c *Context, w http.ResponseWriter, r *http.Request) {
+	userID := r.Header.Get("Mattermost-User-ID")
+
+	var digest app.Digest
+	if err := json.NewDecoder(r.Body).Decode(&digest); err != nil {
+		h.HandleErrorWithCode(w, c.logger, http.StatusBadRequest, "unable to decode digest", err)
+		return
+	}
+
+	if digest.ID != "" {
+		h.HandleErrorWithCode(w, c.logger, http.StatusBadRequest, "id must be blank", nil)
+		return
+	}
+
+	// Digests are always owned by the user subscribing
+	digest.UserID = userID
+
+	if !h.PermissionsCheck(w, c.logger, h.permissions.DigestManage(userID, digest)) {
+		return
+	}
+
+	id, err := h.digestService.Create(digest)
+	if err != nil {
+		h.HandleErrorWithCode(w, c.logger, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	result := struct {
+		ID string `json:"id"`
+	}{
+		ID: id,
+	}
+	w.Header().Add("Location", makeAPIURL(h.pluginAPI, "digest/%s", id))
+
+	ReturnJSON(w, &result, http.StatusCreated)
+}
+
+func (h *DigestHandler) getDigests(c *Context, w http.ResponseWriter, r *http.Request) {
+	userID := r.Header.Get("Mattermost-User-ID")
+
+	digests, err := h.digestService.GetForUser(userID)
+	if err != nil {
+		h.HandleError(w, c.logger, err)
+		return
+	}
+
+	ReturnJSON(w, digests, http.StatusOK)
+}
+
+func (h *DigestHandler) getDigest(c *Context, w http.ResponseWriter, r *http.Request) {
+	userID := r.Header.Get("Mattermost-User-ID")
+	vars := mux.Vars(r)
+
+	digest, ok := h.getDigestForUser(c, w, userID, vars["id"])
+	if !ok {
+		return
+	}
+
+	ReturnJSON(w, digest, http.StatusOK)
+}
+
+func (h *DigestHandler) updateDigest(c *Context, w http.ResponseWriter, r *http.Request) {
+	userID := r.Header.Get("Mattermost-User-ID")
+	vars := mux.Vars(r)
+
+	var digest app.Digest
+	if err := json.NewDecoder(r.Body).Decode(&digest); err != nil {
+		h.HandleErrorWithCode(w, c.logger, http.StatusBadRequest, "unable to decode digest", err)
+		return
+	}
+
+	existing, ok := h.getDigestForUser(c, w, userID, vars["id"])
+	if !ok {
+		return
+	}
+
+	// Digests can't move between views or users
+	digest.ID = existing.ID
+	digest.ViewID = existing.ViewID
+	digest.UserID = existing.UserID
+
+	if !h.PermissionsCheck(w, c.logger, h.permissions.DigestManage(userID, digest)) {
+		return
+	}
+
+	if err := h.digestService.Update(digest); err != nil {
+		h.HandleErrorWithCode(w, c.logger, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h *DigestHandler) deleteDigest(c *Context, w http.ResponseWriter, r *http.Request) {
+	userID := r.Header.Get("Mattermost-User-ID")
+	vars := mux.Vars(r)
+
+	digest, ok := h.getDigestForUser(c, w, userID, vars["id"])
+	if !ok {
+		return
+	}
+
+	if err := h.digestService.Delete(digest.ID); err != nil {
+		h.HandleError(w, c.logger, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// getDigestForUser gets the digest and checks the user can manage it. Returns false after handling
+// the error if not.
+func (h *DigestHandler) getDigestForUser(c *Context, w http.ResponseWriter, userID string, id string) (app.Digest, bool) {
+	if id == "" {
+		h.HandleErrorWithCode(w, c.logger, http.StatusBadRequest, "id must be set", nil)
+		return app.Digest{}, false
+	}
+
+	digest, err := h.digestService.Get(id)
+	if errors.Is(err, app.ErrNotFound) {
+		h.HandleErrorWithCode(w, c.logger, http.StatusNotFound, "digest not found", err)
+		return app.Digest{}, false
+	} else if err != nil {
+		h.HandleError(w, c.logger, err)
+		return app.Digest{}, false
+	}
+
+	if !h.PermissionsCheck(w, c.logger, h.permissions.DigestManage(userID, digest)) {
+		return app.Digest{}, false
+	}
+
+	return digest, true
+}
@@ -0,0 +1,205 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/jwilander/mattermost-plugin-properties/server/app"
+	"github.com/jwilander/mattermost-plugin-properties/server/config"
+	"github.com/mattermost/mattermost/server/public/pluginapi"
+	"github.com/pkg/errors"
+)
+
+// PatternHandler is the API handler.
+type PatternHandler struct {
+	*ErrorHandler
+	patternService app.PatternService
+	pluginAPI      *pluginapi.Client
+	config         config.Service
+	permissions    *app.PermissionsService
+}
+
+// NewPatternHandler returns a new pattern api handler
+func NewPatternHandler(router *mux.Router, patternService app.PatternService, api *pluginapi.Client, configService config.Service, permissions *app.PermissionsService) *PatternHandler {
+	handler := &PatternHandler{
+		ErrorHandler:   &ErrorHandler{},
+		patternService: patternService,
+		pluginAPI:      api,
+		config:         configService,
+		permissions:    permissions,
+	}
+
+	patternRouter := router.PathPrefix("/pattern").Subrouter()
+
+	patternRouter.HandleFunc("", withContext(handler.createPattern)).Methods(http.MethodPost)
+	patternRouter.HandleFunc("", withContext(handler.getPatterns)).Methods(http.MethodGet)
+	patternRouter.HandleFunc("/{id}", withContext(handler.getPattern)).Methods(http.MethodGet)
+	patternRouter.HandleFunc("/{id}", withContext(handler.updatePattern)).Methods(http.MethodPut)
+	patternRouter.HandleFunc("/{id}", withContext(handler.deletePattern)).Methods(http.MethodDelete)
+
+	return handler
+}
+
+func (h *PatternHandler) createPattern(c *Context, w http.ResponseWriter, r *http.Request) {
+	userID := r.Header.Get("Mattermost-User-ID")
+
+	var pattern app.Pattern
+	if err := json.NewDecoder(r.Body).Decode(&pattern); err != nil {
+		h.HandleErrorWithCode(w, c.logger, http.StatusBadRequest, "unable to decode pattern", err)
+		return
+	}
+
+	pattern.UpdateBy = userID
+
+	if pattern.ID != "" {
+		h.HandleErrorWithCode(w, c.logger, http.StatusBadRequest, "id must be blank", nil)
+		return
+	}
+
+	// Channel patterns always belong to the team of their channel
+	if pattern.ChannelID != "" {
+		channel, err := h.pluginAPI.Channel.Get(pattern.ChannelID)
+		if err != nil {
+			h.HandleErrorWithCode(w, c.logger, http.StatusBadRequest, "unable to get channel", err)
+			return
+		}
+		pattern.TeamID = channel.TeamId
+	}
+
+	if !h.PermissionsCheck(w, c.logger, h.permissions.PatternManage(userID, pattern.TeamID)) {
+		return
+	}
+
+	id, err := h.patternService.Create(pattern)
+	if err != nil {
+		h.HandleErrorWithCode(w, c.logger, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	result := struct {
+		ID string `json:"id"`
+	}{
+		ID: id,
+	}
+	w.Header().Add("Location", makeAPIURL(h.pluginAPI, "pattern/%s", id))
+
+	ReturnJSON(w, &result, http.StatusCreated)
+}
+
+const defaultPatternsPerPage = 100
+
+func (h *PatternHandler) getPatterns(c *Context, w http.ResponseWriter, r *http.Request) {
+	userID := r.Header.Get("Mattermost-User-ID")
+
+	query := r.URL.Query()
+	teamID := query.Get("team_id")
+	page, err := strconv.Atoi(query.Get("page"))
+	if err != nil {
+		page = 0
+	}
+	perPage, err := strconv.Atoi(query.Get("per_page"))
+	if err != nil {
+		perPage = defaultPatternsPerPage
+	}
+
+	if !h.PermissionsCheck(w, c.logger, h.permissions.PatternManage(userID, teamID)) {
+		return
+	}
+
+	patterns, err := h.patternService.GetPatterns(app.PatternFilterOptions{
+		TeamID:    teamID,
+		ChannelID: query.Get("channel_id"),
+		Page:      page,
+		PerPage:   perPage,
+	})
+	if err != nil {
+		h.HandleError(w, c.logger, err)
+		return
+	}
+
+	ReturnJSON(w, patterns, http.StatusOK)
+}
+
+func (h *PatternHandler) getPattern(c *Context, w http.ResponseWriter, r *http.Request) {
+	userID := r.Header.Get("Mattermost-User-ID")
+	vars := mux.Vars(r)
+
+	pattern, ok := h.getPatternForUser(c, w, userID, vars["id"])
+	if !ok {
+		return
+	}
+
+	ReturnJSON(w, pattern, http.StatusOK)
+}
+
+func (h *PatternHandler) updatePattern(c *Context, w http.ResponseWriter, r *http.Request) {
+	userID := r.Header.Get("Mattermost-User-ID")
+	vars := mux.Vars(r)
+
+	var pattern app.Pattern
+	if err := json.NewDecoder(r.Body).Decode(&pattern); err != nil {
+		h.HandleErrorWithCode(w, c.logger, http.StatusBadRequest, "unable to decode pattern", err)
+		return
+	}
+
+	existing, ok := h.getPatternForUser(c, w, userID, vars["id"])
+	if !ok {
+		return
+	}
+
+	// Patterns can't move between teams or channels
+	pattern.ID = existing.ID
+	pattern.TeamID = existing.TeamID
+	pattern.ChannelID = existing.ChannelID
+	pattern.UpdateBy = userID
+
+	if err := h.patternService.Update(pattern); err != nil {
+		h.HandleErrorWithCode(w, c.logger, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h *PatternHandler) deletePattern(c *Context, w http.ResponseWriter, r *http.Request) {
+	userID := r.Header.Get("Mattermost-User-ID")
+	vars := mux.Vars(r)
+
+	pattern, ok := h.getPatternForUser(c, w, userID, vars["id"])
+	if !ok {
+		return
+	}
+
+	if err := h.patternService.Delete(pattern.ID); err != nil {
+		h.HandleError(w, c.logger, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// getPatternForUser gets the pattern and checks the user can manage it. Returns false after handling
+// the error if not.
+func (h *PatternHandler) getPatternForUser(c *Context, w http.ResponseWriter, userID string, id string) (app.Pattern, bool) {
+	if id == "" {
+		h.HandleErrorWithCode(w, c.logger, http.StatusBadRequest, "id must be set", nil)
+		return app.Pattern{}, false
+	}
+
+	pattern, err := h.patternService.Get(id)
+	if errors.Is(err, app.ErrNotFound) {
+		h.HandleErrorWithCode(w, c.logger, http.StatusNotFound, "pattern not found", err)
+		return app.Pattern{}, false
+	} else if err != nil {
+		h.HandleError(w, c.logger, err)
+		return app.Pattern{}, false
+	}
+
+	if !h.PermissionsCheck(w, c.logger, h.permissions.PatternManage(userID, pattern.TeamID)) {
+		return app.Pattern{}, false
+	}
+
+	return pattern, true
+}
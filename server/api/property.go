@@ -37,12 +37,13 @@ func NewPropertyHandler(router *mux.Router, propertyService app.PropertyService,
 	propertyRouter.HandleFunc("/{id}", withContext(handler.updateProperty)).Methods(http.MethodPut)
 	propertyRouter.HandleFunc("/{id}", withContext(handler.deleteProperty)).Methods(http.MethodDelete)
 	propertyRouter.HandleFunc("/object/{objectID}", withContext(handler.getPropertiesForObject)).Methods(http.MethodGet)
+	propertyRouter.HandleFunc("/object/{objectID}/referencing", withContext(handler.getReferencingProperties)).Methods(http.MethodGet)
 
 	return handler
 }
 
 func (h *PropertyHandler) validProperty(w http.ResponseWriter, logger logrus.FieldLogger, property *app.Property) bool {
-	if property.ObjectType != app.PropertyObjectTypePost && property.ObjectType != app.PropertyObjectTypeChannel {
+	if property.ObjectType != app.PropertyObjectTypePost && property.ObjectType != app.PropertyObjectTypeChannel && property.ObjectType != app.PropertyObjectTypeFile {
 		err := errors.New("Invalid object_type")
 		h.HandleErrorWithCode(w, logger, http.StatusBadRequest, err.Error(), err)
 		return false
@@ -60,7 +61,7 @@ func (h *PropertyHandler) validProperty(w http.ResponseWriter, logger logrus.Fie
 		return false
 	}
 
-	//TODO: implement validate value
+	// Values are normalized and validated against the type of their field by the PropertyService
 
 	return true
 }
@@ -86,8 +87,15 @@ func (h *PropertyHandler) createProperty(c *Context, w http.ResponseWriter, r *h
 		return
 	}
 
+	if !h.PermissionsCheck(w, c.logger, h.permissions.ReferencesRead(userID, property)) {
+		return
+	}
+
 	id, err := h.propertyService.Create(property)
-	if err != nil {
+	if errors.Is(err, app.ErrInvalidValue) {
+		h.HandleErrorWithCode(w, c.logger, http.StatusBadRequest, err.Error(), err)
+		return
+	} else if err != nil {
 		h.HandleError(w, c.logger, err)
 		return
 	}
@@ -107,7 +115,7 @@ type UpdatePropertyValue struct {
 }
 
 func (h *PropertyHandler) updateProperty(c *Context, w http.ResponseWriter, r *http.Request) {
-	//userID := r.Header.Get("Mattermost-User-ID")
+	userID := r.Header.Get("Mattermost-User-ID")
 	vars := mux.Vars(r)
 	updateID := vars["id"]
 
@@ -122,15 +130,30 @@ func (h *PropertyHandler) updateProperty(c *Context, w http.ResponseWriter, r *h
 		return
 	}
 
-	//TODO: implement validate value
-
 	//TODO: implement permission check for property update
 	/*if !h.PermissionsCheck(w, c.logger, h.permissions.PropertyUpdate(userID, property)) {
 		return
 	}*/
 
-	err := h.propertyService.UpdateValue(updateID, update.Value)
-	if err != nil {
+	property, err := h.propertyService.Get(updateID)
+	if errors.Is(err, app.ErrNotFound) {
+		h.HandleErrorWithCode(w, c.logger, http.StatusNotFound, "property not found", err)
+		return
+	} else if err != nil {
+		h.HandleError(w, c.logger, err)
+		return
+	}
+
+	property.Value = update.Value
+	if !h.PermissionsCheck(w, c.logger, h.permissions.ReferencesRead(userID, property)) {
+		return
+	}
+
+	err = h.propertyService.UpdateValue(updateID, update.Value)
+	if errors.Is(err, app.ErrInvalidValue) {
+		h.HandleErrorWithCode(w, c.logger, http.StatusBadRequest, err.Error(), err)
+		return
+	} else if err != nil {
 		h.HandleError(w, c.logger, err)
 		return
 	}
@@ -149,6 +172,24 @@ func (h *PropertyHandler) getPropertiesForObject(c *Context, w http.ResponseWrit
 
 	//TODO: implement permission check
 
+	// Posts also pick up the properties inherited from the root of their thread
+	if r.URL.Query().Get("object_type") == app.PropertyObjectTypePost {
+		post, err := h.pluginAPI.Post.GetPost(objectID)
+		if err != nil {
+			h.HandleErrorWithCode(w, c.logger, http.StatusNotFound, "post not found", err)
+			return
+		}
+
+		properties, err := h.propertyService.GetForPost(post)
+		if err != nil {
+			h.HandleError(w, c.logger, err)
+			return
+		}
+
+		ReturnJSON(w, properties, http.StatusOK)
+		return
+	}
+
 	properties, err := h.propertyService.GetForObject(objectID)
 	if err != nil {
 		h.HandleError(w, c.logger, err)
@@ -158,6 +199,27 @@ func (h *PropertyHandler) getPropertiesForObject(c *Context, w http.ResponseWrit
 	ReturnJSON(w, properties, http.StatusOK)
 }
 
+// getReferencingProperties returns the properties of relation fields linking to the object, of the
+// objects the user can read.
+func (h *PropertyHandler) getReferencingProperties(c *Context, w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	objectID := vars["objectID"]
+
+	if objectID == "" {
+		h.HandleErrorWithCode(w, c.logger, http.StatusBadRequest, "invalid object_id parameter", errors.New("objectID cannot be empty"))
+		return
+	}
+
+	properties, err := h.propertyService.GetReferencing(objectID)
+	if err != nil {
+		h.HandleError(w, c.logger, err)
+		return
+	}
+
+	userID := r.Header.Get("Mattermost-User-ID")
+	ReturnJSON(w, h.permissions.FilterPropertiesRead(userID, properties), http.StatusOK)
+}
+
 func (h *PropertyHandler) deleteProperty(c *Context, w http.ResponseWriter, r *http.Request) {
 	//userID := r.Header.Get("Mattermost-User-ID")
 	vars := mux.Vars(r)
@@ -168,8 +230,6 @@ func (h *PropertyHandler) deleteProperty(c *Context, w http.ResponseWriter, r *h
 		return
 	}
 
-	//TODO: implement validate value
-
 	//TODO: implement permission check for property update
 	/*if !h.PermissionsCheck(w, c.logger, h.permissions.PropertyDelete(userID, property)) {
 		return
@@ -42,39 +42,13 @@ func NewPropertyFieldHandler(router *mux.Router, propertyFieldService app.Proper
 }
 
 func (h *PropertyFieldHandler) validPropertyField(w http.ResponseWriter, logger logrus.FieldLogger, propertyField *app.PropertyField) bool {
-	if propertyField.Type != app.PropertyFieldTypeText && propertyField.Type != app.PropertyFieldTypeSelect && propertyField.Type != app.PropertyFieldTypeUser {
-		err := errors.New("Invalid type")
-		h.HandleErrorWithCode(w, logger, http.StatusBadRequest, err.Error(), err)
-		return false
-	}
-
+	// The type of the field is validated by the service
 	if propertyField.Name == "" {
 		err := errors.New("Invalid name")
 		h.HandleErrorWithCode(w, logger, http.StatusBadRequest, err.Error(), err)
 		return false
 	}
 
-	if len(propertyField.Values) > 0 {
-		if propertyField.Type == app.PropertyFieldTypeText {
-			err := errors.New("Invalid values: text type has no values")
-			h.HandleErrorWithCode(w, logger, http.StatusBadRequest, err.Error(), err)
-			return false
-		} else if propertyField.Type == app.PropertyFieldTypeUser {
-			err := errors.New("Invalid values: user type has no values")
-			h.HandleErrorWithCode(w, logger, http.StatusBadRequest, err.Error(), err)
-			return false
-		} else if propertyField.Type == app.PropertyFieldTypeSelect {
-			for _, v := range propertyField.Values {
-				_, ok := v.(string)
-				if !ok {
-					err := errors.New("Invalid values: select type must have string values")
-					h.HandleErrorWithCode(w, logger, http.StatusBadRequest, err.Error(), err)
-					return false
-				}
-			}
-		}
-	}
-
 	return true
 }
 
@@ -104,7 +78,10 @@ func (h *PropertyFieldHandler) createPropertyField(c *Context, w http.ResponseWr
 	}*/
 
 	id, err := h.propertyFieldService.Create(propertyField)
-	if err != nil {
+	if errors.Is(err, app.ErrInvalidField) {
+		h.HandleErrorWithCode(w, c.logger, http.StatusBadRequest, err.Error(), err)
+		return
+	} else if err != nil {
 		h.HandleError(w, c.logger, err)
 		return
 	}
@@ -169,7 +146,13 @@ func (h *PropertyFieldHandler) updatePropertyField(c *Context, w http.ResponseWr
 	}*/
 
 	err := h.propertyFieldService.Update(propertyField)
-	if err != nil {
+	if errors.Is(err, app.ErrInvalidField) {
+		h.HandleErrorWithCode(w, c.logger, http.StatusBadRequest, err.Error(), err)
+		return
+	} else if errors.Is(err, app.ErrNotFound) {
+		h.HandleErrorWithCode(w, c.logger, http.StatusNotFound, "property field not found", err)
+		return
+	} else if err != nil {
 		h.HandleError(w, c.logger, err)
 		return
 	}
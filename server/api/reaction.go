@@ -0,0 +1,194 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/jwilander/mattermost-plugin-properties/server/app"
+	"github.com/jwilander/mattermost-plugin-properties/server/config"
+	"github.com/mattermost/mattermost/server/public/pluginapi"
+	"github.com/pkg/errors"
+)
+
+// ReactionMappingHandler is the API handler.
+type ReactionMappingHandler struct {
+	*ErrorHandler
+	reactionMappingService app.ReactionMappingService
+	pluginAPI              *pluginapi.Client
+	config                 config.Service
+	permissions            *app.PermissionsService
+}
+
+// NewReactionMappingHandler returns a new reaction mapping api handler
+func NewReactionMappingHandler(router *mux.Router, reactionMappingService app.ReactionMappingService, api *pluginapi.Client, configService config.Service, permissions *app.PermissionsService) *ReactionMappingHandler {
+	handler := &ReactionMappingHandler{
+		ErrorHandler:           &ErrorHandler{},
+		reactionMappingService: reactionMappingService,
+		pluginAPI:              api,
+		config:                 configService,
+		permissions:            permissions,
+	}
+
+	mappingRouter := router.PathPrefix("/reaction_mapping").Subrouter()
+
+	mappingRouter.HandleFunc("", withContext(handler.createMapping)).Methods(http.MethodPost)
+	mappingRouter.HandleFunc("", withContext(handler.getMappings)).Methods(http.MethodGet)
+	mappingRouter.HandleFunc("/{id}", withContext(handler.getMapping)).Methods(http.MethodGet)
+	mappingRouter.HandleFunc("/{id}", withContext(handler.updateMapping)).Methods(http.MethodPut)
+	mappingRouter.HandleFunc("/{id}", withContext(handler.deleteMapping)).Methods(http.MethodDelete)
+
+	return handler
+}
+
+func (h *ReactionMappingHandler) createMapping(c *Context, w http.ResponseWriter, r *http.Request) {
+	userID := r.Header.Get("Mattermost-User-ID")
+
+	var mapping app.ReactionMapping
+	if err := json.NewDecoder(r.Body).Decode(&mapping); err != nil {
+		h.HandleErrorWithCode(w, c.logger, http.StatusBadRequest, "unable to decode reaction mapping", err)
+		return
+	}
+
+	mapping.UpdateBy = userID
+
+	if mapping.ID != "" {
+		h.HandleErrorWithCode(w, c.logger, http.StatusBadRequest, "id must be blank", nil)
+		return
+	}
+
+	if mapping.ChannelID == "" {
+		h.HandleErrorWithCode(w, c.logger, http.StatusBadRequest, "channel_id must be set", nil)
+		return
+	}
+
+	if !h.PermissionsCheck(w, c.logger, h.permissions.ReactionMappingManage(userID, mapping.ChannelID)) {
+		return
+	}
+
+	channel, err := h.pluginAPI.Channel.Get(mapping.ChannelID)
+	if err != nil {
+		h.HandleErrorWithCode(w, c.logger, http.StatusBadRequest, "unable to get channel", err)
+		return
+	}
+	mapping.TeamID = channel.TeamId
+
+	id, err := h.reactionMappingService.Create(mapping)
+	if err != nil {
+		h.HandleErrorWithCode(w, c.logger, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	result := struct {
+		ID string `json:"id"`
+	}{
+		ID: id,
+	}
+	w.Header().Add("Location", makeAPIURL(h.pluginAPI, "reaction_mapping/%s", id))
+
+	ReturnJSON(w, &result, http.StatusCreated)
+}
+
+func (h *ReactionMappingHandler) getMappings(c *Context, w http.ResponseWriter, r *http.Request) {
+	userID := r.Header.Get("Mattermost-User-ID")
+
+	channelID := r.URL.Query().Get("channel_id")
+	if channelID == "" {
+		h.HandleErrorWithCode(w, c.logger, http.StatusBadRequest, "channel_id must be set", nil)
+		return
+	}
+
+	if !h.PermissionsCheck(w, c.logger, h.permissions.ReactionMappingManage(userID, channelID)) {
+		return
+	}
+
+	mappings, err := h.reactionMappingService.GetForChannel(channelID)
+	if err != nil {
+		h.HandleError(w, c.logger, err)
+		return
+	}
+
+	ReturnJSON(w, mappings, http.StatusOK)
+}
+
+func (h *ReactionMappingHandler) getMapping(c *Context, w http.ResponseWriter, r *http.Request) {
+	userID := r.Header.Get("Mattermost-User-ID")
+	vars := mux.Vars(r)
+
+	mapping, ok := h.getMappingForUser(c, w, userID, vars["id"])
+	if !ok {
+		return
+	}
+
+	ReturnJSON(w, mapping, http.StatusOK)
+}
+
+func (h *ReactionMappingHandler) updateMapping(c *Context, w http.ResponseWriter, r *http.Request) {
+	userID := r.Header.Get("Mattermost-User-ID")
+	vars := mux.Vars(r)
+
+	var mapping app.ReactionMapping
+	if err := json.NewDecoder(r.Body).Decode(&mapping); err != nil {
+		h.HandleErrorWithCode(w, c.logger, http.StatusBadRequest, "unable to decode reaction mapping", err)
+		return
+	}
+
+	existing, ok := h.getMappingForUser(c, w, userID, vars["id"])
+	if !ok {
+		return
+	}
+
+	// Mappings can't move between channels
+	mapping.ID = existing.ID
+	mapping.TeamID = existing.TeamID
+	mapping.ChannelID = existing.ChannelID
+	mapping.UpdateBy = userID
+
+	if err := h.reactionMappingService.Update(mapping); err != nil {
+		h.HandleErrorWithCode(w, c.logger, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h *ReactionMappingHandler) deleteMapping(c *Context, w http.ResponseWriter, r *http.Request) {
+	userID := r.Header.Get("Mattermost-User-ID")
+	vars := mux.Vars(r)
+
+	mapping, ok := h.getMappingForUser(c, w, userID, vars["id"])
+	if !ok {
+		return
+	}
+
+	if err := h.reactionMappingService.Delete(mapping.ID); err != nil {
+		h.HandleError(w, c.logger, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// getMappingForUser gets the reaction mapping and checks the user can manage it. Returns false
+// after handling the error if not.
+func (h *ReactionMappingHandler) getMappingForUser(c *Context, w http.ResponseWriter, userID string, id string) (app.ReactionMapping, bool) {
+	if id == "" {
+		h.HandleErrorWithCode(w, c.logger, http.StatusBadRequest, "id must be set", nil)
+		return app.ReactionMapping{}, false
+	}
+
+	mapping, err := h.reactionMappingService.Get(id)
+	if errors.Is(err, app.ErrNotFound) {
+		h.HandleErrorWithCode(w, c.logger, http.StatusNotFound, "reaction mapping not found", err)
+		return app.ReactionMapping{}, false
+	} else if err != nil {
+		h.HandleError(w, c.logger, err)
+		return app.ReactionMapping{}, false
+	}
+
+	if !h.PermissionsCheck(w, c.logger, h.permissions.ReactionMappingManage(userID, mapping.ChannelID)) {
+		return app.ReactionMapping{}, false
+	}
+
+	return mapping, true
+}
@@ -0,0 +1,194 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/jwilander/mattermost-plugin-properties/server/app"
+	"github.com/jwilander/mattermost-plugin-properties/server/config"
+	"github.com/mattermost/mattermost/server/public/pluginapi"
+	"github.com/pkg/errors"
+)
+
+// ReminderHandler is the API handler.
+type ReminderHandler struct {
+	*ErrorHandler
+	reminderService app.ReminderService
+	pluginAPI       *pluginapi.Client
+	config          config.Service
+	permissions     *app.PermissionsService
+}
+
+// NewReminderHandler returns a new reminder api handler
+func NewReminderHandler(router *mux.Router, reminderService app.ReminderService, api *pluginapi.Client, configService config.Service, permissions *app.PermissionsService) *ReminderHandler {
+	handler := &ReminderHandler{
+		ErrorHandler:    &ErrorHandler{},
+		reminderService: reminderService,
+		pluginAPI:       api,
+		config:          configService,
+		permissions:     permissions,
+	}
+
+	reminderRouter := router.PathPrefix("/reminder").Subrouter()
+
+	reminderRouter.HandleFunc("", withContext(handler.createReminder)).Methods(http.MethodPost)
+	reminderRouter.HandleFunc("", withContext(handler.getReminders)).Methods(http.MethodGet)
+	reminderRouter.HandleFunc("/{id}", withContext(handler.getReminder)).Methods(http.MethodGet)
+	reminderRouter.HandleFunc("/{id}", withContext(handler.updateReminder)).Methods(http.MethodPut)
+	reminderRouter.HandleFunc("/{id}", withContext(handler.deleteReminder)).Methods(http.MethodDelete)
+
+	return handler
+}
+
+func (h *ReminderHandler) createReminder(c *Context, w http.ResponseWriter, r *http.Request) {
+	userID := r.Header.Get("Mattermost-User-ID")
+
+	var reminder app.Reminder
+	if err := json.NewDecoder(r.Body).Decode(&reminder); err != nil {
+		h.HandleErrorWithCode(w, c.logger, http.StatusBadRequest, "unable to decode reminder", err)
+		return
+	}
+
+	reminder.UpdateBy = userID
+
+	if reminder.ID != "" {
+		h.HandleErrorWithCode(w, c.logger, http.StatusBadRequest, "id must be blank", nil)
+		return
+	}
+
+	if !h.PermissionsCheck(w, c.logger, h.permissions.ReminderManage(userID, reminder.TeamID)) {
+		return
+	}
+
+	id, err := h.reminderService.Create(reminder)
+	if err != nil {
+		h.HandleErrorWithCode(w, c.logger, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	result := struct {
+		ID string `json:"id"`
+	}{
+		ID: id,
+	}
+	w.Header().Add("Location", makeAPIURL(h.pluginAPI, "reminder/%s", id))
+
+	ReturnJSON(w, &result, http.StatusCreated)
+}
+
+const defaultRemindersPerPage = 100
+
+func (h *ReminderHandler) getReminders(c *Context, w http.ResponseWriter, r *http.Request) {
+	userID := r.Header.Get("Mattermost-User-ID")
+
+	query := r.URL.Query()
+	teamID := query.Get("team_id")
+	page, err := strconv.Atoi(query.Get("page"))
+	if err != nil {
+		page = 0
+	}
+	perPage, err := strconv.Atoi(query.Get("per_page"))
+	if err != nil {
+		perPage = defaultRemindersPerPage
+	}
+
+	if !h.PermissionsCheck(w, c.logger, h.permissions.ReminderManage(userID, teamID)) {
+		return
+	}
+
+	reminders, err := h.reminderService.GetReminders(app.ReminderFilterOptions{
+		TeamID:          teamID,
+		PropertyFieldID: query.Get("property_field_id"),
+		Page:            page,
+		PerPage:         perPage,
+	})
+	if err != nil {
+		h.HandleError(w, c.logger, err)
+		return
+	}
+
+	ReturnJSON(w, reminders, http.StatusOK)
+}
+
+func (h *ReminderHandler) getReminder(c *Context, w http.ResponseWriter, r *http.Request) {
+	userID := r.Header.Get("Mattermost-User-ID")
+	vars := mux.Vars(r)
+
+	reminder, ok := h.getReminderForUser(c, w, userID, vars["id"])
+	if !ok {
+		return
+	}
+
+	ReturnJSON(w, reminder, http.StatusOK)
+}
+
+func (h *ReminderHandler) updateReminder(c *Context, w http.ResponseWriter, r *http.Request) {
+	userID := r.Header.Get("Mattermost-User-ID")
+	vars := mux.Vars(r)
+
+	var reminder app.Reminder
+	if err := json.NewDecoder(r.Body).Decode(&reminder); err != nil {
+		h.HandleErrorWithCode(w, c.logger, http.StatusBadRequest, "unable to decode reminder", err)
+		return
+	}
+
+	existing, ok := h.getReminderForUser(c, w, userID, vars["id"])
+	if !ok {
+		return
+	}
+
+	// Reminders can't move between teams
+	reminder.ID = existing.ID
+	reminder.TeamID = existing.TeamID
+	reminder.UpdateBy = userID
+
+	if err := h.reminderService.Update(reminder); err != nil {
+		h.HandleErrorWithCode(w, c.logger, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h *ReminderHandler) deleteReminder(c *Context, w http.ResponseWriter, r *http.Request) {
+	userID := r.Header.Get("Mattermost-User-ID")
+	vars := mux.Vars(r)
+
+	reminder, ok := h.getReminderForUser(c, w, userID, vars["id"])
+	if !ok {
+		return
+	}
+
+	if err := h.reminderService.Delete(reminder.ID); err != nil {
+		h.HandleError(w, c.logger, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// getReminderForUser gets the reminder and checks the user can manage it. Returns false after handling
+// the error if not.
+func (h *ReminderHandler) getReminderForUser(c *Context, w http.ResponseWriter, userID string, id string) (app.Reminder, bool) {
+	if id == "" {
+		h.HandleErrorWithCode(w, c.logger, http.StatusBadRequest, "id must be set", nil)
+		return app.Reminder{}, false
+	}
+
+	reminder, err := h.reminderService.Get(id)
+	if errors.Is(err, app.ErrNotFound) {
+		h.HandleErrorWithCode(w, c.logger, http.StatusNotFound, "reminder not found", err)
+		return app.Reminder{}, false
+	} else if err != nil {
+		h.HandleError(w, c.logger, err)
+		return app.Reminder{}, false
+	}
+
+	if !h.PermissionsCheck(w, c.logger, h.permissions.ReminderManage(userID, reminder.TeamID)) {
+		return app.Reminder{}, false
+	}
+
+	return reminder, true
+}
@@ -0,0 +1,194 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/jwilander/mattermost-plugin-properties/server/app"
+	"github.com/jwilander/mattermost-plugin-properties/server/config"
+	"github.com/mattermost/mattermost/server/public/pluginapi"
+	"github.com/pkg/errors"
+)
+
+// RuleHandler is the API handler.
+type RuleHandler struct {
+	*ErrorHandler
+	ruleService app.RuleService
+	pluginAPI   *pluginapi.Client
+	config      config.Service
+	permissions *app.PermissionsService
+}
+
+// NewRuleHandler returns a new rule api handler
+func NewRuleHandler(router *mux.Router, ruleService app.RuleService, api *pluginapi.Client, configService config.Service, permissions *app.PermissionsService) *RuleHandler {
+	handler := &RuleHandler{
+		ErrorHandler: &ErrorHandler{},
+		ruleService:  ruleService,
+		pluginAPI:    api,
+		config:       configService,
+		permissions:  permissions,
+	}
+
+	ruleRouter := router.PathPrefix("/rule").Subrouter()
+
+	ruleRouter.HandleFunc("", withContext(handler.createRule)).Methods(http.MethodPost)
+	ruleRouter.HandleFunc("", withContext(handler.getRules)).Methods(http.MethodGet)
+	ruleRouter.HandleFunc("/{id}", withContext(handler.getRule)).Methods(http.MethodGet)
+	ruleRouter.HandleFunc("/{id}", withContext(handler.updateRule)).Methods(http.MethodPut)
+	ruleRouter.HandleFunc("/{id}", withContext(handler.deleteRule)).Methods(http.MethodDelete)
+
+	return handler
+}
+
+func (h *RuleHandler) createRule(c *Context, w http.ResponseWriter, r *http.Request) {
+	userID := r.Header.Get("Mattermost-User-ID")
+
+	var rule app.Rule
+	if err := json.NewDecoder(r.Body).Decode(&rule); err != nil {
+		h.HandleErrorWithCode(w, c.logger, http.StatusBadRequest, "unable to decode rule", err)
+		return
+	}
+
+	rule.UpdateBy = userID
+
+	if rule.ID != "" {
+		h.HandleErrorWithCode(w, c.logger, http.StatusBadRequest, "id must be blank", nil)
+		return
+	}
+
+	if !h.PermissionsCheck(w, c.logger, h.permissions.RuleManage(userID, rule.TeamID)) {
+		return
+	}
+
+	id, err := h.ruleService.Create(rule)
+	if err != nil {
+		h.HandleErrorWithCode(w, c.logger, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	result := struct {
+		ID string `json:"id"`
+	}{
+		ID: id,
+	}
+	w.Header().Add("Location", makeAPIURL(h.pluginAPI, "rule/%s", id))
+
+	ReturnJSON(w, &result, http.StatusCreated)
+}
+
+const defaultRulesPerPage = 100
+
+func (h *RuleHandler) getRules(c *Context, w http.ResponseWriter, r *http.Request) {
+	userID := r.Header.Get("Mattermost-User-ID")
+
+	query := r.URL.Query()
+	teamID := query.Get("team_id")
+	page, err := strconv.Atoi(query.Get("page"))
+	if err != nil {
+		page = 0
+	}
+	perPage, err := strconv.Atoi(query.Get("per_page"))
+	if err != nil {
+		perPage = defaultRulesPerPage
+	}
+
+	if !h.PermissionsCheck(w, c.logger, h.permissions.RuleManage(userID, teamID)) {
+		return
+	}
+
+	rules, err := h.ruleService.GetRules(app.RuleFilterOptions{
+		TeamID:          teamID,
+		PropertyFieldID: query.Get("property_field_id"),
+		Page:            page,
+		PerPage:         perPage,
+	})
+	if err != nil {
+		h.HandleError(w, c.logger, err)
+		return
+	}
+
+	ReturnJSON(w, rules, http.StatusOK)
+}
+
+func (h *RuleHandler) getRule(c *Context, w http.ResponseWriter, r *http.Request) {
+	userID := r.Header.Get("Mattermost-User-ID")
+	vars := mux.Vars(r)
+
+	rule, ok := h.getRuleForUser(c, w, userID, vars["id"])
+	if !ok {
+		return
+	}
+
+	ReturnJSON(w, rule, http.StatusOK)
+}
+
+func (h *RuleHandler) updateRule(c *Context, w http.ResponseWriter, r *http.Request) {
+	userID := r.Header.Get("Mattermost-User-ID")
+	vars := mux.Vars(r)
+
+	var rule app.Rule
+	if err := json.NewDecoder(r.Body).Decode(&rule); err != nil {
+		h.HandleErrorWithCode(w, c.logger, http.StatusBadRequest, "unable to decode rule", err)
+		return
+	}
+
+	existing, ok := h.getRuleForUser(c, w, userID, vars["id"])
+	if !ok {
+		return
+	}
+
+	// Rules can't move between teams
+	rule.ID = existing.ID
+	rule.TeamID = existing.TeamID
+	rule.UpdateBy = userID
+
+	if err := h.ruleService.Update(rule); err != nil {
+		h.HandleErrorWithCode(w, c.logger, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h *RuleHandler) deleteRule(c *Context, w http.ResponseWriter, r *http.Request) {
+	userID := r.Header.Get("Mattermost-User-ID")
+	vars := mux.Vars(r)
+
+	rule, ok := h.getRuleForUser(c, w, userID, vars["id"])
+	if !ok {
+		return
+	}
+
+	if err := h.ruleService.Delete(rule.ID); err != nil {
+		h.HandleError(w, c.logger, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// getRuleForUser gets the rule and checks the user can manage it. Returns false after handling
+// the error if not.
+func (h *RuleHandler) getRuleForUser(c *Context, w http.ResponseWriter, userID string, id string) (app.Rule, bool) {
+	if id == "" {
+		h.HandleErrorWithCode(w, c.logger, http.StatusBadRequest, "id must be set", nil)
+		return app.Rule{}, false
+	}
+
+	rule, err := h.ruleService.Get(id)
+	if errors.Is(err, app.ErrNotFound) {
+		h.HandleErrorWithCode(w, c.logger, http.StatusNotFound, "rule not found", err)
+		return app.Rule{}, false
+	} else if err != nil {
+		h.HandleError(w, c.logger, err)
+		return app.Rule{}, false
+	}
+
+	if !h.PermissionsCheck(w, c.logger, h.permissions.RuleManage(userID, rule.TeamID)) {
+		return app.Rule{}, false
+	}
+
+	return rule, true
+}
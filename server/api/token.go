@@ -0,0 +1,163 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/jwilander/mattermost-plugin-properties/server/app"
+	"github.com/jwilander/mattermost-plugin-properties/server/config"
+	"github.com/mattermost/mattermost/server/public/pluginapi"
+	"github.com/pkg/errors"
+)
+
+// maxTokenWrites is the maximum number of writes accepted in a single batch.
+const maxTokenWrites = 200
+
+// TokenHandler is the API handler.
+type TokenHandler struct {
+	*ErrorHandler
+	tokenService app.TokenService
+	pluginAPI    *pluginapi.Client
+	config       config.Service
+	permissions  *app.PermissionsService
+}
+
+// NewTokenHandler returns a new token api handler, serving token management to users and
+// property writes to external tools.
+func NewTokenHandler(router *mux.Router, externalRouter *mux.Router, tokenService app.TokenService, api *pluginapi.Client, configService config.Service, permissions *app.PermissionsService) *TokenHandler {
+	handler := &TokenHandler{
+		ErrorHandler: &ErrorHandler{},
+		tokenService: tokenService,
+		pluginAPI:    api,
+		config:       configService,
+		permissions:  permissions,
+	}
+
+	tokenRouter := router.PathPrefix("/token").Subrouter()
+
+	tokenRouter.HandleFunc("", withContext(handler.createToken)).Methods(http.MethodPost)
+	tokenRouter.HandleFunc("", withContext(handler.getTokens)).Methods(http.MethodGet)
+	tokenRouter.HandleFunc("/{id}", withContext(handler.deleteToken)).Methods(http.MethodDelete)
+
+	propertiesRouter := externalRouter.PathPrefix("/properties").Subrouter()
+
+	propertiesRouter.HandleFunc("", withContext(handler.writeProperties)).Methods(http.MethodPost)
+
+	return handler
+}
+
+func (h *TokenHandler) createToken(c *Context, w http.ResponseWriter, r *http.Request) {
+	userID := r.Header.Get("Mattermost-User-ID")
+
+	var token app.Token
+	if err := json.NewDecoder(r.Body).Decode(&token); err != nil {
+		h.HandleErrorWithCode(w, c.logger, http.StatusBadRequest, "unable to decode token", err)
+		return
+	}
+
+	if token.ID != "" {
+		h.HandleErrorWithCode(w, c.logger, http.StatusBadRequest, "id must be blank", nil)
+		return
+	}
+
+	token.CreateBy = userID
+	token.LastUsedAt = 0
+
+	if !h.PermissionsCheck(w, c.logger, h.permissions.TokenManage(userID, token.TeamID)) {
+		return
+	}
+
+	id, secret, err := h.tokenService.Create(token)
+	if err != nil {
+		h.HandleErrorWithCode(w, c.logger, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	// The secret can't be retrieved again after this response
+	result := struct {
+		ID    string `json:"id"`
+		Token string `json:"token"`
+	}{
+		ID:    id,
+		Token: secret,
+	}
+
+	ReturnJSON(w, &result, http.StatusCreated)
+}
+
+func (h *TokenHandler) getTokens(c *Context, w http.ResponseWriter, r *http.Request) {
+	userID := r.Header.Get("Mattermost-User-ID")
+
+	teamID := r.URL.Query().Get("team_id")
+	if teamID == "" {
+		h.HandleErrorWithCode(w, c.logger, http.StatusBadRequest, "team_id must be set", nil)
+		return
+	}
+
+	if !h.PermissionsCheck(w, c.logger, h.permissions.TokenManage(userID, teamID)) {
+		return
+	}
+
+	tokens, err := h.tokenService.GetForTeam(teamID)
+	if err != nil {
+		h.HandleError(w, c.logger, err)
+		return
+	}
+
+	ReturnJSON(w, tokens, http.StatusOK)
+}
+
+func (h *TokenHandler) deleteToken(c *Context, w http.ResponseWriter, r *http.Request) {
+	userID := r.Header.Get("Mattermost-User-ID")
+	vars := mux.Vars(r)
+
+	token, err := h.tokenService.Get(vars["id"])
+	if errors.Is(err, app.ErrNotFound) {
+		h.HandleErrorWithCode(w, c.logger, http.StatusNotFound, "token not found", err)
+		return
+	} else if err != nil {
+		h.HandleError(w, c.logger, err)
+		return
+	}
+
+	if !h.PermissionsCheck(w, c.logger, h.permissions.TokenManage(userID, token.TeamID)) {
+		return
+	}
+
+	if err = h.tokenService.Delete(token.ID); err != nil {
+		h.HandleError(w, c.logger, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// writeProperties applies a batch of writes, authenticated by the token in the Authorization
+// header: `Authorization: Bearer <token>`.
+func (h *TokenHandler) writeProperties(c *Context, w http.ResponseWriter, r *http.Request) {
+	secret := strings.TrimSpace(strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "))
+
+	token, err := h.tokenService.Authenticate(secret)
+	if errors.Is(err, app.ErrNoPermissions) {
+		h.HandleErrorWithCode(w, c.logger, http.StatusUnauthorized, "Not authorized", err)
+		return
+	} else if err != nil {
+		h.HandleError(w, c.logger, err)
+		return
+	}
+
+	var writes []app.TokenWrite
+	if err = json.NewDecoder(r.Body).Decode(&writes); err != nil {
+		h.HandleErrorWithCode(w, c.logger, http.StatusBadRequest, "unable to decode writes", err)
+		return
+	}
+
+	if len(writes) > maxTokenWrites {
+		h.HandleErrorWithCode(w, c.logger, http.StatusBadRequest, "too many writes in batch", nil)
+		return
+	}
+
+	ReturnJSON(w, h.tokenService.Write(token, writes), http.StatusOK)
+}
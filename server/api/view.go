@@ -4,6 +4,8 @@ import (
 	"encoding/json"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/jwilander/mattermost-plugin-properties/server/app"
@@ -15,20 +17,22 @@ import (
 // ViewHandler is the API handler.
 type ViewHandler struct {
 	*ErrorHandler
-	viewService app.ViewService
-	pluginAPI   *pluginapi.Client
-	config      config.Service
-	permissions *app.PermissionsService
+	viewService      app.ViewService
+	viewWatchService app.ViewWatchService
+	pluginAPI        *pluginapi.Client
+	config           config.Service
+	permissions      *app.PermissionsService
 }
 
 // NewViewHandler returns a new view api handler
-func NewViewHandler(router *mux.Router, viewService app.ViewService, api *pluginapi.Client, configService config.Service, permissions *app.PermissionsService) *ViewHandler {
+func NewViewHandler(router *mux.Router, viewService app.ViewService, viewWatchService app.ViewWatchService, api *pluginapi.Client, configService config.Service, permissions *app.PermissionsService) *ViewHandler {
 	handler := &ViewHandler{
-		ErrorHandler: &ErrorHandler{},
-		viewService:  viewService,
-		pluginAPI:    api,
-		config:       configService,
-		permissions:  permissions,
+		ErrorHandler:     &ErrorHandler{},
+		viewService:      viewService,
+		viewWatchService: viewWatchService,
+		pluginAPI:        api,
+		config:           configService,
+		permissions:      permissions,
 	}
 
 	viewRouter := router.PathPrefix("/view").Subrouter()
@@ -36,7 +40,17 @@ func NewViewHandler(router *mux.Router, viewService app.ViewService, api *plugin
 	viewRouter.HandleFunc("", withContext(handler.createView)).Methods(http.MethodPost)
 	viewRouter.HandleFunc("/{id}", withContext(handler.patchView)).Methods(http.MethodPatch)
 	viewRouter.HandleFunc("/{id}/query", withContext(handler.queryView)).Methods(http.MethodGet)
+	viewRouter.HandleFunc("/{id}/board", withContext(handler.getBoard)).Methods(http.MethodGet)
+	viewRouter.HandleFunc("/{id}/move", withContext(handler.moveObject)).Methods(http.MethodPost)
+	viewRouter.HandleFunc("/{id}/aggregate", withContext(handler.aggregateView)).Methods(http.MethodGet)
+	viewRouter.HandleFunc("/{id}/chart", withContext(handler.getChart)).Methods(http.MethodGet)
+	viewRouter.HandleFunc("/{id}/calendar", withContext(handler.getCalendar)).Methods(http.MethodGet)
+	viewRouter.HandleFunc("/{id}/timeline", withContext(handler.getTimeline)).Methods(http.MethodGet)
 	viewRouter.HandleFunc("/user/{id}", withContext(handler.getForUser)).Methods(http.MethodGet)
+	viewRouter.HandleFunc("/watches", withContext(handler.getWatches)).Methods(http.MethodGet)
+	viewRouter.HandleFunc("/{id}/watch", withContext(handler.watchView)).Methods(http.MethodPost)
+	viewRouter.HandleFunc("/{id}/watch", withContext(handler.unwatchView)).Methods(http.MethodDelete)
+	viewRouter.HandleFunc("/{id}", withContext(handler.getView)).Methods(http.MethodGet)
 
 	return handler
 }
@@ -75,6 +89,24 @@ func (h *ViewHandler) createView(c *Context, w http.ResponseWriter, r *http.Requ
 	ReturnJSON(w, &result, http.StatusCreated)
 }
 
+func (h *ViewHandler) getView(c *Context, w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	//TODO: implement permission check
+
+	view, err := h.viewService.Get(id)
+	if errors.Is(err, app.ErrNotFound) {
+		h.HandleErrorWithCode(w, c.logger, http.StatusNotFound, "view not found", err)
+		return
+	} else if err != nil {
+		h.HandleError(w, c.logger, err)
+		return
+	}
+
+	ReturnJSON(w, view, http.StatusOK)
+}
+
 const defaultQueryPerPage = 60
 
 func (h *ViewHandler) queryView(c *Context, w http.ResponseWriter, r *http.Request) {
@@ -109,6 +141,206 @@ func (h *ViewHandler) queryView(c *Context, w http.ResponseWriter, r *http.Reque
 	ReturnJSON(w, objects, http.StatusOK)
 }
 
+// getBoard returns a page of the objects of a kanban view bucketed by column and swimlane.
+func (h *ViewHandler) getBoard(c *Context, w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	query := r.URL.Query()
+	page, err := strconv.Atoi(query.Get("page"))
+	if err != nil {
+		page = 0
+	}
+	perPage, err := strconv.Atoi(query.Get("per_page"))
+	if err != nil {
+		perPage = defaultQueryPerPage
+	}
+
+	//TODO: implement permission check
+
+	board, err := h.viewService.GetBoard(id, page, perPage)
+	if errors.Is(err, app.ErrNotFound) {
+		h.HandleErrorWithCode(w, c.logger, http.StatusNotFound, "view or field not found", err)
+		return
+	} else if err != nil {
+		h.HandleErrorWithCode(w, c.logger, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	ReturnJSON(w, board, http.StatusOK)
+}
+
+// moveObject moves an object of a kanban view to a column and a position within it, returning
+// the updated view. Moves based on an outdated view fail with a conflict.
+func (h *ViewHandler) moveObject(c *Context, w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	var move app.Move
+	if err := json.NewDecoder(r.Body).Decode(&move); err != nil {
+		h.HandleErrorWithCode(w, c.logger, http.StatusBadRequest, "unable to decode move", err)
+		return
+	}
+
+	existing, err := h.viewService.Get(id)
+	if errors.Is(err, app.ErrNotFound) {
+		h.HandleErrorWithCode(w, c.logger, http.StatusNotFound, "view not found", err)
+		return
+	} else if err != nil {
+		h.HandleError(w, c.logger, err)
+		return
+	}
+
+	userID := r.Header.Get("Mattermost-User-ID")
+	if !h.PermissionsCheck(w, c.logger, h.permissions.ViewRead(userID, existing)) {
+		return
+	}
+
+	objectType := move.ObjectType
+	if objectType == "" {
+		objectType = app.PropertyObjectTypePost
+	}
+	if !h.PermissionsCheck(w, c.logger, h.permissions.PropertyCreate(userID, app.Property{ObjectID: move.ObjectID, ObjectType: objectType})) {
+		return
+	}
+
+	if move.Force && !h.PermissionsCheck(w, c.logger, h.permissions.WIPLimitOverride(userID, existing.Query.TeamID)) {
+		return
+	}
+
+	view, err := h.viewService.Move(id, move)
+	if errors.Is(err, app.ErrNotFound) {
+		h.HandleErrorWithCode(w, c.logger, http.StatusNotFound, "view or field not found", err)
+		return
+	} else if errors.Is(err, app.ErrConflict) {
+		h.HandleErrorWithCode(w, c.logger, http.StatusConflict, "view was changed by someone else, reload and try again", err)
+		return
+	} else if errors.Is(err, app.ErrLimitExceeded) {
+		h.HandleErrorWithCode(w, c.logger, http.StatusUnprocessableEntity, err.Error(), err)
+		return
+	} else if err != nil {
+		h.HandleErrorWithCode(w, c.logger, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	ReturnJSON(w, view, http.StatusOK)
+}
+
+// aggregateView groups the objects of the view by the comma separated fields of group_by, or by
+// the group by field of the view, summarizing the number fields listed in number_fields.
+func (h *ViewHandler) aggregateView(c *Context, w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	query := r.URL.Query()
+	options := app.AggregateOptions{
+		GroupByFieldIDs: splitList(query.Get("group_by")),
+		NumberFieldIDs:  splitList(query.Get("number_fields")),
+	}
+
+	//TODO: implement permission check
+
+	aggregation, err := h.viewService.AggregateView(id, options)
+	if errors.Is(err, app.ErrNotFound) {
+		h.HandleErrorWithCode(w, c.logger, http.StatusNotFound, "view or field not found", err)
+		return
+	} else if err != nil {
+		h.HandleErrorWithCode(w, c.logger, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	ReturnJSON(w, aggregation, http.StatusOK)
+}
+
+func (h *ViewHandler) getChart(c *Context, w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	//TODO: implement permission check
+
+	chart, err := h.viewService.GetChart(id)
+	if errors.Is(err, app.ErrNotFound) {
+		h.HandleErrorWithCode(w, c.logger, http.StatusNotFound, "view or field not found", err)
+		return
+	} else if err != nil {
+		h.HandleErrorWithCode(w, c.logger, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	ReturnJSON(w, chart, http.StatusOK)
+}
+
+func (h *ViewHandler) getCalendar(c *Context, w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	//TODO: implement permission check
+
+	from, err := time.Parse(time.DateOnly, r.URL.Query().Get("from"))
+	if err != nil {
+		h.HandleErrorWithCode(w, c.logger, http.StatusBadRequest, "from should be a date formatted as YYYY-MM-DD", err)
+		return
+	}
+
+	to, err := time.Parse(time.DateOnly, r.URL.Query().Get("to"))
+	if err != nil {
+		h.HandleErrorWithCode(w, c.logger, http.StatusBadRequest, "to should be a date formatted as YYYY-MM-DD", err)
+		return
+	}
+
+	calendar, err := h.viewService.GetCalendar(id, from, to)
+	if errors.Is(err, app.ErrNotFound) {
+		h.HandleErrorWithCode(w, c.logger, http.StatusNotFound, "view not found", err)
+		return
+	} else if err != nil {
+		h.HandleErrorWithCode(w, c.logger, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	ReturnJSON(w, calendar, http.StatusOK)
+}
+
+func (h *ViewHandler) getTimeline(c *Context, w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	//TODO: implement permission check
+
+	from, err := time.Parse(time.DateOnly, r.URL.Query().Get("from"))
+	if err != nil {
+		h.HandleErrorWithCode(w, c.logger, http.StatusBadRequest, "from should be a date formatted as YYYY-MM-DD", err)
+		return
+	}
+
+	to, err := time.Parse(time.DateOnly, r.URL.Query().Get("to"))
+	if err != nil {
+		h.HandleErrorWithCode(w, c.logger, http.StatusBadRequest, "to should be a date formatted as YYYY-MM-DD", err)
+		return
+	}
+
+	timeline, err := h.viewService.GetTimeline(id, from, to)
+	if errors.Is(err, app.ErrNotFound) {
+		h.HandleErrorWithCode(w, c.logger, http.StatusNotFound, "view not found", err)
+		return
+	} else if err != nil {
+		h.HandleErrorWithCode(w, c.logger, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	ReturnJSON(w, timeline, http.StatusOK)
+}
+
+// splitList splits a comma separated query parameter, ignoring blank items.
+func splitList(list string) []string {
+	items := []string{}
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
 func (h *ViewHandler) getForUser(c *Context, w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
@@ -159,3 +391,43 @@ func (h *ViewHandler) patchView(c *Context, w http.ResponseWriter, r *http.Reque
 
 	w.WriteHeader(http.StatusOK)
 }
+
+func (h *ViewHandler) getWatches(c *Context, w http.ResponseWriter, r *http.Request) {
+	userID := r.Header.Get("Mattermost-User-ID")
+
+	watches, err := h.viewWatchService.GetForUser(userID)
+	if err != nil {
+		h.HandleError(w, c.logger, err)
+		return
+	}
+
+	ReturnJSON(w, watches, http.StatusOK)
+}
+
+func (h *ViewHandler) watchView(c *Context, w http.ResponseWriter, r *http.Request) {
+	userID := r.Header.Get("Mattermost-User-ID")
+	vars := mux.Vars(r)
+
+	err := h.viewWatchService.Watch(vars["id"], userID)
+	if errors.Is(err, app.ErrNotFound) {
+		h.HandleErrorWithCode(w, c.logger, http.StatusNotFound, "view not found", err)
+		return
+	} else if err != nil {
+		h.HandleError(w, c.logger, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h *ViewHandler) unwatchView(c *Context, w http.ResponseWriter, r *http.Request) {
+	userID := r.Header.Get("Mattermost-User-ID")
+	vars := mux.Vars(r)
+
+	if err := h.viewWatchService.Unwatch(vars["id"], userID); err != nil {
+		h.HandleError(w, c.logger, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
@@ -0,0 +1,245 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/jwilander/mattermost-plugin-properties/server/app"
+	"github.com/jwilander/mattermost-plugin-properties/server/config"
+	"github.com/mattermost/mattermost/server/public/pluginapi"
+	"github.com/pkg/errors"
+)
+
+// WebhookHandler is the API handler.
+type WebhookHandler struct {
+	*ErrorHandler
+	webhookService app.WebhookService
+	pluginAPI      *pluginapi.Client
+	config         config.Service
+	permissions    *app.PermissionsService
+}
+
+// NewWebhookHandler returns a new webhook api handler
+func NewWebhookHandler(router *mux.Router, webhookService app.WebhookService, api *pluginapi.Client, configService config.Service, permissions *app.PermissionsService) *WebhookHandler {
+	handler := &WebhookHandler{
+		ErrorHandler:   &ErrorHandler{},
+		webhookService: webhookService,
+		pluginAPI:      api,
+		config:         configService,
+		permissions:    permissions,
+	}
+
+	webhookRouter := router.PathPrefix("/webhook").Subrouter()
+
+	webhookRouter.HandleFunc("", withContext(handler.createWebhook)).Methods(http.MethodPost)
+	webhookRouter.HandleFunc("", withContext(handler.getWebhooks)).Methods(http.MethodGet)
+	webhookRouter.HandleFunc("/{id}", withContext(handler.getWebhook)).Methods(http.MethodGet)
+	webhookRouter.HandleFunc("/{id}", withContext(handler.updateWebhook)).Methods(http.MethodPut)
+	webhookRouter.HandleFunc("/{id}", withContext(handler.deleteWebhook)).Methods(http.MethodDelete)
+	webhookRouter.HandleFunc("/{id}/deliveries", withContext(handler.getDeliveries)).Methods(http.MethodGet)
+
+	return handler
+}
+
+func (h *WebhookHandler) createWebhook(c *Context, w http.ResponseWriter, r *http.Request) {
+	userID := r.Header.Get("Mattermost-User-ID")
+
+	var webhook app.Webhook
+	if err := json.NewDecoder(r.Body).Decode(&webhook); err != nil {
+		h.HandleErrorWithCode(w, c.logger, http.StatusBadRequest, "unable to decode webhook", err)
+		return
+	}
+
+	webhook.UpdateBy = userID
+
+	if webhook.ID != "" {
+		h.HandleErrorWithCode(w, c.logger, http.StatusBadRequest, "id must be blank", nil)
+		return
+	}
+
+	// Channel webhooks always belong to the team of their channel
+	if webhook.ChannelID != "" {
+		channel, err := h.pluginAPI.Channel.Get(webhook.ChannelID)
+		if err != nil {
+			h.HandleErrorWithCode(w, c.logger, http.StatusBadRequest, "unable to get channel", err)
+			return
+		}
+		webhook.TeamID = channel.TeamId
+	}
+
+	if !h.PermissionsCheck(w, c.logger, h.permissions.WebhookManage(userID, webhook.TeamID)) {
+		return
+	}
+
+	id, err := h.webhookService.Create(webhook)
+	if err != nil {
+		h.HandleErrorWithCode(w, c.logger, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	result := struct {
+		ID string `json:"id"`
+	}{
+		ID: id,
+	}
+	w.Header().Add("Location", makeAPIURL(h.pluginAPI, "webhook/%s", id))
+
+	ReturnJSON(w, &result, http.StatusCreated)
+}
+
+const defaultWebhooksPerPage = 100
+
+func (h *WebhookHandler) getWebhooks(c *Context, w http.ResponseWriter, r *http.Request) {
+	userID := r.Header.Get("Mattermost-User-ID")
+
+	query := r.URL.Query()
+	teamID := query.Get("team_id")
+	page, err := strconv.Atoi(query.Get("page"))
+	if err != nil {
+		page = 0
+	}
+	perPage, err := strconv.Atoi(query.Get("per_page"))
+	if err != nil {
+		perPage = defaultWebhooksPerPage
+	}
+
+	if !h.PermissionsCheck(w, c.logger, h.permissions.WebhookManage(userID, teamID)) {
+		return
+	}
+
+	webhooks, err := h.webhookService.GetWebhooks(app.WebhookFilterOptions{
+		TeamID:  teamID,
+		Page:    page,
+		PerPage: perPage,
+	})
+	if err != nil {
+		h.HandleError(w, c.logger, err)
+		return
+	}
+
+	for i := range webhooks {
+		webhooks[i].Sanitize()
+	}
+
+	ReturnJSON(w, webhooks, http.StatusOK)
+}
+
+func (h *WebhookHandler) getWebhook(c *Context, w http.ResponseWriter, r *http.Request) {
+	userID := r.Header.Get("Mattermost-User-ID")
+	vars := mux.Vars(r)
+
+	webhook, ok := h.getWebhookForUser(c, w, userID, vars["id"])
+	if !ok {
+		return
+	}
+
+	webhook.Sanitize()
+	ReturnJSON(w, webhook, http.StatusOK)
+}
+
+func (h *WebhookHandler) updateWebhook(c *Context, w http.ResponseWriter, r *http.Request) {
+	userID := r.Header.Get("Mattermost-User-ID")
+	vars := mux.Vars(r)
+
+	var webhook app.Webhook
+	if err := json.NewDecoder(r.Body).Decode(&webhook); err != nil {
+		h.HandleErrorWithCode(w, c.logger, http.StatusBadRequest, "unable to decode webhook", err)
+		return
+	}
+
+	existing, ok := h.getWebhookForUser(c, w, userID, vars["id"])
+	if !ok {
+		return
+	}
+
+	// Webhooks can't move between teams or channels
+	webhook.ID = existing.ID
+	webhook.TeamID = existing.TeamID
+	webhook.ChannelID = existing.ChannelID
+	webhook.UpdateBy = userID
+
+	// The secret is never returned, so keep it unless a new one is sent
+	if webhook.Secret == "" {
+		webhook.Secret = existing.Secret
+	}
+
+	if err := h.webhookService.Update(webhook); err != nil {
+		h.HandleErrorWithCode(w, c.logger, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h *WebhookHandler) deleteWebhook(c *Context, w http.ResponseWriter, r *http.Request) {
+	userID := r.Header.Get("Mattermost-User-ID")
+	vars := mux.Vars(r)
+
+	webhook, ok := h.getWebhookForUser(c, w, userID, vars["id"])
+	if !ok {
+		return
+	}
+
+	if err := h.webhookService.Delete(webhook.ID); err != nil {
+		h.HandleError(w, c.logger, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+const defaultDeliveriesPerPage = 50
+
+func (h *WebhookHandler) getDeliveries(c *Context, w http.ResponseWriter, r *http.Request) {
+	userID := r.Header.Get("Mattermost-User-ID")
+	vars := mux.Vars(r)
+
+	webhook, ok := h.getWebhookForUser(c, w, userID, vars["id"])
+	if !ok {
+		return
+	}
+
+	query := r.URL.Query()
+	page, err := strconv.Atoi(query.Get("page"))
+	if err != nil {
+		page = 0
+	}
+	perPage, err := strconv.Atoi(query.Get("per_page"))
+	if err != nil {
+		perPage = defaultDeliveriesPerPage
+	}
+
+	deliveries, err := h.webhookService.GetDeliveries(webhook.ID, page, perPage)
+	if err != nil {
+		h.HandleError(w, c.logger, err)
+		return
+	}
+
+	ReturnJSON(w, deliveries, http.StatusOK)
+}
+
+// getWebhookForUser gets the webhook and checks the user can manage it. Returns false after
+// handling the error if not.
+func (h *WebhookHandler) getWebhookForUser(c *Context, w http.ResponseWriter, userID string, id string) (app.Webhook, bool) {
+	if id == "" {
+		h.HandleErrorWithCode(w, c.logger, http.StatusBadRequest, "id must be set", nil)
+		return app.Webhook{}, false
+	}
+
+	webhook, err := h.webhookService.Get(id)
+	if errors.Is(err, app.ErrNotFound) {
+		h.HandleErrorWithCode(w, c.logger, http.StatusNotFound, "webhook not found", err)
+		return app.Webhook{}, false
+	} else if err != nil {
+		h.HandleError(w, c.logger, err)
+		return app.Webhook{}, false
+	}
+
+	if !h.PermissionsCheck(w, c.logger, h.permissions.WebhookManage(userID, webhook.TeamID)) {
+		return app.Webhook{}, false
+	}
+
+	return webhook, true
+}
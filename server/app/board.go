@@ -0,0 +1,276 @@
+package app
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+// BoardData holds the objects of a kanban view bucketed into cells, one per column and swimlane.
+// Counts cover all the objects of the view, while cells only list the objects of the requested
+// page. Boards without a swimlane field have a single lane without a value.
+type BoardData struct {
+	GroupByFieldID  string        `json:"group_by_field_id"`
+	SwimlaneFieldID string        `json:"swimlane_field_id,omitempty"`
+	Columns         []BoardColumn `json:"columns"`
+	Lanes           []BoardLane   `json:"lanes"`
+	Objects         Objects       `json:"objects"`
+}
+
+// BoardColumn is a value of the group by field, nil for the objects without a value. OverLimit
+// is set when the column holds more objects than its WIP limit.
+type BoardColumn struct {
+	Value     *string `json:"value"`
+	Count     int64   `json:"count"`
+	Limit     int     `json:"limit,omitempty"`
+	OverLimit bool    `json:"over_limit,omitempty"`
+}
+
+// BoardLane is a value of the swimlane field, nil for the objects without a value. Its cells are
+// in the order of the columns.
+type BoardLane struct {
+	Value *string     `json:"value"`
+	Count int64       `json:"count"`
+	Cells []BoardCell `json:"cells"`
+}
+
+type BoardCell struct {
+	Count     int64    `json:"count"`
+	ObjectIDs []string `json:"object_ids"`
+}
+
+// boardAxis keeps the values of the columns or the lanes of a board, the options of the field
+// first, then other values in the order they were added and the objects without a value last.
+type boardAxis struct {
+	values []string
+	index  map[string]int
+}
+
+func newBoardAxis(options []interface{}) *boardAxis {
+	ba := &boardAxis{index: map[string]int{}}
+	for _, option := range options {
+		ba.add(fmt.Sprint(option))
+	}
+	return ba
+}
+
+func (ba *boardAxis) add(value string) {
+	if _, ok := ba.index[value]; !ok {
+		ba.index[value] = len(ba.values)
+		ba.values = append(ba.values, value)
+	}
+}
+
+func (ba *boardAxis) addValue(value *string) {
+	if value != nil {
+		ba.add(*value)
+	}
+}
+
+// len counts the values, including the one of the objects without a value.
+func (ba *boardAxis) len() int {
+	return len(ba.values) + 1
+}
+
+func (ba *boardAxis) position(value *string) int {
+	if value == nil {
+		return len(ba.values)
+	}
+	return ba.index[*value]
+}
+
+func (ba *boardAxis) value(position int) *string {
+	if position == len(ba.values) {
+		return nil
+	}
+	return &ba.values[position]
+}
+
+// propertyValues returns the values of a field as strings, a single nil for objects without a value.
+func propertyValues(properties PropertiesList, fieldID string) []*string {
+	values := []*string{}
+	if fieldID != "" {
+		for _, property := range properties {
+			if property.PropertyFieldID != fieldID {
+				continue
+			}
+			for _, value := range property.Value {
+				v := fmt.Sprint(value)
+				values = append(values, &v)
+			}
+		}
+	}
+
+	if len(values) == 0 {
+		return []*string{nil}
+	}
+	return values
+}
+
+// buildBoard lays out a board from the counts of the cells, the columns and the lanes, the cells
+// being aggregated by both fields when there's a swimlane field. The objects are listed in each
+// cell matching one of their values.
+func buildBoard(groupByOptions, swimlaneOptions []interface{}, cells, columns, lanes Aggregation, properties map[string]PropertiesList, objectIDs []string, groupByFieldID, swimlaneFieldID string) BoardData {
+	columnAxis := newBoardAxis(groupByOptions)
+	laneAxis := newBoardAxis(nil)
+	if swimlaneFieldID != "" {
+		laneAxis = newBoardAxis(swimlaneOptions)
+	}
+
+	for _, group := range cells.Groups {
+		columnAxis.addValue(group.Values[0])
+		if swimlaneFieldID != "" {
+			laneAxis.addValue(group.Values[1])
+		}
+	}
+	for _, objectID := range objectIDs {
+		for _, value := range propertyValues(properties[objectID], groupByFieldID) {
+			columnAxis.addValue(value)
+		}
+		if swimlaneFieldID != "" {
+			for _, value := range propertyValues(properties[objectID], swimlaneFieldID) {
+				laneAxis.addValue(value)
+			}
+		}
+	}
+
+	board := BoardData{
+		GroupByFieldID:  groupByFieldID,
+		SwimlaneFieldID: swimlaneFieldID,
+		Columns:         make([]BoardColumn, columnAxis.len()),
+		Lanes:           make([]BoardLane, laneAxis.len()),
+	}
+	for c := range board.Columns {
+		board.Columns[c] = BoardColumn{Value: columnAxis.value(c)}
+	}
+	for l := range board.Lanes {
+		board.Lanes[l] = BoardLane{Value: laneAxis.value(l), Cells: make([]BoardCell, columnAxis.len())}
+		for c := range board.Lanes[l].Cells {
+			board.Lanes[l].Cells[c].ObjectIDs = []string{}
+		}
+	}
+
+	for _, group := range columns.Groups {
+		board.Columns[columnAxis.position(group.Values[0])].Count += group.Count
+	}
+
+	if swimlaneFieldID == "" {
+		for _, group := range cells.Groups {
+			board.Lanes[0].Cells[columnAxis.position(group.Values[0])].Count += group.Count
+		}
+		for _, group := range lanes.Groups {
+			board.Lanes[0].Count += group.Count
+		}
+	} else {
+		for _, group := range cells.Groups {
+			board.Lanes[laneAxis.position(group.Values[1])].Cells[columnAxis.position(group.Values[0])].Count += group.Count
+		}
+		for _, group := range lanes.Groups {
+			board.Lanes[laneAxis.position(group.Values[0])].Count += group.Count
+		}
+	}
+
+	for _, objectID := range objectIDs {
+		laneValues := []*string{nil}
+		if swimlaneFieldID != "" {
+			laneValues = propertyValues(properties[objectID], swimlaneFieldID)
+		}
+
+		for _, laneValue := range laneValues {
+			lane := &board.Lanes[laneAxis.position(laneValue)]
+			for _, columnValue := range propertyValues(properties[objectID], groupByFieldID) {
+				cell := &lane.Cells[columnAxis.position(columnValue)]
+				cell.ObjectIDs = append(cell.ObjectIDs, objectID)
+			}
+		}
+	}
+
+	return board
+}
+
+func (vs *viewService) GetBoard(id string, page int, perPage int) (BoardData, error) {
+	view, err := vs.store.Get(id)
+	if err != nil {
+		return BoardData{}, errors.Wrap(err, "could not get view")
+	}
+
+	if view.Type != ViewTypeKanban {
+		return BoardData{}, errors.Errorf("View '%s' is not a kanban board", view.Title)
+	}
+	if view.Format.GroupByFieldID == "" {
+		return BoardData{}, errors.New("Format GroupByFieldID should be set for kanban boards")
+	}
+
+	groupByField, err := vs.propertyFieldService.Get(view.Format.GroupByFieldID)
+	if err != nil {
+		return BoardData{}, errors.Wrap(err, "could not get group by field")
+	}
+
+	var swimlaneOptions []interface{}
+	cellFieldIDs := []string{view.Format.GroupByFieldID}
+	laneFieldIDs := []string{}
+	if view.Format.SwimlaneFieldID != "" {
+		swimlaneField, fieldErr := vs.propertyFieldService.Get(view.Format.SwimlaneFieldID)
+		if fieldErr != nil {
+			return BoardData{}, errors.Wrap(fieldErr, "could not get swimlane field")
+		}
+		swimlaneOptions = swimlaneField.Values
+		cellFieldIDs = append(cellFieldIDs, view.Format.SwimlaneFieldID)
+		laneFieldIDs = append(laneFieldIDs, view.Format.SwimlaneFieldID)
+	}
+
+	cells, err := vs.store.Aggregate(view.Query, AggregateOptions{GroupByFieldIDs: cellFieldIDs})
+	if err != nil {
+		return BoardData{}, errors.Wrap(err, "could not count cells")
+	}
+
+	// Objects with several values are counted once per column and once per lane
+	columns := cells
+	if view.Format.SwimlaneFieldID != "" {
+		columns, err = vs.store.Aggregate(view.Query, AggregateOptions{GroupByFieldIDs: []string{view.Format.GroupByFieldID}})
+		if err != nil {
+			return BoardData{}, errors.Wrap(err, "could not count columns")
+		}
+	}
+
+	lanes, err := vs.store.Aggregate(view.Query, AggregateOptions{GroupByFieldIDs: laneFieldIDs})
+	if err != nil {
+		return BoardData{}, errors.Wrap(err, "could not count lanes")
+	}
+
+	objects, err := vs.getObjectsForView(view, page, perPage)
+	if err != nil {
+		return BoardData{}, err
+	}
+
+	board := buildBoard(groupByField.Values, swimlaneOptions, cells, columns, lanes, objects.Properties, objectsIDs(objects), view.Format.GroupByFieldID, view.Format.SwimlaneFieldID)
+	board.Objects = objects
+
+	for c, column := range board.Columns {
+		value := ""
+		if column.Value != nil {
+			value = *column.Value
+		}
+
+		board.Columns[c].Limit = view.Format.WIPLimits[value]
+		board.Columns[c].OverLimit = board.Columns[c].Limit > 0 && column.Count > int64(board.Columns[c].Limit)
+
+		for _, lane := range board.Lanes {
+			lane.Cells[c].ObjectIDs = orderColumn(lane.Cells[c].ObjectIDs, view.Format.ColumnOrders[value])
+		}
+	}
+
+	return board, nil
+}
+
+// objectsIDs returns the ids of the posts and files, in order.
+func objectsIDs(objects Objects) []string {
+	ids := make([]string, 0, len(objects.Posts)+len(objects.Files))
+	for _, post := range objects.Posts {
+		ids = append(ids, post.Id)
+	}
+	for _, fileInfo := range objects.Files {
+		ids = append(ids, fileInfo.Id)
+	}
+	return ids
+}
@@ -0,0 +1,82 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildBoard(t *testing.T) {
+	open, done, alice, bob := "open", "done", "alice", "bob"
+	value := func(fieldID string, v ...interface{}) Property {
+		return Property{PropertyFieldID: fieldID, Value: v}
+	}
+
+	t.Run("without swimlanes", func(t *testing.T) {
+		cells := Aggregation{Groups: []AggregationGroup{
+			{Values: []*string{&done}, Count: 4},
+			{Values: []*string{nil}, Count: 1},
+		}}
+		lanes := Aggregation{Groups: []AggregationGroup{{Values: []*string{}, Count: 5}}}
+		properties := map[string]PropertiesList{
+			"a": {value("status", "done")},
+			"b": {},
+		}
+
+		board := buildBoard([]interface{}{"open", "done"}, nil, cells, cells, lanes, properties, []string{"a", "b"}, "status", "")
+
+		assert.Equal(t, []BoardColumn{{Value: &open}, {Value: &done, Count: 4}, {Value: nil, Count: 1}}, board.Columns)
+		assert.Equal(t, []BoardLane{{
+			Value: nil,
+			Count: 5,
+			Cells: []BoardCell{
+				{ObjectIDs: []string{}},
+				{Count: 4, ObjectIDs: []string{"a"}},
+				{Count: 1, ObjectIDs: []string{"b"}},
+			},
+		}}, board.Lanes)
+	})
+
+	t.Run("with swimlanes", func(t *testing.T) {
+		cells := Aggregation{Groups: []AggregationGroup{
+			{Values: []*string{&open, &alice}, Count: 2},
+			{Values: []*string{&open, &bob}, Count: 1},
+			{Values: []*string{&done, nil}, Count: 3},
+		}}
+		columns := Aggregation{Groups: []AggregationGroup{
+			{Values: []*string{&open}, Count: 2},
+			{Values: []*string{&done}, Count: 3},
+		}}
+		lanes := Aggregation{Groups: []AggregationGroup{
+			{Values: []*string{&alice}, Count: 2},
+			{Values: []*string{&bob}, Count: 1},
+			{Values: []*string{nil}, Count: 3},
+		}}
+		properties := map[string]PropertiesList{
+			// Assigned to both, so listed in both lanes
+			"a": {value("status", "open"), value("owner", "alice", "bob")},
+			"b": {value("status", "done")},
+		}
+
+		board := buildBoard([]interface{}{"open", "done"}, nil, cells, columns, lanes, properties, []string{"a", "b"}, "status", "owner")
+
+		assert.Equal(t, []BoardColumn{{Value: &open, Count: 2}, {Value: &done, Count: 3}, {Value: nil}}, board.Columns)
+		assert.Equal(t, []BoardLane{
+			{Value: &alice, Count: 2, Cells: []BoardCell{
+				{Count: 2, ObjectIDs: []string{"a"}},
+				{ObjectIDs: []string{}},
+				{ObjectIDs: []string{}},
+			}},
+			{Value: &bob, Count: 1, Cells: []BoardCell{
+				{Count: 1, ObjectIDs: []string{"a"}},
+				{ObjectIDs: []string{}},
+				{ObjectIDs: []string{}},
+			}},
+			{Value: nil, Count: 3, Cells: []BoardCell{
+				{ObjectIDs: []string{}},
+				{Count: 3, ObjectIDs: []string{"b"}},
+				{ObjectIDs: []string{}},
+			}},
+		}, board.Lanes)
+	})
+}
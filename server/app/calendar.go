@@ -0,0 +1,176 @@
+package app
+
+import (
+	"time"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/pkg/errors"
+)
+
+const (
+	// maxCalendarDays bounds the window of calendar and timeline requests.
+	maxCalendarDays = 366
+	// maxCalendarObjects bounds the objects returned for a calendar window.
+	maxCalendarObjects = 1000
+)
+
+// CalendarData holds the objects of a calendar window, with the ids of the objects spanning each
+// day keyed by the day as "2006-01-02". Days without objects are left out. Truncated is set when
+// the window has more than maxCalendarObjects objects, the ones starting last being left out.
+type CalendarData struct {
+	Objects   Objects             `json:"objects"`
+	Days      map[string][]string `json:"days"`
+	Truncated bool                `json:"truncated"`
+}
+
+// calendarDays buckets the objects by the days their date fields span within the window. Objects
+// with an end before their start only span their start.
+func calendarDays(properties map[string]PropertiesList, objectIDs []string, calendar CalendarFormat, from, to time.Time) map[string][]string {
+	days := map[string][]string{}
+	first := from.Format(dateLayout)
+	last := to.Format(dateLayout)
+
+	for _, objectID := range objectIDs {
+		day, ok := propertyDay(properties[objectID], calendar.StartFieldID)
+		if !ok {
+			continue
+		}
+
+		lastDay := day
+		if endDay, hasEnd := propertyDay(properties[objectID], calendar.EndFieldID); hasEnd && !endDay.Before(day) {
+			lastDay = endDay
+		}
+
+		for ; !day.After(lastDay); day = day.AddDate(0, 0, 1) {
+			date := day.Format(dateLayout)
+			if date < first {
+				continue
+			}
+			if date > last {
+				break
+			}
+			days[date] = append(days[date], objectID)
+		}
+	}
+
+	return days
+}
+
+// propertyDay returns the day of the first value of a date field, taken in the timezone of the
+// value itself.
+func propertyDay(properties PropertiesList, fieldID string) (time.Time, bool) {
+	if fieldID == "" {
+		return time.Time{}, false
+	}
+
+	for _, property := range properties {
+		if property.PropertyFieldID != fieldID || len(property.Value) == 0 {
+			continue
+		}
+
+		t, ok := ParseDateValue(property.Value[0])
+		if !ok {
+			return time.Time{}, false
+		}
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC), true
+	}
+
+	return time.Time{}, false
+}
+
+// validateWindow checks the days requested by calendar and timeline views.
+func validateWindow(from, to time.Time) error {
+	if to.Before(from) {
+		return errors.New("The end of the window should not be before its start")
+	}
+	if to.Sub(from) > maxCalendarDays*24*time.Hour {
+		return errors.Errorf("The window should not be longer than %d days", maxCalendarDays)
+	}
+
+	return nil
+}
+
+func (vs *viewService) GetCalendar(id string, from, to time.Time) (CalendarData, error) {
+	view, err := vs.store.Get(id)
+	if err != nil {
+		return CalendarData{}, errors.Wrap(err, "could not get view")
+	}
+
+	if view.Type != ViewTypeCalendar || view.Format.Calendar == nil {
+		return CalendarData{}, errors.Errorf("View '%s' is not a calendar", view.Title)
+	}
+	calendar := *view.Format.Calendar
+
+	if err = validateWindow(from, to); err != nil {
+		return CalendarData{}, err
+	}
+
+	ids, truncated, err := vs.queryDateRange(view.Query, calendar.StartFieldID, calendar.EndFieldID, from, to, maxCalendarObjects)
+	if err != nil {
+		return CalendarData{}, err
+	}
+
+	objects, err := vs.getObjectsByID(view.Query.ObjectType, ids)
+	if err != nil {
+		return CalendarData{}, err
+	}
+
+	return CalendarData{
+		Objects:   objects,
+		Days:      calendarDays(objects.Properties, ids, calendar, from, to),
+		Truncated: truncated,
+	}, nil
+}
+
+// queryDateRange returns the ids of up to limit objects spanning the window, and whether there
+// were more.
+func (vs *viewService) queryDateRange(query Query, startFieldID, endFieldID string, from, to time.Time, limit int) ([]string, bool, error) {
+	ids, err := vs.store.QueryDateRange(query, startFieldID, endFieldID, from.Format(dateLayout), to.Format(dateLayout), limit+1)
+	if err != nil {
+		return nil, false, errors.Wrap(err, "could not query objects")
+	}
+
+	if len(ids) > limit {
+		return ids[:limit], true, nil
+	}
+	return ids, false, nil
+}
+
+// getObjectsByID returns the objects of the given type with their properties. Objects that no
+// longer exist are left out.
+func (vs *viewService) getObjectsByID(objectType string, ids []string) (Objects, error) {
+	objects := Objects{Posts: []*model.Post{}, Files: []*model.FileInfo{}, Properties: map[string]PropertiesList{}}
+	if len(ids) == 0 {
+		return objects, nil
+	}
+
+	if objectType == PropertyObjectTypeFile {
+		for _, fileID := range ids {
+			fileInfo, err := vs.api.File.GetInfo(fileID)
+			if err != nil || fileInfo.DeleteAt != 0 {
+				continue
+			}
+
+			objects.Files = append(objects.Files, fileInfo)
+			if err = vs.addPropertiesForObject(objects, fileInfo.Id); err != nil {
+				return Objects{}, err
+			}
+		}
+
+		return objects, nil
+	}
+
+	posts, err := vs.getPosts(ids)
+	if err != nil {
+		return Objects{}, err
+	}
+	objects.Posts = posts
+
+	for _, post := range objects.Posts {
+		if err = vs.addPropertiesForPost(objects, post); err != nil {
+			return Objects{}, err
+		}
+	}
+
+	return objects, nil
+}
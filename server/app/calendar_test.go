@@ -0,0 +1,103 @@
+package app
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCalendarDays(t *testing.T) {
+	from := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 3, 5, 0, 0, 0, 0, time.UTC)
+	calendar := CalendarFormat{StartFieldID: "start", EndFieldID: "end"}
+
+	value := func(fieldID string, v interface{}) Property {
+		return Property{PropertyFieldID: fieldID, Value: []interface{}{v}}
+	}
+
+	cases := []struct {
+		Name       string
+		Properties PropertiesList
+		Expected   map[string][]string
+	}{
+		{
+			Name:       "start only",
+			Properties: PropertiesList{value("start", "2024-03-02")},
+			Expected:   map[string][]string{"2024-03-02": {"a"}},
+		},
+		{
+			Name:       "start to end",
+			Properties: PropertiesList{value("start", "2024-03-02"), value("end", "2024-03-04")},
+			Expected:   map[string][]string{"2024-03-02": {"a"}, "2024-03-03": {"a"}, "2024-03-04": {"a"}},
+		},
+		{
+			Name:       "clipped to the window",
+			Properties: PropertiesList{value("start", "2024-02-27"), value("end", "2024-03-02")},
+			Expected:   map[string][]string{"2024-03-01": {"a"}, "2024-03-02": {"a"}},
+		},
+		{
+			Name:       "end before start",
+			Properties: PropertiesList{value("start", "2024-03-03"), value("end", "2024-03-01")},
+			Expected:   map[string][]string{"2024-03-03": {"a"}},
+		},
+		{
+			Name:       "timestamp on its own day",
+			Properties: PropertiesList{value("start", "2024-03-05T23:30:00-05:00")},
+			Expected:   map[string][]string{"2024-03-05": {"a"}},
+		},
+		{
+			Name:       "no start",
+			Properties: PropertiesList{value("end", "2024-03-02")},
+			Expected:   map[string][]string{},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			properties := map[string]PropertiesList{"a": c.Properties}
+			assert.Equal(t, c.Expected, calendarDays(properties, []string{"a"}, calendar, from, to))
+		})
+	}
+}
+
+func TestQueryDateRange(t *testing.T) {
+	from := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 3, 5, 0, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		Name              string
+		Matching          []string
+		ExpectedIDs       []string
+		ExpectedTruncated bool
+	}{
+		{
+			Name:        "under the limit",
+			Matching:    []string{"a", "b"},
+			ExpectedIDs: []string{"a", "b"},
+		},
+		{
+			Name:        "at the limit",
+			Matching:    []string{"a", "b", "c"},
+			ExpectedIDs: []string{"a", "b", "c"},
+		},
+		{
+			Name:              "over the limit",
+			Matching:          []string{"a", "b", "c", "d"},
+			ExpectedIDs:       []string{"a", "b", "c"},
+			ExpectedTruncated: true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			vs := &viewService{store: &fakeViewStore{matching: c.Matching}}
+
+			ids, truncated, err := vs.queryDateRange(Query{}, "start", "end", from, to, 3)
+			require.NoError(t, err)
+			assert.Equal(t, c.ExpectedIDs, ids)
+			assert.Equal(t, c.ExpectedTruncated, truncated)
+		})
+	}
+}
@@ -0,0 +1,221 @@
+package app
+
+import (
+	"fmt"
+	"time"
+)
+
+// ChartFormat describes what a chart view plots.
+type ChartFormat struct {
+	Kind string `json:"kind"`
+	// DimensionFieldID groups the objects. Line charts plot a series per option of the field.
+	DimensionFieldID string `json:"dimension_field_id"`
+	// SeriesFieldID splits the bars of bar charts into a series per value.
+	SeriesFieldID string `json:"series_field_id,omitempty"`
+	// Measure is what is plotted for each group, count when blank. Line charts only plot counts.
+	Measure string `json:"measure"`
+	// MeasureFieldID is the number field summarized by measures other than count.
+	MeasureFieldID string `json:"measure_field_id,omitempty"`
+	// Days is how many days up to today line charts plot.
+	Days int `json:"days,omitempty"`
+}
+
+const (
+	ChartKindBar  = "bar"
+	ChartKindPie  = "pie"
+	ChartKindLine = "line"
+)
+
+const (
+	ChartMeasureCount = "count"
+	ChartMeasureSum   = "sum"
+	ChartMeasureAvg   = "avg"
+	ChartMeasureMin   = "min"
+	ChartMeasureMax   = "max"
+)
+
+const (
+	defaultChartDays = 30
+	maxChartDays     = 365
+
+	// ChartNoValueLabel labels the objects without a value for the dimension.
+	ChartNoValueLabel = "No value"
+)
+
+// ChartData holds ready to plot series, each with a value for every label.
+type ChartData struct {
+	Kind   string        `json:"kind"`
+	Labels []string      `json:"labels"`
+	Series []ChartSeries `json:"series"`
+}
+
+type ChartSeries struct {
+	Name   string    `json:"name"`
+	Values []float64 `json:"values"`
+}
+
+// chartMeasure returns the plotted value of a group of an aggregation.
+func chartMeasure(chart ChartFormat, group AggregationGroup) float64 {
+	metric := group.Metrics[chart.MeasureFieldID]
+
+	switch chart.Measure {
+	case ChartMeasureSum:
+		return metric.Sum
+	case ChartMeasureAvg:
+		return metric.Avg
+	case ChartMeasureMin:
+		return metric.Min
+	case ChartMeasureMax:
+		return metric.Max
+	default:
+		return float64(group.Count)
+	}
+}
+
+// chartLabels keeps the labels in the order they were added, the options of the field first and
+// the objects without a value last.
+type chartLabels struct {
+	labels  []string
+	index   map[string]int
+	noValue bool
+}
+
+func newChartLabels(options []interface{}) *chartLabels {
+	cl := &chartLabels{index: map[string]int{}}
+	for _, option := range options {
+		cl.add(fmt.Sprint(option))
+	}
+	return cl
+}
+
+func (cl *chartLabels) add(label string) {
+	if _, ok := cl.index[label]; !ok {
+		cl.index[label] = len(cl.labels)
+		cl.labels = append(cl.labels, label)
+	}
+}
+
+func (cl *chartLabels) addValue(value *string) {
+	if value == nil {
+		cl.noValue = true
+		return
+	}
+	cl.add(*value)
+}
+
+// list returns the labels, the position of a value being found with position once listed.
+func (cl *chartLabels) list() []string {
+	if cl.noValue {
+		cl.add(ChartNoValueLabel)
+	}
+	return cl.labels
+}
+
+func (cl *chartLabels) position(value *string) int {
+	if value == nil {
+		return cl.index[ChartNoValueLabel]
+	}
+	return cl.index[*value]
+}
+
+// chartFromAggregation plots an aggregation grouped by the dimension field, and by the series
+// field when set.
+func chartFromAggregation(chart ChartFormat, dimensionOptions, seriesOptions []interface{}, aggregation Aggregation) ChartData {
+	labels := newChartLabels(dimensionOptions)
+	series := newChartLabels(seriesOptions)
+	for _, group := range aggregation.Groups {
+		labels.addValue(group.Values[0])
+		if chart.SeriesFieldID != "" {
+			series.addValue(group.Values[1])
+		}
+	}
+
+	measure := chart.Measure
+	if measure == "" {
+		measure = ChartMeasureCount
+	}
+
+	seriesNames := []string{measure}
+	if chart.SeriesFieldID != "" {
+		seriesNames = series.list()
+	}
+
+	data := ChartData{
+		Kind:   chart.Kind,
+		Labels: labels.list(),
+		Series: make([]ChartSeries, len(seriesNames)),
+	}
+	for i, name := range seriesNames {
+		data.Series[i] = ChartSeries{Name: name, Values: make([]float64, len(data.Labels))}
+	}
+
+	for _, group := range aggregation.Groups {
+		s := 0
+		if chart.SeriesFieldID != "" {
+			s = series.position(group.Values[1])
+		}
+		data.Series[s].Values[labels.position(group.Values[0])] += chartMeasure(chart, group)
+	}
+
+	return data
+}
+
+// chartDays returns the first day plotted by a line chart and the days it covers.
+func chartDays(chart ChartFormat, now time.Time) (time.Time, int) {
+	days := chart.Days
+	if days <= 0 {
+		days = defaultChartDays
+	}
+
+	now = now.UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	return today.AddDate(0, 0, 1-days), days
+}
+
+// chartOverTime counts, at the end of each day, the objects having each value of the field
+// according to its history. Only the objects in objectIDs are counted.
+func chartOverTime(chart ChartFormat, options []interface{}, history []PropertyHistoryEntry, objectIDs map[string]bool, start time.Time, days int) ChartData {
+	series := newChartLabels(options)
+	for _, entry := range history {
+		if !objectIDs[entry.ObjectID] {
+			continue
+		}
+		for _, v := range entry.Value {
+			series.add(fmt.Sprint(v))
+		}
+	}
+
+	data := ChartData{
+		Kind:   chart.Kind,
+		Labels: make([]string, days),
+		Series: make([]ChartSeries, len(series.list())),
+	}
+	for i, name := range series.list() {
+		data.Series[i] = ChartSeries{Name: name, Values: make([]float64, days)}
+	}
+
+	values := map[string][]interface{}{}
+	next := 0
+	for day := 0; day < days; day++ {
+		date := start.AddDate(0, 0, day)
+		data.Labels[day] = date.Format(dateLayout)
+
+		end := date.AddDate(0, 0, 1).UnixMilli()
+		for ; next < len(history) && history[next].CreateAt < end; next++ {
+			values[history[next].ObjectID] = history[next].Value
+		}
+
+		for objectID, value := range values {
+			if !objectIDs[objectID] {
+				continue
+			}
+			for _, v := range value {
+				label := fmt.Sprint(v)
+				data.Series[series.position(&label)].Values[day]++
+			}
+		}
+	}
+
+	return data
+}
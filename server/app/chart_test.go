@@ -0,0 +1,122 @@
+package app
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChartFromAggregation(t *testing.T) {
+	open, done, alice := "open", "done", "alice"
+
+	cases := []struct {
+		Name        string
+		Chart       ChartFormat
+		Aggregation Aggregation
+		Expected    ChartData
+	}{
+		{
+			Name:  "counts per option",
+			Chart: ChartFormat{Kind: ChartKindBar, DimensionFieldID: "status"},
+			Aggregation: Aggregation{Groups: []AggregationGroup{
+				{Values: []*string{&done}, Count: 2},
+				{Values: []*string{nil}, Count: 1},
+			}},
+			Expected: ChartData{
+				Kind:   ChartKindBar,
+				Labels: []string{"open", "done", ChartNoValueLabel},
+				Series: []ChartSeries{{Name: ChartMeasureCount, Values: []float64{0, 2, 1}}},
+			},
+		},
+		{
+			Name:  "sum of a number field",
+			Chart: ChartFormat{Kind: ChartKindPie, DimensionFieldID: "status", Measure: ChartMeasureSum, MeasureFieldID: "estimate"},
+			Aggregation: Aggregation{Groups: []AggregationGroup{
+				{Values: []*string{&open}, Count: 2, Metrics: map[string]AggregationMetric{"estimate": {Count: 2, Sum: 5}}},
+				{Values: []*string{&done}, Count: 1},
+			}},
+			Expected: ChartData{
+				Kind:   ChartKindPie,
+				Labels: []string{"open", "done"},
+				Series: []ChartSeries{{Name: ChartMeasureSum, Values: []float64{5, 0}}},
+			},
+		},
+		{
+			Name:  "series per value",
+			Chart: ChartFormat{Kind: ChartKindBar, DimensionFieldID: "status", SeriesFieldID: "owner"},
+			Aggregation: Aggregation{Groups: []AggregationGroup{
+				{Values: []*string{&open, &alice}, Count: 2},
+				{Values: []*string{&open, nil}, Count: 1},
+				{Values: []*string{&done, &alice}, Count: 3},
+			}},
+			Expected: ChartData{
+				Kind:   ChartKindBar,
+				Labels: []string{"open", "done"},
+				Series: []ChartSeries{
+					{Name: "alice", Values: []float64{2, 3}},
+					{Name: ChartNoValueLabel, Values: []float64{1, 0}},
+				},
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			assert.Equal(t, c.Expected, chartFromAggregation(c.Chart, []interface{}{"open", "done"}, nil, c.Aggregation))
+		})
+	}
+}
+
+func TestChartOverTime(t *testing.T) {
+	start := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	at := func(day int, hour int) int64 {
+		return start.AddDate(0, 0, day).Add(time.Duration(hour) * time.Hour).UnixMilli()
+	}
+
+	history := []PropertyHistoryEntry{
+		{ObjectID: "a", Value: []interface{}{"open"}, CreateAt: at(-5, 0)},
+		{ObjectID: "b", Value: []interface{}{"open"}, CreateAt: at(0, 10)},
+		{ObjectID: "other", Value: []interface{}{"open"}, CreateAt: at(0, 11)},
+		{ObjectID: "a", Value: []interface{}{"done"}, CreateAt: at(1, 9)},
+		{ObjectID: "b", Value: []interface{}{}, CreateAt: at(2, 9)},
+	}
+
+	data := chartOverTime(
+		ChartFormat{Kind: ChartKindLine},
+		[]interface{}{"open", "done"},
+		history,
+		map[string]bool{"a": true, "b": true},
+		start,
+		3,
+	)
+
+	assert.Equal(t, ChartData{
+		Kind:   ChartKindLine,
+		Labels: []string{"2024-03-01", "2024-03-02", "2024-03-03"},
+		Series: []ChartSeries{
+			{Name: "open", Values: []float64{2, 1, 0}},
+			{Name: "done", Values: []float64{0, 1, 1}},
+		},
+	}, data)
+}
+
+func TestGetChartOverTime(t *testing.T) {
+	propertyService := &fakePropertyService{history: []PropertyHistoryEntry{
+		{ObjectID: "a", Value: []interface{}{"open"}, CreateAt: time.Now().UnixMilli()},
+	}}
+	vs := &viewService{
+		store:           &fakeViewStore{matching: []string{"a"}},
+		propertyService: propertyService,
+	}
+
+	data, err := vs.getChartOverTime(View{}, ChartFormat{Kind: ChartKindLine, Days: 3}, PropertyField{ID: "status"})
+	require.NoError(t, err)
+
+	// The history is only loaded from the first day of the chart to the end of today
+	start, _ := chartDays(ChartFormat{Days: 3}, time.Now())
+	assert.Equal(t, start.UnixMilli(), propertyService.historySince)
+	assert.Equal(t, start.AddDate(0, 0, 3).UnixMilli()-1, propertyService.historyUntil)
+	assert.Equal(t, []float64{0, 0, 1}, data.Series[0].Values)
+}
@@ -0,0 +1,47 @@
+package app
+
+// Digest periodically posts a summary of a view to a channel, or as a DM to its user when
+// ChannelID is blank.
+type Digest struct {
+	ID        string `json:"id"`
+	ViewID    string `json:"view_id"`
+	UserID    string `json:"user_id"`
+	ChannelID string `json:"channel_id"`
+	Frequency string `json:"frequency"`
+	// Weekday is only used by weekly digests, 0 is Sunday.
+	Weekday int `json:"weekday"`
+	// Time of the day the digest is posted at, as "15:04".
+	Time       string `json:"time"`
+	Timezone   string `json:"timezone"`
+	LastSentAt int64  `json:"last_sent_at"`
+	NextRunAt  int64  `json:"next_run_at"`
+	CreateAt   int64  `json:"create_at"`
+	UpdateAt   int64  `json:"update_at"`
+}
+
+const (
+	DigestFrequencyDaily  = "daily"
+	DigestFrequencyWeekly = "weekly"
+)
+
+type DigestStore interface {
+	Get(id string) (Digest, error)
+	Create(digest Digest) (string, error)
+	GetForUser(userID string) ([]Digest, error)
+	// GetDue returns the digests whose next run is at or before the given time.
+	GetDue(now int64) ([]Digest, error)
+	Update(digest Digest) error
+	UpdateSent(id string, lastSentAt int64, nextRunAt int64) error
+	Delete(id string) error
+}
+
+type DigestService interface {
+	Get(id string) (Digest, error)
+	Create(digest Digest) (string, error)
+	GetForUser(userID string) ([]Digest, error)
+	Update(digest Digest) error
+	Delete(id string) error
+
+	// Run posts all digests that are due.
+	Run()
+}
@@ -0,0 +1,325 @@
+package app
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/pluginapi"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	digestPerPage = 200
+	// digestMaxObjects bounds the objects of a view summarized by a digest.
+	digestMaxObjects = 1000
+	// digestMaxChanges bounds the new or changed objects listed by a digest.
+	digestMaxChanges = 20
+)
+
+type digestService struct {
+	store                DigestStore
+	viewService          ViewService
+	propertyFieldService PropertyFieldService
+	api                  *pluginapi.Client
+	botID                string
+}
+
+func NewDigestService(store DigestStore, viewService ViewService, propertyFieldService PropertyFieldService, api *pluginapi.Client, botID string) DigestService {
+	return &digestService{
+		store:                store,
+		viewService:          viewService,
+		propertyFieldService: propertyFieldService,
+		api:                  api,
+		botID:                botID,
+	}
+}
+
+func (ds *digestService) Get(id string) (Digest, error) {
+	return ds.store.Get(id)
+}
+
+func (ds *digestService) Create(digest Digest) (string, error) {
+	nextRun, err := ds.validate(digest)
+	if err != nil {
+		return "", err
+	}
+	digest.NextRunAt = nextRun.UnixMilli()
+
+	return ds.store.Create(digest)
+}
+
+func (ds *digestService) GetForUser(userID string) ([]Digest, error) {
+	return ds.store.GetForUser(userID)
+}
+
+func (ds *digestService) Update(digest Digest) error {
+	nextRun, err := ds.validate(digest)
+	if err != nil {
+		return err
+	}
+	digest.NextRunAt = nextRun.UnixMilli()
+
+	return ds.store.Update(digest)
+}
+
+func (ds *digestService) Delete(id string) error {
+	return ds.store.Delete(id)
+}
+
+// validate checks the digest and returns when it should next be posted.
+func (ds *digestService) validate(digest Digest) (time.Time, error) {
+	if digest.ViewID == "" {
+		return time.Time{}, errors.New("ViewID should not be blank")
+	}
+
+	if digest.UserID == "" {
+		return time.Time{}, errors.New("UserID should not be blank")
+	}
+
+	if _, err := ds.viewService.Get(digest.ViewID); err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return time.Time{}, errors.Errorf("Tried to create digest with unknown view with id: '%s'", digest.ViewID)
+		}
+		return time.Time{}, err
+	}
+
+	return nextDigestRun(digest, time.Now())
+}
+
+func (ds *digestService) Run() {
+	now := time.Now()
+	digests, err := ds.store.GetDue(now.UnixMilli())
+	if err != nil {
+		logrus.WithError(err).Warn("Failed to get due digests")
+		return
+	}
+
+	for _, digest := range digests {
+		if err = ds.send(digest); err != nil {
+			logrus.WithError(err).WithField("digest_id", digest.ID).Warn("Failed to send digest")
+		}
+
+		// Schedule the next run even after a failure so a broken digest doesn't retry every run
+		nextRun, nextErr := nextDigestRun(digest, now)
+		if nextErr != nil {
+			logrus.WithError(nextErr).WithField("digest_id", digest.ID).Warn("Failed to schedule digest")
+			continue
+		}
+
+		if err = ds.store.UpdateSent(digest.ID, now.UnixMilli(), nextRun.UnixMilli()); err != nil {
+			logrus.WithError(err).WithField("digest_id", digest.ID).Warn("Failed to update digest")
+		}
+	}
+}
+
+// digestItem is an object of the view as summarized by the digest.
+type digestItem struct {
+	id         string
+	channelID  string
+	text       string
+	changedAt  int64
+	properties PropertiesList
+}
+
+func (ds *digestService) send(digest Digest) error {
+	view, err := ds.viewService.Get(digest.ViewID)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get view '%s'", digest.ViewID)
+	}
+
+	items, err := ds.viewItems(view)
+	if err != nil {
+		return err
+	}
+
+	since := digest.LastSentAt
+	if since == 0 {
+		since = digest.CreateAt
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("#### %s\n", view.Title))
+	sb.WriteString(fmt.Sprintf("%d items\n", len(items)))
+
+	if view.Format.GroupByFieldID != "" {
+		counts, countErr := ds.groupCounts(view.Format.GroupByFieldID, items)
+		if countErr != nil {
+			return countErr
+		}
+		sb.WriteString("\n| | Count |\n|---|---|\n")
+		for _, count := range counts {
+			sb.WriteString(fmt.Sprintf("| %s | %d |\n", count.name, count.count))
+		}
+	}
+
+	changed := []digestItem{}
+	for _, item := range items {
+		if item.changedAt > since {
+			changed = append(changed, item)
+		}
+	}
+	sort.Slice(changed, func(i, j int) bool { return changed[i].changedAt > changed[j].changedAt })
+
+	sb.WriteString(fmt.Sprintf("\n**New or changed since %s:** %d\n", time.UnixMilli(since).UTC().Format("Jan 2 15:04 MST"), len(changed)))
+	for i, item := range changed {
+		if i == digestMaxChanges {
+			sb.WriteString(fmt.Sprintf("- and %d more\n", len(changed)-digestMaxChanges))
+			break
+		}
+		sb.WriteString(fmt.Sprintf("- %s %s\n", item.text, permalink(ds.api, item.channelID, item.id)))
+	}
+
+	post := &model.Post{
+		UserId:  ds.botID,
+		Message: sb.String(),
+	}
+
+	if digest.ChannelID != "" {
+		post.ChannelId = digest.ChannelID
+		return ds.api.Post.CreatePost(post)
+	}
+
+	return ds.api.Post.DM(ds.botID, digest.UserID, post)
+}
+
+// viewItems collects the objects of the view, up to digestMaxObjects.
+func (ds *digestService) viewItems(view View) ([]digestItem, error) {
+	items := []digestItem{}
+	for page := 0; len(items) < digestMaxObjects; page++ {
+		objects, err := ds.viewService.GetObjectsForView(view.ID, page, digestPerPage)
+		if err != nil && !errors.Is(err, ErrNotFound) {
+			return nil, errors.Wrapf(err, "failed to get objects of view '%s'", view.ID)
+		}
+
+		for _, post := range objects.Posts {
+			items = append(items, newDigestItem(post.Id, post.ChannelId, post.Message, post.CreateAt, objects.Properties[post.Id]))
+		}
+
+		for _, file := range objects.Files {
+			channelID := ""
+			if file.PostId != "" {
+				if post, postErr := ds.api.Post.GetPost(file.PostId); postErr == nil {
+					channelID = post.ChannelId
+				}
+			}
+			items = append(items, newDigestItem(file.PostId, channelID, file.Name, file.CreateAt, objects.Properties[file.Id]))
+		}
+
+		if len(objects.Posts)+len(objects.Files) < digestPerPage {
+			break
+		}
+	}
+
+	return items, nil
+}
+
+func newDigestItem(id, channelID, text string, createAt int64, properties PropertiesList) digestItem {
+	text = strings.TrimSpace(strings.SplitN(text, "\n", 2)[0])
+	if len(text) > 80 {
+		text = text[:77] + "..."
+	}
+
+	changedAt := createAt
+	for _, property := range properties {
+		if property.UpdateAt > changedAt {
+			changedAt = property.UpdateAt
+		}
+	}
+
+	return digestItem{
+		id:         id,
+		channelID:  channelID,
+		text:       text,
+		changedAt:  changedAt,
+		properties: properties,
+	}
+}
+
+type digestCount struct {
+	name  string
+	count int
+}
+
+// groupCounts counts the items per option of the field, in the order of the options.
+func (ds *digestService) groupCounts(fieldID string, items []digestItem) ([]digestCount, error) {
+	field, err := ds.propertyFieldService.Get(fieldID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get group by field '%s'", fieldID)
+	}
+
+	counts := make([]digestCount, 0, len(field.Values)+1)
+	index := map[string]int{}
+	for _, option := range field.Values {
+		index[fmt.Sprint(option)] = len(counts)
+		counts = append(counts, digestCount{name: fmt.Sprint(option)})
+	}
+
+	noValue := 0
+	for _, item := range items {
+		grouped := false
+		for _, property := range item.properties {
+			if property.PropertyFieldID != fieldID || len(property.Value) == 0 {
+				continue
+			}
+
+			name := fmt.Sprint(property.Value[0])
+			i, ok := index[name]
+			if !ok {
+				i = len(counts)
+				index[name] = i
+				counts = append(counts, digestCount{name: name})
+			}
+			counts[i].count++
+			grouped = true
+			break
+		}
+
+		if !grouped {
+			noValue++
+		}
+	}
+
+	return append(counts, digestCount{name: "_No value_", count: noValue}), nil
+}
+
+// nextDigestRun returns the first time after the given time the digest should be posted.
+func nextDigestRun(digest Digest, after time.Time) (time.Time, error) {
+	location := time.UTC
+	if digest.Timezone != "" {
+		var err error
+		location, err = time.LoadLocation(digest.Timezone)
+		if err != nil {
+			return time.Time{}, errors.Errorf("Unknown timezone '%s'", digest.Timezone)
+		}
+	}
+
+	at, err := time.Parse("15:04", digest.Time)
+	if err != nil {
+		return time.Time{}, errors.Errorf("Time '%s' should be formatted as HH:MM", digest.Time)
+	}
+
+	local := after.In(location)
+	next := time.Date(local.Year(), local.Month(), local.Day(), at.Hour(), at.Minute(), 0, 0, location)
+
+	switch digest.Frequency {
+	case DigestFrequencyDaily:
+		if !next.After(after) {
+			next = next.AddDate(0, 0, 1)
+		}
+	case DigestFrequencyWeekly:
+		if digest.Weekday < 0 || digest.Weekday > 6 {
+			return time.Time{}, errors.New("Weekday should be between 0 and 6")
+		}
+		for !next.After(after) || next.Weekday() != time.Weekday(digest.Weekday) {
+			next = next.AddDate(0, 0, 1)
+		}
+	default:
+		return time.Time{}, errors.Errorf("Unknown frequency '%s'", digest.Frequency)
+	}
+
+	return next, nil
+}
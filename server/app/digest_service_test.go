@@ -0,0 +1,85 @@
+package app
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNextDigestRun(t *testing.T) {
+	// A Wednesday
+	after := time.Date(2024, 3, 13, 12, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		Name     string
+		Digest   Digest
+		Expected time.Time
+		Error    bool
+	}{
+		{
+			Name:     "daily later today",
+			Digest:   Digest{Frequency: DigestFrequencyDaily, Time: "17:30"},
+			Expected: time.Date(2024, 3, 13, 17, 30, 0, 0, time.UTC),
+		},
+		{
+			Name:     "daily already passed today",
+			Digest:   Digest{Frequency: DigestFrequencyDaily, Time: "09:00"},
+			Expected: time.Date(2024, 3, 14, 9, 0, 0, 0, time.UTC),
+		},
+		{
+			Name:     "daily exactly now",
+			Digest:   Digest{Frequency: DigestFrequencyDaily, Time: "12:00"},
+			Expected: time.Date(2024, 3, 14, 12, 0, 0, 0, time.UTC),
+		},
+		{
+			Name:     "daily in another timezone",
+			Digest:   Digest{Frequency: DigestFrequencyDaily, Time: "09:00", Timezone: "America/New_York"},
+			Expected: time.Date(2024, 3, 13, 13, 0, 0, 0, time.UTC),
+		},
+		{
+			Name:     "weekly later this week",
+			Digest:   Digest{Frequency: DigestFrequencyWeekly, Weekday: int(time.Friday), Time: "09:00"},
+			Expected: time.Date(2024, 3, 15, 9, 0, 0, 0, time.UTC),
+		},
+		{
+			Name:     "weekly same day already passed",
+			Digest:   Digest{Frequency: DigestFrequencyWeekly, Weekday: int(time.Wednesday), Time: "09:00"},
+			Expected: time.Date(2024, 3, 20, 9, 0, 0, 0, time.UTC),
+		},
+		{
+			Name:   "unknown frequency",
+			Digest: Digest{Frequency: "hourly", Time: "09:00"},
+			Error:  true,
+		},
+		{
+			Name:   "invalid time",
+			Digest: Digest{Frequency: DigestFrequencyDaily, Time: "9am"},
+			Error:  true,
+		},
+		{
+			Name:   "unknown timezone",
+			Digest: Digest{Frequency: DigestFrequencyDaily, Time: "09:00", Timezone: "Mars/Olympus"},
+			Error:  true,
+		},
+		{
+			Name:   "invalid weekday",
+			Digest: Digest{Frequency: DigestFrequencyWeekly, Weekday: 7, Time: "09:00"},
+			Error:  true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			next, err := nextDigestRun(c.Digest, after)
+			if c.Error {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.True(t, c.Expected.Equal(next), "expected %s, got %s", c.Expected, next)
+		})
+	}
+}
@@ -4,3 +4,15 @@ import "github.com/pkg/errors"
 
 // ErrNotFound used when an entity is not found.
 var ErrNotFound = errors.New("not found")
+
+// ErrInvalidValue used when a property value is rejected by a validator.
+var ErrInvalidValue = errors.New("invalid value")
+
+// ErrConflict used when an entity was changed since it was read.
+var ErrConflict = errors.New("conflict")
+
+// ErrLimitExceeded used when a change would exceed a configured limit.
+var ErrLimitExceeded = errors.New("limit exceeded")
+
+// ErrInvalidField used when the definition of a property field is rejected.
+var ErrInvalidField = errors.New("invalid field")
@@ -0,0 +1,472 @@
+package app
+
+import (
+	"fmt"
+	"math"
+	"net/mail"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/mattermost/mattermost/server/public/pluginapi"
+	"github.com/pkg/errors"
+)
+
+// FieldType defines what fields of a type and their values look like, who can read what values
+// reference, and how views filter and sort on them. Types are looked up by name, so adding one
+// doesn't mean touching the API, the services and the stores.
+type FieldType interface {
+	// ValidateField checks the definition of a field of the type.
+	ValidateField(ctx FieldTypeContext, field PropertyField) error
+	// Normalize converts a value to how it's stored, before it's validated.
+	Normalize(field PropertyField, value []interface{}) []interface{}
+	// ValidateValue checks the value of a property of a field of the type, belonging to
+	// ctx.ObjectID.
+	ValidateValue(ctx FieldTypeContext, field PropertyField, value []interface{}) error
+	// ReferencesRead checks that the user can read the objects a value of a field of the type
+	// references.
+	ReferencesRead(ctx FieldTypeContext, userID string, field PropertyField, value []interface{}) error
+	// Operators are the query condition operators supported on fields of the type.
+	Operators() []string
+	// Predicate builds the SQL predicate of a condition on a field of the type.
+	Predicate(condition Condition) (SQLExpr, error)
+	// Matches evaluates a condition against the value of a field of the type in memory, agreeing
+	// with Predicate. Unset fields have a nil value.
+	Matches(condition Condition, value []interface{}) bool
+	// SortKey builds the SQL expression ordering objects by the first value of a field of the type.
+	SortKey(fieldID string) SQLExpr
+}
+
+// FieldTypeContext is what field types check fields and values against.
+type FieldTypeContext struct {
+	// Fields looks up the fields a field depends on, such as the fields its formula references.
+	Fields PropertyFieldService
+	// API looks up the objects values reference.
+	API *pluginapi.Client
+	// ObjectID is the object whose value is checked.
+	ObjectID string
+}
+
+// SQLExpr is a SQL expression and the arguments of its placeholders. Expressions apply to the
+// Properties JSON column of PROP_Property_Query_View, aliased p, keyed by field id. Literal
+// question marks are escaped as ??.
+type SQLExpr struct {
+	SQL  string
+	Args []interface{}
+}
+
+var fieldTypes = map[string]FieldType{
+	PropertyFieldTypeText:     baseFieldType{name: PropertyFieldTypeText},
+	PropertyFieldTypeSelect:   selectFieldType{baseFieldType{name: PropertyFieldTypeSelect}},
+	PropertyFieldTypeUser:     baseFieldType{name: PropertyFieldTypeUser},
+	PropertyFieldTypeDate:     baseFieldType{name: PropertyFieldTypeDate},
+	PropertyFieldTypeNumber:   numberFieldType{baseFieldType{name: PropertyFieldTypeNumber}},
+	PropertyFieldTypeFormula:  formulaFieldType{baseFieldType{name: PropertyFieldTypeFormula}},
+	PropertyFieldTypeRelation: relationFieldType{baseFieldType{name: PropertyFieldTypeRelation}},
+	PropertyFieldTypeRollup:   rollupFieldType{numberFieldType{baseFieldType{name: PropertyFieldTypeRollup}}},
+	PropertyFieldTypeChannel:  referenceFieldType{baseFieldType{name: PropertyFieldTypeChannel}},
+	PropertyFieldTypeTeam:     referenceFieldType{baseFieldType{name: PropertyFieldTypeTeam}},
+	PropertyFieldTypeCheckbox: checkboxFieldType{baseFieldType{name: PropertyFieldTypeCheckbox}},
+	PropertyFieldTypeURL:      urlFieldType{domainFieldType{baseFieldType{name: PropertyFieldTypeURL}}},
+	PropertyFieldTypeEmail:    emailFieldType{domainFieldType{baseFieldType{name: PropertyFieldTypeEmail}}},
+	PropertyFieldTypeRating:   ratingFieldType{numberFieldType{baseFieldType{name: PropertyFieldTypeRating}}},
+}
+
+// GetFieldType returns the field type of the given name.
+func GetFieldType(name string) (FieldType, bool) {
+	fieldType, ok := fieldTypes[name]
+	return fieldType, ok
+}
+
+// propertyField returns the field of a property from the details the store joins to it.
+func propertyField(property Property) PropertyField {
+	return PropertyField{
+		ID:     property.PropertyFieldID,
+		Name:   property.PropertyFieldName,
+		Type:   property.PropertyFieldType,
+		Values: property.PropertyFieldValues,
+	}
+}
+
+// normalizeValue converts a value to how the type of its field stores it.
+func normalizeValue(property Property, value []interface{}) []interface{} {
+	fieldType, ok := GetFieldType(property.PropertyFieldType)
+	if !ok {
+		return value
+	}
+	return fieldType.Normalize(propertyField(property), value)
+}
+
+// validateType checks values against the type of their field.
+func (ps *propertyService) validateType(property Property) error {
+	fieldType, ok := GetFieldType(property.PropertyFieldType)
+	if !ok {
+		return nil
+	}
+
+	ctx := FieldTypeContext{Fields: ps.propertyFieldService, API: ps.api, ObjectID: property.ObjectID}
+	return fieldType.ValidateValue(ctx, propertyField(property), property.Value)
+}
+
+// baseFieldType implements the types without settings and whose values can be anything, not
+// referencing other objects. They support no condition operators and sort as text.
+type baseFieldType struct {
+	name string
+}
+
+func (t baseFieldType) ValidateField(ctx FieldTypeContext, field PropertyField) error {
+	switch {
+	case len(field.Values) > 0:
+		return errors.Errorf("Invalid values: %s type has no values", t.name)
+	case field.Formula != "":
+		return errors.New("Invalid formula: only formula type has a formula")
+	case field.Relation != nil:
+		return errors.New("Invalid relation: only relation type has relation settings")
+	case field.Rollup != nil:
+		return errors.New("Invalid rollup: only rollup type has rollup settings")
+	}
+	return nil
+}
+
+func (t baseFieldType) Normalize(field PropertyField, value []interface{}) []interface{} {
+	return value
+}
+
+func (t baseFieldType) ValidateValue(ctx FieldTypeContext, field PropertyField, value []interface{}) error {
+	return nil
+}
+
+func (t baseFieldType) ReferencesRead(ctx FieldTypeContext, userID string, field PropertyField, value []interface{}) error {
+	return nil
+}
+
+func (t baseFieldType) Operators() []string {
+	return nil
+}
+
+func (t baseFieldType) Predicate(condition Condition) (SQLExpr, error) {
+	return SQLExpr{}, t.unsupported(condition)
+}
+
+func (t baseFieldType) Matches(condition Condition, value []interface{}) bool {
+	return false
+}
+
+func (t baseFieldType) SortKey(fieldID string) SQLExpr {
+	return textKey(fieldID)
+}
+
+func (t baseFieldType) unsupported(condition Condition) error {
+	return errors.Errorf("operator '%s' is not supported by %s fields", condition.Operator, t.name)
+}
+
+// textKey is the first value of a field as text.
+func textKey(fieldID string) SQLExpr {
+	return SQLExpr{SQL: "p.Properties->?->>0", Args: []interface{}{fieldID}}
+}
+
+// numberKey is the first value of a field when it's a JSON number, and NULL otherwise.
+func numberKey(fieldID string) SQLExpr {
+	return SQLExpr{
+		SQL:  "CASE WHEN json_typeof(p.Properties->?->0) = 'number' THEN (p.Properties->?->>0)::float8 END",
+		Args: []interface{}{fieldID, fieldID},
+	}
+}
+
+// numberFieldType compares and sorts values as numbers, converting numeric strings to numbers.
+// Values which aren't numbers never match a comparison and sort last.
+type numberFieldType struct {
+	baseFieldType
+}
+
+func (t numberFieldType) Normalize(field PropertyField, value []interface{}) []interface{} {
+	normalized := make([]interface{}, len(value))
+	for i, v := range value {
+		normalized[i] = v
+		if s, ok := v.(string); ok {
+			if number, err := strconv.ParseFloat(strings.TrimSpace(s), 64); err == nil {
+				normalized[i] = number
+			}
+		}
+	}
+	return normalized
+}
+
+func (t numberFieldType) ValidateValue(ctx FieldTypeContext, field PropertyField, value []interface{}) error {
+	for _, v := range value {
+		if _, ok := v.(float64); !ok {
+			return errors.Errorf("Values of number field '%s' should be numbers", field.Name)
+		}
+	}
+	return nil
+}
+
+func (t numberFieldType) Operators() []string {
+	return []string{OperatorGreaterOrEqual, OperatorLessOrEqual}
+}
+
+func (t numberFieldType) Predicate(condition Condition) (SQLExpr, error) {
+	var operator string
+	switch condition.Operator {
+	case OperatorGreaterOrEqual:
+		operator = ">="
+	case OperatorLessOrEqual:
+		operator = "<="
+	default:
+		return SQLExpr{}, t.unsupported(condition)
+	}
+
+	operand, ok := numberValue(condition.Value)
+	if !ok {
+		return SQLExpr{}, errors.Errorf("operand '%s' of operator '%s' should be a number", condition.Value, condition.Operator)
+	}
+
+	key := numberKey(condition.FieldID)
+	return SQLExpr{SQL: fmt.Sprintf("%s %s ?", key.SQL, operator), Args: append(key.Args, operand)}, nil
+}
+
+func (t numberFieldType) Matches(condition Condition, value []interface{}) bool {
+	if len(value) == 0 {
+		return false
+	}
+	number, ok := value[0].(float64)
+	operand, operandOK := numberValue(condition.Value)
+	if !ok || !operandOK {
+		return false
+	}
+
+	switch condition.Operator {
+	case OperatorGreaterOrEqual:
+		return number >= operand
+	case OperatorLessOrEqual:
+		return number <= operand
+	}
+	return false
+}
+
+func (t numberFieldType) SortKey(fieldID string) SQLExpr {
+	return numberKey(fieldID)
+}
+
+type selectFieldType struct {
+	baseFieldType
+}
+
+func (t selectFieldType) ValidateField(ctx FieldTypeContext, field PropertyField) error {
+	for _, v := range field.Values {
+		if _, ok := v.(string); !ok {
+			return errors.New("Invalid values: select type must have string values")
+		}
+	}
+	field.Values = nil
+	return t.baseFieldType.ValidateField(ctx, field)
+}
+
+func (t selectFieldType) ValidateValue(ctx FieldTypeContext, field PropertyField, value []interface{}) error {
+	for _, v := range value {
+		s, ok := v.(string)
+		if !ok || !hasOption(field, s) {
+			return errors.Errorf("'%v' is not an option of field '%s'", v, field.Name)
+		}
+	}
+	return nil
+}
+
+// formulaFieldType values are computed numbers, text or bools. Numbers sort first, as numbers,
+// the text of other values breaking ties.
+type formulaFieldType struct {
+	baseFieldType
+}
+
+func (t formulaFieldType) SortKey(fieldID string) SQLExpr {
+	return numberKey(fieldID)
+}
+
+// ValidateField parses the formula against the fields of the team of the field.
+func (t formulaFieldType) ValidateField(ctx FieldTypeContext, field PropertyField) error {
+	formula := field.Formula
+	field.Formula = ""
+	if err := t.baseFieldType.ValidateField(ctx, field); err != nil {
+		return err
+	}
+
+	fields, err := formulaFields(ctx.Fields, field.TeamID)
+	if err != nil {
+		return err
+	}
+
+	_, err = ParseFormula(formula, fields)
+	return err
+}
+
+// checkboxFieldType values are the strings "true" or "false" rather than JSON bools, like formulas
+// computing bools. Includes and excludes of views filter with the jsonb ? operator, which only
+// matches strings, and kanban columns group by the text of values, so strings let checkboxes be
+// filtered and grouped on like options. Bools sent by clients are normalized to strings.
+type checkboxFieldType struct {
+	baseFieldType
+}
+
+func (t checkboxFieldType) Normalize(field PropertyField, value []interface{}) []interface{} {
+	normalized := make([]interface{}, len(value))
+	for i, v := range value {
+		normalized[i] = v
+		if checked, ok := v.(bool); ok {
+			normalized[i] = strconv.FormatBool(checked)
+		}
+	}
+	return normalized
+}
+
+func (t checkboxFieldType) Operators() []string {
+	return []string{OperatorIsChecked}
+}
+
+func (t checkboxFieldType) Predicate(condition Condition) (SQLExpr, error) {
+	if condition.Operator != OperatorIsChecked {
+		return SQLExpr{}, t.unsupported(condition)
+	}
+	return SQLExpr{SQL: "p.Properties->?->>0 = 'true'", Args: []interface{}{condition.FieldID}}, nil
+}
+
+func (t checkboxFieldType) Matches(condition Condition, value []interface{}) bool {
+	return condition.Operator == OperatorIsChecked && len(value) > 0 && value[0] == "true"
+}
+
+func (t checkboxFieldType) ValidateValue(ctx FieldTypeContext, field PropertyField, value []interface{}) error {
+	if len(value) > 1 {
+		return errors.Errorf("Checkbox field '%s' should have a single value", field.Name)
+	}
+	for _, v := range value {
+		if v != "true" && v != "false" {
+			return errors.Errorf("Value of checkbox field '%s' should be 'true' or 'false'", field.Name)
+		}
+	}
+	return nil
+}
+
+// domainFieldType matches values by their domain, the host of URLs or the part of email
+// addresses after the '@'.
+type domainFieldType struct {
+	baseFieldType
+}
+
+func (t domainFieldType) Normalize(field PropertyField, value []interface{}) []interface{} {
+	return trimValues(value)
+}
+
+func (t domainFieldType) Operators() []string {
+	return []string{OperatorDomainEquals}
+}
+
+func (t domainFieldType) Predicate(condition Condition) (SQLExpr, error) {
+	if condition.Operator != OperatorDomainEquals {
+		return SQLExpr{}, t.unsupported(condition)
+	}
+
+	domain := "COALESCE(substring(v from '^[A-Za-z][A-Za-z0-9+.-]*://(??:[^@/]*@)??([^/:??#]+)'), substring(v from '@([^@]*)$'))"
+	return SQLExpr{
+		SQL:  fmt.Sprintf("EXISTS (SELECT 1 FROM json_array_elements_text(p.Properties->?) v WHERE lower(%s) = lower(?))", domain),
+		Args: []interface{}{condition.FieldID, condition.Value},
+	}, nil
+}
+
+func (t domainFieldType) Matches(condition Condition, value []interface{}) bool {
+	if condition.Operator != OperatorDomainEquals {
+		return false
+	}
+	for _, v := range value {
+		if valueDomain(v) == strings.ToLower(condition.Value) {
+			return true
+		}
+	}
+	return false
+}
+
+// urlFieldType values are absolute http or https URLs.
+type urlFieldType struct {
+	domainFieldType
+}
+
+func (t urlFieldType) ValidateValue(ctx FieldTypeContext, field PropertyField, value []interface{}) error {
+	for _, v := range value {
+		s, ok := v.(string)
+		if !ok {
+			return errors.Errorf("Values of URL field '%s' should be URLs", field.Name)
+		}
+		u, err := url.ParseRequestURI(s)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+			return errors.Errorf("'%s' of field '%s' should be an http or https URL", s, field.Name)
+		}
+	}
+	return nil
+}
+
+// emailFieldType values are bare email addresses, without a display name.
+type emailFieldType struct {
+	domainFieldType
+}
+
+func (t emailFieldType) ValidateValue(ctx FieldTypeContext, field PropertyField, value []interface{}) error {
+	for _, v := range value {
+		s, ok := v.(string)
+		if !ok {
+			return errors.Errorf("Values of email field '%s' should be email addresses", field.Name)
+		}
+		address, err := mail.ParseAddress(s)
+		if err != nil || address.Address != s {
+			return errors.Errorf("'%s' of field '%s' should be an email address", s, field.Name)
+		}
+	}
+	return nil
+}
+
+// ratingFieldType values are whole numbers from 1 to maxRating.
+type ratingFieldType struct {
+	numberFieldType
+}
+
+const maxRating = 5
+
+func (t ratingFieldType) ValidateValue(ctx FieldTypeContext, field PropertyField, value []interface{}) error {
+	if len(value) > 1 {
+		return errors.Errorf("Rating field '%s' should have a single value", field.Name)
+	}
+	for _, v := range value {
+		rating, ok := v.(float64)
+		if !ok || rating != math.Trunc(rating) || rating < 1 || rating > maxRating {
+			return errors.Errorf("Value of rating field '%s' should be a whole number from 1 to %d", field.Name, maxRating)
+		}
+	}
+	return nil
+}
+
+// trimValues trims the spaces around string values.
+func trimValues(value []interface{}) []interface{} {
+	trimmed := make([]interface{}, len(value))
+	for i, v := range value {
+		trimmed[i] = v
+		if s, ok := v.(string); ok {
+			trimmed[i] = strings.TrimSpace(s)
+		}
+	}
+	return trimmed
+}
+
+// valueDomain returns the lowercased domain of a URL or email address, blank for other values.
+func valueDomain(value interface{}) string {
+	s, ok := value.(string)
+	if !ok {
+		return ""
+	}
+
+	if u, err := url.Parse(s); err == nil && u.Scheme != "" && u.Host != "" {
+		return strings.ToLower(u.Hostname())
+	}
+
+	if i := strings.LastIndex(s, "@"); i >= 0 {
+		return strings.ToLower(s[i+1:])
+	}
+
+	return ""
+}
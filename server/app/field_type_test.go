@@ -0,0 +1,208 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFieldTypeValidateValue(t *testing.T) {
+	cases := []struct {
+		Name      string
+		FieldType string
+		Value     []interface{}
+		Valid     bool
+	}{
+		{"checked", PropertyFieldTypeCheckbox, []interface{}{"true"}, true},
+		{"unchecked", PropertyFieldTypeCheckbox, []interface{}{"false"}, true},
+		{"checkbox bool", PropertyFieldTypeCheckbox, []interface{}{true}, false},
+		{"checkbox values", PropertyFieldTypeCheckbox, []interface{}{"true", "false"}, false},
+		{"url", PropertyFieldTypeURL, []interface{}{"https://docs.example.com/spec?v=2"}, true},
+		{"url without scheme", PropertyFieldTypeURL, []interface{}{"docs.example.com"}, false},
+		{"url scheme", PropertyFieldTypeURL, []interface{}{"javascript:alert(1)"}, false},
+		{"email", PropertyFieldTypeEmail, []interface{}{"jo@example.com"}, true},
+		{"email name", PropertyFieldTypeEmail, []interface{}{"Jo <jo@example.com>"}, false},
+		{"email invalid", PropertyFieldTypeEmail, []interface{}{"jo"}, false},
+		{"rating", PropertyFieldTypeRating, []interface{}{float64(5)}, true},
+		{"rating fraction", PropertyFieldTypeRating, []interface{}{float64(2.5)}, false},
+		{"rating zero", PropertyFieldTypeRating, []interface{}{float64(0)}, false},
+		{"rating string", PropertyFieldTypeRating, []interface{}{"3"}, false},
+		{"rating unset", PropertyFieldTypeRating, []interface{}{}, true},
+		{"number", PropertyFieldTypeNumber, []interface{}{float64(2.5), float64(-1)}, true},
+		{"number string", PropertyFieldTypeNumber, []interface{}{"2.5"}, false},
+		{"number bool", PropertyFieldTypeNumber, []interface{}{true}, false},
+		{"number unset", PropertyFieldTypeNumber, []interface{}{}, true},
+		{"option", PropertyFieldTypeSelect, []interface{}{"Open"}, true},
+		{"options", PropertyFieldTypeSelect, []interface{}{"Open", "Done"}, true},
+		{"unknown option", PropertyFieldTypeSelect, []interface{}{"Closed"}, false},
+		{"option number", PropertyFieldTypeSelect, []interface{}{float64(1)}, false},
+		{"option unset", PropertyFieldTypeSelect, []interface{}{}, true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			fieldType, ok := GetFieldType(c.FieldType)
+			assert.True(t, ok)
+
+			err := fieldType.ValidateValue(FieldTypeContext{}, PropertyField{Name: "Field", Type: c.FieldType, Values: []interface{}{"Open", "Done"}}, c.Value)
+			assert.Equal(t, c.Valid, err == nil, err)
+		})
+	}
+}
+
+func TestFieldTypeMatches(t *testing.T) {
+	cases := []struct {
+		Name      string
+		FieldType string
+		Condition Condition
+		Value     []interface{}
+		Expected  bool
+	}{
+		{"is checked", PropertyFieldTypeCheckbox, Condition{Operator: OperatorIsChecked}, []interface{}{"true"}, true},
+		{"is not checked", PropertyFieldTypeCheckbox, Condition{Operator: OperatorIsChecked}, []interface{}{"false"}, false},
+		{"checkbox unset", PropertyFieldTypeCheckbox, Condition{Operator: OperatorIsChecked}, nil, false},
+		{"url domain", PropertyFieldTypeURL, Condition{Operator: OperatorDomainEquals, Value: "Example.com"}, []interface{}{"https://user@example.com:8080/a"}, true},
+		{"url subdomain", PropertyFieldTypeURL, Condition{Operator: OperatorDomainEquals, Value: "example.com"}, []interface{}{"https://docs.example.com"}, false},
+		{"email domain", PropertyFieldTypeEmail, Condition{Operator: OperatorDomainEquals, Value: "example.com"}, []interface{}{"jo@other.org", "jo@EXAMPLE.com"}, true},
+		{"rating greater", PropertyFieldTypeRating, Condition{Operator: OperatorGreaterOrEqual, Value: "4"}, []interface{}{float64(4)}, true},
+		{"rating lower", PropertyFieldTypeRating, Condition{Operator: OperatorGreaterOrEqual, Value: "4"}, []interface{}{float64(3)}, false},
+		{"rating at most", PropertyFieldTypeRating, Condition{Operator: OperatorLessOrEqual, Value: "4"}, []interface{}{float64(3)}, true},
+		{"number text", PropertyFieldTypeNumber, Condition{Operator: OperatorLessOrEqual, Value: "4"}, []interface{}{"3"}, false},
+		{"number unset", PropertyFieldTypeNumber, Condition{Operator: OperatorGreaterOrEqual, Value: "1"}, nil, false},
+		{"unsupported operator", PropertyFieldTypeNumber, Condition{Operator: OperatorIsChecked}, []interface{}{float64(1)}, false},
+		{"text", PropertyFieldTypeText, Condition{Operator: OperatorDomainEquals, Value: "example.com"}, []interface{}{"jo@example.com"}, false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			fieldType, ok := GetFieldType(c.FieldType)
+			assert.True(t, ok)
+			assert.Equal(t, c.Expected, fieldType.Matches(c.Condition, c.Value))
+		})
+	}
+}
+
+func TestFieldTypeValidateField(t *testing.T) {
+	ctx := FieldTypeContext{Fields: &fakePropertyFieldService{fields: []PropertyField{
+		{ID: "points", Name: "Points", Type: PropertyFieldTypeNumber},
+		{ID: "status", Name: "Status", Type: PropertyFieldTypeSelect},
+		{ID: "left", Name: "Left", Type: PropertyFieldTypeFormula},
+		{ID: "tasks", Name: "Tasks", Type: PropertyFieldTypeRelation},
+		{ID: "epic", Name: "Epic", Type: PropertyFieldTypeRelation, Relation: &RelationSettings{BackLinkFieldID: "tasks"}},
+	}}}
+
+	cases := []struct {
+		Name  string
+		Field PropertyField
+		Valid bool
+	}{
+		{"text", PropertyField{Type: PropertyFieldTypeText}, true},
+		{"text values", PropertyField{Type: PropertyFieldTypeText, Values: []interface{}{"a"}}, false},
+		{"select values", PropertyField{Type: PropertyFieldTypeSelect, Values: []interface{}{"a", "b"}}, true},
+		{"select number values", PropertyField{Type: PropertyFieldTypeSelect, Values: []interface{}{float64(1)}}, false},
+		{"formula", PropertyField{Type: PropertyFieldTypeFormula, Formula: "points * 2"}, true},
+		{"formula unknown field", PropertyField{Type: PropertyFieldTypeFormula, Formula: "effort * 2"}, false},
+		{"number formula", PropertyField{Type: PropertyFieldTypeNumber, Formula: "points * 2"}, false},
+		{"relation", PropertyField{Type: PropertyFieldTypeRelation, Relation: &RelationSettings{}}, true},
+		{"relation object type", PropertyField{Type: PropertyFieldTypeRelation, Relation: &RelationSettings{ObjectType: PropertyObjectTypeFile}}, false},
+		{"relation back-link", PropertyField{ID: "subtasks", Type: PropertyFieldTypeRelation, Relation: &RelationSettings{BackLinkFieldID: "tasks"}}, true},
+		{"relation back-link not a relation", PropertyField{Type: PropertyFieldTypeRelation, Relation: &RelationSettings{BackLinkFieldID: "points"}}, false},
+		{"relation back-link linking back to another field", PropertyField{ID: "subtasks", Type: PropertyFieldTypeRelation, Relation: &RelationSettings{BackLinkFieldID: "epic"}}, false},
+		{"rollup relation", PropertyField{Type: PropertyFieldTypeRollup, Relation: &RelationSettings{}}, false},
+		{"rollup", PropertyField{Type: PropertyFieldTypeRollup, Rollup: &RollupSettings{RelationFieldID: "tasks", FieldID: "points", Function: RollupFunctionSum}}, true},
+		{"rollup without settings", PropertyField{Type: PropertyFieldTypeRollup}, false},
+		{"rollup unknown function", PropertyField{Type: PropertyFieldTypeRollup, Rollup: &RollupSettings{RelationFieldID: "tasks", Function: "avg"}}, false},
+		{"rollup of a field other than a relation", PropertyField{Type: PropertyFieldTypeRollup, Rollup: &RollupSettings{RelationFieldID: "points", Function: RollupFunctionCount}}, false},
+		{"rollup sum of a select", PropertyField{Type: PropertyFieldTypeRollup, Rollup: &RollupSettings{RelationFieldID: "tasks", FieldID: "status", Function: RollupFunctionSum}}, false},
+		{"rollup of a computed field", PropertyField{Type: PropertyFieldTypeRollup, Rollup: &RollupSettings{RelationFieldID: "tasks", FieldID: "left", Function: RollupFunctionCount}}, false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			fieldType, ok := GetFieldType(c.Field.Type)
+			assert.True(t, ok)
+
+			err := fieldType.ValidateField(ctx, c.Field)
+			assert.Equal(t, c.Valid, err == nil, err)
+		})
+	}
+}
+
+func TestFieldTypeNormalize(t *testing.T) {
+	cases := []struct {
+		Name      string
+		FieldType string
+		Value     []interface{}
+		Expected  []interface{}
+	}{
+		{"checkbox bool", PropertyFieldTypeCheckbox, []interface{}{true}, []interface{}{"true"}},
+		{"rating string", PropertyFieldTypeRating, []interface{}{" 4"}, []interface{}{float64(4)}},
+		{"number text", PropertyFieldTypeNumber, []interface{}{"many"}, []interface{}{"many"}},
+		{"url spaces", PropertyFieldTypeURL, []interface{}{" https://example.com "}, []interface{}{"https://example.com"}},
+		{"text", PropertyFieldTypeText, []interface{}{" 4 "}, []interface{}{" 4 "}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			fieldType, ok := GetFieldType(c.FieldType)
+			assert.True(t, ok)
+			assert.Equal(t, c.Expected, fieldType.Normalize(PropertyField{Type: c.FieldType}, c.Value))
+		})
+	}
+}
+
+func TestFieldTypePredicate(t *testing.T) {
+	cases := []struct {
+		Name         string
+		FieldType    string
+		Condition    Condition
+		ExpectedSQL  string
+		ExpectedArgs []interface{}
+	}{
+		{
+			Name:         "rating at least",
+			FieldType:    PropertyFieldTypeRating,
+			Condition:    Condition{FieldID: "stars", Operator: OperatorGreaterOrEqual, Value: "4"},
+			ExpectedSQL:  "CASE WHEN json_typeof(p.Properties->?->0) = 'number' THEN (p.Properties->?->>0)::float8 END >= ?",
+			ExpectedArgs: []interface{}{"stars", "stars", float64(4)},
+		},
+		{
+			Name:         "checkbox checked",
+			FieldType:    PropertyFieldTypeCheckbox,
+			Condition:    Condition{FieldID: "done", Operator: OperatorIsChecked},
+			ExpectedSQL:  "p.Properties->?->>0 = 'true'",
+			ExpectedArgs: []interface{}{"done"},
+		},
+		{
+			Name:      "rating operand",
+			FieldType: PropertyFieldTypeRating,
+			Condition: Condition{FieldID: "stars", Operator: OperatorGreaterOrEqual, Value: "many"},
+		},
+		{
+			Name:      "rating unsupported operator",
+			FieldType: PropertyFieldTypeRating,
+			Condition: Condition{FieldID: "stars", Operator: OperatorIsChecked},
+		},
+		{
+			Name:      "checkbox unsupported operator",
+			FieldType: PropertyFieldTypeCheckbox,
+			Condition: Condition{FieldID: "done", Operator: OperatorGreaterOrEqual, Value: "1"},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			fieldType, ok := GetFieldType(c.FieldType)
+			assert.True(t, ok)
+
+			predicate, err := fieldType.Predicate(c.Condition)
+			if c.ExpectedSQL == "" {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, c.ExpectedSQL, predicate.SQL)
+			assert.Equal(t, c.ExpectedArgs, predicate.Args)
+		})
+	}
+}
@@ -0,0 +1,800 @@
+package app
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/pkg/errors"
+)
+
+// Formulas are expressions computing the value of a formula field from the other properties of
+// the same object and its metadata, for example:
+//
+//	days_since(create_at)
+//	if(status = "Done", 0, points * 2)
+//
+// Fields are referenced by name, lowercased with spaces replaced by underscores, or verbatim
+// between brackets ("[Story points]"). The metadata of the object is create_at and update_at.
+// Values are numbers, text, booleans and dates. Missing values are null and spread through
+// arithmetic, so a formula over a field without a value has no value either.
+
+// maxFormulaLength bounds the length of the expression of a formula field.
+const maxFormulaLength = 1024
+
+const (
+	FormulaTypeNumber = "number"
+	FormulaTypeText   = "text"
+	FormulaTypeBool   = "bool"
+	FormulaTypeDate   = "date"
+)
+
+const (
+	formulaMetadataCreateAt = "create_at"
+	formulaMetadataUpdateAt = "update_at"
+)
+
+type FormulaService interface {
+	// Run recomputes the formulas changing over time, such as the number of days since a date.
+	Run()
+	// Close waits for the formulas being computed for the objects of a team.
+	Close()
+}
+
+// Formula is a parsed and type checked formula.
+type Formula struct {
+	root *formulaNode
+
+	// FieldIDs are the fields the formula depends on.
+	FieldIDs map[string]bool
+	// Metadata is set when the formula depends on the metadata of the object.
+	Metadata bool
+	// Volatile is set when the value of the formula changes over time without any of its
+	// dependencies changing.
+	Volatile bool
+}
+
+// Type returns the type of the values of the formula.
+func (f *Formula) Type() string {
+	return f.root.typ
+}
+
+// FormulaInputs are the values a formula is evaluated against, keyed by property field id.
+type FormulaInputs struct {
+	Values   map[string][]interface{}
+	CreateAt int64
+	UpdateAt int64
+	Now      time.Time
+}
+
+const (
+	formulaNodeLiteral = iota
+	formulaNodeField
+	formulaNodeMetadata
+	formulaNodeUnary
+	formulaNodeBinary
+	formulaNodeCall
+)
+
+type formulaNode struct {
+	kind  int
+	typ   string
+	op    string
+	value interface{}
+	field PropertyField
+	args  []*formulaNode
+}
+
+// formulaFunction describes a function of the language. Blank argument types accept any type,
+// and the result of if has the type of its branches.
+type formulaFunction struct {
+	args     []string
+	result   string
+	volatile bool
+}
+
+var formulaFunctions = map[string]formulaFunction{
+	"if":         {args: []string{FormulaTypeBool, "", ""}},
+	"days_since": {args: []string{FormulaTypeDate}, result: FormulaTypeNumber, volatile: true},
+	"days_until": {args: []string{FormulaTypeDate}, result: FormulaTypeNumber, volatile: true},
+	"today":      {args: []string{}, result: FormulaTypeDate, volatile: true},
+	"round":      {args: []string{FormulaTypeNumber}, result: FormulaTypeNumber},
+	"min":        {args: []string{FormulaTypeNumber, FormulaTypeNumber}, result: FormulaTypeNumber},
+	"max":        {args: []string{FormulaTypeNumber, FormulaTypeNumber}, result: FormulaTypeNumber},
+	"empty":      {args: []string{""}, result: FormulaTypeBool},
+}
+
+const (
+	formulaTokenEOF = iota
+	formulaTokenNumber
+	formulaTokenString
+	formulaTokenIdent
+	formulaTokenField
+	formulaTokenOp
+)
+
+// formulaOperators are the operators of the language, longest first so they are matched greedily.
+var formulaOperators = []string{"!=", "<=", ">=", "+", "-", "*", "/", "(", ")", ",", "=", "<", ">"}
+
+type formulaToken struct {
+	kind int
+	text string
+	pos  int
+}
+
+// tokenizeFormula splits an expression into tokens.
+func tokenizeFormula(expression string) ([]formulaToken, error) {
+	runes := []rune(expression)
+	tokens := []formulaToken{}
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+
+		case unicode.IsDigit(r) || (r == '.' && i+1 < len(runes) && unicode.IsDigit(runes[i+1])):
+			start := i
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, formulaToken{formulaTokenNumber, string(runes[start:i]), start})
+
+		case r == '"':
+			start := i
+			var sb strings.Builder
+			for i++; ; i++ {
+				if i >= len(runes) {
+					return nil, errors.Errorf("Unterminated string at position %d", start+1)
+				}
+				if runes[i] == '\\' && i+1 < len(runes) {
+					i++
+					sb.WriteRune(runes[i])
+					continue
+				}
+				if runes[i] == '"' {
+					i++
+					break
+				}
+				sb.WriteRune(runes[i])
+			}
+			tokens = append(tokens, formulaToken{formulaTokenString, sb.String(), start})
+
+		case r == '[':
+			end := i + 1
+			for end < len(runes) && runes[end] != ']' {
+				end++
+			}
+			if end == len(runes) {
+				return nil, errors.Errorf("Unterminated field name at position %d", i+1)
+			}
+			tokens = append(tokens, formulaToken{formulaTokenField, string(runes[i+1 : end]), i})
+			i = end + 1
+
+		case unicode.IsLetter(r) || r == '_':
+			start := i
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_') {
+				i++
+			}
+			tokens = append(tokens, formulaToken{formulaTokenIdent, string(runes[start:i]), start})
+
+		default:
+			op := ""
+			for _, candidate := range formulaOperators {
+				if strings.HasPrefix(string(runes[i:]), candidate) {
+					op = candidate
+					break
+				}
+			}
+			if op == "" {
+				return nil, errors.Errorf("Unexpected '%c' at position %d", r, i+1)
+			}
+			tokens = append(tokens, formulaToken{formulaTokenOp, op, i})
+			i += len(op)
+		}
+	}
+
+	return append(tokens, formulaToken{formulaTokenEOF, "", len(runes)}), nil
+}
+
+// formulaParser is a recursive descent parser checking the types of the expression as it goes.
+type formulaParser struct {
+	tokens  []formulaToken
+	pos     int
+	fields  map[string]PropertyField
+	formula *Formula
+}
+
+// fieldKey is how a field is referenced without brackets.
+func fieldKey(name string) string {
+	return strings.ReplaceAll(strings.ToLower(strings.TrimSpace(name)), " ", "_")
+}
+
+// ParseFormula parses an expression against the fields it may reference, returning an error
+// describing the first syntax or type error.
+func ParseFormula(expression string, fields []PropertyField) (*Formula, error) {
+	if strings.TrimSpace(expression) == "" {
+		return nil, errors.New("Formula should not be blank")
+	}
+	if len(expression) > maxFormulaLength {
+		return nil, errors.Errorf("Formula should not be longer than %d characters", maxFormulaLength)
+	}
+
+	tokens, err := tokenizeFormula(expression)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &formulaParser{
+		tokens:  tokens,
+		fields:  make(map[string]PropertyField, len(fields)),
+		formula: &Formula{FieldIDs: map[string]bool{}},
+	}
+	for _, field := range fields {
+		p.fields[fieldKey(field.Name)] = field
+	}
+
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if token := p.peek(); token.kind != formulaTokenEOF {
+		return nil, errors.Errorf("Unexpected '%s' at position %d", token.text, token.pos+1)
+	}
+
+	p.formula.root = root
+	return p.formula, nil
+}
+
+func (p *formulaParser) peek() formulaToken {
+	return p.tokens[p.pos]
+}
+
+func (p *formulaParser) next() formulaToken {
+	token := p.tokens[p.pos]
+	if token.kind != formulaTokenEOF {
+		p.pos++
+	}
+	return token
+}
+
+// acceptOp consumes the next token if it is one of the operators or keywords.
+func (p *formulaParser) acceptOp(ops ...string) (string, bool) {
+	token := p.peek()
+	if token.kind != formulaTokenOp && token.kind != formulaTokenIdent {
+		return "", false
+	}
+	for _, op := range ops {
+		if strings.EqualFold(token.text, op) {
+			p.next()
+			return op, true
+		}
+	}
+	return "", false
+}
+
+func (p *formulaParser) expectOp(op string) error {
+	if _, ok := p.acceptOp(op); !ok {
+		token := p.peek()
+		if token.kind == formulaTokenEOF {
+			return errors.Errorf("Expected '%s' at the end of the formula", op)
+		}
+		return errors.Errorf("Expected '%s' at position %d", op, token.pos+1)
+	}
+	return nil
+}
+
+func (p *formulaParser) parseOr() (*formulaNode, error) {
+	return p.parseLogical("or", p.parseAnd)
+}
+
+func (p *formulaParser) parseAnd() (*formulaNode, error) {
+	return p.parseLogical("and", p.parseNot)
+}
+
+func (p *formulaParser) parseLogical(op string, operand func() (*formulaNode, error)) (*formulaNode, error) {
+	left, err := operand()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		if _, ok := p.acceptOp(op); !ok {
+			return left, nil
+		}
+		right, rightErr := operand()
+		if rightErr != nil {
+			return nil, rightErr
+		}
+		if left.typ != FormulaTypeBool || right.typ != FormulaTypeBool {
+			return nil, errors.Errorf("'%s' expects booleans, got %s and %s", op, left.typ, right.typ)
+		}
+		left = &formulaNode{kind: formulaNodeBinary, typ: FormulaTypeBool, op: op, args: []*formulaNode{left, right}}
+	}
+}
+
+func (p *formulaParser) parseNot() (*formulaNode, error) {
+	if _, ok := p.acceptOp("not"); !ok {
+		return p.parseComparison()
+	}
+
+	operand, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	if operand.typ != FormulaTypeBool {
+		return nil, errors.Errorf("'not' expects a boolean, got %s", operand.typ)
+	}
+	return &formulaNode{kind: formulaNodeUnary, typ: FormulaTypeBool, op: "not", args: []*formulaNode{operand}}, nil
+}
+
+func (p *formulaParser) parseComparison() (*formulaNode, error) {
+	left, err := p.parseAdditive()
+	if err != nil {
+		return nil, err
+	}
+
+	op, ok := p.acceptOp("=", "!=", "<=", ">=", "<", ">")
+	if !ok {
+		return left, nil
+	}
+
+	right, err := p.parseAdditive()
+	if err != nil {
+		return nil, err
+	}
+	if left.typ != right.typ {
+		return nil, errors.Errorf("'%s' compares values of the same type, got %s and %s", op, left.typ, right.typ)
+	}
+	if left.typ == FormulaTypeBool && op != "=" && op != "!=" {
+		return nil, errors.Errorf("'%s' does not compare booleans", op)
+	}
+	return &formulaNode{kind: formulaNodeBinary, typ: FormulaTypeBool, op: op, args: []*formulaNode{left, right}}, nil
+}
+
+func (p *formulaParser) parseAdditive() (*formulaNode, error) {
+	left, err := p.parseMultiplicative()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		op, ok := p.acceptOp("+", "-")
+		if !ok {
+			return left, nil
+		}
+		right, rightErr := p.parseMultiplicative()
+		if rightErr != nil {
+			return nil, rightErr
+		}
+
+		// Days are added to and subtracted from dates, and dates subtracted from each other
+		var typ string
+		switch {
+		case left.typ == FormulaTypeNumber && right.typ == FormulaTypeNumber:
+			typ = FormulaTypeNumber
+		case op == "+" && left.typ == FormulaTypeText && right.typ == FormulaTypeText:
+			typ = FormulaTypeText
+		case left.typ == FormulaTypeDate && right.typ == FormulaTypeNumber:
+			typ = FormulaTypeDate
+		case op == "-" && left.typ == FormulaTypeDate && right.typ == FormulaTypeDate:
+			typ = FormulaTypeNumber
+		default:
+			return nil, errors.Errorf("'%s' does not apply to %s and %s", op, left.typ, right.typ)
+		}
+		left = &formulaNode{kind: formulaNodeBinary, typ: typ, op: op, args: []*formulaNode{left, right}}
+	}
+}
+
+func (p *formulaParser) parseMultiplicative() (*formulaNode, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		op, ok := p.acceptOp("*", "/")
+		if !ok {
+			return left, nil
+		}
+		right, rightErr := p.parseUnary()
+		if rightErr != nil {
+			return nil, rightErr
+		}
+		if left.typ != FormulaTypeNumber || right.typ != FormulaTypeNumber {
+			return nil, errors.Errorf("'%s' expects numbers, got %s and %s", op, left.typ, right.typ)
+		}
+		left = &formulaNode{kind: formulaNodeBinary, typ: FormulaTypeNumber, op: op, args: []*formulaNode{left, right}}
+	}
+}
+
+func (p *formulaParser) parseUnary() (*formulaNode, error) {
+	if _, ok := p.acceptOp("-"); !ok {
+		return p.parsePrimary()
+	}
+
+	operand, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	if operand.typ != FormulaTypeNumber {
+		return nil, errors.Errorf("'-' expects a number, got %s", operand.typ)
+	}
+	return &formulaNode{kind: formulaNodeUnary, typ: FormulaTypeNumber, op: "-", args: []*formulaNode{operand}}, nil
+}
+
+func (p *formulaParser) parsePrimary() (*formulaNode, error) {
+	token := p.next()
+
+	switch token.kind {
+	case formulaTokenNumber:
+		value, err := strconv.ParseFloat(token.text, 64)
+		if err != nil {
+			return nil, errors.Errorf("Invalid number '%s' at position %d", token.text, token.pos+1)
+		}
+		return &formulaNode{kind: formulaNodeLiteral, typ: FormulaTypeNumber, value: value}, nil
+
+	case formulaTokenString:
+		return &formulaNode{kind: formulaNodeLiteral, typ: FormulaTypeText, value: token.text}, nil
+
+	case formulaTokenField:
+		return p.fieldNode(token.text, token.pos)
+
+	case formulaTokenIdent:
+		name := strings.ToLower(token.text)
+		switch {
+		case name == "true" || name == "false":
+			return &formulaNode{kind: formulaNodeLiteral, typ: FormulaTypeBool, value: name == "true"}, nil
+		case p.peek().kind == formulaTokenOp && p.peek().text == "(":
+			return p.callNode(name, token.pos)
+		case name == formulaMetadataCreateAt || name == formulaMetadataUpdateAt:
+			p.formula.Metadata = true
+			if name == formulaMetadataUpdateAt {
+				// Editing a post doesn't change any property, so its update time is polled
+				p.formula.Volatile = true
+			}
+			return &formulaNode{kind: formulaNodeMetadata, typ: FormulaTypeDate, op: name}, nil
+		}
+		return p.fieldNode(token.text, token.pos)
+
+	case formulaTokenOp:
+		if token.text == "(" {
+			node, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			if err = p.expectOp(")"); err != nil {
+				return nil, err
+			}
+			return node, nil
+		}
+		return nil, errors.Errorf("Unexpected '%s' at position %d", token.text, token.pos+1)
+	}
+
+	return nil, errors.New("Unexpected end of the formula")
+}
+
+func (p *formulaParser) fieldNode(name string, pos int) (*formulaNode, error) {
+	field, ok := p.fields[fieldKey(name)]
+	if !ok {
+		return nil, errors.Errorf("Unknown field '%s' at position %d", name, pos+1)
+	}
+
+	var typ string
+	switch field.Type {
+	case PropertyFieldTypeNumber:
+		typ = FormulaTypeNumber
+	case PropertyFieldTypeDate:
+		typ = FormulaTypeDate
+	case PropertyFieldTypeFormula:
+		return nil, errors.Errorf("Field '%s' is a formula, formulas cannot reference other formulas", field.Name)
+	case PropertyFieldTypeRollup:
+		return nil, errors.Errorf("Field '%s' is a rollup, formulas cannot reference rollups", field.Name)
+	default:
+		typ = FormulaTypeText
+	}
+
+	p.formula.FieldIDs[field.ID] = true
+	return &formulaNode{kind: formulaNodeField, typ: typ, field: field}, nil
+}
+
+func (p *formulaParser) callNode(name string, pos int) (*formulaNode, error) {
+	function, ok := formulaFunctions[name]
+	if !ok {
+		return nil, errors.Errorf("Unknown function '%s' at position %d", name, pos+1)
+	}
+
+	if err := p.expectOp("("); err != nil {
+		return nil, err
+	}
+	args := []*formulaNode{}
+	if _, ok = p.acceptOp(")"); !ok {
+		for {
+			arg, argErr := p.parseOr()
+			if argErr != nil {
+				return nil, argErr
+			}
+			args = append(args, arg)
+			if _, ok = p.acceptOp(","); !ok {
+				break
+			}
+		}
+		if err := p.expectOp(")"); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(args) != len(function.args) {
+		return nil, errors.Errorf("'%s' expects %d arguments, got %d", name, len(function.args), len(args))
+	}
+	for i, typ := range function.args {
+		if typ != "" && args[i].typ != typ {
+			return nil, errors.Errorf("Argument %d of '%s' should be a %s, got %s", i+1, name, typ, args[i].typ)
+		}
+	}
+
+	result := function.result
+	if name == "if" {
+		if args[1].typ != args[2].typ {
+			return nil, errors.Errorf("Both branches of 'if' should have the same type, got %s and %s", args[1].typ, args[2].typ)
+		}
+		result = args[1].typ
+	}
+
+	if function.volatile {
+		p.formula.Volatile = true
+	}
+
+	return &formulaNode{kind: formulaNodeCall, typ: result, op: name, args: args}, nil
+}
+
+// Evaluate computes the value of the formula, nil when it has no value.
+func (f *Formula) Evaluate(inputs FormulaInputs) interface{} {
+	return f.root.eval(inputs)
+}
+
+// Value returns the value of the formula as stored in a property, dates formatted as days and
+// booleans as text so they can be filtered on like options. Returns nil when it has no value.
+func (f *Formula) Value(inputs FormulaInputs) []interface{} {
+	switch v := f.Evaluate(inputs).(type) {
+	case float64:
+		return []interface{}{v}
+	case string:
+		return []interface{}{v}
+	case bool:
+		return []interface{}{strconv.FormatBool(v)}
+	case time.Time:
+		return []interface{}{v.Format(dateLayout)}
+	}
+	return nil
+}
+
+// day truncates a time to its day, in UTC.
+func day(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func (n *formulaNode) eval(inputs FormulaInputs) interface{} {
+	switch n.kind {
+	case formulaNodeLiteral:
+		return n.value
+
+	case formulaNodeField:
+		return fieldInput(n, inputs.Values[n.field.ID])
+
+	case formulaNodeMetadata:
+		millis := inputs.CreateAt
+		if n.op == formulaMetadataUpdateAt {
+			millis = inputs.UpdateAt
+		}
+		if millis == 0 {
+			return nil
+		}
+		return day(time.UnixMilli(millis).UTC())
+
+	case formulaNodeUnary:
+		operand := n.args[0].eval(inputs)
+		if n.op == "not" {
+			b, _ := operand.(bool)
+			return !b
+		}
+		if number, ok := operand.(float64); ok {
+			return -number
+		}
+		return nil
+
+	case formulaNodeBinary:
+		return n.evalBinary(inputs)
+
+	case formulaNodeCall:
+		return n.evalCall(inputs)
+	}
+
+	return nil
+}
+
+// fieldInput converts the first value of a field to the type the formula expects.
+func fieldInput(n *formulaNode, value []interface{}) interface{} {
+	if len(value) == 0 || value[0] == nil {
+		return nil
+	}
+
+	switch n.typ {
+	case FormulaTypeNumber:
+		switch v := value[0].(type) {
+		case float64:
+			return v
+		case string:
+			if number, err := strconv.ParseFloat(v, 64); err == nil {
+				return number
+			}
+		}
+		return nil
+	case FormulaTypeDate:
+		if t, ok := ParseDateValue(value[0]); ok {
+			return day(t)
+		}
+		return nil
+	}
+
+	return fmt.Sprint(value[0])
+}
+
+func (n *formulaNode) evalBinary(inputs FormulaInputs) interface{} {
+	left := n.args[0].eval(inputs)
+
+	// Logical operators short circuit and treat null as false
+	switch n.op {
+	case "and":
+		if b, _ := left.(bool); !b {
+			return false
+		}
+		b, _ := n.args[1].eval(inputs).(bool)
+		return b
+	case "or":
+		if b, _ := left.(bool); b {
+			return true
+		}
+		b, _ := n.args[1].eval(inputs).(bool)
+		return b
+	}
+
+	right := n.args[1].eval(inputs)
+
+	switch n.op {
+	case "=":
+		return formulaEqual(left, right)
+	case "!=":
+		return !formulaEqual(left, right)
+	case "<", "<=", ">", ">=":
+		cmp, ok := formulaCompare(left, right)
+		if !ok {
+			return false
+		}
+		switch n.op {
+		case "<":
+			return cmp < 0
+		case "<=":
+			return cmp <= 0
+		case ">":
+			return cmp > 0
+		}
+		return cmp >= 0
+	}
+
+	if left == nil || right == nil {
+		return nil
+	}
+
+	switch l := left.(type) {
+	case float64:
+		r := right.(float64)
+		switch n.op {
+		case "+":
+			return l + r
+		case "-":
+			return l - r
+		case "*":
+			return l * r
+		case "/":
+			if r == 0 {
+				return nil
+			}
+			return l / r
+		}
+	case string:
+		return l + right.(string)
+	case time.Time:
+		switch r := right.(type) {
+		case float64:
+			if n.op == "-" {
+				r = -r
+			}
+			return l.AddDate(0, 0, int(r))
+		case time.Time:
+			return math.Round(l.Sub(r).Hours() / 24)
+		}
+	}
+
+	return nil
+}
+
+func formulaEqual(left, right interface{}) bool {
+	if left == nil || right == nil {
+		return left == nil && right == nil
+	}
+	if l, ok := left.(time.Time); ok {
+		return l.Equal(right.(time.Time))
+	}
+	return left == right
+}
+
+// formulaCompare orders two values of the same type, failing when either is null.
+func formulaCompare(left, right interface{}) (int, bool) {
+	if left == nil || right == nil {
+		return 0, false
+	}
+
+	switch l := left.(type) {
+	case float64:
+		r := right.(float64)
+		switch {
+		case l < r:
+			return -1, true
+		case l > r:
+			return 1, true
+		}
+		return 0, true
+	case string:
+		return strings.Compare(l, right.(string)), true
+	case time.Time:
+		return l.Compare(right.(time.Time)), true
+	}
+
+	return 0, false
+}
+
+func (n *formulaNode) evalCall(inputs FormulaInputs) interface{} {
+	if n.op == "if" {
+		if b, _ := n.args[0].eval(inputs).(bool); b {
+			return n.args[1].eval(inputs)
+		}
+		return n.args[2].eval(inputs)
+	}
+
+	args := make([]interface{}, len(n.args))
+	for i, arg := range n.args {
+		args[i] = arg.eval(inputs)
+	}
+
+	switch n.op {
+	case "today":
+		return day(inputs.Now.UTC())
+	case "empty":
+		return args[0] == nil || args[0] == ""
+	}
+
+	for _, arg := range args {
+		if arg == nil {
+			return nil
+		}
+	}
+
+	switch n.op {
+	case "days_since":
+		return math.Round(day(inputs.Now.UTC()).Sub(args[0].(time.Time)).Hours() / 24)
+	case "days_until":
+		return math.Round(args[0].(time.Time).Sub(day(inputs.Now.UTC())).Hours() / 24)
+	case "round":
+		return math.Round(args[0].(float64))
+	case "min":
+		return math.Min(args[0].(float64), args[1].(float64))
+	case "max":
+		return math.Max(args[0].(float64), args[1].(float64))
+	}
+
+	return nil
+}
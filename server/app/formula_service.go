@@ -0,0 +1,340 @@
+package app
+
+import (
+	"sync"
+	"time"
+
+	"github.com/mattermost/mattermost/server/public/pluginapi"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// formulaObjectsPerPage is how many objects are loaded at once when recomputing a formula for a team.
+const formulaObjectsPerPage = 200
+
+// formulaCacheTTL is how long the parsed formulas of a team are kept. Fields changed on this node
+// clear them right away, the TTL catching up with the changes made on other nodes and deletions.
+const formulaCacheTTL = time.Minute
+
+// fieldFormula is a formula field with its parsed formula.
+type fieldFormula struct {
+	field   PropertyField
+	formula *Formula
+}
+
+// teamFormulas are the parsed formulas applying to the objects of a team.
+type teamFormulas struct {
+	formulas []fieldFormula
+	parsedAt time.Time
+}
+
+// formulaService keeps the values of formula fields up to date. Computed values are written with
+// SetComputedValue, since the PropertyService rejects other writes to formula fields.
+type formulaService struct {
+	store                PropertyStore
+	propertyService      PropertyService
+	propertyFieldService PropertyFieldService
+	api                  *pluginapi.Client
+
+	formulasLock sync.Mutex
+	formulas     map[string]teamFormulas
+	// formulasGeneration changes whenever the formulas are cleared, so formulas parsed from fields
+	// loaded before aren't cached.
+	formulasGeneration int
+
+	recomputing sync.WaitGroup
+}
+
+func NewFormulaService(store PropertyStore, propertyService PropertyService, propertyFieldService PropertyFieldService, api *pluginapi.Client) FormulaService {
+	fs := &formulaService{
+		store:                store,
+		propertyService:      propertyService,
+		propertyFieldService: propertyFieldService,
+		api:                  api,
+		formulas:             map[string]teamFormulas{},
+	}
+
+	propertyService.RegisterValidator(fs.validateComputed)
+	propertyService.RegisterChangeListener(fs.onPropertyChange)
+	propertyFieldService.RegisterChangeListener(fs.onFieldChange)
+
+	return fs
+}
+
+// validateComputed rejects values set on formula fields other than the computed ones.
+func (fs *formulaService) validateComputed(property Property) error {
+	if property.PropertyFieldType == PropertyFieldTypeFormula && !property.Computed {
+		return errors.Errorf("Values of formula field '%s' are computed", property.PropertyFieldName)
+	}
+	return nil
+}
+
+// onPropertyChange recomputes the formulas of the object depending on the changed field. Formulas
+// without any field are computed as soon as the object has properties.
+func (fs *formulaService) onPropertyChange(change PropertyChange) {
+	property := change.Property
+	if property.PropertyFieldType == PropertyFieldTypeFormula {
+		return
+	}
+
+	formulas, err := fs.getFormulas(property.TeamID)
+	if err != nil {
+		logrus.WithError(err).WithField("team_id", property.TeamID).Warn("Failed to get formulas")
+		return
+	}
+
+	dependent := []fieldFormula{}
+	for _, f := range formulas {
+		if f.formula.FieldIDs[property.PropertyFieldID] || len(f.formula.FieldIDs) == 0 {
+			dependent = append(dependent, f)
+		}
+	}
+	if len(dependent) == 0 {
+		return
+	}
+
+	object := PropertyObject{
+		ObjectID:   property.ObjectID,
+		ObjectType: property.ObjectType,
+		ChannelID:  property.ChannelID,
+		TeamID:     property.TeamID,
+	}
+	if err = fs.recompute(object, dependent, time.Now()); err != nil {
+		logrus.WithError(err).WithField("object_id", property.ObjectID).Warn("Failed to recompute formulas")
+	}
+}
+
+// onFieldChange computes a formula for all the objects of its team once saved. It runs in the
+// background since it goes over every object of the team. Any field changing may change what
+// formulas reference, so the parsed formulas are cleared.
+func (fs *formulaService) onFieldChange(field PropertyField) {
+	fs.formulasLock.Lock()
+	fs.formulas = map[string]teamFormulas{}
+	fs.formulasGeneration++
+	fs.formulasLock.Unlock()
+
+	if field.Type != PropertyFieldTypeFormula {
+		return
+	}
+
+	fs.recomputing.Add(1)
+	go func() {
+		defer fs.recomputing.Done()
+		formulas, err := fs.getFormulas(field.TeamID)
+		if err != nil {
+			logrus.WithError(err).WithField("property_field_id", field.ID).Warn("Failed to get formulas")
+			return
+		}
+
+		for _, f := range formulas {
+			if f.field.ID == field.ID {
+				fs.recomputeTeam(field.TeamID, []fieldFormula{f}, time.Now())
+				return
+			}
+		}
+	}()
+}
+
+func (fs *formulaService) Close() {
+	fs.recomputing.Wait()
+}
+
+// Run recomputes the formulas changing over time, for the objects having a value for the formula
+// or any field it references.
+func (fs *formulaService) Run() {
+	fields, err := getAllFields(fs.propertyFieldService, PropertyFieldFilterOptions{Types: []string{PropertyFieldTypeFormula}})
+	if err != nil {
+		logrus.WithError(err).Warn("Failed to get formula fields")
+		return
+	}
+
+	teamIDs := map[string]bool{}
+	for _, field := range fields {
+		teamIDs[field.TeamID] = true
+	}
+
+	now := time.Now()
+	for teamID := range teamIDs {
+		formulas, formulasErr := fs.getFormulas(teamID)
+		if formulasErr != nil {
+			logrus.WithError(formulasErr).WithField("team_id", teamID).Warn("Failed to get formulas")
+			continue
+		}
+
+		objects := map[string]PropertyObject{}
+		dependent := map[string][]fieldFormula{}
+		for _, f := range formulas {
+			if !f.formula.Volatile || f.field.TeamID != teamID {
+				continue
+			}
+
+			if err = fs.addObjects(f, objects, dependent); err != nil {
+				logrus.WithError(err).WithField("property_field_id", f.field.ID).Warn("Failed to get objects of formula")
+			}
+		}
+
+		for objectID, objectFormulas := range dependent {
+			if err = fs.recompute(objects[objectID], objectFormulas, now); err != nil {
+				logrus.WithError(err).WithField("object_id", objectID).Warn("Failed to recompute formulas")
+			}
+		}
+	}
+}
+
+// addObjects adds the objects of the team of a formula having a value for it or for any field it
+// references, with the formulas to recompute for each of them.
+func (fs *formulaService) addObjects(f fieldFormula, objects map[string]PropertyObject, dependent map[string][]fieldFormula) error {
+	fieldIDs := []string{f.field.ID}
+	for fieldID := range f.formula.FieldIDs {
+		fieldIDs = append(fieldIDs, fieldID)
+	}
+
+	added := map[string]bool{}
+	for _, fieldID := range fieldIDs {
+		filter := PropertyFilterOptions{PropertyFieldID: fieldID, TeamID: f.field.TeamID, PerPage: formulaObjectsPerPage}
+		for ; ; filter.Page++ {
+			properties, err := fs.propertyService.GetProperties(filter)
+			if err != nil && !errors.Is(err, ErrNotFound) {
+				return errors.Wrapf(err, "could not get properties of field '%s'", fieldID)
+			}
+
+			for _, property := range properties {
+				if added[property.ObjectID] {
+					continue
+				}
+				added[property.ObjectID] = true
+
+				objects[property.ObjectID] = PropertyObject{
+					ObjectID:   property.ObjectID,
+					ObjectType: property.ObjectType,
+					ChannelID:  property.ChannelID,
+					TeamID:     property.TeamID,
+				}
+				dependent[property.ObjectID] = append(dependent[property.ObjectID], f)
+			}
+
+			if len(properties) < formulaObjectsPerPage {
+				break
+			}
+		}
+	}
+
+	return nil
+}
+
+// getFormulas returns the parsed formulas applying to the objects of a team, skipping the ones
+// which don't parse anymore, for example after a field they reference was deleted. They are
+// parsed again once formulaCacheTTL passed.
+func (fs *formulaService) getFormulas(teamID string) ([]fieldFormula, error) {
+	fs.formulasLock.Lock()
+	cached, ok := fs.formulas[teamID]
+	generation := fs.formulasGeneration
+	fs.formulasLock.Unlock()
+	if ok && time.Since(cached.parsedAt) < formulaCacheTTL {
+		return cached.formulas, nil
+	}
+
+	parsedAt := time.Now()
+	fields, err := formulaFields(fs.propertyFieldService, teamID)
+	if err != nil {
+		return nil, err
+	}
+
+	formulas := []fieldFormula{}
+	for _, field := range fields {
+		if field.Type != PropertyFieldTypeFormula {
+			continue
+		}
+
+		formula, parseErr := ParseFormula(field.Formula, fields)
+		if parseErr != nil {
+			logrus.WithError(parseErr).WithField("property_field_id", field.ID).Warn("Failed to parse formula")
+			continue
+		}
+		formulas = append(formulas, fieldFormula{field: field, formula: formula})
+	}
+
+	fs.formulasLock.Lock()
+	if generation == fs.formulasGeneration {
+		fs.formulas[teamID] = teamFormulas{formulas: formulas, parsedAt: parsedAt}
+	}
+	fs.formulasLock.Unlock()
+
+	return formulas, nil
+}
+
+// recomputeTeam recomputes formulas for all the objects of a team, or of all teams when blank.
+func (fs *formulaService) recomputeTeam(teamID string, formulas []fieldFormula, now time.Time) {
+	for page := 0; ; page++ {
+		objects, err := fs.store.GetObjects(teamID, page, formulaObjectsPerPage)
+		if err != nil {
+			logrus.WithError(err).WithField("team_id", teamID).Warn("Failed to get objects")
+			return
+		}
+
+		for _, object := range objects {
+			if err = fs.recompute(object, formulas, now); err != nil {
+				logrus.WithError(err).WithField("object_id", object.ObjectID).Warn("Failed to recompute formulas")
+			}
+		}
+
+		if len(objects) < formulaObjectsPerPage {
+			return
+		}
+	}
+}
+
+// recompute evaluates formulas against the properties of an object, writing the values which changed.
+func (fs *formulaService) recompute(object PropertyObject, formulas []fieldFormula, now time.Time) error {
+	properties, err := fs.propertyService.GetForObject(object.ObjectID)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return errors.Wrap(err, "could not get properties of object")
+	}
+
+	inputs := FormulaInputs{Values: map[string][]interface{}{}, Now: now}
+	for _, property := range properties {
+		inputs.Values[property.PropertyFieldID] = property.Value
+	}
+
+	for _, f := range formulas {
+		if f.formula.Metadata {
+			inputs.CreateAt, inputs.UpdateAt, err = objectTimes(fs.api, object)
+			if err != nil {
+				return err
+			}
+			break
+		}
+	}
+
+	for _, f := range formulas {
+		if err = fs.propertyService.SetComputedValue(object, f.field.ID, f.formula.Value(inputs)); err != nil {
+			return errors.Wrapf(err, "could not set value of formula field '%s'", f.field.ID)
+		}
+	}
+
+	return nil
+}
+
+// objectTimes returns when an object was created and last updated.
+func objectTimes(api *pluginapi.Client, object PropertyObject) (int64, int64, error) {
+	switch object.ObjectType {
+	case PropertyObjectTypeChannel:
+		channel, err := api.Channel.Get(object.ObjectID)
+		if err != nil {
+			return 0, 0, errors.Wrapf(err, "failed to get channel '%s'", object.ObjectID)
+		}
+		return channel.CreateAt, channel.UpdateAt, nil
+	case PropertyObjectTypeFile:
+		fileInfo, err := api.File.GetInfo(object.ObjectID)
+		if err != nil {
+			return 0, 0, errors.Wrapf(err, "failed to get file '%s'", object.ObjectID)
+		}
+		return fileInfo.CreateAt, fileInfo.UpdateAt, nil
+	}
+
+	post, err := api.Post.GetPost(object.ObjectID)
+	if err != nil {
+		return 0, 0, errors.Wrapf(err, "failed to get post '%s'", object.ObjectID)
+	}
+	return post.CreateAt, post.UpdateAt, nil
+}
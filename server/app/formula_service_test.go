@@ -0,0 +1,101 @@
+package app
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost/server/public/model"
+)
+
+// countingPropertyFieldService counts the calls to GetFields.
+type countingPropertyFieldService struct {
+	fakePropertyFieldService
+	getFieldsCalls int
+}
+
+func (s *countingPropertyFieldService) GetFields(filter PropertyFieldFilterOptions) ([]PropertyField, error) {
+	s.getFieldsCalls++
+	return s.fakePropertyFieldService.GetFields(filter)
+}
+
+func newTestFormulaService(properties map[string][]Property) (*formulaService, *countingPropertyFieldService, *fakePropertyService) {
+	fieldService := &countingPropertyFieldService{fakePropertyFieldService: fakePropertyFieldService{fields: []PropertyField{
+		{ID: "due", TeamID: "team1", Name: "Due", Type: PropertyFieldTypeDate},
+		{ID: "status", TeamID: "team1", Name: "Status", Type: PropertyFieldTypeSelect},
+		{ID: "left", TeamID: "team1", Name: "Left", Type: PropertyFieldTypeFormula, Formula: "days_until(due)"},
+		{ID: "double", TeamID: "team1", Name: "Double", Type: PropertyFieldTypeFormula, Formula: `status + status`},
+	}}}
+	propertyService := &fakePropertyService{properties: properties}
+
+	fs := &formulaService{
+		propertyService:      propertyService,
+		propertyFieldService: fieldService,
+		formulas:             map[string]teamFormulas{},
+	}
+	return fs, fieldService, propertyService
+}
+
+func TestFormulaServiceCachesFormulas(t *testing.T) {
+	fs, fieldService, _ := newTestFormulaService(nil)
+
+	formulas, err := fs.getFormulas("team1")
+	require.NoError(t, err)
+	require.Len(t, formulas, 2)
+
+	_, err = fs.getFormulas("team1")
+	require.NoError(t, err)
+	assert.Equal(t, 1, fieldService.getFieldsCalls)
+
+	fs.onFieldChange(PropertyField{ID: "status", TeamID: "team1", Type: PropertyFieldTypeSelect})
+	_, err = fs.getFormulas("team1")
+	require.NoError(t, err)
+	assert.Equal(t, 2, fieldService.getFieldsCalls)
+
+	fs.formulas["team1"] = teamFormulas{formulas: formulas, parsedAt: time.Now().Add(-formulaCacheTTL)}
+	_, err = fs.getFormulas("team1")
+	require.NoError(t, err)
+	assert.Equal(t, 3, fieldService.getFieldsCalls)
+}
+
+func TestFormulaServiceRun(t *testing.T) {
+	due := time.Now().UTC().AddDate(0, 0, 3).Format("2006-01-02")
+	fs, _, propertyService := newTestFormulaService(map[string][]Property{
+		"dated":     {{ObjectID: "dated", TeamID: "team1", PropertyFieldID: "due", Value: []interface{}{due}}},
+		"undated":   {{ObjectID: "undated", TeamID: "team1", PropertyFieldID: "status", Value: []interface{}{"Open"}}},
+		"otherteam": {{ObjectID: "otherteam", TeamID: "team2", PropertyFieldID: "due", Value: []interface{}{due}}},
+	})
+
+	fs.Run()
+
+	// Only the volatile formula is recomputed, for the objects of its team having its field
+	require.Len(t, propertyService.computed, 1)
+	assert.Equal(t, "dated", propertyService.computed[0].ObjectID)
+	assert.Equal(t, "left", propertyService.computed[0].PropertyFieldID)
+	assert.Equal(t, []interface{}{float64(3)}, propertyService.computed[0].Value)
+}
+
+func TestFormulaServiceAddObjects(t *testing.T) {
+	properties := map[string][]Property{}
+	for i := 0; i < formulaObjectsPerPage+1; i++ {
+		objectID := model.NewId()
+		properties[objectID] = []Property{{ID: model.NewId(), ObjectID: objectID, TeamID: "team1", PropertyFieldID: "due"}}
+	}
+	fs, _, _ := newTestFormulaService(properties)
+
+	formulas, err := fs.getFormulas("team1")
+	require.NoError(t, err)
+
+	objects := map[string]PropertyObject{}
+	dependent := map[string][]fieldFormula{}
+	for _, f := range formulas {
+		if f.field.ID == "left" {
+			require.NoError(t, fs.addObjects(f, objects, dependent))
+		}
+	}
+
+	assert.Len(t, objects, formulaObjectsPerPage+1)
+	assert.Len(t, dependent, formulaObjectsPerPage+1)
+}
@@ -0,0 +1,98 @@
+package app
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormula(t *testing.T) {
+	fields := []PropertyField{
+		{ID: "status", Name: "Status", Type: PropertyFieldTypeSelect},
+		{ID: "points", Name: "Points", Type: PropertyFieldTypeNumber},
+		{ID: "due", Name: "Due date", Type: PropertyFieldTypeDate},
+		{ID: "age", Name: "Age", Type: PropertyFieldTypeFormula},
+	}
+	now := time.Date(2024, 3, 10, 15, 0, 0, 0, time.UTC)
+	inputs := FormulaInputs{
+		Values: map[string][]interface{}{
+			"status": {"Open"},
+			"points": {float64(3)},
+			"due":    {"2024-03-15"},
+		},
+		CreateAt: time.Date(2024, 3, 1, 23, 0, 0, 0, time.UTC).UnixMilli(),
+		Now:      now,
+	}
+
+	cases := []struct {
+		Expression string
+		Expected   []interface{}
+		Type       string
+	}{
+		{`days_since(create_at)`, []interface{}{float64(9)}, FormulaTypeNumber},
+		{`if(status = "Done", 0, points * 2)`, []interface{}{float64(6)}, FormulaTypeNumber},
+		{`if(status != "Done" and points >= 3, "big", "small")`, []interface{}{"big"}, FormulaTypeText},
+		{`days_until([Due date])`, []interface{}{float64(5)}, FormulaTypeNumber},
+		{`due_date + 7`, []interface{}{"2024-03-22"}, FormulaTypeDate},
+		{`due_date - create_at`, []interface{}{float64(14)}, FormulaTypeNumber},
+		{`not empty(status)`, []interface{}{"true"}, FormulaTypeBool},
+		{`round(points / 2) + -1`, []interface{}{float64(1)}, FormulaTypeNumber},
+		{`max(points, 10) - min(points, 10)`, []interface{}{float64(7)}, FormulaTypeNumber},
+		{`status + "!"`, []interface{}{"Open!"}, FormulaTypeText},
+		{`points / 0`, nil, FormulaTypeNumber},
+		{`update_at`, nil, FormulaTypeDate},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Expression, func(t *testing.T) {
+			formula, err := ParseFormula(c.Expression, fields)
+			require.NoError(t, err)
+			assert.Equal(t, c.Type, formula.Type())
+			assert.Equal(t, c.Expected, formula.Value(inputs))
+		})
+	}
+
+	t.Run("dependencies", func(t *testing.T) {
+		formula, err := ParseFormula(`if(status = "Done", 0, points)`, fields)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]bool{"status": true, "points": true}, formula.FieldIDs)
+		assert.False(t, formula.Metadata)
+		assert.False(t, formula.Volatile)
+
+		formula, err = ParseFormula(`days_since(create_at)`, fields)
+		require.NoError(t, err)
+		assert.Empty(t, formula.FieldIDs)
+		assert.True(t, formula.Metadata)
+		assert.True(t, formula.Volatile)
+	})
+
+	errorCases := []struct {
+		Expression string
+		Expected   string
+	}{
+		{``, "Formula should not be blank"},
+		{`points +`, "Unexpected end of the formula"},
+		{`(points`, "Expected ')' at the end of the formula"},
+		{`points # 2`, "Unexpected '#' at position 8"},
+		{`"open`, "Unterminated string at position 1"},
+		{`owner`, "Unknown field 'owner' at position 1"},
+		{`age * 2`, "Field 'Age' is a formula, formulas cannot reference other formulas"},
+		{`status * 2`, "'*' expects numbers, got text and number"},
+		{`status = 2`, "'=' compares values of the same type, got text and number"},
+		{`if(points, 1, 2)`, "Argument 1 of 'if' should be a bool, got number"},
+		{`if(points > 1, 1, "2")`, "Both branches of 'if' should have the same type, got number and text"},
+		{`days_since(points)`, "Argument 1 of 'days_since' should be a date, got number"},
+		{`round(1, 2)`, "'round' expects 1 arguments, got 2"},
+		{`sqrt(4)`, "Unknown function 'sqrt' at position 1"},
+	}
+
+	for _, c := range errorCases {
+		t.Run(c.Expected, func(t *testing.T) {
+			_, err := ParseFormula(c.Expression, fields)
+			require.Error(t, err)
+			assert.Equal(t, c.Expected, err.Error())
+		})
+	}
+}
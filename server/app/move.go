@@ -0,0 +1,212 @@
+package app
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+// Move places an object of a kanban view in the column of Value, blank for the objects without a
+// value, at Position within the column.
+type Move struct {
+	ObjectID   string `json:"object_id"`
+	ObjectType string `json:"object_type"`
+	Value      string `json:"value"`
+	Position   int    `json:"position"`
+	// UpdateAt is the update time of the view the move is based on. The move fails with
+	// ErrConflict when the view was changed since, unless it's zero.
+	UpdateAt int64 `json:"update_at"`
+	// Force moves the object even when its column is at its WIP limit.
+	Force bool `json:"force"`
+}
+
+// moveInOrders returns the column orders with the object removed from its column and inserted
+// at the position of the column of value, or last when the position is past its end.
+func moveInOrders(orders map[string][]string, objectID, value string, position int) map[string][]string {
+	moved := make(map[string][]string, len(orders)+1)
+	for column, order := range orders {
+		remaining := make([]string, 0, len(order))
+		for _, id := range order {
+			if id != objectID {
+				remaining = append(remaining, id)
+			}
+		}
+		if len(remaining) > 0 {
+			moved[column] = remaining
+		}
+	}
+
+	order := moved[value]
+	if position > len(order) {
+		position = len(order)
+	}
+
+	moved[value] = append(order[:position:position], append([]string{objectID}, order[position:]...)...)
+
+	return moved
+}
+
+// orderColumn sorts the objects of a column by its order, keeping the objects it doesn't list
+// last in their original order.
+func orderColumn(objectIDs []string, order []string) []string {
+	positions := make(map[string]int, len(order))
+	for i, id := range order {
+		positions[id] = i
+	}
+
+	sorted := make([]string, len(objectIDs))
+	copy(sorted, objectIDs)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		pi, iListed := positions[sorted[i]]
+		pj, jListed := positions[sorted[j]]
+		if iListed && jListed {
+			return pi < pj
+		}
+		return iListed && !jListed
+	})
+
+	return sorted
+}
+
+func (vs *viewService) Move(id string, move Move) (View, error) {
+	if move.ObjectID == "" {
+		return View{}, errors.New("ObjectID should not be blank")
+	}
+
+	if move.Position < 0 {
+		return View{}, errors.New("Position should not be negative")
+	}
+
+	if move.ObjectType == "" {
+		move.ObjectType = PropertyObjectTypePost
+	}
+
+	view, err := vs.store.Get(id)
+	if err != nil {
+		return View{}, errors.Wrap(err, "could not get view")
+	}
+
+	if view.Type != ViewTypeKanban || view.Format.GroupByFieldID == "" {
+		return View{}, errors.Errorf("View '%s' is not a kanban board", view.Title)
+	}
+
+	if move.UpdateAt != 0 && move.UpdateAt != view.UpdateAt {
+		return View{}, errors.Wrapf(ErrConflict, "view '%s' was changed since %d", view.Title, move.UpdateAt)
+	}
+
+	field, err := vs.propertyFieldService.Get(view.Format.GroupByFieldID)
+	if err != nil {
+		return View{}, errors.Wrap(err, "could not get group by field")
+	}
+
+	if move.Value != "" && field.Type == PropertyFieldTypeSelect && !hasOption(field, move.Value) {
+		return View{}, errors.Errorf("'%s' is not an option of field '%s'", move.Value, field.Name)
+	}
+
+	matching, err := vs.store.FilterObjects(view.Query, []string{move.ObjectID})
+	if err != nil {
+		return View{}, errors.Wrap(err, "could not check object is in view")
+	}
+	if len(matching) == 0 {
+		return View{}, errors.Errorf("Object '%s' is not part of view '%s'", move.ObjectID, view.Title)
+	}
+
+	if !move.Force {
+		if err = vs.checkWIPLimit(view, move); err != nil {
+			return View{}, err
+		}
+	}
+
+	// Everything is checked before writing, but the value and the format are separate writes: the
+	// value is put back when the format can't be saved, such as when the view was changed in the
+	// meantime, and a failure to put it back is returned along with the error.
+	undo, err := vs.setColumnValue(field, move)
+	if err != nil {
+		return View{}, err
+	}
+
+	format := view.Format
+	format.ColumnOrders = moveInOrders(view.Format.ColumnOrders, move.ObjectID, move.Value, move.Position)
+
+	updateAt, err := vs.store.UpdateFormat(id, format, view.UpdateAt)
+	if err != nil {
+		if undoErr := undo(); undoErr != nil {
+			return View{}, errors.Wrapf(err, "could not undo the value change (%s)", undoErr.Error())
+		}
+		return View{}, err
+	}
+
+	view.Format = format
+	view.UpdateAt = updateAt
+
+	return view, nil
+}
+
+// setColumnValue sets the value of the group by field of the moved object, returning a function
+// putting back the previous value.
+func (vs *viewService) setColumnValue(field PropertyField, move Move) (func() error, error) {
+	noop := func() error { return nil }
+
+	properties, err := vs.propertyService.GetForObject(move.ObjectID)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return nil, errors.Wrap(err, "could not get properties of object")
+	}
+
+	existing := FindProperty(properties, field.ID)
+
+	switch {
+	case existing == nil && move.Value == "":
+		return noop, nil
+
+	case existing == nil:
+		channelID, teamID, locationErr := objectLocation(vs.api, move.ObjectID, move.ObjectType, properties)
+		if locationErr != nil {
+			return nil, locationErr
+		}
+
+		createdID, createErr := vs.propertyService.Create(Property{
+			ObjectID:        move.ObjectID,
+			ObjectType:      move.ObjectType,
+			PropertyFieldID: field.ID,
+			ChannelID:       channelID,
+			TeamID:          teamID,
+			Value:           []interface{}{move.Value},
+		})
+		if createErr != nil {
+			return nil, createErr
+		}
+		return func() error { return vs.propertyService.Delete(createdID) }, nil
+
+	case move.Value == "":
+		if err = vs.propertyService.Delete(existing.ID); err != nil {
+			return nil, err
+		}
+		previous := *existing
+		previous.ID = ""
+		return func() error {
+			_, createErr := vs.propertyService.Create(previous)
+			return createErr
+		}, nil
+
+	case len(existing.Value) == 1 && fmt.Sprint(existing.Value[0]) == move.Value:
+		// Moved within its column
+		return noop, nil
+
+	default:
+		if err = vs.propertyService.UpdateValue(existing.ID, []interface{}{move.Value}); err != nil {
+			return nil, err
+		}
+		previous := *existing
+		return func() error { return vs.propertyService.UpdateValue(previous.ID, previous.Value) }, nil
+	}
+}
+
+func hasOption(field PropertyField, value string) bool {
+	for _, option := range field.Values {
+		if fmt.Sprint(option) == value {
+			return true
+		}
+	}
+	return false
+}
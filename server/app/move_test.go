@@ -0,0 +1,127 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMoveInOrders(t *testing.T) {
+	cases := []struct {
+		Name     string
+		Orders   map[string][]string
+		Value    string
+		Position int
+		Expected map[string][]string
+	}{
+		{
+			Name:     "into an empty board",
+			Orders:   nil,
+			Value:    "open",
+			Position: 3,
+			Expected: map[string][]string{"open": {"x"}},
+		},
+		{
+			Name:     "to another column",
+			Orders:   map[string][]string{"open": {"a", "x", "b"}, "done": {"c", "d"}},
+			Value:    "done",
+			Position: 1,
+			Expected: map[string][]string{"open": {"a", "b"}, "done": {"c", "x", "d"}},
+		},
+		{
+			Name:     "within its column",
+			Orders:   map[string][]string{"open": {"x", "a", "b"}},
+			Value:    "open",
+			Position: 2,
+			Expected: map[string][]string{"open": {"a", "b", "x"}},
+		},
+		{
+			Name:     "out of the last object of a column",
+			Orders:   map[string][]string{"open": {"x"}},
+			Value:    "",
+			Position: 0,
+			Expected: map[string][]string{"": {"x"}},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			assert.Equal(t, c.Expected, moveInOrders(c.Orders, "x", c.Value, c.Position))
+		})
+	}
+}
+
+func TestOrderColumn(t *testing.T) {
+	assert.Equal(t, []string{"c", "a", "b", "d"}, orderColumn([]string{"a", "b", "c", "d"}, []string{"c", "x", "a"}))
+	assert.Equal(t, []string{"a", "b"}, orderColumn([]string{"a", "b"}, nil))
+}
+
+func TestMove(t *testing.T) {
+	view := View{
+		ID:       "view",
+		Title:    "Board",
+		Type:     ViewTypeKanban,
+		UpdateAt: 10,
+		Format:   Format{GroupByFieldID: "status"},
+	}
+	field := PropertyField{ID: "status", Name: "Status", Type: PropertyFieldTypeSelect, Values: []interface{}{"Open", "Done"}}
+
+	cases := []struct {
+		Name            string
+		Move            Move
+		UpdateErr       error
+		ExpectedError   string
+		ExpectedUpdates []Property
+	}{
+		{
+			Name:            "to another column",
+			Move:            Move{ObjectID: "post1", Value: "Done", UpdateAt: 10},
+			ExpectedUpdates: []Property{{ID: "property1", Value: []interface{}{"Done"}}},
+		},
+		{
+			Name:          "object outside of the view",
+			Move:          Move{ObjectID: "post2", Value: "Done"},
+			ExpectedError: "Object 'post2' is not part of view 'Board'",
+		},
+		{
+			Name:          "outdated view",
+			Move:          Move{ObjectID: "post1", Value: "Done", UpdateAt: 5},
+			ExpectedError: "conflict",
+		},
+		{
+			Name:      "view changed while moving",
+			Move:      Move{ObjectID: "post1", Value: "Done"},
+			UpdateErr: ErrConflict,
+			ExpectedUpdates: []Property{
+				{ID: "property1", Value: []interface{}{"Done"}},
+				{ID: "property1", Value: []interface{}{"Open"}},
+			},
+			ExpectedError: "conflict",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			propertyService := &fakePropertyService{properties: map[string][]Property{
+				"post1": {{ID: "property1", ObjectID: "post1", PropertyFieldID: "status", Value: []interface{}{"Open"}}},
+			}}
+			vs := &viewService{
+				store:                &fakeViewStore{view: view, matching: []string{"post1"}, updateFormatAt: 20, updateErr: c.UpdateErr},
+				propertyService:      propertyService,
+				propertyFieldService: &fakePropertyFieldService{fields: []PropertyField{field}},
+			}
+
+			moved, err := vs.Move("view", c.Move)
+			assert.Equal(t, c.ExpectedUpdates, propertyService.updates)
+			if c.ExpectedError != "" {
+				assert.ErrorContains(t, err, c.ExpectedError)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, int64(20), moved.UpdateAt)
+			assert.Equal(t, map[string][]string{"Done": {"post1"}}, moved.Format.ColumnOrders)
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package app
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/mattermost/mattermost/server/public/pluginapi"
+	"github.com/pkg/errors"
+)
+
+// dateLayout is the format of values of date fields without a time.
+const dateLayout = "2006-01-02"
+
+// objectThread returns the channel of the object and, for posts and files, the root of the
+// thread it belongs to.
+func objectThread(api *pluginapi.Client, property Property) (string, string, error) {
+	postID := property.ObjectID
+
+	switch property.ObjectType {
+	case PropertyObjectTypeChannel:
+		return property.ObjectID, "", nil
+	case PropertyObjectTypeFile:
+		fileInfo, err := api.File.GetInfo(property.ObjectID)
+		if err != nil {
+			return "", "", errors.Wrapf(err, "failed to get file '%s'", property.ObjectID)
+		}
+		postID = fileInfo.PostId
+	}
+
+	post, err := api.Post.GetPost(postID)
+	if err != nil {
+		return "", "", errors.Wrapf(err, "failed to get post '%s'", postID)
+	}
+
+	rootID := post.RootId
+	if rootID == "" {
+		rootID = post.Id
+	}
+
+	return post.ChannelId, rootID, nil
+}
+
+// FindProperty returns the property of the field among the properties of an object, nil when the
+// object has none.
+func FindProperty(properties []Property, fieldID string) *Property {
+	for i := range properties {
+		if properties[i].PropertyFieldID == fieldID {
+			return &properties[i]
+		}
+	}
+
+	return nil
+}
+
+// objectLocation returns the channel and team of an object, taken from its properties when it
+// has some.
+func objectLocation(api *pluginapi.Client, objectID, objectType string, properties PropertiesList) (string, string, error) {
+	for _, property := range properties {
+		if property.ChannelID != "" || property.TeamID != "" {
+			return property.ChannelID, property.TeamID, nil
+		}
+	}
+
+	channelID, _, err := objectThread(api, Property{ObjectID: objectID, ObjectType: objectType})
+	if err != nil {
+		return "", "", err
+	}
+
+	channel, err := api.Channel.Get(channelID)
+	if err != nil {
+		return "", "", errors.Wrapf(err, "failed to get channel '%s'", channelID)
+	}
+
+	return channelID, channel.TeamId, nil
+}
+
+// permalink returns the link to the post, or blank if the post isn't in a team channel.
+func permalink(api *pluginapi.Client, channelID, postID string) string {
+	siteURL := ""
+	if config := api.Configuration.GetConfig(); config.ServiceSettings.SiteURL != nil {
+		siteURL = *config.ServiceSettings.SiteURL
+	}
+
+	channel, err := api.Channel.Get(channelID)
+	if err != nil || channel.TeamId == "" {
+		return ""
+	}
+
+	team, err := api.Team.Get(channel.TeamId)
+	if err != nil {
+		return ""
+	}
+
+	return fmt.Sprintf("%s/%s/pl/%s", siteURL, team.Name, postID)
+}
+
+// ParseDateValue parses a value of a date field.
+func ParseDateValue(value interface{}) (time.Time, bool) {
+	s, ok := value.(string)
+	if !ok {
+		return time.Time{}, false
+	}
+
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, true
+	}
+
+	if t, err := time.Parse(dateLayout, s); err == nil {
+		return t, true
+	}
+
+	return time.Time{}, false
+}
@@ -0,0 +1,64 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindProperty(t *testing.T) {
+	properties := []Property{
+		{ID: "property1", PropertyFieldID: "status"},
+		{ID: "property2", PropertyFieldID: "owner"},
+	}
+
+	cases := []struct {
+		Name       string
+		Properties []Property
+		FieldID    string
+		Expected   string
+	}{
+		{
+			Name:       "first field",
+			Properties: properties,
+			FieldID:    "status",
+			Expected:   "property1",
+		},
+		{
+			Name:       "last field",
+			Properties: properties,
+			FieldID:    "owner",
+			Expected:   "property2",
+		},
+		{
+			Name:       "missing field",
+			Properties: properties,
+			FieldID:    "priority",
+		},
+		{
+			Name:       "no properties",
+			Properties: nil,
+			FieldID:    "status",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			property := FindProperty(c.Properties, c.FieldID)
+			if c.Expected == "" {
+				assert.Nil(t, property)
+				return
+			}
+			require.NotNil(t, property)
+			assert.Equal(t, c.Expected, property.ID)
+		})
+	}
+}
+
+func TestFindPropertyReturnsElement(t *testing.T) {
+	properties := []Property{{ID: "property1", PropertyFieldID: "status"}}
+
+	FindProperty(properties, "status").Value = []interface{}{"Done"}
+	assert.Equal(t, []interface{}{"Done"}, properties[0].Value)
+}
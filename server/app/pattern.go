@@ -0,0 +1,61 @@
+package app
+
+import (
+	"github.com/mattermost/mattermost/server/public/model"
+)
+
+// Pattern automatically sets a property on new posts of a channel, or of a whole team when
+// ChannelID is blank, whose content matches.
+type Pattern struct {
+	ID              string        `json:"id"`
+	TeamID          string        `json:"team_id"`
+	ChannelID       string        `json:"channel_id"`
+	Type            string        `json:"type"`
+	Match           string        `json:"match"`
+	PropertyFieldID string        `json:"property_field_id"`
+	AttachmentField string        `json:"attachment_field"`
+	CreateAt        int64         `json:"create_at"`
+	UpdateAt        int64         `json:"update_at"`
+	UpdateBy        string        `json:"update_by"`
+	Value           []interface{} `json:"value" db:"-"`
+}
+
+const (
+	// PatternTypeHashtag matches posts containing the hashtag in Match.
+	PatternTypeHashtag = "hashtag"
+	// PatternTypeKeyword matches posts containing the word in Match, ignoring case.
+	PatternTypeKeyword = "keyword"
+	// PatternTypeRegex matches posts whose message matches the regular expression in Match.
+	// Values may refer to submatches, e.g. "$1".
+	PatternTypeRegex = "regex"
+	// PatternTypeBot matches posts by the user id in Match. When AttachmentField is set, the
+	// value is taken from the attachment field with that title instead of Value.
+	PatternTypeBot = "bot"
+)
+
+type PatternFilterOptions struct {
+	TeamID    string
+	ChannelID string
+	Page      int
+	PerPage   int
+}
+
+type PatternStore interface {
+	Get(id string) (Pattern, error)
+	Create(pattern Pattern) (string, error)
+	GetPatterns(filter PatternFilterOptions) ([]Pattern, error)
+	GetForChannel(teamID string, channelID string) ([]Pattern, error)
+	Update(pattern Pattern) error
+	Delete(id string) error
+}
+
+type PatternService interface {
+	Get(id string) (Pattern, error)
+	Create(pattern Pattern) (string, error)
+	GetPatterns(filter PatternFilterOptions) ([]Pattern, error)
+	Update(pattern Pattern) error
+	Delete(id string) error
+
+	// ApplyToPost creates the properties of all patterns matching the new post.
+	ApplyToPost(post *model.Post) error
+}
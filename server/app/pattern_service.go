@@ -0,0 +1,263 @@
+package app
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/pluginapi"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+type patternService struct {
+	store                PatternStore
+	propertyService      PropertyService
+	propertyFieldService PropertyFieldService
+	api                  *pluginapi.Client
+	botID                string
+
+	// regexps caches the compiled expressions of regex patterns by their Match, so posts don't
+	// recompile them. Expressions are compiled when patterns are saved, or on first use for
+	// patterns saved by another server.
+	regexps     map[string]*regexp.Regexp
+	regexpsLock sync.RWMutex
+}
+
+func NewPatternService(store PatternStore, propertyService PropertyService, propertyFieldService PropertyFieldService, api *pluginapi.Client, botID string) PatternService {
+	return &patternService{
+		store:                store,
+		propertyService:      propertyService,
+		propertyFieldService: propertyFieldService,
+		api:                  api,
+		botID:                botID,
+		regexps:              map[string]*regexp.Regexp{},
+	}
+}
+
+func (ps *patternService) Get(id string) (Pattern, error) {
+	return ps.store.Get(id)
+}
+
+func (ps *patternService) Create(pattern Pattern) (string, error) {
+	if err := ps.validate(pattern); err != nil {
+		return "", err
+	}
+
+	return ps.store.Create(pattern)
+}
+
+func (ps *patternService) GetPatterns(filter PatternFilterOptions) ([]Pattern, error) {
+	return ps.store.GetPatterns(filter)
+}
+
+func (ps *patternService) Update(pattern Pattern) error {
+	if err := ps.validate(pattern); err != nil {
+		return err
+	}
+
+	return ps.store.Update(pattern)
+}
+
+func (ps *patternService) Delete(id string) error {
+	return ps.store.Delete(id)
+}
+
+func (ps *patternService) validate(pattern Pattern) error {
+	if pattern.TeamID == "" {
+		return errors.New("TeamID should not be blank")
+	}
+
+	if pattern.Match == "" {
+		return errors.New("Match should not be blank")
+	}
+
+	if pattern.PropertyFieldID == "" {
+		return errors.New("PropertyFieldID should not be blank")
+	}
+
+	if _, err := ps.propertyFieldService.Get(pattern.PropertyFieldID); err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return errors.Errorf("Tried to create pattern with unknown property_field with id: '%s'", pattern.PropertyFieldID)
+		}
+		return err
+	}
+
+	switch pattern.Type {
+	case PatternTypeHashtag, PatternTypeKeyword:
+	case PatternTypeRegex:
+		if _, err := ps.compile(pattern.Match); err != nil {
+			return errors.Wrap(err, "Match is not a valid regular expression")
+		}
+	case PatternTypeBot:
+		if !model.IsValidId(pattern.Match) {
+			return errors.New("Match should be the id of a bot user")
+		}
+	default:
+		return errors.Errorf("Unknown pattern type '%s'", pattern.Type)
+	}
+
+	if pattern.AttachmentField != "" && pattern.Type != PatternTypeBot {
+		return errors.Errorf("AttachmentField is only supported for pattern type '%s'", PatternTypeBot)
+	}
+
+	if len(pattern.Value) == 0 && pattern.AttachmentField == "" {
+		return errors.New("Value should not be empty")
+	}
+
+	return nil
+}
+
+func (ps *patternService) ApplyToPost(post *model.Post) error {
+	if post.IsSystemMessage() || post.UserId == ps.botID {
+		return nil
+	}
+
+	channel, err := ps.api.Channel.Get(post.ChannelId)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get channel '%s'", post.ChannelId)
+	}
+
+	// Patterns only apply to team channels
+	if channel.TeamId == "" {
+		return nil
+	}
+
+	patterns, err := ps.store.GetForChannel(channel.TeamId, channel.Id)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get patterns for channel '%s'", channel.Id)
+	}
+
+	// The first matching pattern of a field wins
+	setFields := map[string]bool{}
+	var properties []Property
+	loaded := false
+	for _, pattern := range patterns {
+		if setFields[pattern.PropertyFieldID] {
+			continue
+		}
+
+		value, matched := ps.matchPattern(pattern, post)
+		if !matched || len(value) == 0 {
+			continue
+		}
+
+		// Only load the properties of the post once a pattern matches
+		if !loaded {
+			properties, err = ps.propertyService.GetForObject(post.Id)
+			if err != nil && !errors.Is(err, ErrNotFound) {
+				return errors.Wrapf(err, "failed to get properties for post '%s'", post.Id)
+			}
+			loaded = true
+		}
+
+		if existing := FindProperty(properties, pattern.PropertyFieldID); existing != nil {
+			err = ps.propertyService.UpdateValue(existing.ID, value)
+		} else {
+			_, err = ps.propertyService.Create(Property{
+				ObjectID:        post.Id,
+				ObjectType:      PropertyObjectTypePost,
+				PropertyFieldID: pattern.PropertyFieldID,
+				ChannelID:       channel.Id,
+				TeamID:          channel.TeamId,
+				Value:           value,
+			})
+		}
+		if err != nil {
+			logrus.WithError(err).WithFields(logrus.Fields{
+				"pattern_id": pattern.ID,
+				"post_id":    post.Id,
+			}).Warn("Failed to set property for matching pattern")
+			continue
+		}
+		setFields[pattern.PropertyFieldID] = true
+	}
+
+	return nil
+}
+
+// compile returns the compiled regular expression, from the cache when it was compiled before.
+func (ps *patternService) compile(expr string) (*regexp.Regexp, error) {
+	ps.regexpsLock.RLock()
+	re, ok := ps.regexps[expr]
+	ps.regexpsLock.RUnlock()
+	if ok {
+		return re, nil
+	}
+
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	ps.regexpsLock.Lock()
+	ps.regexps[expr] = re
+	ps.regexpsLock.Unlock()
+
+	return re, nil
+}
+
+// matchPattern returns the value to set when the post matches the pattern.
+func (ps *patternService) matchPattern(pattern Pattern, post *model.Post) ([]interface{}, bool) {
+	switch pattern.Type {
+	case PatternTypeHashtag:
+		hashtags, _ := model.ParseHashtags(post.Message)
+		match := "#" + strings.TrimPrefix(pattern.Match, "#")
+		for _, hashtag := range strings.Fields(hashtags) {
+			if strings.EqualFold(hashtag, match) {
+				return pattern.Value, true
+			}
+		}
+	case PatternTypeKeyword:
+		for _, word := range strings.FieldsFunc(post.Message, isWordSeparator) {
+			if strings.EqualFold(word, pattern.Match) {
+				return pattern.Value, true
+			}
+		}
+	case PatternTypeRegex:
+		re, err := ps.compile(pattern.Match)
+		if err != nil {
+			return nil, false
+		}
+
+		submatches := re.FindStringSubmatchIndex(post.Message)
+		if submatches == nil {
+			return nil, false
+		}
+
+		value := make([]interface{}, len(pattern.Value))
+		for i, v := range pattern.Value {
+			template, ok := v.(string)
+			if !ok {
+				value[i] = v
+				continue
+			}
+			value[i] = string(re.ExpandString(nil, template, post.Message, submatches))
+		}
+		return value, true
+	case PatternTypeBot:
+		if post.UserId != pattern.Match {
+			return nil, false
+		}
+
+		if pattern.AttachmentField == "" {
+			return pattern.Value, true
+		}
+
+		for _, attachment := range post.Attachments() {
+			for _, field := range attachment.Fields {
+				if field != nil && strings.EqualFold(field.Title, pattern.AttachmentField) {
+					return []interface{}{fmt.Sprint(field.Value)}, true
+				}
+			}
+		}
+	}
+
+	return nil, false
+}
+
+func isWordSeparator(r rune) bool {
+	return !(r == '_' || r == '-' || r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r > 127)
+}
@@ -0,0 +1,199 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/plugin/plugintest"
+	"github.com/mattermost/mattermost/server/public/pluginapi"
+)
+
+// fakePatternStore returns the patterns it's given for every channel, other methods panic.
+type fakePatternStore struct {
+	PatternStore
+	patterns []Pattern
+}
+
+func (s *fakePatternStore) GetForChannel(_ string, _ string) ([]Pattern, error) {
+	return s.patterns, nil
+}
+
+func TestMatchPattern(t *testing.T) {
+	botUserID := model.NewId()
+	ps := NewPatternService(nil, nil, nil, nil, "bot").(*patternService)
+
+	attachmentPost := &model.Post{UserId: botUserID}
+	attachmentPost.AddProp("attachments", []*model.SlackAttachment{
+		{Fields: []*model.SlackAttachmentField{{Title: "Build", Value: "passed"}}},
+	})
+
+	cases := []struct {
+		Name     string
+		Pattern  Pattern
+		Post     *model.Post
+		Expected []interface{}
+		Matched  bool
+	}{
+		{
+			Name:     "hashtag",
+			Pattern:  Pattern{Type: PatternTypeHashtag, Match: "#bug", Value: []interface{}{"Bug"}},
+			Post:     &model.Post{Message: "Crashes on start #Bug"},
+			Expected: []interface{}{"Bug"},
+			Matched:  true,
+		},
+		{
+			Name:    "hashtag prefix",
+			Pattern: Pattern{Type: PatternTypeHashtag, Match: "bug", Value: []interface{}{"Bug"}},
+			Post:    &model.Post{Message: "#bugfix"},
+		},
+		{
+			Name:     "keyword",
+			Pattern:  Pattern{Type: PatternTypeKeyword, Match: "outage", Value: []interface{}{"High"}},
+			Post:     &model.Post{Message: "Partial OUTAGE, investigating."},
+			Expected: []interface{}{"High"},
+			Matched:  true,
+		},
+		{
+			Name:    "keyword inside a word",
+			Pattern: Pattern{Type: PatternTypeKeyword, Match: "out", Value: []interface{}{"High"}},
+			Post:    &model.Post{Message: "Partial outage"},
+		},
+		{
+			Name:     "regex capture groups",
+			Pattern:  Pattern{Type: PatternTypeRegex, Match: `(?P<project>[A-Z]+)-(\d+)`, Value: []interface{}{"${project}", "#$2", float64(1)}},
+			Post:     &model.Post{Message: "Fixed in MM-1234"},
+			Expected: []interface{}{"MM", "#1234", float64(1)},
+			Matched:  true,
+		},
+		{
+			Name:    "regex not matching",
+			Pattern: Pattern{Type: PatternTypeRegex, Match: `[A-Z]+-\d+`, Value: []interface{}{"$0"}},
+			Post:    &model.Post{Message: "no ticket"},
+		},
+		{
+			Name:    "invalid regex",
+			Pattern: Pattern{Type: PatternTypeRegex, Match: `(`, Value: []interface{}{"x"}},
+			Post:    &model.Post{Message: "("},
+		},
+		{
+			Name:     "bot",
+			Pattern:  Pattern{Type: PatternTypeBot, Match: botUserID, Value: []interface{}{"Automated"}},
+			Post:     &model.Post{UserId: botUserID},
+			Expected: []interface{}{"Automated"},
+			Matched:  true,
+		},
+		{
+			Name:    "other user than the bot",
+			Pattern: Pattern{Type: PatternTypeBot, Match: botUserID, Value: []interface{}{"Automated"}},
+			Post:    &model.Post{UserId: model.NewId()},
+		},
+		{
+			Name:     "bot attachment field",
+			Pattern:  Pattern{Type: PatternTypeBot, Match: botUserID, AttachmentField: "build"},
+			Post:     attachmentPost,
+			Expected: []interface{}{"passed"},
+			Matched:  true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			value, matched := ps.matchPattern(c.Pattern, c.Post)
+			assert.Equal(t, c.Matched, matched)
+			assert.Equal(t, c.Expected, value)
+		})
+	}
+}
+
+func TestPatternRegexCache(t *testing.T) {
+	fieldService := &fakePropertyFieldService{fields: []PropertyField{{ID: "ticket"}}}
+	ps := NewPatternService(nil, nil, fieldService, nil, "bot").(*patternService)
+
+	pattern := Pattern{TeamID: "team1", Type: PatternTypeRegex, Match: `[A-Z]+-\d+`, PropertyFieldID: "ticket", Value: []interface{}{"$0"}}
+	require.NoError(t, ps.validate(pattern))
+	require.Contains(t, ps.regexps, pattern.Match)
+	compiled := ps.regexps[pattern.Match]
+
+	value, matched := ps.matchPattern(pattern, &model.Post{Message: "See MM-1"})
+	assert.True(t, matched)
+	assert.Equal(t, []interface{}{"MM-1"}, value)
+	assert.Same(t, compiled, ps.regexps[pattern.Match])
+
+	pattern.Match = `(`
+	assert.Error(t, ps.validate(pattern))
+	assert.NotContains(t, ps.regexps, pattern.Match)
+}
+
+func TestApplyToPost(t *testing.T) {
+	api := &plugintest.API{}
+	api.On("GetChannel", "channel1").Return(&model.Channel{Id: "channel1", TeamId: "team1"}, nil)
+	api.On("GetChannel", "dm").Return(&model.Channel{Id: "dm"}, nil)
+
+	patterns := []Pattern{
+		{ID: "pattern1", Type: PatternTypeKeyword, Match: "outage", PropertyFieldID: "priority", Value: []interface{}{"High"}},
+		{ID: "pattern2", Type: PatternTypeKeyword, Match: "incident", PropertyFieldID: "priority", Value: []interface{}{"Medium"}},
+		{ID: "pattern3", Type: PatternTypeRegex, Match: `MM-(\d+)`, PropertyFieldID: "ticket", Value: []interface{}{"$1"}},
+	}
+
+	cases := []struct {
+		Name            string
+		Post            *model.Post
+		Existing        []Property
+		Expected        []Property
+		ExpectedUpdates []Property
+	}{
+		{
+			Name: "first pattern of a field wins",
+			Post: &model.Post{Id: "post1", ChannelId: "channel1", UserId: "user1", Message: "incident: outage of MM-12"},
+			Expected: []Property{
+				{ObjectID: "post1", ObjectType: PropertyObjectTypePost, PropertyFieldID: "priority", ChannelID: "channel1", TeamID: "team1", Value: []interface{}{"High"}},
+				{ObjectID: "post1", ObjectType: PropertyObjectTypePost, PropertyFieldID: "ticket", ChannelID: "channel1", TeamID: "team1", Value: []interface{}{"12"}},
+			},
+		},
+		{
+			Name:     "existing property of the field is updated",
+			Post:     &model.Post{Id: "post1", ChannelId: "channel1", UserId: "user1", Message: "outage"},
+			Existing: []Property{{ID: "property1", ObjectID: "post1", PropertyFieldID: "priority", Value: []interface{}{"Low"}}},
+			ExpectedUpdates: []Property{
+				{ID: "property1", Value: []interface{}{"High"}},
+			},
+		},
+		{
+			Name: "no match",
+			Post: &model.Post{Id: "post1", ChannelId: "channel1", UserId: "user1", Message: "all good"},
+		},
+		{
+			Name: "posts of the plugin bot are skipped",
+			Post: &model.Post{Id: "post1", ChannelId: "channel1", UserId: "bot", Message: "outage"},
+		},
+		{
+			Name: "system messages are skipped",
+			Post: &model.Post{Id: "post1", ChannelId: "channel1", UserId: "user1", Type: model.PostTypeJoinChannel, Message: "outage"},
+		},
+		{
+			Name: "channels outside of teams are skipped",
+			Post: &model.Post{Id: "post1", ChannelId: "dm", UserId: "user1", Message: "outage"},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			propertyService := &fakePropertyService{properties: map[string][]Property{}}
+			if c.Existing != nil {
+				propertyService.properties["post1"] = c.Existing
+			}
+			ps := NewPatternService(&fakePatternStore{patterns: patterns}, propertyService, nil, pluginapi.NewClient(api, nil), "bot")
+
+			require.NoError(t, ps.ApplyToPost(c.Post))
+
+			for i := range propertyService.created {
+				propertyService.created[i].ID = ""
+			}
+			assert.Equal(t, c.Expected, propertyService.created)
+			assert.Equal(t, c.ExpectedUpdates, propertyService.updates)
+		})
+	}
+}
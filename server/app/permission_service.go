@@ -39,13 +39,20 @@ func NewPermissionsService(
 
 func (p *PermissionsService) PropertyCreate(userID string, property Property) error {
 	if property.ObjectType == PropertyObjectTypePost {
-		post, err := p.pluginAPI.Post.GetPost(property.ObjectID)
+		if err := p.postPropertyCreate(userID, property.ObjectID); err != nil {
+			return err
+		}
+	} else if property.ObjectType == PropertyObjectTypeFile {
+		fileInfo, err := p.pluginAPI.File.GetInfo(property.ObjectID)
 		if err != nil {
-			return errors.Wrap(err, "invalid post")
+			return errors.Wrap(err, "invalid file")
+		}
+		if fileInfo.PostId == "" {
+			return errors.Errorf("file `%s` is not attached to a post", fileInfo.Id)
 		}
-		//TODO: implement config-based permission check for editing someone else's post
-		if !p.pluginAPI.User.HasPermissionToChannel(userID, post.ChannelId, model.PermissionCreatePost) {
-			return errors.Errorf("user `%s` does not have permission to create posts in channel `%s`", userID, post.ChannelId)
+		// Files inherit the permissions of the post they are attached to
+		if err := p.postPropertyCreate(userID, fileInfo.PostId); err != nil {
+			return err
 		}
 	} else if property.ObjectType == PropertyObjectTypeChannel {
 		channel, err := p.pluginAPI.Channel.Get(property.ObjectID)
@@ -62,7 +69,177 @@ func (p *PermissionsService) PropertyCreate(userID string, property Property) er
 			return errors.New("permission check for dms/gms not implemented")
 		}
 	} else {
-		return errors.Errorf("permission checks only implemented for `%s` and `%s`", PropertyObjectTypePost, PropertyObjectTypeChannel)
+		return errors.Errorf("permission checks only implemented for `%s`, `%s` and `%s`", PropertyObjectTypePost, PropertyObjectTypeChannel, PropertyObjectTypeFile)
+	}
+
+	return nil
+}
+
+// ReferencesRead checks that the user can read the objects a property references, as its field
+// type defines, such as the objects a relation links to.
+func (p *PermissionsService) ReferencesRead(userID string, property Property) error {
+	field, err := p.propertyFieldService.Get(property.PropertyFieldID)
+	if err != nil {
+		return errors.Wrap(err, "invalid property field")
+	}
+
+	fieldType, ok := GetFieldType(field.Type)
+	if !ok {
+		return nil
+	}
+
+	ctx := FieldTypeContext{Fields: p.propertyFieldService, API: p.pluginAPI, ObjectID: property.ObjectID}
+	return fieldType.ReferencesRead(ctx, userID, field, property.Value)
+}
+
+// FilterPropertiesRead returns the properties whose objects the user can read, by the channel,
+// or the team for objects outside of channels.
+func (p *PermissionsService) FilterPropertiesRead(userID string, properties []Property) []Property {
+	readable := map[string]bool{}
+	filtered := []Property{}
+	for _, property := range properties {
+		key := "channel:" + property.ChannelID
+		if property.ChannelID == "" {
+			key = "team:" + property.TeamID
+		}
+
+		canRead, checked := readable[key]
+		if !checked {
+			switch {
+			case property.ChannelID != "":
+				canRead = p.pluginAPI.User.HasPermissionToChannel(userID, property.ChannelID, model.PermissionReadChannelContent)
+			case property.TeamID != "":
+				canRead = p.pluginAPI.User.HasPermissionToTeam(userID, property.TeamID, model.PermissionViewTeam)
+			default:
+				canRead = IsSystemAdmin(userID, p.pluginAPI)
+			}
+			readable[key] = canRead
+		}
+
+		if canRead {
+			filtered = append(filtered, property)
+		}
+	}
+
+	return filtered
+}
+
+func (p *PermissionsService) postPropertyCreate(userID string, postID string) error {
+	post, err := p.pluginAPI.Post.GetPost(postID)
+	if err != nil {
+		return errors.Wrap(err, "invalid post")
+	}
+	//TODO: implement config-based permission check for editing someone else's post
+	if !p.pluginAPI.User.HasPermissionToChannel(userID, post.ChannelId, model.PermissionCreatePost) {
+		return errors.Errorf("user `%s` does not have permission to create posts in channel `%s`", userID, post.ChannelId)
+	}
+
+	return nil
+}
+
+// ViewRead checks that the user can read the objects of a view, in the channel or team it
+// queries. Views of all teams can only be read by system admins.
+func (p *PermissionsService) ViewRead(userID string, view View) error {
+	return viewRead(p.pluginAPI, userID, view)
+}
+
+func viewRead(api *pluginapi.Client, userID string, view View) error {
+	if IsSystemAdmin(userID, api) {
+		return nil
+	}
+
+	switch {
+	case view.Query.ChannelID != "":
+		if !api.User.HasPermissionToChannel(userID, view.Query.ChannelID, model.PermissionReadChannel) {
+			return errors.Errorf("user `%s` does not have permission to read channel `%s`", userID, view.Query.ChannelID)
+		}
+	case view.Query.TeamID != "":
+		if !api.User.HasPermissionToTeam(userID, view.Query.TeamID, model.PermissionViewTeam) {
+			return errors.Errorf("user `%s` does not have permission to view team `%s`", userID, view.Query.TeamID)
+		}
+	default:
+		return errors.Errorf("user `%s` does not have permission to read view `%s` without a team", userID, view.ID)
+	}
+
+	return nil
+}
+
+// RuleManage checks that the user can manage the rules of the team. Rules without a team
+// can only be managed by system admins.
+func (p *PermissionsService) RuleManage(userID string, teamID string) error {
+	return p.teamManage(userID, teamID, "rules")
+}
+
+// PatternManage checks that the user can manage the patterns of the team. Patterns without a
+// team can only be managed by system admins.
+func (p *PermissionsService) PatternManage(userID string, teamID string) error {
+	return p.teamManage(userID, teamID, "patterns")
+}
+
+// WebhookManage checks that the user can manage the webhooks of the team. Webhooks without a
+// team receive the changes of all teams and can only be managed by system admins.
+func (p *PermissionsService) WebhookManage(userID string, teamID string) error {
+	return p.teamManage(userID, teamID, "webhooks")
+}
+
+// ReminderManage checks that the user can manage the reminders of the team.
+func (p *PermissionsService) ReminderManage(userID string, teamID string) error {
+	return p.teamManage(userID, teamID, "reminders")
+}
+
+// TokenManage checks that the user can manage the tokens of the team.
+func (p *PermissionsService) TokenManage(userID string, teamID string) error {
+	return p.teamManage(userID, teamID, "tokens")
+}
+
+// DigestManage checks that the user owns the digest and can post to its channel, if any.
+func (p *PermissionsService) DigestManage(userID string, digest Digest) error {
+	if IsSystemAdmin(userID, p.pluginAPI) {
+		return nil
+	}
+
+	if digest.UserID != userID {
+		return errors.Errorf("user `%s` does not have permission to manage digest `%s`", userID, digest.ID)
+	}
+
+	if digest.ChannelID != "" && !p.pluginAPI.User.HasPermissionToChannel(userID, digest.ChannelID, model.PermissionCreatePost) {
+		return errors.Errorf("user `%s` does not have permission to create posts in channel `%s`", userID, digest.ChannelID)
+	}
+
+	return nil
+}
+
+// WIPLimitOverride checks that the user can move objects past the WIP limits of the views of the
+// team. Views without a team can only be overridden by system admins.
+func (p *PermissionsService) WIPLimitOverride(userID string, teamID string) error {
+	return p.teamManage(userID, teamID, "WIP limit overrides")
+}
+
+// ReactionMappingManage checks that the user can manage the reaction mappings of the channel,
+// which requires being a channel admin.
+func (p *PermissionsService) ReactionMappingManage(userID string, channelID string) error {
+	if IsSystemAdmin(userID, p.pluginAPI) {
+		return nil
+	}
+
+	if !p.pluginAPI.User.HasPermissionToChannel(userID, channelID, model.PermissionManageChannelRoles) {
+		return errors.Errorf("user `%s` does not have permission to manage reaction mappings for channel `%s`", userID, channelID)
+	}
+
+	return nil
+}
+
+func (p *PermissionsService) teamManage(userID string, teamID string, what string) error {
+	if IsSystemAdmin(userID, p.pluginAPI) {
+		return nil
+	}
+
+	if teamID == "" {
+		return errors.Errorf("user `%s` does not have permission to manage %s without a team", userID, what)
+	}
+
+	if !p.pluginAPI.User.HasPermissionToTeam(userID, teamID, model.PermissionManageTeam) {
+		return errors.Errorf("user `%s` does not have permission to manage %s for team `%s`", userID, what, teamID)
 	}
 
 	return nil
@@ -0,0 +1,112 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/plugin/plugintest"
+	"github.com/mattermost/mattermost/server/public/pluginapi"
+)
+
+func TestFilterPropertiesRead(t *testing.T) {
+	api := &plugintest.API{}
+	api.On("HasPermissionTo", "user", model.PermissionManageSystem).Return(false)
+	api.On("HasPermissionToChannel", "user", "public", model.PermissionReadChannelContent).Return(true).Once()
+	api.On("HasPermissionToChannel", "user", "private", model.PermissionReadChannelContent).Return(false).Once()
+	api.On("HasPermissionToTeam", "user", "team1", model.PermissionViewTeam).Return(true).Once()
+	api.On("HasPermissionToTeam", "user", "team2", model.PermissionViewTeam).Return(false).Once()
+	defer api.AssertExpectations(t)
+
+	p := NewPermissionsService(nil, nil, pluginapi.NewClient(api, nil), nil)
+
+	properties := []Property{
+		{ID: "public1", ChannelID: "public", TeamID: "team1"},
+		{ID: "private", ChannelID: "private", TeamID: "team1"},
+		{ID: "public2", ChannelID: "public", TeamID: "team1"},
+		{ID: "team1", TeamID: "team1"},
+		{ID: "team2", TeamID: "team2"},
+		{ID: "nowhere"},
+	}
+
+	ids := []string{}
+	for _, property := range p.FilterPropertiesRead("user", properties) {
+		ids = append(ids, property.ID)
+	}
+	assert.Equal(t, []string{"public1", "public2", "team1"}, ids)
+}
+
+func TestReferencesRead(t *testing.T) {
+	api := &plugintest.API{}
+	api.On("HasPermissionToChannel", "user", "public", model.PermissionReadChannel).Return(true)
+	api.On("HasPermissionToChannel", "user", "private", model.PermissionReadChannel).Return(false)
+	api.On("HasPermissionToTeam", "user", "team1", model.PermissionViewTeam).Return(true)
+	api.On("HasPermissionToTeam", "user", "team2", model.PermissionViewTeam).Return(false)
+	api.On("GetPost", "post1").Return(&model.Post{Id: "post1", ChannelId: "public"}, nil)
+	api.On("GetPost", "post2").Return(&model.Post{Id: "post2", ChannelId: "private"}, nil)
+	api.On("HasPermissionToChannel", "user", "public", model.PermissionReadChannelContent).Return(true)
+	api.On("HasPermissionToChannel", "user", "private", model.PermissionReadChannelContent).Return(false)
+
+	fieldService := &fakePropertyFieldService{fields: []PropertyField{
+		{ID: "channel", Type: PropertyFieldTypeChannel},
+		{ID: "team", Type: PropertyFieldTypeTeam},
+		{ID: "text", Type: PropertyFieldTypeText},
+		{ID: "tasks", Type: PropertyFieldTypeRelation},
+	}}
+	p := NewPermissionsService(nil, fieldService, pluginapi.NewClient(api, nil), nil)
+
+	cases := []struct {
+		Name     string
+		Property Property
+		Expected string
+	}{
+		{
+			Name:     "readable channels",
+			Property: Property{PropertyFieldID: "channel", Value: []interface{}{"public"}},
+		},
+		{
+			Name:     "unreadable channel",
+			Property: Property{PropertyFieldID: "channel", Value: []interface{}{"public", "private"}},
+			Expected: "user `user` does not have permission to read channel `private`",
+		},
+		{
+			Name:     "readable teams",
+			Property: Property{PropertyFieldID: "team", Value: []interface{}{"team1"}},
+		},
+		{
+			Name:     "unreadable team",
+			Property: Property{PropertyFieldID: "team", Value: []interface{}{"team2"}},
+			Expected: "user `user` does not have permission to view team `team2`",
+		},
+		{
+			Name:     "readable linked posts",
+			Property: Property{PropertyFieldID: "tasks", Value: []interface{}{"post1"}},
+		},
+		{
+			Name:     "linked post of an unreadable channel",
+			Property: Property{PropertyFieldID: "tasks", Value: []interface{}{"post1", "post2"}},
+			Expected: "user `user` does not have permission to read channel `private`",
+		},
+		{
+			Name:     "other field type",
+			Property: Property{PropertyFieldID: "text", Value: []interface{}{"private"}},
+		},
+		{
+			Name:     "unknown field",
+			Property: Property{PropertyFieldID: "unknown"},
+			Expected: "invalid property field: not found",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			err := p.ReferencesRead("user", c.Property)
+			if c.Expected == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, c.Expected)
+			}
+		})
+	}
+}
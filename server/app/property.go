@@ -1,5 +1,9 @@
 package app
 
+import (
+	"github.com/mattermost/mattermost/server/public/model"
+)
+
 type Property struct {
 	ID                  string        `json:"id"`
 	ObjectID            string        `json:"object_id"`
@@ -11,23 +15,135 @@ type Property struct {
 	PropertyFieldType   string        `json:"property_field_type"`
 	PropertyFieldValues []interface{} `json:"property_field_values"`
 	Value               []interface{} `json:"value" db:"-"`
+	// UpdateAt is when the value was last set.
+	UpdateAt int64 `json:"update_at"`
+
+	PropertyFieldInheritToReplies bool `json:"property_field_inherit_to_replies"`
+	// Inherited is set when the property belongs to the root post of the thread
+	// rather than the requested post.
+	Inherited bool `json:"inherited" db:"-"`
+	// Computed is set on the values written by SetComputedValue, letting them through the
+	// validators rejecting values set on computed fields.
+	Computed bool `json:"-" db:"-"`
 }
 
 const (
 	PropertyObjectTypePost    = "post"
 	PropertyObjectTypeChannel = "channel"
+	PropertyObjectTypeFile    = "file"
 )
 
+const (
+	PropertyChangeTypeCreated = "created"
+	PropertyChangeTypeUpdated = "updated"
+	PropertyChangeTypeDeleted = "deleted"
+)
+
+// PropertyChange describes a write to a property, passed to the change listeners of the PropertyService.
+type PropertyChange struct {
+	Type          string        `json:"type"`
+	Property      Property      `json:"property"`
+	PreviousValue []interface{} `json:"previous_value"`
+}
+
+// PropertyHistoryEntry is a value a property had from CreateAt on. Deleting a property records
+// an empty value.
+type PropertyHistoryEntry struct {
+	PropertyID      string        `json:"property_id"`
+	ObjectID        string        `json:"object_id"`
+	PropertyFieldID string        `json:"property_field_id"`
+	Value           []interface{} `json:"value" db:"-"`
+	CreateAt        int64         `json:"create_at"`
+}
+
+// PropertyFilterOptions selects the properties of a field. Blank options match everything.
+type PropertyFilterOptions struct {
+	PropertyFieldID string
+	TeamID          string
+	// ObjectIDs only keeps the properties of these objects when set.
+	ObjectIDs []string
+	// DateFrom and DateTo bound the day of the first value of date properties, both
+	// "2006-01-02" and inclusive.
+	DateFrom string
+	DateTo   string
+	// UpdatedBefore only keeps the properties last set before the time, in milliseconds.
+	UpdatedBefore int64
+	// Page and PerPage page through the properties ordered by id, all of them being returned
+	// when PerPage is 0.
+	Page    int
+	PerPage int
+}
+
+// PropertyObject is an object having properties, in the channel and team of its properties.
+type PropertyObject struct {
+	ObjectID   string `json:"object_id"`
+	ObjectType string `json:"object_type"`
+	ChannelID  string `json:"channel_id"`
+	TeamID     string `json:"team_id"`
+}
+
 type PropertyStore interface {
+	Get(id string) (Property, error)
 	GetByObjectID(objectID string) ([]Property, error)
+	// GetForObjects returns the properties of the objects, only those of the given fields when
+	// any are given.
+	GetForObjects(objectIDs []string, fieldIDs []string) ([]Property, error)
+	GetByFieldID(propertyFieldID string) ([]Property, error)
+	// GetProperties returns the properties of the field matching the filter.
+	GetProperties(filter PropertyFilterOptions) ([]Property, error)
+	// GetReferencing returns the properties of relation fields linking to the object.
+	GetReferencing(objectID string) ([]Property, error)
+	// GetObjects returns the objects having properties in the team, or in any team when blank,
+	// ordered by id.
+	GetObjects(teamID string, page int, perPage int) ([]PropertyObject, error)
 	Create(property Property) (string, error)
 	UpdateValue(id string, value []interface{}) error
 	Delete(id string) error
+	// GetHistoryForField returns the history of the values of the field from since to until,
+	// oldest first. It starts with the value each property had at since, when it had one.
+	GetHistoryForField(propertyFieldID string, since int64, until int64) ([]PropertyHistoryEntry, error)
 }
 
 type PropertyService interface {
+	Get(id string) (Property, error)
 	Create(property Property) (string, error)
 	GetForObject(objectID string) ([]Property, error)
+	// GetForObjects returns the properties of the objects, only those of the given fields when
+	// any are given.
+	GetForObjects(objectIDs []string, fieldIDs []string) ([]Property, error)
+	GetForField(propertyFieldID string) ([]Property, error)
+	// GetProperties returns the properties of the field matching the filter.
+	GetProperties(filter PropertyFilterOptions) ([]Property, error)
+	// GetReferencing returns the properties of relation fields linking to the object.
+	GetReferencing(objectID string) ([]Property, error)
+	// GetHistoryForField returns the history of the values of the field from since to until,
+	// oldest first. It starts with the value each property had at since, when it had one.
+	GetHistoryForField(propertyFieldID string, since int64, until int64) ([]PropertyHistoryEntry, error)
+	// GetForPost returns the properties of a post, including those inherited from the root of its thread.
+	GetForPost(post *model.Post) ([]Property, error)
+	// GetForPosts is GetForPost for several posts at once, keyed by post id, only returning the
+	// properties of the given fields when any are given.
+	GetForPosts(posts []*model.Post, fieldIDs []string) (map[string][]Property, error)
 	UpdateValue(id string, value []interface{}) error
 	Delete(id string) error
+	// SetComputedValue writes the value of a computed field of an object, creating, updating or
+	// deleting its property when the value is nil. Values that didn't change aren't written.
+	// Writes are validated and notify the change listeners like the others, except that
+	// properties are marked Computed.
+	SetComputedValue(object PropertyObject, fieldID string, value []interface{}) error
+
+	// RegisterChangeListener registers a function that will be called after a property has been
+	// created, updated or deleted. Returns an id which can be used to unregister the listener.
+	RegisterChangeListener(listener func(change PropertyChange)) string
+
+	// UnregisterChangeListener unregisters the listener function identified by id.
+	UnregisterChangeListener(id string)
+
+	// RegisterValidator registers a function that will be called before a property is created or
+	// its value updated, with the value to be written. An error rejects the write. Returns an id
+	// which can be used to unregister the validator.
+	RegisterValidator(validator func(property Property) error) string
+
+	// UnregisterValidator unregisters the validator function identified by id.
+	UnregisterValidator(id string)
 }
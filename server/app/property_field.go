@@ -8,20 +8,87 @@ type PropertyField struct {
 	Name     string        `json:"name"`
 	Type     string        `json:"type"`
 	Values   []interface{} `json:"values" db:"-"`
+
+	// InheritToReplies makes properties of this field set on a root post visible on its replies.
+	InheritToReplies bool `json:"inherit_to_replies"`
+
+	// Formula is the expression computing the values of formula fields.
+	Formula string `json:"formula,omitempty"`
+
+	// Relation configures relation fields.
+	Relation *RelationSettings `json:"relation,omitempty" db:"-"`
+
+	// Rollup configures rollup fields.
+	Rollup *RollupSettings `json:"rollup,omitempty" db:"-"`
+}
+
+// RelationSettings configures the objects a relation field links to.
+type RelationSettings struct {
+	// ObjectType is the type of the linked objects, posts or channels.
+	ObjectType string `json:"object_type"`
+
+	// BackLinkFieldID is a relation field kept up to date on the linked objects, listing the
+	// objects linking to them. The back-link field links back to this field in turn, or to
+	// itself for symmetric relations.
+	BackLinkFieldID string `json:"back_link_field_id,omitempty"`
+}
+
+const (
+	// RollupFunctionCount counts the related objects, only the ones having Value when set.
+	RollupFunctionCount = "count"
+	RollupFunctionSum   = "sum"
+	RollupFunctionMin   = "min"
+	RollupFunctionMax   = "max"
+	// RollupFunctionPercent is the percentage of related objects having Value, followed by the
+	// number of those objects and of all related objects, so views sort on the percentage.
+	RollupFunctionPercent = "percent"
+)
+
+// RollupSettings configures how a rollup field aggregates a field of the objects linked to by a
+// relation field.
+type RollupSettings struct {
+	RelationFieldID string `json:"relation_field_id"`
+	// FieldID is the field of the related objects aggregated, a number field for sum, min and max.
+	FieldID  string `json:"field_id,omitempty"`
+	Function string `json:"function"`
+	// Value is the value of FieldID counted by count and percent, for example "Done".
+	Value string `json:"value,omitempty"`
 }
 
 type PropertyFieldFilterOptions struct {
 	TeamID                   string
 	ExcludeHigherLevelFields bool
 	SearchTerm               string
-	Page                     int
-	PerPage                  int
+	// Types restricts the fields to the ones of these types when set.
+	Types   []string
+	Page    int
+	PerPage int
 }
 
 const (
 	PropertyFieldTypeText   = "text"
 	PropertyFieldTypeSelect = "select"
 	PropertyFieldTypeUser   = "user"
+	// PropertyFieldTypeDate values are dates ("2006-01-02") or RFC 3339 timestamps.
+	PropertyFieldTypeDate = "date"
+	// PropertyFieldTypeNumber values are JSON numbers.
+	PropertyFieldTypeNumber = "number"
+	// PropertyFieldTypeFormula values are computed from the formula of the field and can't be set.
+	PropertyFieldTypeFormula = "formula"
+	// PropertyFieldTypeRelation values are the ids of the objects linked to.
+	PropertyFieldTypeRelation = "relation"
+	// PropertyFieldTypeRollup values aggregate a field of related objects and can't be set.
+	PropertyFieldTypeRollup = "rollup"
+	// PropertyFieldTypeChannel values are the ids of channels.
+	PropertyFieldTypeChannel = "channel"
+	// PropertyFieldTypeTeam values are the ids of teams.
+	PropertyFieldTypeTeam = "team"
+	// PropertyFieldTypeCheckbox values are "true" or "false".
+	PropertyFieldTypeCheckbox = "checkbox"
+	PropertyFieldTypeURL      = "url"
+	PropertyFieldTypeEmail    = "email"
+	// PropertyFieldTypeRating values are whole numbers from 1 to 5.
+	PropertyFieldTypeRating = "rating"
 )
 
 type PropertyFieldStore interface {
@@ -38,4 +105,11 @@ type PropertyFieldService interface {
 	GetFields(filter PropertyFieldFilterOptions) ([]PropertyField, error)
 	Update(propertyField PropertyField) error
 	Delete(id string) error
+
+	// RegisterChangeListener registers a function that will be called after a property field has
+	// been created or updated. Returns an id which can be used to unregister the listener.
+	RegisterChangeListener(listener func(propertyField PropertyField)) string
+
+	// UnregisterChangeListener unregisters the listener function identified by id.
+	UnregisterChangeListener(id string)
 }
@@ -1,18 +1,29 @@
 package app
 
 import (
+	"sync"
+
+	"github.com/mattermost/mattermost/server/public/model"
 	"github.com/mattermost/mattermost/server/public/pluginapi"
+	"github.com/pkg/errors"
 )
 
+// fieldsPerPage is how many fields are loaded at once when going through all of them.
+const fieldsPerPage = 200
+
 type propertyFieldService struct {
 	store PropertyFieldStore
 	api   *pluginapi.Client
+
+	changeListenersLock sync.RWMutex
+	changeListeners     map[string]func(propertyField PropertyField)
 }
 
 func NewPropertyFieldService(store PropertyFieldStore, api *pluginapi.Client) PropertyFieldService {
 	return &propertyFieldService{
-		store: store,
-		api:   api,
+		store:           store,
+		api:             api,
+		changeListeners: make(map[string]func(propertyField PropertyField)),
 	}
 }
 
@@ -21,9 +32,23 @@ func (ps *propertyFieldService) Get(id string) (PropertyField, error) {
 }
 
 func (ps *propertyFieldService) Create(propertyField PropertyField) (string, error) {
-	//TODO: validate types
+	if err := ps.validateFieldType(propertyField); err != nil {
+		return "", err
+	}
+
+	id, err := ps.store.Create(propertyField)
+	if err != nil {
+		return "", err
+	}
+
+	propertyField.ID = id
+	if err = ps.linkBack(propertyField); err != nil {
+		return "", err
+	}
 
-	return ps.store.Create(propertyField)
+	ps.notifyChangeListeners(propertyField)
+
+	return id, nil
 }
 
 func (ps *propertyFieldService) GetFields(filter PropertyFieldFilterOptions) ([]PropertyField, error) {
@@ -31,9 +56,142 @@ func (ps *propertyFieldService) GetFields(filter PropertyFieldFilterOptions) ([]
 }
 
 func (ps *propertyFieldService) Update(propertyField PropertyField) error {
-	return ps.store.Update(propertyField)
+	existing, err := ps.store.Get(propertyField.ID)
+	if err != nil {
+		return err
+	}
+
+	// The type and team of a field never change
+	propertyField.Type = existing.Type
+	propertyField.TeamID = existing.TeamID
+	if err = ps.validateFieldType(propertyField); err != nil {
+		return err
+	}
+
+	if err = ps.store.Update(propertyField); err != nil {
+		return err
+	}
+
+	if err = ps.linkBack(propertyField); err != nil {
+		return err
+	}
+
+	ps.notifyChangeListeners(propertyField)
+
+	return nil
 }
 
 func (ps *propertyFieldService) Delete(id string) error {
 	return ps.store.Delete(id)
 }
+
+// validateFieldType checks the field against its registered type, wrapping errors with ErrInvalidField.
+func (ps *propertyFieldService) validateFieldType(propertyField PropertyField) error {
+	fieldType, ok := GetFieldType(propertyField.Type)
+	if !ok {
+		return errors.Wrapf(ErrInvalidField, "Unknown field type '%s'", propertyField.Type)
+	}
+
+	if err := fieldType.ValidateField(FieldTypeContext{Fields: ps, API: ps.api}, propertyField); err != nil {
+		return errors.Wrap(ErrInvalidField, err.Error())
+	}
+
+	return nil
+}
+
+// linkBack makes the back-link field of a relation field link back to it, so links are kept up to
+// date from both sides.
+func (ps *propertyFieldService) linkBack(propertyField PropertyField) error {
+	if propertyField.Relation == nil || propertyField.Relation.BackLinkFieldID == "" || propertyField.Relation.BackLinkFieldID == propertyField.ID {
+		return nil
+	}
+
+	backLinkField, err := ps.store.Get(propertyField.Relation.BackLinkFieldID)
+	if err != nil {
+		return errors.Wrap(err, "could not get back-link field")
+	}
+	if backLinkField.Relation != nil && backLinkField.Relation.BackLinkFieldID == propertyField.ID {
+		return nil
+	}
+
+	relation := RelationSettings{}
+	if backLinkField.Relation != nil {
+		relation = *backLinkField.Relation
+	}
+	relation.BackLinkFieldID = propertyField.ID
+	backLinkField.Relation = &relation
+
+	if err = ps.store.Update(backLinkField); err != nil {
+		return errors.Wrap(err, "could not link back-link field back")
+	}
+
+	return nil
+}
+
+// getAllFields pages through the fields matching the filter, ignoring its page settings.
+func getAllFields(propertyFieldService PropertyFieldService, filter PropertyFieldFilterOptions) ([]PropertyField, error) {
+	filter.PerPage = fieldsPerPage
+
+	fields := []PropertyField{}
+	for filter.Page = 0; ; filter.Page++ {
+		page, err := propertyFieldService.GetFields(filter)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not get fields")
+		}
+		fields = append(fields, page...)
+
+		if len(page) < fieldsPerPage {
+			return fields, nil
+		}
+	}
+}
+
+// formulaFields returns the fields the formulas of a team can reference, the fields of the team
+// and the ones without a team. Formulas without a team only reference fields without a team.
+func formulaFields(propertyFieldService PropertyFieldService, teamID string) ([]PropertyField, error) {
+	fields, err := getAllFields(propertyFieldService, PropertyFieldFilterOptions{TeamID: teamID})
+	if err != nil {
+		return nil, err
+	}
+
+	if teamID != "" {
+		return fields, nil
+	}
+
+	teamless := []PropertyField{}
+	for _, field := range fields {
+		if field.TeamID == "" {
+			teamless = append(teamless, field)
+		}
+	}
+	return teamless, nil
+}
+
+func (ps *propertyFieldService) RegisterChangeListener(listener func(propertyField PropertyField)) string {
+	ps.changeListenersLock.Lock()
+	defer ps.changeListenersLock.Unlock()
+
+	id := model.NewId()
+	ps.changeListeners[id] = listener
+	return id
+}
+
+func (ps *propertyFieldService) UnregisterChangeListener(id string) {
+	ps.changeListenersLock.Lock()
+	defer ps.changeListenersLock.Unlock()
+
+	delete(ps.changeListeners, id)
+}
+
+func (ps *propertyFieldService) notifyChangeListeners(propertyField PropertyField) {
+	ps.changeListenersLock.RLock()
+	listeners := make([]func(propertyField PropertyField), 0, len(ps.changeListeners))
+	for _, listener := range ps.changeListeners {
+		listeners = append(listeners, listener)
+	}
+	ps.changeListenersLock.RUnlock()
+
+	for _, listener := range listeners {
+		listener(propertyField)
+	}
+}
@@ -0,0 +1,56 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateFieldType(t *testing.T) {
+	cases := []struct {
+		Name     string
+		Field    PropertyField
+		Expected string
+	}{
+		{
+			Name:  "select",
+			Field: PropertyField{Type: PropertyFieldTypeSelect, Values: []interface{}{"Open", "Done"}},
+		},
+		{
+			Name:  "checkbox",
+			Field: PropertyField{Type: PropertyFieldTypeCheckbox},
+		},
+		{
+			Name:     "unknown type",
+			Field:    PropertyField{Type: "color"},
+			Expected: "Unknown field type 'color': invalid field",
+		},
+		{
+			Name:     "select values",
+			Field:    PropertyField{Type: PropertyFieldTypeSelect, Values: []interface{}{float64(1)}},
+			Expected: "Invalid values: select type must have string values: invalid field",
+		},
+		{
+			Name:     "text values",
+			Field:    PropertyField{Type: PropertyFieldTypeText, Values: []interface{}{"Open"}},
+			Expected: "Invalid values: text type has no values: invalid field",
+		},
+		{
+			Name:     "formula of another type",
+			Field:    PropertyField{Type: PropertyFieldTypeNumber, Formula: "1 + 1"},
+			Expected: "Invalid formula: only formula type has a formula: invalid field",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			err := (&propertyFieldService{}).validateFieldType(c.Field)
+			if c.Expected == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, ErrInvalidField)
+			assert.EqualError(t, err, c.Expected)
+		})
+	}
+}
@@ -1,6 +1,10 @@
 package app
 
 import (
+	"reflect"
+	"sync"
+
+	"github.com/mattermost/mattermost/server/public/model"
 	"github.com/mattermost/mattermost/server/public/pluginapi"
 	"github.com/pkg/errors"
 )
@@ -9,17 +13,38 @@ type propertyService struct {
 	store                PropertyStore
 	propertyFieldService PropertyFieldService
 	api                  *pluginapi.Client
+
+	changeListenersLock sync.RWMutex
+	changeListeners     map[string]func(change PropertyChange)
+
+	validatorsLock sync.RWMutex
+	validators     map[string]func(property Property) error
 }
 
 func NewPropertyService(store PropertyStore, propertyFieldService PropertyFieldService, api *pluginapi.Client) PropertyService {
-	return &propertyService{
+	ps := &propertyService{
 		store:                store,
 		propertyFieldService: propertyFieldService,
 		api:                  api,
+		changeListeners:      make(map[string]func(change PropertyChange)),
+		validators:           make(map[string]func(property Property) error),
 	}
+
+	ps.RegisterValidator(ps.validateType)
+	ps.RegisterChangeListener(ps.updateBackLinks)
+
+	return ps
+}
+
+func (ps *propertyService) Get(id string) (Property, error) {
+	return ps.store.Get(id)
 }
 
 func (ps *propertyService) Create(property Property) (string, error) {
+	return ps.create(property, false)
+}
+
+func (ps *propertyService) create(property Property, computed bool) (string, error) {
 	if property.ObjectID == "" {
 		return "", errors.New("ObjectID should not be blank")
 	}
@@ -33,7 +58,7 @@ func (ps *propertyService) Create(property Property) (string, error) {
 	}
 
 	// Confirm field exists for property
-	_, err := ps.propertyFieldService.Get(property.PropertyFieldID)
+	field, err := ps.propertyFieldService.Get(property.PropertyFieldID)
 	if err != nil {
 		if errors.Is(err, ErrNotFound) {
 			return "", errors.Errorf("Tried to create property with unknown property_field with id: '%s'", property.PropertyFieldID)
@@ -41,13 +66,28 @@ func (ps *propertyService) Create(property Property) (string, error) {
 		return "", err
 	}
 
-	//TODO: validate value for selected field
+	property.PropertyFieldName = field.Name
+	property.PropertyFieldType = field.Type
+	property.PropertyFieldValues = field.Values
+	property.PropertyFieldInheritToReplies = field.InheritToReplies
+	property.Computed = computed
+	property.Value = normalizeValue(property, property.Value)
+	if err = ps.validate(property); err != nil {
+		return "", err
+	}
 
 	id, err := ps.store.Create(property)
 	if err != nil {
 		return "", err
 	}
 
+	property.ID = id
+	ps.notifyChangeListeners(PropertyChange{
+		Type:          PropertyChangeTypeCreated,
+		Property:      property,
+		PreviousValue: []interface{}{},
+	})
+
 	return id, nil
 }
 
@@ -55,10 +95,239 @@ func (ps *propertyService) GetForObject(objectID string) ([]Property, error) {
 	return ps.store.GetByObjectID(objectID)
 }
 
+func (ps *propertyService) GetForObjects(objectIDs []string, fieldIDs []string) ([]Property, error) {
+	return ps.store.GetForObjects(objectIDs, fieldIDs)
+}
+
+func (ps *propertyService) GetForField(propertyFieldID string) ([]Property, error) {
+	return ps.store.GetByFieldID(propertyFieldID)
+}
+
+func (ps *propertyService) GetProperties(filter PropertyFilterOptions) ([]Property, error) {
+	return ps.store.GetProperties(filter)
+}
+
+func (ps *propertyService) GetReferencing(objectID string) ([]Property, error) {
+	return ps.store.GetReferencing(objectID)
+}
+
+func (ps *propertyService) GetHistoryForField(propertyFieldID string, since int64, until int64) ([]PropertyHistoryEntry, error) {
+	return ps.store.GetHistoryForField(propertyFieldID, since, until)
+}
+
+func (ps *propertyService) GetForPost(post *model.Post) ([]Property, error) {
+	properties, err := ps.store.GetByObjectID(post.Id)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return nil, err
+	}
+
+	if post.RootId == "" {
+		return properties, nil
+	}
+
+	rootProperties, err := ps.store.GetByObjectID(post.RootId)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return nil, errors.Wrapf(err, "could not get properties for root post with id '%s'", post.RootId)
+	}
+
+	return inheritProperties(properties, rootProperties), nil
+}
+
+func (ps *propertyService) GetForPosts(posts []*model.Post, fieldIDs []string) (map[string][]Property, error) {
+	objectIDs := make([]string, 0, len(posts))
+	for _, post := range posts {
+		objectIDs = append(objectIDs, post.Id)
+		if post.RootId != "" {
+			objectIDs = append(objectIDs, post.RootId)
+		}
+	}
+
+	properties, err := ps.store.GetForObjects(objectIDs, fieldIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	byObject := map[string][]Property{}
+	for _, property := range properties {
+		byObject[property.ObjectID] = append(byObject[property.ObjectID], property)
+	}
+
+	byPost := make(map[string][]Property, len(posts))
+	for _, post := range posts {
+		byPost[post.Id] = byObject[post.Id]
+		if post.RootId != "" {
+			byPost[post.Id] = inheritProperties(byObject[post.Id], byObject[post.RootId])
+		}
+		if byPost[post.Id] == nil {
+			byPost[post.Id] = []Property{}
+		}
+	}
+
+	return byPost, nil
+}
+
+// inheritProperties adds the properties of the root post of a thread inherited by a reply to the
+// properties of the reply. Properties set on the reply itself take precedence.
+func inheritProperties(properties []Property, rootProperties []Property) []Property {
+	hasField := map[string]bool{}
+	for _, property := range properties {
+		hasField[property.PropertyFieldID] = true
+	}
+
+	for _, rootProperty := range rootProperties {
+		if !rootProperty.PropertyFieldInheritToReplies || hasField[rootProperty.PropertyFieldID] {
+			continue
+		}
+		rootProperty.Inherited = true
+		properties = append(properties, rootProperty)
+	}
+
+	return properties
+}
+
 func (ps *propertyService) UpdateValue(id string, value []interface{}) error {
-	return ps.store.UpdateValue(id, value)
+	property, err := ps.store.Get(id)
+	if err != nil {
+		return err
+	}
+
+	return ps.updateValue(property, value, false)
+}
+
+func (ps *propertyService) updateValue(property Property, value []interface{}, computed bool) error {
+	if value == nil {
+		value = []interface{}{}
+	}
+
+	previousValue := property.Value
+	value = normalizeValue(property, value)
+	property.Value = value
+	property.Computed = computed
+	if err := ps.validate(property); err != nil {
+		return err
+	}
+
+	if err := ps.store.UpdateValue(property.ID, value); err != nil {
+		return err
+	}
+
+	ps.notifyChangeListeners(PropertyChange{
+		Type:          PropertyChangeTypeUpdated,
+		Property:      property,
+		PreviousValue: previousValue,
+	})
+
+	return nil
 }
 
 func (ps *propertyService) Delete(id string) error {
-	return ps.store.Delete(id)
+	property, err := ps.store.Get(id)
+	if err != nil {
+		return err
+	}
+
+	if err = ps.store.Delete(id); err != nil {
+		return err
+	}
+
+	previousValue := property.Value
+	property.Value = []interface{}{}
+	ps.notifyChangeListeners(PropertyChange{
+		Type:          PropertyChangeTypeDeleted,
+		Property:      property,
+		PreviousValue: previousValue,
+	})
+
+	return nil
+}
+
+func (ps *propertyService) SetComputedValue(object PropertyObject, fieldID string, value []interface{}) error {
+	properties, err := ps.store.GetForObjects([]string{object.ObjectID}, []string{fieldID})
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return errors.Wrapf(err, "could not get property of field '%s' for object '%s'", fieldID, object.ObjectID)
+	}
+
+	property := FindProperty(properties, fieldID)
+	switch {
+	case property == nil && value == nil:
+		return nil
+	case property == nil:
+		_, err = ps.create(Property{
+			ObjectID:        object.ObjectID,
+			ObjectType:      object.ObjectType,
+			PropertyFieldID: fieldID,
+			ChannelID:       object.ChannelID,
+			TeamID:          object.TeamID,
+			Value:           value,
+		}, true)
+		return err
+	case value == nil:
+		return ps.Delete(property.ID)
+	case reflect.DeepEqual(property.Value, value):
+		return nil
+	}
+
+	return ps.updateValue(*property, value, true)
+}
+
+func (ps *propertyService) RegisterChangeListener(listener func(change PropertyChange)) string {
+	ps.changeListenersLock.Lock()
+	defer ps.changeListenersLock.Unlock()
+
+	id := model.NewId()
+	ps.changeListeners[id] = listener
+	return id
+}
+
+func (ps *propertyService) UnregisterChangeListener(id string) {
+	ps.changeListenersLock.Lock()
+	defer ps.changeListenersLock.Unlock()
+
+	delete(ps.changeListeners, id)
+}
+
+// notifyChangeListeners calls the change listeners without holding the lock, since listeners
+// may write properties themselves.
+func (ps *propertyService) notifyChangeListeners(change PropertyChange) {
+	ps.changeListenersLock.RLock()
+	listeners := make([]func(change PropertyChange), 0, len(ps.changeListeners))
+	for _, listener := range ps.changeListeners {
+		listeners = append(listeners, listener)
+	}
+	ps.changeListenersLock.RUnlock()
+
+	for _, listener := range listeners {
+		listener(change)
+	}
+}
+
+func (ps *propertyService) RegisterValidator(validator func(property Property) error) string {
+	ps.validatorsLock.Lock()
+	defer ps.validatorsLock.Unlock()
+
+	id := model.NewId()
+	ps.validators[id] = validator
+	return id
+}
+
+func (ps *propertyService) UnregisterValidator(id string) {
+	ps.validatorsLock.Lock()
+	defer ps.validatorsLock.Unlock()
+
+	delete(ps.validators, id)
+}
+
+// validate runs the validators against a property about to be written, wrapping the first error
+// with ErrInvalidValue.
+func (ps *propertyService) validate(property Property) error {
+	ps.validatorsLock.RLock()
+	defer ps.validatorsLock.RUnlock()
+
+	for _, validator := range ps.validators {
+		if err := validator(property); err != nil {
+			return errors.Wrap(ErrInvalidValue, err.Error())
+		}
+	}
+
+	return nil
 }
@@ -0,0 +1,258 @@
+package app
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost/server/public/model"
+)
+
+// fakePropertyStore keeps properties in memory, other methods panic.
+type fakePropertyStore struct {
+	PropertyStore
+	properties []Property
+}
+
+func (s *fakePropertyStore) Get(id string) (Property, error) {
+	for _, property := range s.properties {
+		if property.ID == id {
+			return property, nil
+		}
+	}
+	return Property{}, ErrNotFound
+}
+
+func (s *fakePropertyStore) GetByObjectID(objectID string) ([]Property, error) {
+	properties := []Property{}
+	for _, property := range s.properties {
+		if property.ObjectID == objectID {
+			properties = append(properties, property)
+		}
+	}
+	if len(properties) == 0 {
+		return nil, ErrNotFound
+	}
+	return properties, nil
+}
+
+func (s *fakePropertyStore) GetForObjects(objectIDs []string, fieldIDs []string) ([]Property, error) {
+	properties := []Property{}
+	for _, property := range s.properties {
+		if slices.Contains(objectIDs, property.ObjectID) && (fieldIDs == nil || slices.Contains(fieldIDs, property.PropertyFieldID)) {
+			properties = append(properties, property)
+		}
+	}
+	return properties, nil
+}
+
+func (s *fakePropertyStore) Create(property Property) (string, error) {
+	property.ID = model.NewId()
+	s.properties = append(s.properties, property)
+	return property.ID, nil
+}
+
+func (s *fakePropertyStore) UpdateValue(id string, value []interface{}) error {
+	for i := range s.properties {
+		if s.properties[i].ID == id {
+			s.properties[i].Value = value
+			return nil
+		}
+	}
+	return ErrNotFound
+}
+
+func (s *fakePropertyStore) Delete(id string) error {
+	for i := range s.properties {
+		if s.properties[i].ID == id {
+			s.properties = slices.Delete(s.properties, i, i+1)
+			return nil
+		}
+	}
+	return ErrNotFound
+}
+
+func TestGetForPost(t *testing.T) {
+	ps := &propertyService{store: &fakePropertyStore{properties: []Property{
+		{ID: "root-status", ObjectID: "root", PropertyFieldID: "status", PropertyFieldInheritToReplies: true},
+		{ID: "root-owner", ObjectID: "root", PropertyFieldID: "owner", PropertyFieldInheritToReplies: true},
+		{ID: "root-notes", ObjectID: "root", PropertyFieldID: "notes"},
+		{ID: "reply-owner", ObjectID: "reply", PropertyFieldID: "owner", PropertyFieldInheritToReplies: true},
+	}}}
+
+	cases := []struct {
+		Name     string
+		Post     *model.Post
+		Expected []string
+	}{
+		{
+			Name:     "root post",
+			Post:     &model.Post{Id: "root"},
+			Expected: []string{"root-status", "root-owner", "root-notes"},
+		},
+		{
+			Name:     "reply overriding an inherited field",
+			Post:     &model.Post{Id: "reply", RootId: "root"},
+			Expected: []string{"reply-owner", "root-status"},
+		},
+		{
+			Name:     "reply without properties",
+			Post:     &model.Post{Id: "other", RootId: "root"},
+			Expected: []string{"root-status", "root-owner"},
+		},
+		{
+			Name:     "post without properties",
+			Post:     &model.Post{Id: "other"},
+			Expected: []string{},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			properties, err := ps.GetForPost(c.Post)
+			require.NoError(t, err)
+
+			ids := []string{}
+			for _, property := range properties {
+				ids = append(ids, property.ID)
+				assert.Equal(t, property.ObjectID != c.Post.Id, property.Inherited)
+			}
+			assert.Equal(t, c.Expected, ids)
+		})
+	}
+}
+
+func TestGetForPosts(t *testing.T) {
+	ps := &propertyService{store: &fakePropertyStore{properties: []Property{
+		{ID: "root-status", ObjectID: "root", PropertyFieldID: "status", PropertyFieldInheritToReplies: true},
+		{ID: "root-owner", ObjectID: "root", PropertyFieldID: "owner", PropertyFieldInheritToReplies: true},
+		{ID: "root-notes", ObjectID: "root", PropertyFieldID: "notes"},
+		{ID: "reply-owner", ObjectID: "reply", PropertyFieldID: "owner", PropertyFieldInheritToReplies: true},
+	}}}
+
+	posts := []*model.Post{{Id: "root"}, {Id: "reply", RootId: "root"}, {Id: "other"}}
+
+	cases := []struct {
+		Name     string
+		FieldIDs []string
+		Expected map[string][]string
+	}{
+		{
+			Name:     "all fields",
+			FieldIDs: nil,
+			Expected: map[string][]string{
+				"root":  {"root-status", "root-owner", "root-notes"},
+				"reply": {"reply-owner", "root-status"},
+				"other": {},
+			},
+		},
+		{
+			Name:     "some fields",
+			FieldIDs: []string{"owner", "notes"},
+			Expected: map[string][]string{
+				"root":  {"root-owner", "root-notes"},
+				"reply": {"reply-owner"},
+				"other": {},
+			},
+		},
+		{
+			Name:     "inherited field only",
+			FieldIDs: []string{"status"},
+			Expected: map[string][]string{
+				"root":  {"root-status"},
+				"reply": {"root-status"},
+				"other": {},
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			properties, err := ps.GetForPosts(posts, c.FieldIDs)
+			require.NoError(t, err)
+
+			ids := map[string][]string{}
+			for postID, postProperties := range properties {
+				ids[postID] = []string{}
+				for _, property := range postProperties {
+					ids[postID] = append(ids[postID], property.ID)
+					assert.Equal(t, property.ObjectID != postID, property.Inherited)
+				}
+			}
+			assert.Equal(t, c.Expected, ids)
+		})
+	}
+}
+
+func TestSetComputedValue(t *testing.T) {
+	store := &fakePropertyStore{}
+	fieldService := &fakePropertyFieldService{fields: []PropertyField{{ID: "left", Name: "Left", Type: PropertyFieldTypeFormula}}}
+	ps := NewPropertyService(store, fieldService, nil)
+	ps.RegisterValidator((&formulaService{}).validateComputed)
+
+	changes := []string{}
+	ps.RegisterChangeListener(func(change PropertyChange) {
+		changes = append(changes, change.Type)
+	})
+
+	object := PropertyObject{ObjectID: "post1", ObjectType: PropertyObjectTypePost, ChannelID: "channel1", TeamID: "team1"}
+
+	cases := []struct {
+		Name            string
+		Value           []interface{}
+		ExpectedValue   []interface{}
+		ExpectedChanges []string
+	}{
+		{
+			Name:            "created",
+			Value:           []interface{}{float64(3)},
+			ExpectedValue:   []interface{}{float64(3)},
+			ExpectedChanges: []string{PropertyChangeTypeCreated},
+		},
+		{
+			Name:            "unchanged",
+			Value:           []interface{}{float64(3)},
+			ExpectedValue:   []interface{}{float64(3)},
+			ExpectedChanges: []string{},
+		},
+		{
+			Name:            "updated",
+			Value:           []interface{}{float64(2)},
+			ExpectedValue:   []interface{}{float64(2)},
+			ExpectedChanges: []string{PropertyChangeTypeUpdated},
+		},
+		{
+			Name:            "deleted",
+			Value:           nil,
+			ExpectedValue:   nil,
+			ExpectedChanges: []string{PropertyChangeTypeDeleted},
+		},
+		{
+			Name:            "nothing to delete",
+			Value:           nil,
+			ExpectedValue:   nil,
+			ExpectedChanges: []string{},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			changes = []string{}
+			require.NoError(t, ps.SetComputedValue(object, "left", c.Value))
+			assert.Equal(t, c.ExpectedChanges, changes)
+
+			var value []interface{}
+			if property := FindProperty(store.properties, "left"); property != nil {
+				value = property.Value
+			}
+			assert.Equal(t, c.ExpectedValue, value)
+		})
+	}
+
+	t.Run("other writes rejected", func(t *testing.T) {
+		_, err := ps.Create(Property{ObjectID: "post1", PropertyFieldID: "left", Value: []interface{}{float64(1)}})
+		assert.ErrorIs(t, err, ErrInvalidValue)
+	})
+}
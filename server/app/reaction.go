@@ -0,0 +1,45 @@
+package app
+
+import (
+	"github.com/mattermost/mattermost/server/public/model"
+)
+
+// ReactionMapping sets a property on posts of a channel when someone reacts to them with the
+// emoji, and clears it again when the reaction is removed.
+type ReactionMapping struct {
+	ID              string `json:"id"`
+	TeamID          string `json:"team_id"`
+	ChannelID       string `json:"channel_id"`
+	EmojiName       string `json:"emoji_name"`
+	PropertyFieldID string `json:"property_field_id"`
+	// ValueFromReactor sets the value to the reacting user instead of Value. Only valid for
+	// user fields.
+	ValueFromReactor bool          `json:"value_from_reactor"`
+	CreateAt         int64         `json:"create_at"`
+	UpdateAt         int64         `json:"update_at"`
+	UpdateBy         string        `json:"update_by"`
+	Value            []interface{} `json:"value" db:"-"`
+}
+
+type ReactionMappingStore interface {
+	Get(id string) (ReactionMapping, error)
+	Create(mapping ReactionMapping) (string, error)
+	GetForChannel(channelID string) ([]ReactionMapping, error)
+	GetForEmoji(channelID string, emojiName string) ([]ReactionMapping, error)
+	Update(mapping ReactionMapping) error
+	Delete(id string) error
+}
+
+type ReactionMappingService interface {
+	Get(id string) (ReactionMapping, error)
+	Create(mapping ReactionMapping) (string, error)
+	GetForChannel(channelID string) ([]ReactionMapping, error)
+	Update(mapping ReactionMapping) error
+	Delete(id string) error
+
+	// ApplyReactionAdded sets the properties mapped to the emoji of the new reaction.
+	ApplyReactionAdded(reaction *model.Reaction) error
+
+	// ApplyReactionRemoved clears the properties mapped to the emoji of the removed reaction.
+	ApplyReactionRemoved(reaction *model.Reaction) error
+}
@@ -0,0 +1,250 @@
+package app
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/pluginapi"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+type reactionMappingService struct {
+	store                ReactionMappingStore
+	propertyService      PropertyService
+	propertyFieldService PropertyFieldService
+	permissions          *PermissionsService
+	api                  *pluginapi.Client
+}
+
+func NewReactionMappingService(store ReactionMappingStore, propertyService PropertyService, propertyFieldService PropertyFieldService, permissions *PermissionsService, api *pluginapi.Client) ReactionMappingService {
+	return &reactionMappingService{
+		store:                store,
+		propertyService:      propertyService,
+		propertyFieldService: propertyFieldService,
+		permissions:          permissions,
+		api:                  api,
+	}
+}
+
+func (rms *reactionMappingService) Get(id string) (ReactionMapping, error) {
+	return rms.store.Get(id)
+}
+
+func (rms *reactionMappingService) Create(mapping ReactionMapping) (string, error) {
+	mapping.EmojiName = strings.Trim(mapping.EmojiName, ":")
+	if err := rms.validate(mapping); err != nil {
+		return "", err
+	}
+
+	return rms.store.Create(mapping)
+}
+
+func (rms *reactionMappingService) GetForChannel(channelID string) ([]ReactionMapping, error) {
+	return rms.store.GetForChannel(channelID)
+}
+
+func (rms *reactionMappingService) Update(mapping ReactionMapping) error {
+	mapping.EmojiName = strings.Trim(mapping.EmojiName, ":")
+	if err := rms.validate(mapping); err != nil {
+		return err
+	}
+
+	return rms.store.Update(mapping)
+}
+
+func (rms *reactionMappingService) Delete(id string) error {
+	return rms.store.Delete(id)
+}
+
+func (rms *reactionMappingService) validate(mapping ReactionMapping) error {
+	if mapping.ChannelID == "" {
+		return errors.New("ChannelID should not be blank")
+	}
+
+	if mapping.EmojiName == "" {
+		return errors.New("EmojiName should not be blank")
+	}
+
+	if mapping.PropertyFieldID == "" {
+		return errors.New("PropertyFieldID should not be blank")
+	}
+
+	field, err := rms.propertyFieldService.Get(mapping.PropertyFieldID)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return errors.Errorf("Tried to create reaction mapping with unknown property_field with id: '%s'", mapping.PropertyFieldID)
+		}
+		return err
+	}
+
+	channel, err := rms.api.Channel.Get(mapping.ChannelID)
+	if err != nil {
+		return errors.Errorf("Channel '%s' of the reaction mapping does not exist", mapping.ChannelID)
+	}
+
+	if field.TeamID != "" && field.TeamID != channel.TeamId {
+		return errors.Errorf("property_field '%s' belongs to another team", mapping.PropertyFieldID)
+	}
+
+	if mapping.ValueFromReactor {
+		if field.Type != PropertyFieldTypeUser {
+			return errors.Errorf("ValueFromReactor is only supported for fields of type '%s'", PropertyFieldTypeUser)
+		}
+	} else if len(mapping.Value) == 0 {
+		return errors.New("Value should not be empty")
+	}
+
+	return nil
+}
+
+func (rms *reactionMappingService) ApplyReactionAdded(reaction *model.Reaction) error {
+	post, mappings, err := rms.mappingsForReaction(reaction)
+	if err != nil || len(mappings) == 0 {
+		return err
+	}
+
+	channel, err := rms.api.Channel.Get(post.ChannelId)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get channel '%s'", post.ChannelId)
+	}
+
+	for _, mapping := range mappings {
+		property := Property{
+			ObjectID:        post.Id,
+			ObjectType:      PropertyObjectTypePost,
+			PropertyFieldID: mapping.PropertyFieldID,
+			ChannelID:       channel.Id,
+			TeamID:          channel.TeamId,
+			Value:           mapping.Value,
+		}
+		if mapping.ValueFromReactor {
+			property.Value = []interface{}{reaction.UserId}
+		}
+
+		if err = rms.permissions.PropertyCreate(reaction.UserId, property); err != nil {
+			logrus.WithError(err).WithField("mapping_id", mapping.ID).Debug("Reacting user may not set the mapped property")
+			continue
+		}
+
+		var existing *Property
+		existing, err = rms.existingProperty(post.Id, mapping.PropertyFieldID)
+		if err != nil {
+			return err
+		}
+
+		// Reactors are added to the users already set, like they're removed one by one
+		if existing != nil && mapping.ValueFromReactor {
+			if slices.Contains(existing.Value, interface{}(reaction.UserId)) {
+				continue
+			}
+			property.Value = append(slices.Clone(existing.Value), reaction.UserId)
+		}
+
+		if existing != nil {
+			err = rms.propertyService.UpdateValue(existing.ID, property.Value)
+		} else {
+			_, err = rms.propertyService.Create(property)
+		}
+		if err != nil {
+			logrus.WithError(err).WithFields(logrus.Fields{
+				"mapping_id": mapping.ID,
+				"post_id":    post.Id,
+			}).Warn("Failed to set property for reaction")
+		}
+	}
+
+	return nil
+}
+
+func (rms *reactionMappingService) ApplyReactionRemoved(reaction *model.Reaction) error {
+	post, mappings, err := rms.mappingsForReaction(reaction)
+	if err != nil || len(mappings) == 0 {
+		return err
+	}
+
+	reactions, err := rms.api.Post.GetReactions(post.Id)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get reactions for post '%s'", post.Id)
+	}
+
+	// The value stays as long as someone else still reacts with the emoji
+	stillReacted := false
+	for _, r := range reactions {
+		if r.EmojiName == reaction.EmojiName && r.UserId != reaction.UserId {
+			stillReacted = true
+			break
+		}
+	}
+
+	for _, mapping := range mappings {
+		if err = rms.permissions.PropertyCreate(reaction.UserId, Property{ObjectID: post.Id, ObjectType: PropertyObjectTypePost}); err != nil {
+			logrus.WithError(err).WithField("mapping_id", mapping.ID).Debug("Reacting user may not clear the mapped property")
+			continue
+		}
+
+		var existing *Property
+		existing, err = rms.existingProperty(post.Id, mapping.PropertyFieldID)
+		if err != nil {
+			return err
+		}
+		if existing == nil {
+			continue
+		}
+
+		var value []interface{}
+		if mapping.ValueFromReactor {
+			value = make([]interface{}, 0, len(existing.Value))
+			for _, v := range existing.Value {
+				if v != reaction.UserId {
+					value = append(value, v)
+				}
+			}
+			if len(value) == len(existing.Value) {
+				continue
+			}
+		} else if stillReacted || !sameValues(existing.Value, mapping.Value) {
+			// Someone else still reacts with the emoji or the value has since been changed
+			continue
+		}
+
+		if len(value) == 0 {
+			err = rms.propertyService.Delete(existing.ID)
+		} else {
+			err = rms.propertyService.UpdateValue(existing.ID, value)
+		}
+		if err != nil {
+			logrus.WithError(err).WithFields(logrus.Fields{
+				"mapping_id": mapping.ID,
+				"post_id":    post.Id,
+			}).Warn("Failed to clear property for reaction")
+		}
+	}
+
+	return nil
+}
+
+// mappingsForReaction returns the post reacted to and the mappings of its channel for the emoji.
+func (rms *reactionMappingService) mappingsForReaction(reaction *model.Reaction) (*model.Post, []ReactionMapping, error) {
+	post, err := rms.api.Post.GetPost(reaction.PostId)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "failed to get post '%s'", reaction.PostId)
+	}
+
+	mappings, err := rms.store.GetForEmoji(post.ChannelId, reaction.EmojiName)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "failed to get reaction mappings for channel '%s'", post.ChannelId)
+	}
+
+	return post, mappings, nil
+}
+
+func (rms *reactionMappingService) existingProperty(objectID string, propertyFieldID string) (*Property, error) {
+	properties, err := rms.propertyService.GetForObject(objectID)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return nil, errors.Wrapf(err, "failed to get properties for object '%s'", objectID)
+	}
+
+	return FindProperty(properties, propertyFieldID), nil
+}
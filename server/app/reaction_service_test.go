@@ -0,0 +1,254 @@
+package app
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/plugin/plugintest"
+	"github.com/mattermost/mattermost/server/public/pluginapi"
+)
+
+// fakeReactionMappingStore returns the mappings it's given for their emoji, other methods panic.
+type fakeReactionMappingStore struct {
+	ReactionMappingStore
+	mappings []ReactionMapping
+}
+
+func (s *fakeReactionMappingStore) GetForEmoji(_ string, emojiName string) ([]ReactionMapping, error) {
+	mappings := []ReactionMapping{}
+	for _, mapping := range s.mappings {
+		if mapping.EmojiName == emojiName {
+			mappings = append(mappings, mapping)
+		}
+	}
+	return mappings, nil
+}
+
+var testReactionMappings = []ReactionMapping{
+	{ID: "mapping1", ChannelID: "channel1", EmojiName: "white_check_mark", PropertyFieldID: "status", Value: []interface{}{"Done"}},
+	{ID: "mapping2", ChannelID: "channel1", EmojiName: "eyes", PropertyFieldID: "reviewers", ValueFromReactor: true},
+}
+
+// newTestReactionMappingService returns a service for a post in a channel where user1 and user2
+// may set properties and guest may not, with the given reactions.
+func newTestReactionMappingService(propertyService *fakePropertyService, reactions []*model.Reaction) ReactionMappingService {
+	api := &plugintest.API{}
+	api.On("GetPost", "post1").Return(&model.Post{Id: "post1", ChannelId: "channel1"}, nil)
+	api.On("GetChannel", "channel1").Return(&model.Channel{Id: "channel1", TeamId: "team1"}, nil)
+	api.On("GetReactions", "post1").Return(reactions, nil)
+	api.On("HasPermissionToChannel", "user1", "channel1", model.PermissionCreatePost).Return(true)
+	api.On("HasPermissionToChannel", "user2", "channel1", model.PermissionCreatePost).Return(true)
+	api.On("HasPermissionToChannel", "guest", "channel1", model.PermissionCreatePost).Return(false)
+
+	client := pluginapi.NewClient(api, nil)
+	permissions := NewPermissionsService(propertyService, nil, client, nil)
+
+	return NewReactionMappingService(&fakeReactionMappingStore{mappings: testReactionMappings}, propertyService, nil, permissions, client)
+}
+
+func TestApplyReactionAdded(t *testing.T) {
+	cases := []struct {
+		Name            string
+		Reaction        *model.Reaction
+		Existing        []Property
+		ExpectedCreated []Property
+		ExpectedUpdates []Property
+	}{
+		{
+			Name:     "new property",
+			Reaction: &model.Reaction{UserId: "user1", PostId: "post1", EmojiName: "white_check_mark"},
+			ExpectedCreated: []Property{
+				{ObjectID: "post1", ObjectType: PropertyObjectTypePost, PropertyFieldID: "status", ChannelID: "channel1", TeamID: "team1", Value: []interface{}{"Done"}},
+			},
+		},
+		{
+			Name:     "existing property",
+			Reaction: &model.Reaction{UserId: "user1", PostId: "post1", EmojiName: "white_check_mark"},
+			Existing: []Property{{ID: "property1", ObjectID: "post1", PropertyFieldID: "status", Value: []interface{}{"Open"}}},
+			ExpectedUpdates: []Property{
+				{ID: "property1", Value: []interface{}{"Done"}},
+			},
+		},
+		{
+			Name:     "value from reactor",
+			Reaction: &model.Reaction{UserId: "user2", PostId: "post1", EmojiName: "eyes"},
+			ExpectedCreated: []Property{
+				{ObjectID: "post1", ObjectType: PropertyObjectTypePost, PropertyFieldID: "reviewers", ChannelID: "channel1", TeamID: "team1", Value: []interface{}{"user2"}},
+			},
+		},
+		{
+			Name:     "reactor added to the users set",
+			Reaction: &model.Reaction{UserId: "user2", PostId: "post1", EmojiName: "eyes"},
+			Existing: []Property{{ID: "property1", ObjectID: "post1", PropertyFieldID: "reviewers", Value: []interface{}{"user1"}}},
+			ExpectedUpdates: []Property{
+				{ID: "property1", Value: []interface{}{"user1", "user2"}},
+			},
+		},
+		{
+			Name:     "reactor already set",
+			Reaction: &model.Reaction{UserId: "user1", PostId: "post1", EmojiName: "eyes"},
+			Existing: []Property{{ID: "property1", ObjectID: "post1", PropertyFieldID: "reviewers", Value: []interface{}{"user1"}}},
+		},
+		{
+			Name:     "reactor without permission",
+			Reaction: &model.Reaction{UserId: "guest", PostId: "post1", EmojiName: "white_check_mark"},
+		},
+		{
+			Name:     "unmapped emoji",
+			Reaction: &model.Reaction{UserId: "user1", PostId: "post1", EmojiName: "tada"},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			propertyService := &fakePropertyService{properties: map[string][]Property{}}
+			if c.Existing != nil {
+				propertyService.properties["post1"] = c.Existing
+			}
+			rms := newTestReactionMappingService(propertyService, nil)
+
+			require.NoError(t, rms.ApplyReactionAdded(c.Reaction))
+
+			for i := range propertyService.created {
+				propertyService.created[i].ID = ""
+			}
+			assert.Equal(t, c.ExpectedCreated, propertyService.created)
+			assert.Equal(t, c.ExpectedUpdates, propertyService.updates)
+		})
+	}
+}
+
+func TestApplyReactionRemoved(t *testing.T) {
+	cases := []struct {
+		Name            string
+		Reaction        *model.Reaction
+		Reactions       []*model.Reaction
+		Existing        []Property
+		ExpectedDeleted []string
+		ExpectedUpdates []Property
+	}{
+		{
+			Name:            "last reaction clears the value",
+			Reaction:        &model.Reaction{UserId: "user1", PostId: "post1", EmojiName: "white_check_mark"},
+			Existing:        []Property{{ID: "property1", ObjectID: "post1", PropertyFieldID: "status", Value: []interface{}{"Done"}}},
+			ExpectedDeleted: []string{"property1"},
+		},
+		{
+			Name:      "someone else still reacts",
+			Reaction:  &model.Reaction{UserId: "user1", PostId: "post1", EmojiName: "white_check_mark"},
+			Reactions: []*model.Reaction{{UserId: "user2", PostId: "post1", EmojiName: "white_check_mark"}},
+			Existing:  []Property{{ID: "property1", ObjectID: "post1", PropertyFieldID: "status", Value: []interface{}{"Done"}}},
+		},
+		{
+			Name:     "value changed since",
+			Reaction: &model.Reaction{UserId: "user1", PostId: "post1", EmojiName: "white_check_mark"},
+			Existing: []Property{{ID: "property1", ObjectID: "post1", PropertyFieldID: "status", Value: []interface{}{"Open"}}},
+		},
+		{
+			Name:     "no property",
+			Reaction: &model.Reaction{UserId: "user1", PostId: "post1", EmojiName: "white_check_mark"},
+		},
+		{
+			Name:     "reactor removed from the value",
+			Reaction: &model.Reaction{UserId: "user1", PostId: "post1", EmojiName: "eyes"},
+			Existing: []Property{{ID: "property1", ObjectID: "post1", PropertyFieldID: "reviewers", Value: []interface{}{"user1", "user2"}}},
+			ExpectedUpdates: []Property{
+				{ID: "property1", Value: []interface{}{"user2"}},
+			},
+		},
+		{
+			Name:            "last reactor removed",
+			Reaction:        &model.Reaction{UserId: "user1", PostId: "post1", EmojiName: "eyes"},
+			Existing:        []Property{{ID: "property1", ObjectID: "post1", PropertyFieldID: "reviewers", Value: []interface{}{"user1"}}},
+			ExpectedDeleted: []string{"property1"},
+		},
+		{
+			Name:     "reactor without permission",
+			Reaction: &model.Reaction{UserId: "guest", PostId: "post1", EmojiName: "white_check_mark"},
+			Existing: []Property{{ID: "property1", ObjectID: "post1", PropertyFieldID: "status", Value: []interface{}{"Done"}}},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			propertyService := &fakePropertyService{properties: map[string][]Property{}}
+			if c.Existing != nil {
+				propertyService.properties["post1"] = c.Existing
+			}
+			rms := newTestReactionMappingService(propertyService, c.Reactions)
+
+			require.NoError(t, rms.ApplyReactionRemoved(c.Reaction))
+
+			assert.Equal(t, c.ExpectedDeleted, propertyService.deleted)
+			assert.Equal(t, c.ExpectedUpdates, propertyService.updates)
+		})
+	}
+}
+
+func TestReactionMappingValidate(t *testing.T) {
+	api := &plugintest.API{}
+	api.On("GetChannel", "channel1").Return(&model.Channel{Id: "channel1", TeamId: "team1"}, nil)
+	api.On("GetChannel", "missing").Return(nil, model.NewAppError("GetChannel", "app.channel.get.existing.app_error", nil, "", http.StatusNotFound))
+
+	fieldService := &fakePropertyFieldService{fields: []PropertyField{
+		{ID: "status", TeamID: "team1", Type: PropertyFieldTypeSelect},
+		{ID: "global", Type: PropertyFieldTypeSelect},
+		{ID: "reviewers", TeamID: "team1", Type: PropertyFieldTypeUser},
+		{ID: "other", TeamID: "team2", Type: PropertyFieldTypeSelect},
+	}}
+	rms := NewReactionMappingService(nil, nil, fieldService, nil, pluginapi.NewClient(api, nil)).(*reactionMappingService)
+
+	cases := []struct {
+		Name     string
+		Mapping  ReactionMapping
+		Expected string
+	}{
+		{
+			Name:    "field of the team",
+			Mapping: ReactionMapping{ChannelID: "channel1", EmojiName: "white_check_mark", PropertyFieldID: "status", Value: []interface{}{"Done"}},
+		},
+		{
+			Name:    "field of all teams",
+			Mapping: ReactionMapping{ChannelID: "channel1", EmojiName: "white_check_mark", PropertyFieldID: "global", Value: []interface{}{"Done"}},
+		},
+		{
+			Name:    "value from reactor",
+			Mapping: ReactionMapping{ChannelID: "channel1", EmojiName: "eyes", PropertyFieldID: "reviewers", ValueFromReactor: true},
+		},
+		{
+			Name:     "field of another team",
+			Mapping:  ReactionMapping{ChannelID: "channel1", EmojiName: "white_check_mark", PropertyFieldID: "other", Value: []interface{}{"Done"}},
+			Expected: "property_field 'other' belongs to another team",
+		},
+		{
+			Name:     "missing channel",
+			Mapping:  ReactionMapping{ChannelID: "missing", EmojiName: "white_check_mark", PropertyFieldID: "status", Value: []interface{}{"Done"}},
+			Expected: "Channel 'missing' of the reaction mapping does not exist",
+		},
+		{
+			Name:     "value from reactor of another field type",
+			Mapping:  ReactionMapping{ChannelID: "channel1", EmojiName: "eyes", PropertyFieldID: "status", ValueFromReactor: true},
+			Expected: "ValueFromReactor is only supported for fields of type 'user'",
+		},
+		{
+			Name:     "empty value",
+			Mapping:  ReactionMapping{ChannelID: "channel1", EmojiName: "white_check_mark", PropertyFieldID: "status"},
+			Expected: "Value should not be empty",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			err := rms.validate(c.Mapping)
+			if c.Expected == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, c.Expected)
+			}
+		})
+	}
+}
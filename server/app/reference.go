@@ -0,0 +1,70 @@
+package app
+
+import (
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/pluginapi"
+	"github.com/pkg/errors"
+)
+
+// isReferenceField returns whether values of the field type are ids of channels or teams.
+func isReferenceField(fieldType string) bool {
+	return fieldType == PropertyFieldTypeChannel || fieldType == PropertyFieldTypeTeam
+}
+
+// referenceExists checks that the id is an existing channel or team, depending on the field type.
+func referenceExists(api *pluginapi.Client, field PropertyField, id string) error {
+	if field.Type == PropertyFieldTypeTeam {
+		if _, err := api.Team.Get(id); err != nil {
+			return errors.Errorf("Team '%s' referenced by field '%s' does not exist", id, field.Name)
+		}
+		return nil
+	}
+
+	if _, err := api.Channel.Get(id); err != nil {
+		return errors.Errorf("Channel '%s' referenced by field '%s' does not exist", id, field.Name)
+	}
+	return nil
+}
+
+// referenceFieldType values are the ids of channels or teams, depending on the type.
+type referenceFieldType struct {
+	baseFieldType
+}
+
+// ValidateValue checks that the value references existing channels or teams.
+func (t referenceFieldType) ValidateValue(ctx FieldTypeContext, field PropertyField, value []interface{}) error {
+	for _, v := range value {
+		id, ok := v.(string)
+		if !ok || id == "" {
+			return errors.Errorf("Values of field '%s' should be %s ids", field.Name, t.name)
+		}
+		if err := referenceExists(ctx.API, field, id); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ReferencesRead checks that the user can read the channels or view the teams referenced.
+func (t referenceFieldType) ReferencesRead(ctx FieldTypeContext, userID string, field PropertyField, value []interface{}) error {
+	for _, v := range value {
+		id, ok := v.(string)
+		if !ok {
+			continue
+		}
+
+		if t.name == PropertyFieldTypeTeam {
+			if !ctx.API.User.HasPermissionToTeam(userID, id, model.PermissionViewTeam) {
+				return errors.Errorf("user `%s` does not have permission to view team `%s`", userID, id)
+			}
+			continue
+		}
+
+		if !ctx.API.User.HasPermissionToChannel(userID, id, model.PermissionReadChannel) {
+			return errors.Errorf("user `%s` does not have permission to read channel `%s`", userID, id)
+		}
+	}
+
+	return nil
+}
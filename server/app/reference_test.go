@@ -0,0 +1,80 @@
+package app
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/plugin/plugintest"
+	"github.com/mattermost/mattermost/server/public/pluginapi"
+)
+
+func TestValidateReference(t *testing.T) {
+	api := &plugintest.API{}
+	api.On("GetChannel", "channel1").Return(&model.Channel{Id: "channel1"}, nil)
+	api.On("GetChannel", "missing").Return(nil, model.NewAppError("GetChannel", "app.channel.get.existing.app_error", nil, "", http.StatusNotFound))
+	api.On("GetTeam", "team1").Return(&model.Team{Id: "team1"}, nil)
+	api.On("GetTeam", "missing").Return(nil, model.NewAppError("GetTeam", "app.team.get.find.app_error", nil, "", http.StatusNotFound))
+
+	ps := &propertyService{api: pluginapi.NewClient(api, nil)}
+
+	cases := []struct {
+		Name      string
+		FieldType string
+		Value     []interface{}
+		Expected  string
+	}{
+		{
+			Name:      "other field type",
+			FieldType: PropertyFieldTypeText,
+			Value:     []interface{}{"missing"},
+		},
+		{
+			Name:      "existing channel",
+			FieldType: PropertyFieldTypeChannel,
+			Value:     []interface{}{"channel1"},
+		},
+		{
+			Name:      "missing channel",
+			FieldType: PropertyFieldTypeChannel,
+			Value:     []interface{}{"channel1", "missing"},
+			Expected:  "Channel 'missing' referenced by field 'Reference' does not exist",
+		},
+		{
+			Name:      "existing team",
+			FieldType: PropertyFieldTypeTeam,
+			Value:     []interface{}{"team1"},
+		},
+		{
+			Name:      "missing team",
+			FieldType: PropertyFieldTypeTeam,
+			Value:     []interface{}{"missing"},
+			Expected:  "Team 'missing' referenced by field 'Reference' does not exist",
+		},
+		{
+			Name:      "not an id",
+			FieldType: PropertyFieldTypeChannel,
+			Value:     []interface{}{float64(1)},
+			Expected:  "Values of field 'Reference' should be channel ids",
+		},
+		{
+			Name:      "empty id",
+			FieldType: PropertyFieldTypeTeam,
+			Value:     []interface{}{""},
+			Expected:  "Values of field 'Reference' should be team ids",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			err := ps.validateType(Property{PropertyFieldName: "Reference", PropertyFieldType: c.FieldType, Value: c.Value})
+			if c.Expected == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, c.Expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,236 @@
+package app
+
+import (
+	"fmt"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// maxRelationValues bounds the objects a relation property links to.
+const maxRelationValues = 100
+
+// relationObjectType returns the type of the objects a relation field links to, posts by default.
+func relationObjectType(field PropertyField) string {
+	if field.Relation == nil || field.Relation.ObjectType == "" {
+		return PropertyObjectTypePost
+	}
+	return field.Relation.ObjectType
+}
+
+// relationDiff returns the ids added to and removed from the value of a relation property.
+func relationDiff(previous, value []interface{}) ([]string, []string) {
+	previousIDs := map[string]bool{}
+	for _, id := range previous {
+		previousIDs[fmt.Sprint(id)] = true
+	}
+
+	ids := map[string]bool{}
+	added := []string{}
+	for _, id := range value {
+		s := fmt.Sprint(id)
+		ids[s] = true
+		if !previousIDs[s] {
+			added = append(added, s)
+		}
+	}
+
+	removed := []string{}
+	for _, id := range previous {
+		if s := fmt.Sprint(id); !ids[s] {
+			removed = append(removed, s)
+		}
+	}
+
+	return added, removed
+}
+
+// relationFieldType values are the ids of the posts or channels linked to.
+type relationFieldType struct {
+	baseFieldType
+}
+
+// ValidateField checks the relation settings. The back-link field should be a relation field
+// not linking back to another field.
+func (t relationFieldType) ValidateField(ctx FieldTypeContext, field PropertyField) error {
+	relation := field.Relation
+	field.Relation = nil
+	if err := t.baseFieldType.ValidateField(ctx, field); err != nil {
+		return err
+	}
+	if relation == nil {
+		return nil
+	}
+
+	if relation.ObjectType != "" && relation.ObjectType != PropertyObjectTypePost && relation.ObjectType != PropertyObjectTypeChannel {
+		return errors.Errorf("Relation ObjectType should be '%s' or '%s'", PropertyObjectTypePost, PropertyObjectTypeChannel)
+	}
+
+	if relation.BackLinkFieldID == "" || relation.BackLinkFieldID == field.ID {
+		return nil
+	}
+
+	backLinkField, err := ctx.Fields.Get(relation.BackLinkFieldID)
+	if errors.Is(err, ErrNotFound) {
+		return errors.Errorf("Back-link field '%s' does not exist", relation.BackLinkFieldID)
+	} else if err != nil {
+		return errors.Wrap(err, "could not get back-link field")
+	}
+
+	if backLinkField.Type != PropertyFieldTypeRelation {
+		return errors.Errorf("Back-link field '%s' should be a relation field", backLinkField.Name)
+	}
+	if backLinkField.Relation != nil && backLinkField.Relation.BackLinkFieldID != "" && backLinkField.Relation.BackLinkFieldID != field.ID {
+		return errors.Errorf("Back-link field '%s' already links back to another field", backLinkField.Name)
+	}
+
+	return nil
+}
+
+// ValidateValue checks that the value links to existing objects of the type of the field, other
+// than the object itself.
+func (t relationFieldType) ValidateValue(ctx FieldTypeContext, field PropertyField, value []interface{}) error {
+	field, err := ctx.Fields.Get(field.ID)
+	if err != nil {
+		return errors.Wrap(err, "could not get relation field")
+	}
+
+	if len(value) > maxRelationValues {
+		return errors.Errorf("Relation field '%s' should not link to more than %d objects", field.Name, maxRelationValues)
+	}
+
+	objectType := relationObjectType(field)
+	for _, v := range value {
+		id, ok := v.(string)
+		if !ok || id == "" {
+			return errors.Errorf("Values of relation field '%s' should be object ids", field.Name)
+		}
+		if id == ctx.ObjectID {
+			return errors.Errorf("Relation field '%s' should not link an object to itself", field.Name)
+		}
+
+		switch objectType {
+		case PropertyObjectTypeChannel:
+			if _, err = ctx.API.Channel.Get(id); err != nil {
+				return errors.Errorf("Channel '%s' linked by field '%s' does not exist", id, field.Name)
+			}
+		default:
+			if _, err = ctx.API.Post.GetPost(id); err != nil {
+				return errors.Errorf("Post '%s' linked by field '%s' does not exist", id, field.Name)
+			}
+		}
+	}
+
+	return nil
+}
+
+// ReferencesRead checks that the user can read the channels linked to, or the channels of the
+// posts linked to.
+func (t relationFieldType) ReferencesRead(ctx FieldTypeContext, userID string, field PropertyField, value []interface{}) error {
+	for _, v := range value {
+		id, ok := v.(string)
+		if !ok {
+			continue
+		}
+
+		channelID, permission := id, model.PermissionReadChannel
+		if relationObjectType(field) == PropertyObjectTypePost {
+			post, err := ctx.API.Post.GetPost(id)
+			if err != nil {
+				return errors.Wrap(err, "invalid post")
+			}
+			channelID, permission = post.ChannelId, model.PermissionReadChannelContent
+		}
+
+		if !ctx.API.User.HasPermissionToChannel(userID, channelID, permission) {
+			return errors.Errorf("user `%s` does not have permission to read channel `%s`", userID, channelID)
+		}
+	}
+
+	return nil
+}
+
+// updateBackLinks keeps the back-link field of the objects linked to by a relation property up to
+// date, adding the object to the ones it now links to and removing it from the others.
+func (ps *propertyService) updateBackLinks(change PropertyChange) {
+	property := change.Property
+	if property.PropertyFieldType != PropertyFieldTypeRelation {
+		return
+	}
+
+	field, err := ps.propertyFieldService.Get(property.PropertyFieldID)
+	if err != nil {
+		logrus.WithError(err).WithField("property_field_id", property.PropertyFieldID).Warn("Failed to get relation field")
+		return
+	}
+	if field.Relation == nil || field.Relation.BackLinkFieldID == "" {
+		return
+	}
+
+	added, removed := relationDiff(change.PreviousValue, property.Value)
+	for _, id := range added {
+		if err = ps.setBackLink(field, id, property.ObjectID, true); err != nil {
+			logrus.WithError(err).WithField("object_id", id).Warn("Failed to add back-link")
+		}
+	}
+	for _, id := range removed {
+		if err = ps.setBackLink(field, id, property.ObjectID, false); err != nil {
+			logrus.WithError(err).WithField("object_id", id).Warn("Failed to remove back-link")
+		}
+	}
+}
+
+// setBackLink adds or removes an object from the back-link field of an object it links to. The
+// write goes through the service so the back-link field links back in turn, which is a no-op
+// once both sides agree.
+func (ps *propertyService) setBackLink(field PropertyField, targetID, objectID string, linked bool) error {
+	properties, err := ps.store.GetByObjectID(targetID)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return errors.Wrap(err, "could not get properties of linked object")
+	}
+
+	existing := FindProperty(properties, field.Relation.BackLinkFieldID)
+
+	if existing == nil {
+		if !linked {
+			return nil
+		}
+
+		objectType := relationObjectType(field)
+		channelID, teamID, locationErr := objectLocation(ps.api, targetID, objectType, properties)
+		if locationErr != nil {
+			return locationErr
+		}
+
+		_, err = ps.Create(Property{
+			ObjectID:        targetID,
+			ObjectType:      objectType,
+			PropertyFieldID: field.Relation.BackLinkFieldID,
+			ChannelID:       channelID,
+			TeamID:          teamID,
+			Value:           []interface{}{objectID},
+		})
+		return err
+	}
+
+	value := []interface{}{}
+	has := false
+	for _, id := range existing.Value {
+		if fmt.Sprint(id) == objectID {
+			has = true
+			continue
+		}
+		value = append(value, id)
+	}
+
+	switch {
+	case has == linked:
+		return nil
+	case linked:
+		return ps.UpdateValue(existing.ID, append(value, objectID))
+	case len(value) == 0:
+		return ps.Delete(existing.ID)
+	}
+	return ps.UpdateValue(existing.ID, value)
+}
@@ -0,0 +1,82 @@
+package app
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/plugin/plugintest"
+	"github.com/mattermost/mattermost/server/public/pluginapi"
+)
+
+func TestRelationValidateValue(t *testing.T) {
+	api := &plugintest.API{}
+	api.On("GetPost", "post2").Return(&model.Post{Id: "post2"}, nil)
+	api.On("GetPost", "missing").Return(nil, model.NewAppError("GetPost", "app.post.get.app_error", nil, "", http.StatusNotFound))
+	api.On("GetChannel", "channel1").Return(&model.Channel{Id: "channel1"}, nil)
+
+	ctx := FieldTypeContext{
+		Fields: &fakePropertyFieldService{fields: []PropertyField{
+			{ID: "tasks", Name: "Tasks", Type: PropertyFieldTypeRelation},
+			{ID: "channels", Name: "Channels", Type: PropertyFieldTypeRelation, Relation: &RelationSettings{ObjectType: PropertyObjectTypeChannel}},
+		}},
+		API:      pluginapi.NewClient(api, nil),
+		ObjectID: "post1",
+	}
+	fieldType, _ := GetFieldType(PropertyFieldTypeRelation)
+
+	cases := []struct {
+		Name     string
+		FieldID  string
+		Value    []interface{}
+		Expected string
+	}{
+		{
+			Name:    "existing post",
+			FieldID: "tasks",
+			Value:   []interface{}{"post2"},
+		},
+		{
+			Name:    "existing channel",
+			FieldID: "channels",
+			Value:   []interface{}{"channel1"},
+		},
+		{
+			Name:     "missing post",
+			FieldID:  "tasks",
+			Value:    []interface{}{"post2", "missing"},
+			Expected: "Post 'missing' linked by field 'Tasks' does not exist",
+		},
+		{
+			Name:     "object itself",
+			FieldID:  "tasks",
+			Value:    []interface{}{"post1"},
+			Expected: "Relation field 'Tasks' should not link an object to itself",
+		},
+		{
+			Name:     "not an id",
+			FieldID:  "tasks",
+			Value:    []interface{}{float64(1)},
+			Expected: "Values of relation field 'Tasks' should be object ids",
+		},
+		{
+			Name:     "unknown field",
+			FieldID:  "unknown",
+			Value:    []interface{}{"post2"},
+			Expected: "could not get relation field: not found",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			err := fieldType.ValidateValue(ctx, PropertyField{ID: c.FieldID, Type: PropertyFieldTypeRelation}, c.Value)
+			if c.Expected == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, c.Expected)
+			}
+		})
+	}
+}
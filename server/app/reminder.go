@@ -0,0 +1,69 @@
+package app
+
+// Reminder notifies about objects whose date field becomes due, or whose select field has kept
+// one of Values for longer than the SLA. When ViewID is set, only objects of the view are
+// considered.
+type Reminder struct {
+	ID              string `json:"id"`
+	TeamID          string `json:"team_id"`
+	ViewID          string `json:"view_id"`
+	PropertyFieldID string `json:"property_field_id"`
+	Type            string `json:"type"`
+	// Duration is in milliseconds. For due reminders it is how long before the date the item
+	// becomes due, for SLA reminders how long the value may stay unchanged.
+	Duration int64 `json:"duration"`
+	// ChannelID receives a post for every notification.
+	ChannelID string `json:"channel_id"`
+	// UserFieldID is a user field whose users of the object receive a DM for every notification.
+	// When neither ChannelID nor UserFieldID is set, the notification is posted in the thread
+	// of the object.
+	UserFieldID string        `json:"user_field_id"`
+	CreateAt    int64         `json:"create_at"`
+	UpdateAt    int64         `json:"update_at"`
+	UpdateBy    string        `json:"update_by"`
+	Values      []interface{} `json:"values" db:"-"`
+}
+
+const (
+	// ReminderTypeDue watches a date field. Objects are notified as overdue for a day after
+	// their date.
+	ReminderTypeDue = "due"
+	// ReminderTypeSLA watches a select field.
+	ReminderTypeSLA = "sla"
+)
+
+const (
+	ReminderNotificationDue     = "due"
+	ReminderNotificationOverdue = "overdue"
+	ReminderNotificationSLA     = "sla"
+)
+
+type ReminderFilterOptions struct {
+	TeamID          string
+	PropertyFieldID string
+	Page            int
+	PerPage         int
+}
+
+type ReminderStore interface {
+	Get(id string) (Reminder, error)
+	Create(reminder Reminder) (string, error)
+	GetReminders(filter ReminderFilterOptions) ([]Reminder, error)
+	Update(reminder Reminder) error
+	Delete(id string) error
+
+	// MarkSent records the notification of the property, returning false if it had already
+	// been sent for the given version of the property.
+	MarkSent(reminderID, propertyID, notification string, propertyUpdateAt int64) (bool, error)
+}
+
+type ReminderService interface {
+	Get(id string) (Reminder, error)
+	Create(reminder Reminder) (string, error)
+	GetReminders(filter ReminderFilterOptions) ([]Reminder, error)
+	Update(reminder Reminder) error
+	Delete(id string) error
+
+	// Run sends the notifications of all reminders that became due since the last run.
+	Run()
+}
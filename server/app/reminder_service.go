@@ -0,0 +1,346 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/pluginapi"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// maxRemindersPerRun bounds the reminders handled by a single run of the job.
+const maxRemindersPerRun = 1000
+
+// reminderOverdueWindow is how long after their date objects are notified as overdue, bounding
+// the properties checked by every run.
+const reminderOverdueWindow = 24 * time.Hour
+
+type reminderService struct {
+	store                ReminderStore
+	propertyService      PropertyService
+	propertyFieldService PropertyFieldService
+	viewService          ViewService
+	api                  *pluginapi.Client
+	botID                string
+}
+
+func NewReminderService(store ReminderStore, propertyService PropertyService, propertyFieldService PropertyFieldService, viewService ViewService, api *pluginapi.Client, botID string) ReminderService {
+	return &reminderService{
+		store:                store,
+		propertyService:      propertyService,
+		propertyFieldService: propertyFieldService,
+		viewService:          viewService,
+		api:                  api,
+		botID:                botID,
+	}
+}
+
+func (rs *reminderService) Get(id string) (Reminder, error) {
+	return rs.store.Get(id)
+}
+
+func (rs *reminderService) Create(reminder Reminder) (string, error) {
+	if err := rs.validate(reminder); err != nil {
+		return "", err
+	}
+
+	return rs.store.Create(reminder)
+}
+
+func (rs *reminderService) GetReminders(filter ReminderFilterOptions) ([]Reminder, error) {
+	return rs.store.GetReminders(filter)
+}
+
+func (rs *reminderService) Update(reminder Reminder) error {
+	if err := rs.validate(reminder); err != nil {
+		return err
+	}
+
+	return rs.store.Update(reminder)
+}
+
+func (rs *reminderService) Delete(id string) error {
+	return rs.store.Delete(id)
+}
+
+func (rs *reminderService) validate(reminder Reminder) error {
+	if reminder.TeamID == "" {
+		return errors.New("TeamID should not be blank")
+	}
+
+	if reminder.PropertyFieldID == "" {
+		return errors.New("PropertyFieldID should not be blank")
+	}
+
+	field, err := rs.propertyFieldService.Get(reminder.PropertyFieldID)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return errors.Errorf("Tried to create reminder with unknown property_field with id: '%s'", reminder.PropertyFieldID)
+		}
+		return err
+	}
+
+	if reminder.Duration < 0 {
+		return errors.New("Duration should not be negative")
+	}
+
+	switch reminder.Type {
+	case ReminderTypeDue:
+		if field.Type != PropertyFieldTypeDate {
+			return errors.Errorf("Reminders of type '%s' need a field of type '%s'", ReminderTypeDue, PropertyFieldTypeDate)
+		}
+	case ReminderTypeSLA:
+		if field.Type != PropertyFieldTypeSelect {
+			return errors.Errorf("Reminders of type '%s' need a field of type '%s'", ReminderTypeSLA, PropertyFieldTypeSelect)
+		}
+		if len(reminder.Values) == 0 {
+			return errors.New("Values should not be empty")
+		}
+		if reminder.Duration == 0 {
+			return errors.New("Duration should be set")
+		}
+	default:
+		return errors.Errorf("Unknown reminder type '%s'", reminder.Type)
+	}
+
+	if reminder.ChannelID != "" {
+		channel, channelErr := rs.api.Channel.Get(reminder.ChannelID)
+		if channelErr != nil {
+			return errors.Errorf("Channel '%s' does not exist", reminder.ChannelID)
+		}
+		if channel.TeamId != reminder.TeamID || !rs.api.User.HasPermissionToChannel(reminder.UpdateBy, channel.Id, model.PermissionCreatePost) {
+			return errors.Errorf("Channel '%s' should be a channel of the team you can post in", reminder.ChannelID)
+		}
+	}
+
+	if reminder.ViewID != "" {
+		view, viewErr := rs.viewService.Get(reminder.ViewID)
+		if viewErr != nil {
+			return errors.Wrapf(viewErr, "failed to get view '%s'", reminder.ViewID)
+		}
+		if (view.Query.TeamID != "" && view.Query.TeamID != reminder.TeamID) || viewRead(rs.api, reminder.UpdateBy, view) != nil {
+			return errors.Errorf("View '%s' should be a view of the team you can read", reminder.ViewID)
+		}
+	}
+
+	if reminder.UserFieldID != "" {
+		userField, fieldErr := rs.propertyFieldService.Get(reminder.UserFieldID)
+		if fieldErr != nil {
+			return errors.Wrapf(fieldErr, "failed to get user field '%s'", reminder.UserFieldID)
+		}
+		if userField.Type != PropertyFieldTypeUser {
+			return errors.Errorf("UserFieldID should be a field of type '%s'", PropertyFieldTypeUser)
+		}
+	}
+
+	return nil
+}
+
+func (rs *reminderService) Run() {
+	reminders, err := rs.store.GetReminders(ReminderFilterOptions{PerPage: maxRemindersPerRun})
+	if err != nil {
+		logrus.WithError(err).Warn("Failed to get reminders")
+		return
+	}
+
+	now := time.Now()
+	for _, reminder := range reminders {
+		if err = rs.runReminder(reminder, now); err != nil {
+			logrus.WithError(err).WithField("reminder_id", reminder.ID).Warn("Failed to run reminder")
+		}
+	}
+}
+
+func (rs *reminderService) runReminder(reminder Reminder, now time.Time) error {
+	properties, err := rs.propertyService.GetProperties(reminderFilter(reminder, now))
+	if err != nil {
+		return errors.Wrapf(err, "failed to get properties of field '%s'", reminder.PropertyFieldID)
+	}
+
+	pending := map[string]Property{}
+	notifications := map[string]string{}
+	objectIDs := []string{}
+	for _, property := range properties {
+		notification := reminderNotification(reminder, property, now)
+		if notification == "" {
+			continue
+		}
+
+		pending[property.ObjectID] = property
+		notifications[property.ObjectID] = notification
+		objectIDs = append(objectIDs, property.ObjectID)
+	}
+
+	if reminder.ViewID != "" && len(objectIDs) > 0 {
+		objectIDs, err = rs.viewService.FilterObjectsInView(reminder.ViewID, objectIDs)
+		if err != nil {
+			return errors.Wrapf(err, "failed to filter objects of view '%s'", reminder.ViewID)
+		}
+	}
+
+	for _, objectID := range objectIDs {
+		property := pending[objectID]
+		notification := notifications[objectID]
+
+		sent, markErr := rs.store.MarkSent(reminder.ID, property.ID, notification, property.UpdateAt)
+		if markErr != nil {
+			return markErr
+		}
+		if !sent {
+			continue
+		}
+
+		if notifyErr := rs.notify(reminder, property, notification); notifyErr != nil {
+			logrus.WithError(notifyErr).WithFields(logrus.Fields{
+				"reminder_id": reminder.ID,
+				"property_id": property.ID,
+			}).Warn("Failed to send reminder")
+		}
+	}
+
+	return nil
+}
+
+// reminderFilter selects the properties which may have a notification due, which
+// reminderNotification then checks exactly.
+func reminderFilter(reminder Reminder, now time.Time) PropertyFilterOptions {
+	filter := PropertyFilterOptions{PropertyFieldID: reminder.PropertyFieldID, TeamID: reminder.TeamID}
+
+	switch reminder.Type {
+	case ReminderTypeDue:
+		// A day more on both sides covers the dates in other time zones than UTC
+		lead := time.Duration(reminder.Duration) * time.Millisecond
+		filter.DateFrom = now.UTC().Add(-reminderOverdueWindow - 24*time.Hour).Format(dateLayout)
+		filter.DateTo = now.UTC().Add(lead + 24*time.Hour).Format(dateLayout)
+	case ReminderTypeSLA:
+		filter.UpdatedBefore = now.Add(-time.Duration(reminder.Duration)*time.Millisecond).UnixMilli() + 1
+	}
+
+	return filter
+}
+
+// reminderNotification returns which notification is due for the property, if any.
+func reminderNotification(reminder Reminder, property Property, now time.Time) string {
+	if len(property.Value) == 0 {
+		return ""
+	}
+
+	switch reminder.Type {
+	case ReminderTypeDue:
+		due, ok := ParseDateValue(property.Value[0])
+		if !ok {
+			return ""
+		}
+		if !now.Before(due) {
+			if now.Sub(due) > reminderOverdueWindow {
+				return ""
+			}
+			return ReminderNotificationOverdue
+		}
+		if !now.Before(due.Add(-time.Duration(reminder.Duration) * time.Millisecond)) {
+			return ReminderNotificationDue
+		}
+	case ReminderTypeSLA:
+		// Properties written before UpdateAt was tracked have no known age
+		if property.UpdateAt == 0 || !containsValue(reminder.Values, property.Value[0]) {
+			return ""
+		}
+		if now.Sub(time.UnixMilli(property.UpdateAt)) >= time.Duration(reminder.Duration)*time.Millisecond {
+			return ReminderNotificationSLA
+		}
+	}
+
+	return ""
+}
+
+func (rs *reminderService) notify(reminder Reminder, property Property, notification string) error {
+	channelID, rootID, err := objectThread(rs.api, property)
+	if err != nil {
+		return err
+	}
+
+	link := ""
+	if rootID != "" {
+		link = permalink(rs.api, channelID, rootID)
+	}
+
+	var message string
+	switch notification {
+	case ReminderNotificationDue:
+		message = fmt.Sprintf("**%s** is due on %s: %s", property.PropertyFieldName, property.Value[0], link)
+	case ReminderNotificationOverdue:
+		message = fmt.Sprintf("**%s** is overdue since %s: %s", property.PropertyFieldName, property.Value[0], link)
+	case ReminderNotificationSLA:
+		since := time.Since(time.UnixMilli(property.UpdateAt)).Round(time.Minute)
+		message = fmt.Sprintf("**%s** has been **%s** for %s, longer than its SLA: %s", property.PropertyFieldName, property.Value[0], since, link)
+	}
+	message = strings.TrimSuffix(message, ": ")
+
+	channel, err := rs.api.Channel.Get(channelID)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get channel '%s'", channelID)
+	}
+
+	// Objects of private channels are only posted about in their own channel, not to its
+	// members' reminder channels
+	notified := false
+	if reminder.ChannelID != "" && (channel.Type == model.ChannelTypeOpen || reminder.ChannelID == channelID) {
+		if err = rs.api.Post.CreatePost(&model.Post{
+			UserId:    rs.botID,
+			ChannelId: reminder.ChannelID,
+			Message:   message,
+		}); err != nil {
+			return errors.Wrapf(err, "failed to post reminder in channel '%s'", reminder.ChannelID)
+		}
+		notified = true
+	}
+
+	if reminder.UserFieldID != "" {
+		userIDs, usersErr := rs.objectUsers(property.ObjectID, reminder.UserFieldID)
+		if usersErr != nil {
+			return usersErr
+		}
+		for _, userID := range userIDs {
+			if !rs.api.User.HasPermissionToChannel(userID, channelID, model.PermissionReadChannelContent) {
+				continue
+			}
+			if err = rs.api.Post.DM(rs.botID, userID, &model.Post{Message: message}); err != nil {
+				return errors.Wrapf(err, "failed to send reminder to user '%s'", userID)
+			}
+			notified = true
+		}
+	}
+
+	if !notified && rootID != "" {
+		return rs.api.Post.CreatePost(&model.Post{
+			UserId:    rs.botID,
+			ChannelId: channelID,
+			RootId:    rootID,
+			Message:   message,
+		})
+	}
+
+	return nil
+}
+
+// objectUsers returns the users set in the user field of the object.
+func (rs *reminderService) objectUsers(objectID, userFieldID string) ([]string, error) {
+	properties, err := rs.propertyService.GetForObject(objectID)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return nil, errors.Wrapf(err, "failed to get properties for object '%s'", objectID)
+	}
+
+	userIDs := []string{}
+	if property := FindProperty(properties, userFieldID); property != nil {
+		for _, v := range property.Value {
+			if userID, ok := v.(string); ok && model.IsValidId(userID) {
+				userIDs = append(userIDs, userID)
+			}
+		}
+	}
+
+	return userIDs, nil
+}
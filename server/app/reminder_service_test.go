@@ -0,0 +1,287 @@
+package app
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/plugin/plugintest"
+	"github.com/mattermost/mattermost/server/public/pluginapi"
+)
+
+func TestReminderNotification(t *testing.T) {
+	now := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+	day := (24 * time.Hour).Milliseconds()
+
+	cases := []struct {
+		Name     string
+		Reminder Reminder
+		Property Property
+		Expected string
+	}{
+		{
+			Name:     "due date far ahead",
+			Reminder: Reminder{Type: ReminderTypeDue, Duration: day},
+			Property: Property{Value: []interface{}{"2024-03-20"}},
+			Expected: "",
+		},
+		{
+			Name:     "due date within the lead time",
+			Reminder: Reminder{Type: ReminderTypeDue, Duration: day},
+			Property: Property{Value: []interface{}{"2024-03-11"}},
+			Expected: ReminderNotificationDue,
+		},
+		{
+			Name:     "due date passed",
+			Reminder: Reminder{Type: ReminderTypeDue},
+			Property: Property{Value: []interface{}{"2024-03-10T08:00:00Z"}},
+			Expected: ReminderNotificationOverdue,
+		},
+		{
+			Name:     "due date passed long ago",
+			Reminder: Reminder{Type: ReminderTypeDue},
+			Property: Property{Value: []interface{}{"2024-03-01"}},
+			Expected: "",
+		},
+		{
+			Name:     "invalid date",
+			Reminder: Reminder{Type: ReminderTypeDue},
+			Property: Property{Value: []interface{}{"soon"}},
+			Expected: "",
+		},
+		{
+			Name:     "sla breached",
+			Reminder: Reminder{Type: ReminderTypeSLA, Values: []interface{}{"Investigating"}, Duration: day},
+			Property: Property{Value: []interface{}{"Investigating"}, UpdateAt: now.Add(-48 * time.Hour).UnixMilli()},
+			Expected: ReminderNotificationSLA,
+		},
+		{
+			Name:     "sla not breached yet",
+			Reminder: Reminder{Type: ReminderTypeSLA, Values: []interface{}{"Investigating"}, Duration: day},
+			Property: Property{Value: []interface{}{"Investigating"}, UpdateAt: now.Add(-time.Hour).UnixMilli()},
+			Expected: "",
+		},
+		{
+			Name:     "sla on another value",
+			Reminder: Reminder{Type: ReminderTypeSLA, Values: []interface{}{"Investigating"}, Duration: day},
+			Property: Property{Value: []interface{}{"Resolved"}, UpdateAt: now.Add(-48 * time.Hour).UnixMilli()},
+			Expected: "",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			assert.Equal(t, c.Expected, reminderNotification(c.Reminder, c.Property, now))
+		})
+	}
+}
+
+func TestReminderFilter(t *testing.T) {
+	now := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+	day := (24 * time.Hour).Milliseconds()
+
+	cases := []struct {
+		Name     string
+		Reminder Reminder
+		Expected PropertyFilterOptions
+	}{
+		{
+			Name:     "due",
+			Reminder: Reminder{Type: ReminderTypeDue, PropertyFieldID: "due", TeamID: "team1", Duration: 3 * day},
+			Expected: PropertyFilterOptions{PropertyFieldID: "due", TeamID: "team1", DateFrom: "2024-03-08", DateTo: "2024-03-14"},
+		},
+		{
+			Name:     "sla",
+			Reminder: Reminder{Type: ReminderTypeSLA, PropertyFieldID: "status", TeamID: "team1", Duration: day},
+			Expected: PropertyFilterOptions{PropertyFieldID: "status", TeamID: "team1", UpdatedBefore: now.Add(-24*time.Hour).UnixMilli() + 1},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			assert.Equal(t, c.Expected, reminderFilter(c.Reminder, now))
+		})
+	}
+}
+
+// fakeViewService returns the views it's given, other methods panic.
+type fakeViewService struct {
+	ViewService
+	views []View
+}
+
+func (s *fakeViewService) Get(id string) (View, error) {
+	for _, view := range s.views {
+		if view.ID == id {
+			return view, nil
+		}
+	}
+	return View{}, ErrNotFound
+}
+
+func TestReminderValidate(t *testing.T) {
+	api := &plugintest.API{}
+	api.On("HasPermissionTo", "author", model.PermissionManageSystem).Return(false)
+	api.On("GetChannel", "channel1").Return(&model.Channel{Id: "channel1", TeamId: "team1"}, nil)
+	api.On("GetChannel", "other-team").Return(&model.Channel{Id: "other-team", TeamId: "team2"}, nil)
+	api.On("GetChannel", "read-only").Return(&model.Channel{Id: "read-only", TeamId: "team1"}, nil)
+	api.On("GetChannel", mock.Anything).Return(nil, model.NewAppError("GetChannel", "app.channel.get.existing.app_error", nil, "", http.StatusNotFound))
+	api.On("HasPermissionToChannel", "author", "read-only", model.PermissionCreatePost).Return(false)
+	api.On("HasPermissionToChannel", "author", mock.Anything, model.PermissionCreatePost).Return(true)
+	api.On("HasPermissionToChannel", "author", "private", model.PermissionReadChannel).Return(false)
+	api.On("HasPermissionToTeam", "author", "team1", model.PermissionViewTeam).Return(true)
+
+	rs := &reminderService{
+		api:                  pluginapi.NewClient(api, nil),
+		propertyFieldService: &fakePropertyFieldService{fields: []PropertyField{{ID: "due", Type: PropertyFieldTypeDate}}},
+		viewService: &fakeViewService{views: []View{
+			{ID: "team-view", Query: Query{TeamID: "team1"}},
+			{ID: "private-view", Query: Query{TeamID: "team1", ChannelID: "private"}},
+			{ID: "other-team-view", Query: Query{TeamID: "team2"}},
+		}},
+	}
+
+	cases := []struct {
+		Name          string
+		ChannelID     string
+		ViewID        string
+		ExpectedError string
+	}{
+		{
+			Name:      "channel and view of the team",
+			ChannelID: "channel1",
+			ViewID:    "team-view",
+		},
+		{
+			Name:          "missing channel",
+			ChannelID:     "missing",
+			ExpectedError: "Channel 'missing' does not exist",
+		},
+		{
+			Name:          "channel of another team",
+			ChannelID:     "other-team",
+			ExpectedError: "Channel 'other-team' should be a channel of the team you can post in",
+		},
+		{
+			Name:          "channel the author can't post in",
+			ChannelID:     "read-only",
+			ExpectedError: "Channel 'read-only' should be a channel of the team you can post in",
+		},
+		{
+			Name:          "view of a channel the author can't read",
+			ViewID:        "private-view",
+			ExpectedError: "View 'private-view' should be a view of the team you can read",
+		},
+		{
+			Name:          "view of another team",
+			ViewID:        "other-team-view",
+			ExpectedError: "View 'other-team-view' should be a view of the team you can read",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			err := rs.validate(Reminder{
+				TeamID:          "team1",
+				PropertyFieldID: "due",
+				Type:            ReminderTypeDue,
+				ChannelID:       c.ChannelID,
+				ViewID:          c.ViewID,
+				UpdateBy:        "author",
+			})
+			if c.ExpectedError != "" {
+				assert.EqualError(t, err, c.ExpectedError)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestReminderNotify(t *testing.T) {
+	member, outsider := model.NewId(), model.NewId()
+
+	api := &plugintest.API{}
+	api.On("GetChannel", "public").Return(&model.Channel{Id: "public", Type: model.ChannelTypeOpen}, nil)
+	api.On("GetChannel", "private").Return(&model.Channel{Id: "private", Type: model.ChannelTypePrivate}, nil)
+	api.On("HasPermissionToChannel", member, mock.Anything, model.PermissionReadChannelContent).Return(true)
+	api.On("HasPermissionToChannel", outsider, "public", model.PermissionReadChannelContent).Return(true)
+	api.On("HasPermissionToChannel", outsider, "private", model.PermissionReadChannelContent).Return(false)
+	api.On("GetDirectChannel", "bot", mock.Anything).Return(func(_, userID string) *model.Channel {
+		return &model.Channel{Id: "dm-" + userID}
+	}, nil)
+
+	var posted []string
+	api.On("CreatePost", mock.Anything).Return(func(post *model.Post) *model.Post {
+		posted = append(posted, post.ChannelId)
+		return post.Clone()
+	}, nil)
+
+	users := Property{PropertyFieldID: "owners", Value: []interface{}{member, outsider}}
+	rs := &reminderService{
+		api:   pluginapi.NewClient(api, nil),
+		botID: "bot",
+		propertyService: &fakePropertyService{properties: map[string][]Property{
+			"public":  {users},
+			"private": {users},
+		}},
+	}
+
+	cases := []struct {
+		Name     string
+		ObjectID string
+		Reminder Reminder
+		Expected []string
+	}{
+		{
+			Name:     "object of a public channel posted in the reminder channel",
+			ObjectID: "public",
+			Reminder: Reminder{ChannelID: "reminders"},
+			Expected: []string{"reminders"},
+		},
+		{
+			Name:     "object of a private channel not posted in another channel",
+			ObjectID: "private",
+			Reminder: Reminder{ChannelID: "reminders"},
+			Expected: nil,
+		},
+		{
+			Name:     "object of a private channel posted in its own channel",
+			ObjectID: "private",
+			Reminder: Reminder{ChannelID: "private"},
+			Expected: []string{"private"},
+		},
+		{
+			Name:     "users of an object of a public channel",
+			ObjectID: "public",
+			Reminder: Reminder{UserFieldID: "owners"},
+			Expected: []string{"dm-" + member, "dm-" + outsider},
+		},
+		{
+			Name:     "users who can't read the private channel of the object skipped",
+			ObjectID: "private",
+			Reminder: Reminder{UserFieldID: "owners"},
+			Expected: []string{"dm-" + member},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			posted = nil
+			property := Property{
+				ObjectID:          c.ObjectID,
+				ObjectType:        PropertyObjectTypeChannel,
+				PropertyFieldName: "Due",
+				Value:             []interface{}{"2024-03-10"},
+			}
+
+			err := rs.notify(c.Reminder, property, ReminderNotificationDue)
+			assert.NoError(t, err)
+			assert.Equal(t, c.Expected, posted)
+		})
+	}
+}
@@ -0,0 +1,165 @@
+package app
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/pkg/errors"
+)
+
+type RollupService interface {
+	// ApplyPostDeleted recomputes the rollups of the objects linking to a deleted post.
+	ApplyPostDeleted(post *model.Post) error
+	// Run recomputes all the rollups, catching up with changes that don't go through the
+	// properties or the hooks.
+	Run()
+	// Close waits for the rollups being computed for the objects having their relation.
+	Close()
+}
+
+// rollupFieldType values are computed numbers aggregating a field over the objects a relation
+// links to.
+type rollupFieldType struct {
+	numberFieldType
+}
+
+// ValidateField checks the rollup settings. Rollups can't aggregate computed fields, since the
+// rollups aren't recomputed when computed values change.
+func (t rollupFieldType) ValidateField(ctx FieldTypeContext, field PropertyField) error {
+	rollup := field.Rollup
+	field.Rollup = nil
+	if err := t.baseFieldType.ValidateField(ctx, field); err != nil {
+		return err
+	}
+	if rollup == nil {
+		return errors.New("Rollup settings should not be blank")
+	}
+
+	switch rollup.Function {
+	case RollupFunctionCount, RollupFunctionSum, RollupFunctionMin, RollupFunctionMax, RollupFunctionPercent:
+	default:
+		return errors.Errorf("Unknown rollup function '%s'", rollup.Function)
+	}
+
+	relationField, err := ctx.Fields.Get(rollup.RelationFieldID)
+	if errors.Is(err, ErrNotFound) {
+		return errors.Errorf("Relation field '%s' does not exist", rollup.RelationFieldID)
+	} else if err != nil {
+		return errors.Wrap(err, "could not get relation field")
+	}
+	if relationField.Type != PropertyFieldTypeRelation {
+		return errors.Errorf("Field '%s' should be a relation field", relationField.Name)
+	}
+
+	if rollup.FieldID == "" {
+		if rollup.Function != RollupFunctionCount {
+			return errors.Errorf("Rollup function '%s' needs a field", rollup.Function)
+		}
+		if rollup.Value != "" {
+			return errors.New("Rollup counting a value needs a field")
+		}
+		return nil
+	}
+
+	rolledUp, err := ctx.Fields.Get(rollup.FieldID)
+	if errors.Is(err, ErrNotFound) {
+		return errors.Errorf("Field '%s' does not exist", rollup.FieldID)
+	} else if err != nil {
+		return errors.Wrap(err, "could not get rolled up field")
+	}
+
+	switch {
+	case rolledUp.Type == PropertyFieldTypeFormula || rolledUp.Type == PropertyFieldTypeRollup:
+		return errors.Errorf("Field '%s' is computed, rollups cannot aggregate computed fields", rolledUp.Name)
+	case rollup.Function == RollupFunctionPercent && rollup.Value == "":
+		return errors.New("Rollup Value should not be blank")
+	case (rollup.Function == RollupFunctionSum || rollup.Function == RollupFunctionMin || rollup.Function == RollupFunctionMax) && rolledUp.Type != PropertyFieldTypeNumber:
+		return errors.Errorf("Field '%s' should be a number field", rolledUp.Name)
+	}
+
+	return nil
+}
+
+// rollupValue aggregates the values of the rolled up field of the related objects, given in the
+// same order as they are linked. It returns nil when there is nothing to aggregate.
+func rollupValue(settings RollupSettings, related [][]interface{}) []interface{} {
+	switch settings.Function {
+	case RollupFunctionCount:
+		count := 0
+		for _, value := range related {
+			if settings.Value == "" || hasValue(value, settings.Value) {
+				count++
+			}
+		}
+		return []interface{}{float64(count)}
+	case RollupFunctionPercent:
+		if len(related) == 0 {
+			return nil
+		}
+		done := 0
+		for _, value := range related {
+			if hasValue(value, settings.Value) {
+				done++
+			}
+		}
+		percent := math.Round(float64(done) * 100 / float64(len(related)))
+		return []interface{}{percent, float64(done), float64(len(related))}
+	}
+
+	var result float64
+	found := false
+	for _, value := range related {
+		if len(value) == 0 {
+			continue
+		}
+		number, ok := numberValue(value[0])
+		if !ok {
+			continue
+		}
+
+		switch {
+		case !found:
+			result = number
+		case settings.Function == RollupFunctionSum:
+			result += number
+		case settings.Function == RollupFunctionMin:
+			result = math.Min(result, number)
+		case settings.Function == RollupFunctionMax:
+			result = math.Max(result, number)
+		}
+		found = true
+	}
+
+	switch {
+	case found:
+		return []interface{}{result}
+	case settings.Function == RollupFunctionSum:
+		return []interface{}{float64(0)}
+	}
+	return nil
+}
+
+// hasValue returns whether one of the values of a property is the given value.
+func hasValue(value []interface{}, expected string) bool {
+	for _, v := range value {
+		if fmt.Sprint(v) == expected {
+			return true
+		}
+	}
+	return false
+}
+
+// numberValue returns the value of a number field as a float, they are stored as numbers or strings.
+func numberValue(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case string:
+		if number, err := strconv.ParseFloat(v, 64); err == nil {
+			return number, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,336 @@
+package app
+
+import (
+	"sync"
+	"time"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/pluginapi"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// rollupObjectsPerPage is how many objects are loaded at once when recomputing a rollup for all
+// the objects having its relation.
+const rollupObjectsPerPage = 200
+
+// rollupCacheTTL is how long the rollup fields are kept. Fields changed on this node clear them
+// right away, the TTL catching up with the changes made on other nodes and deletions.
+const rollupCacheTTL = time.Minute
+
+// rollupFields are the rollup fields with settings, with the type of the objects linked by the
+// relation fields they aggregate over.
+type rollupFields struct {
+	rollups []PropertyField
+	// linkedTypes are keyed by relation field id.
+	linkedTypes map[string]string
+	loadedAt    time.Time
+}
+
+// rollupService keeps the values of rollup fields up to date as the related objects change.
+// Computed values are written with SetComputedValue, like formulas. Deleted posts are left out of
+// the related objects.
+type rollupService struct {
+	propertyService      PropertyService
+	propertyFieldService PropertyFieldService
+	api                  *pluginapi.Client
+
+	fieldsLock sync.Mutex
+	fields     *rollupFields
+	// fieldsGeneration changes whenever the fields are cleared, so fields loaded before aren't
+	// cached.
+	fieldsGeneration int
+
+	recomputing sync.WaitGroup
+}
+
+func NewRollupService(propertyService PropertyService, propertyFieldService PropertyFieldService, api *pluginapi.Client) RollupService {
+	rs := &rollupService{
+		propertyService:      propertyService,
+		propertyFieldService: propertyFieldService,
+		api:                  api,
+	}
+
+	propertyService.RegisterValidator(rs.validateComputed)
+	propertyService.RegisterChangeListener(rs.onPropertyChange)
+	propertyFieldService.RegisterChangeListener(rs.onFieldChange)
+
+	return rs
+}
+
+// validateComputed rejects values set on rollup fields other than the computed ones.
+func (rs *rollupService) validateComputed(property Property) error {
+	if property.PropertyFieldType == PropertyFieldTypeRollup && !property.Computed {
+		return errors.Errorf("Values of rollup field '%s' are computed", property.PropertyFieldName)
+	}
+	return nil
+}
+
+// onPropertyChange recomputes the rollups of the object when one of its relations changed, and
+// the rollups of the objects linking to it when a field they roll up changed.
+func (rs *rollupService) onPropertyChange(change PropertyChange) {
+	property := change.Property
+	if property.PropertyFieldType == PropertyFieldTypeRollup || property.PropertyFieldType == PropertyFieldTypeFormula {
+		return
+	}
+
+	fields, err := rs.getRollups()
+	if err != nil {
+		logrus.WithError(err).Warn("Failed to get rollups")
+		return
+	}
+
+	object := PropertyObject{
+		ObjectID:   property.ObjectID,
+		ObjectType: property.ObjectType,
+		ChannelID:  property.ChannelID,
+		TeamID:     property.TeamID,
+	}
+	objects := map[string]PropertyObject{}
+	dependent := map[string][]PropertyField{}
+	rolledUp := []PropertyField{}
+	for _, rollup := range fields.rollups {
+		if rollup.Rollup.RelationFieldID == property.PropertyFieldID {
+			objects[object.ObjectID] = object
+			dependent[object.ObjectID] = append(dependent[object.ObjectID], rollup)
+		}
+		if rollup.Rollup.FieldID == property.PropertyFieldID {
+			rolledUp = append(rolledUp, rollup)
+		}
+	}
+
+	if len(rolledUp) > 0 {
+		rs.addReferencing(property.ObjectID, rolledUp, objects, dependent)
+	}
+
+	rs.recomputeObjects(objects, dependent)
+}
+
+// ApplyPostDeleted recomputes the rollups of the objects linking to a deleted post, which no
+// longer counts as related to them.
+func (rs *rollupService) ApplyPostDeleted(post *model.Post) error {
+	fields, err := rs.getRollups()
+	if err != nil {
+		return err
+	}
+	if len(fields.rollups) == 0 {
+		return nil
+	}
+
+	objects := map[string]PropertyObject{}
+	dependent := map[string][]PropertyField{}
+	rs.addReferencing(post.Id, fields.rollups, objects, dependent)
+	rs.recomputeObjects(objects, dependent)
+
+	return nil
+}
+
+// addReferencing adds the objects linking to an object through the relation of any of the
+// rollups, with the rollups to recompute for each of them.
+func (rs *rollupService) addReferencing(objectID string, rollups []PropertyField, objects map[string]PropertyObject, dependent map[string][]PropertyField) {
+	referencing, err := rs.propertyService.GetReferencing(objectID)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		logrus.WithError(err).WithField("object_id", objectID).Warn("Failed to get objects linking to object")
+	}
+
+	for _, ref := range referencing {
+		for _, rollup := range rollups {
+			if rollup.Rollup.RelationFieldID != ref.PropertyFieldID {
+				continue
+			}
+			objects[ref.ObjectID] = PropertyObject{
+				ObjectID:   ref.ObjectID,
+				ObjectType: ref.ObjectType,
+				ChannelID:  ref.ChannelID,
+				TeamID:     ref.TeamID,
+			}
+			dependent[ref.ObjectID] = append(dependent[ref.ObjectID], rollup)
+		}
+	}
+}
+
+func (rs *rollupService) recomputeObjects(objects map[string]PropertyObject, dependent map[string][]PropertyField) {
+	for objectID, fields := range dependent {
+		if err := rs.recompute(objects[objectID], fields); err != nil {
+			logrus.WithError(err).WithField("object_id", objectID).Warn("Failed to recompute rollups")
+		}
+	}
+}
+
+// onFieldChange computes a rollup for all the objects having its relation once saved. It runs in
+// the background since there may be many of them. Rollups and relations changing may change what
+// rollups aggregate over, so the fields are cleared.
+func (rs *rollupService) onFieldChange(field PropertyField) {
+	rs.fieldsLock.Lock()
+	rs.fields = nil
+	rs.fieldsGeneration++
+	rs.fieldsLock.Unlock()
+
+	if field.Type != PropertyFieldTypeRollup || field.Rollup == nil {
+		return
+	}
+
+	rs.recomputing.Add(1)
+	go func() {
+		defer rs.recomputing.Done()
+		rs.recomputeField(field)
+	}()
+}
+
+func (rs *rollupService) Close() {
+	rs.recomputing.Wait()
+}
+
+func (rs *rollupService) Run() {
+	fields, err := rs.getRollups()
+	if err != nil {
+		logrus.WithError(err).Warn("Failed to get rollups")
+		return
+	}
+
+	for _, rollup := range fields.rollups {
+		rs.recomputeField(rollup)
+	}
+}
+
+// getRollups returns the rollup fields with settings, of all teams since relations may link
+// objects of different teams. They are loaded again once rollupCacheTTL passed.
+func (rs *rollupService) getRollups() (rollupFields, error) {
+	rs.fieldsLock.Lock()
+	cached := rs.fields
+	generation := rs.fieldsGeneration
+	rs.fieldsLock.Unlock()
+	if cached != nil && time.Since(cached.loadedAt) < rollupCacheTTL {
+		return *cached, nil
+	}
+
+	loadedAt := time.Now()
+	all, err := getAllFields(rs.propertyFieldService, PropertyFieldFilterOptions{Types: []string{PropertyFieldTypeRollup, PropertyFieldTypeRelation}})
+	if err != nil {
+		return rollupFields{}, err
+	}
+
+	fields := rollupFields{rollups: []PropertyField{}, linkedTypes: map[string]string{}, loadedAt: loadedAt}
+	for _, field := range all {
+		switch {
+		case field.Type == PropertyFieldTypeRelation:
+			fields.linkedTypes[field.ID] = relationObjectType(field)
+		case field.Rollup != nil:
+			fields.rollups = append(fields.rollups, field)
+		}
+	}
+
+	rs.fieldsLock.Lock()
+	if generation == rs.fieldsGeneration {
+		rs.fields = &fields
+	}
+	rs.fieldsLock.Unlock()
+
+	return fields, nil
+}
+
+// recomputeField recomputes a rollup for all the objects having its relation.
+func (rs *rollupService) recomputeField(rollup PropertyField) {
+	filter := PropertyFilterOptions{PropertyFieldID: rollup.Rollup.RelationFieldID, PerPage: rollupObjectsPerPage}
+	for ; ; filter.Page++ {
+		relations, err := rs.propertyService.GetProperties(filter)
+		if err != nil && !errors.Is(err, ErrNotFound) {
+			logrus.WithError(err).WithField("property_field_id", rollup.ID).Warn("Failed to get relations of rollup")
+			return
+		}
+
+		for _, relation := range relations {
+			object := PropertyObject{
+				ObjectID:   relation.ObjectID,
+				ObjectType: relation.ObjectType,
+				ChannelID:  relation.ChannelID,
+				TeamID:     relation.TeamID,
+			}
+			if err = rs.recompute(object, []PropertyField{rollup}); err != nil {
+				logrus.WithError(err).WithField("object_id", relation.ObjectID).Warn("Failed to recompute rollup")
+			}
+		}
+
+		if len(relations) < rollupObjectsPerPage {
+			return
+		}
+	}
+}
+
+// recompute aggregates rollups over the objects an object links to, writing the values which changed.
+func (rs *rollupService) recompute(object PropertyObject, rollups []PropertyField) error {
+	properties, err := rs.propertyService.GetForObject(object.ObjectID)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return errors.Wrap(err, "could not get properties of object")
+	}
+
+	fields, err := rs.getRollups()
+	if err != nil {
+		return err
+	}
+
+	existing := map[string]Property{}
+	for _, property := range properties {
+		existing[property.PropertyFieldID] = property
+	}
+
+	relatedProperties := map[string][]Property{}
+	for _, rollup := range rollups {
+		related := [][]interface{}{}
+		for _, id := range existing[rollup.Rollup.RelationFieldID].Value {
+			relatedID, ok := id.(string)
+			if !ok {
+				continue
+			}
+
+			if _, ok = relatedProperties[relatedID]; !ok {
+				relatedProperties[relatedID], err = rs.getRelated(relatedID, fields.linkedTypes[rollup.Rollup.RelationFieldID])
+				if err != nil {
+					return err
+				}
+			}
+			if relatedProperties[relatedID] == nil {
+				continue
+			}
+
+			var value []interface{}
+			if property := FindProperty(relatedProperties[relatedID], rollup.Rollup.FieldID); property != nil {
+				value = property.Value
+			}
+			related = append(related, value)
+		}
+
+		if err = rs.propertyService.SetComputedValue(object, rollup.ID, rollupValue(*rollup.Rollup, related)); err != nil {
+			return errors.Wrapf(err, "could not set value of rollup field '%s'", rollup.ID)
+		}
+	}
+
+	return nil
+}
+
+// getRelated returns the properties of a related object of the given type, nil when it's a post
+// which was deleted, whether it has properties or not.
+func (rs *rollupService) getRelated(objectID, objectType string) ([]Property, error) {
+	properties, err := rs.propertyService.GetForObject(objectID)
+	if errors.Is(err, ErrNotFound) {
+		properties = []Property{}
+	} else if err != nil {
+		return nil, errors.Wrap(err, "could not get properties of related object")
+	}
+
+	if objectType != PropertyObjectTypePost {
+		return properties, nil
+	}
+
+	post, err := rs.api.Post.GetPost(objectID)
+	if errors.Is(err, pluginapi.ErrNotFound) {
+		return nil, nil
+	} else if err != nil {
+		return nil, errors.Wrapf(err, "could not get related post '%s'", objectID)
+	}
+	if post.DeleteAt != 0 {
+		return nil, nil
+	}
+
+	return properties, nil
+}
@@ -0,0 +1,165 @@
+package app
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/plugin/plugintest"
+	"github.com/mattermost/mattermost/server/public/pluginapi"
+)
+
+func TestRollupValue(t *testing.T) {
+	related := [][]interface{}{
+		{"Done"},
+		{"Open"},
+		nil,
+		{"Done"},
+	}
+	points := [][]interface{}{
+		{float64(3)},
+		{"5"},
+		nil,
+		{"big"},
+	}
+
+	cases := []struct {
+		Name     string
+		Settings RollupSettings
+		Related  [][]interface{}
+		Expected []interface{}
+	}{
+		{"count", RollupSettings{Function: RollupFunctionCount}, related, []interface{}{float64(4)}},
+		{"count value", RollupSettings{Function: RollupFunctionCount, Value: "Done"}, related, []interface{}{float64(2)}},
+		{"count nothing", RollupSettings{Function: RollupFunctionCount}, nil, []interface{}{float64(0)}},
+		{"percent", RollupSettings{Function: RollupFunctionPercent, Value: "Done"}, related, []interface{}{float64(50), float64(2), float64(4)}},
+		{"percent rounded", RollupSettings{Function: RollupFunctionPercent, Value: "Open"}, related[:3], []interface{}{float64(33), float64(1), float64(3)}},
+		{"percent nothing", RollupSettings{Function: RollupFunctionPercent, Value: "Done"}, nil, nil},
+		{"sum", RollupSettings{Function: RollupFunctionSum}, points, []interface{}{float64(8)}},
+		{"sum nothing", RollupSettings{Function: RollupFunctionSum}, nil, []interface{}{float64(0)}},
+		{"min", RollupSettings{Function: RollupFunctionMin}, points, []interface{}{float64(3)}},
+		{"max", RollupSettings{Function: RollupFunctionMax}, points, []interface{}{float64(5)}},
+		{"max nothing", RollupSettings{Function: RollupFunctionMax}, related, nil},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			assert.Equal(t, c.Expected, rollupValue(c.Settings, c.Related))
+		})
+	}
+}
+
+// newTestRollupService rolls up the tasks an epic links to, one of them being a deleted post,
+// another a post which no longer exists, another a deleted post without properties and the last
+// a post without properties.
+func newTestRollupService(t *testing.T) (*rollupService, *fakePropertyService) {
+	api := &plugintest.API{}
+	t.Cleanup(func() { api.AssertExpectations(t) })
+	api.On("GetPost", "task1").Return(&model.Post{Id: "task1"}, nil)
+	api.On("GetPost", "task2").Return(&model.Post{Id: "task2", DeleteAt: 1}, nil)
+	api.On("GetPost", "task3").Return(nil, model.NewAppError("GetPost", "app.post.get.app_error", nil, "", http.StatusNotFound))
+	api.On("GetPost", "task4").Return(&model.Post{Id: "task4", DeleteAt: 1}, nil)
+	api.On("GetPost", "task5").Return(&model.Post{Id: "task5"}, nil)
+
+	propertyService := &fakePropertyService{properties: map[string][]Property{
+		"epic":  {{ID: "epic-tasks", ObjectID: "epic", ObjectType: PropertyObjectTypePost, PropertyFieldID: "tasks", Value: []interface{}{"task1", "task2", "task3", "task4", "task5"}}},
+		"task1": {{ObjectID: "task1", ObjectType: PropertyObjectTypePost, PropertyFieldID: "points", Value: []interface{}{float64(3)}}},
+		"task2": {{ObjectID: "task2", ObjectType: PropertyObjectTypePost, PropertyFieldID: "points", Value: []interface{}{float64(5)}}},
+		"task3": {{ObjectID: "task3", ObjectType: PropertyObjectTypePost, PropertyFieldID: "points", Value: []interface{}{float64(8)}}},
+	}}
+	rs := &rollupService{
+		propertyService: propertyService,
+		propertyFieldService: &fakePropertyFieldService{fields: []PropertyField{
+			{ID: "tasks", Type: PropertyFieldTypeRelation},
+			{ID: "count", Type: PropertyFieldTypeRollup, Rollup: &RollupSettings{RelationFieldID: "tasks", Function: RollupFunctionCount}},
+			{ID: "sum", Type: PropertyFieldTypeRollup, Rollup: &RollupSettings{RelationFieldID: "tasks", FieldID: "points", Function: RollupFunctionSum}},
+		}},
+		api: pluginapi.NewClient(api, nil),
+	}
+
+	return rs, propertyService
+}
+
+func TestRollupSkipsDeletedPosts(t *testing.T) {
+	rs, propertyService := newTestRollupService(t)
+
+	fields, err := rs.getRollups()
+	require.NoError(t, err)
+	require.Len(t, fields.rollups, 2)
+
+	require.NoError(t, rs.recompute(PropertyObject{ObjectID: "epic", ObjectType: PropertyObjectTypePost}, fields.rollups))
+
+	values := map[string][]interface{}{}
+	for _, property := range propertyService.computed {
+		assert.Equal(t, "epic", property.ObjectID)
+		values[property.PropertyFieldID] = property.Value
+	}
+	assert.Equal(t, map[string][]interface{}{
+		"count": {float64(2)},
+		"sum":   {float64(3)},
+	}, values)
+}
+
+func TestRollupApplyPostDeleted(t *testing.T) {
+	rs, propertyService := newTestRollupService(t)
+
+	require.NoError(t, rs.ApplyPostDeleted(&model.Post{Id: "task2", DeleteAt: 1}))
+
+	values := map[string][]interface{}{}
+	for _, property := range propertyService.computed {
+		values[property.PropertyFieldID] = property.Value
+	}
+	assert.Equal(t, map[string][]interface{}{
+		"count": {float64(2)},
+		"sum":   {float64(3)},
+	}, values)
+}
+
+func TestRollupServiceCachesFields(t *testing.T) {
+	fieldService := &countingPropertyFieldService{fakePropertyFieldService: fakePropertyFieldService{fields: []PropertyField{
+		{ID: "tasks", Type: PropertyFieldTypeRelation, Relation: &RelationSettings{ObjectType: PropertyObjectTypeChannel}},
+		{ID: "count", Type: PropertyFieldTypeRollup, Rollup: &RollupSettings{RelationFieldID: "tasks", Function: RollupFunctionCount}},
+	}}}
+	rs := &rollupService{propertyFieldService: fieldService}
+
+	fields, err := rs.getRollups()
+	require.NoError(t, err)
+	require.Len(t, fields.rollups, 1)
+	assert.Equal(t, map[string]string{"tasks": PropertyObjectTypeChannel}, fields.linkedTypes)
+
+	_, err = rs.getRollups()
+	require.NoError(t, err)
+	assert.Equal(t, 1, fieldService.getFieldsCalls)
+
+	rs.onFieldChange(PropertyField{ID: "tasks", Type: PropertyFieldTypeRelation})
+	_, err = rs.getRollups()
+	require.NoError(t, err)
+	assert.Equal(t, 2, fieldService.getFieldsCalls)
+
+	rs.fields.loadedAt = time.Now().Add(-rollupCacheTTL)
+	_, err = rs.getRollups()
+	require.NoError(t, err)
+	assert.Equal(t, 3, fieldService.getFieldsCalls)
+}
+
+func TestRollupRecomputeField(t *testing.T) {
+	properties := map[string][]Property{}
+	for i := 0; i < rollupObjectsPerPage+1; i++ {
+		objectID := model.NewId()
+		properties[objectID] = []Property{{ID: model.NewId(), ObjectID: objectID, PropertyFieldID: "tasks", Value: []interface{}{}}}
+	}
+	propertyService := &fakePropertyService{properties: properties}
+	rollup := PropertyField{ID: "count", Type: PropertyFieldTypeRollup, Rollup: &RollupSettings{RelationFieldID: "tasks", Function: RollupFunctionCount}}
+	rs := &rollupService{
+		propertyService:      propertyService,
+		propertyFieldService: &fakePropertyFieldService{fields: []PropertyField{{ID: "tasks", Type: PropertyFieldTypeRelation}, rollup}},
+	}
+
+	rs.recomputeField(rollup)
+
+	assert.Len(t, propertyService.computed, rollupObjectsPerPage+1)
+}
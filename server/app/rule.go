@@ -0,0 +1,58 @@
+package app
+
+type Rule struct {
+	ID              string        `json:"id"`
+	TeamID          string        `json:"team_id"`
+	ChannelID       string        `json:"channel_id"`
+	PropertyFieldID string        `json:"property_field_id"`
+	CreateAt        int64         `json:"create_at"`
+	UpdateAt        int64         `json:"update_at"`
+	UpdateBy        string        `json:"update_by"`
+	Value           []interface{} `json:"value" db:"-"`
+	Actions         []RuleAction  `json:"actions" db:"-"`
+}
+
+// RuleAction is run when the rule it belongs to is triggered. Which of the fields are used
+// depends on the type of the action.
+type RuleAction struct {
+	Type            string        `json:"type"`
+	Message         string        `json:"message,omitempty"`
+	UserID          string        `json:"user_id,omitempty"`
+	ChannelID       string        `json:"channel_id,omitempty"`
+	PropertyFieldID string        `json:"property_field_id,omitempty"`
+	Value           []interface{} `json:"value,omitempty"`
+}
+
+const (
+	// RuleActionTypePostMessage posts Message in ChannelID, or in the thread of the object when ChannelID is blank.
+	RuleActionTypePostMessage = "post_message"
+	// RuleActionTypeMentionUser mentions UserID with Message in the thread of the object.
+	RuleActionTypeMentionUser = "mention_user"
+	// RuleActionTypeSetProperty sets the property of PropertyFieldID on the object to Value.
+	RuleActionTypeSetProperty = "set_property"
+	// RuleActionTypeMovePost moves the post to ChannelID.
+	RuleActionTypeMovePost = "move_post"
+)
+
+type RuleFilterOptions struct {
+	TeamID          string
+	PropertyFieldID string
+	Page            int
+	PerPage         int
+}
+
+type RuleStore interface {
+	Get(id string) (Rule, error)
+	Create(rule Rule) (string, error)
+	GetRules(filter RuleFilterOptions) ([]Rule, error)
+	Update(rule Rule) error
+	Delete(id string) error
+}
+
+type RuleService interface {
+	Get(id string) (Rule, error)
+	Create(rule Rule) (string, error)
+	GetRules(filter RuleFilterOptions) ([]Rule, error)
+	Update(rule Rule) error
+	Delete(id string) error
+}
@@ -0,0 +1,434 @@
+package app
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/pluginapi"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// maxRuleDepth limits how many rules can be triggered in a row for the same object, so rules
+// setting properties that trigger each other don't loop forever.
+const maxRuleDepth = 5
+
+const maxRulesPerField = 200
+
+type ruleService struct {
+	store                RuleStore
+	propertyService      PropertyService
+	propertyFieldService PropertyFieldService
+	api                  *pluginapi.Client
+	botID                string
+
+	// runningLock synchronizes access to running.
+	runningLock sync.Mutex
+	// running counts the nested rule runs per object id.
+	running map[string]int
+}
+
+func NewRuleService(store RuleStore, propertyService PropertyService, propertyFieldService PropertyFieldService, api *pluginapi.Client, botID string) RuleService {
+	rs := &ruleService{
+		store:                store,
+		propertyService:      propertyService,
+		propertyFieldService: propertyFieldService,
+		api:                  api,
+		botID:                botID,
+		running:              map[string]int{},
+	}
+
+	propertyService.RegisterChangeListener(rs.onPropertyChange)
+
+	return rs
+}
+
+func (rs *ruleService) Get(id string) (Rule, error) {
+	return rs.store.Get(id)
+}
+
+func (rs *ruleService) Create(rule Rule) (string, error) {
+	if err := rs.validate(rule); err != nil {
+		return "", err
+	}
+
+	return rs.store.Create(rule)
+}
+
+func (rs *ruleService) GetRules(filter RuleFilterOptions) ([]Rule, error) {
+	return rs.store.GetRules(filter)
+}
+
+func (rs *ruleService) Update(rule Rule) error {
+	if err := rs.validate(rule); err != nil {
+		return err
+	}
+
+	return rs.store.Update(rule)
+}
+
+func (rs *ruleService) Delete(id string) error {
+	return rs.store.Delete(id)
+}
+
+func (rs *ruleService) validate(rule Rule) error {
+	if rule.PropertyFieldID == "" {
+		return errors.New("PropertyFieldID should not be blank")
+	}
+
+	if _, err := rs.propertyFieldService.Get(rule.PropertyFieldID); err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return errors.Errorf("Tried to create rule with unknown property_field with id: '%s'", rule.PropertyFieldID)
+		}
+		return err
+	}
+
+	if len(rule.Actions) == 0 {
+		return errors.New("Actions should not be empty")
+	}
+
+	for _, action := range rule.Actions {
+		switch action.Type {
+		case RuleActionTypePostMessage:
+			if action.Message == "" {
+				return errors.Errorf("Message should not be blank for action type '%s'", action.Type)
+			}
+			if action.ChannelID != "" {
+				if err := rs.validateChannel(rule, action); err != nil {
+					return err
+				}
+			}
+		case RuleActionTypeMentionUser:
+			if action.UserID == "" {
+				return errors.Errorf("UserID should not be blank for action type '%s'", action.Type)
+			}
+		case RuleActionTypeSetProperty:
+			if action.PropertyFieldID == "" {
+				return errors.Errorf("PropertyFieldID should not be blank for action type '%s'", action.Type)
+			}
+			if err := rs.validateField(rule, action); err != nil {
+				return err
+			}
+		case RuleActionTypeMovePost:
+			if action.ChannelID == "" {
+				return errors.Errorf("ChannelID should not be blank for action type '%s'", action.Type)
+			}
+			if err := rs.validateChannel(rule, action); err != nil {
+				return err
+			}
+		default:
+			return errors.Errorf("Unknown action type '%s'", action.Type)
+		}
+	}
+
+	return nil
+}
+
+// validateChannel checks that the channel an action posts to exists, is in the team of the rule
+// and that the author of the rule can post there.
+func (rs *ruleService) validateChannel(rule Rule, action RuleAction) error {
+	channel, err := rs.api.Channel.Get(action.ChannelID)
+	if err != nil {
+		return errors.Errorf("Channel '%s' of action type '%s' does not exist", action.ChannelID, action.Type)
+	}
+
+	if rule.TeamID != "" && channel.TeamId != rule.TeamID {
+		return errors.Errorf("Channel '%s' of action type '%s' should be in the team of the rule", action.ChannelID, action.Type)
+	}
+
+	if !rs.api.User.HasPermissionToChannel(rule.UpdateBy, channel.Id, model.PermissionCreatePost) {
+		return errors.Errorf("Channel '%s' of action type '%s' should be a channel the author of the rule can post in", action.ChannelID, action.Type)
+	}
+
+	return nil
+}
+
+// validateField checks that the field an action sets exists and is a field of the team of the
+// rule, or a field of all teams.
+func (rs *ruleService) validateField(rule Rule, action RuleAction) error {
+	field, err := rs.propertyFieldService.Get(action.PropertyFieldID)
+	if errors.Is(err, ErrNotFound) {
+		return errors.Errorf("Property field '%s' of action type '%s' does not exist", action.PropertyFieldID, action.Type)
+	} else if err != nil {
+		return err
+	}
+
+	if rule.TeamID != "" && field.TeamID != "" && field.TeamID != rule.TeamID {
+		return errors.Errorf("Property field '%s' of action type '%s' should be a field of the team of the rule", action.PropertyFieldID, action.Type)
+	}
+
+	return nil
+}
+
+func (rs *ruleService) onPropertyChange(change PropertyChange) {
+	if change.Type == PropertyChangeTypeDeleted {
+		return
+	}
+
+	objectID := change.Property.ObjectID
+	if !rs.enter(objectID) {
+		logrus.WithField("object_id", objectID).Warn("Skipping rules, too many rules triggered in a row for the same object")
+		return
+	}
+	defer rs.exit(objectID)
+
+	rules, err := rs.store.GetRules(RuleFilterOptions{
+		PropertyFieldID: change.Property.PropertyFieldID,
+		PerPage:         maxRulesPerField,
+	})
+	if err != nil {
+		logrus.WithError(err).WithField("property_field_id", change.Property.PropertyFieldID).Error("Failed to get rules for property change")
+		return
+	}
+
+	for _, rule := range rules {
+		if !ruleMatches(rule, change) {
+			continue
+		}
+
+		for _, action := range rule.Actions {
+			if err := rs.runAction(action, change.Property); err != nil {
+				logrus.WithError(err).WithFields(logrus.Fields{
+					"rule_id":     rule.ID,
+					"action_type": action.Type,
+					"object_id":   objectID,
+				}).Warn("Failed to run rule action")
+			}
+		}
+	}
+}
+
+func (rs *ruleService) enter(objectID string) bool {
+	rs.runningLock.Lock()
+	defer rs.runningLock.Unlock()
+
+	if rs.running[objectID] >= maxRuleDepth {
+		return false
+	}
+	rs.running[objectID]++
+
+	return true
+}
+
+func (rs *ruleService) exit(objectID string) {
+	rs.runningLock.Lock()
+	defer rs.runningLock.Unlock()
+
+	rs.running[objectID]--
+	if rs.running[objectID] <= 0 {
+		delete(rs.running, objectID)
+	}
+}
+
+// suppress prevents any rule from running for the object until the returned function is called.
+func (rs *ruleService) suppress(objectID string) func() {
+	rs.runningLock.Lock()
+	defer rs.runningLock.Unlock()
+
+	rs.running[objectID] = maxRuleDepth
+
+	return func() {
+		rs.runningLock.Lock()
+		defer rs.runningLock.Unlock()
+
+		delete(rs.running, objectID)
+	}
+}
+
+// ruleMatches returns true when the change is in the scope of the rule and the new value
+// gained one of the values the rule waits for. Rules without values match any change.
+func ruleMatches(rule Rule, change PropertyChange) bool {
+	if rule.TeamID != "" && rule.TeamID != change.Property.TeamID {
+		return false
+	}
+
+	if rule.ChannelID != "" && rule.ChannelID != change.Property.ChannelID {
+		return false
+	}
+
+	if len(rule.Value) == 0 {
+		return !sameValues(change.PreviousValue, change.Property.Value)
+	}
+
+	for _, v := range rule.Value {
+		if containsValue(change.Property.Value, v) && !containsValue(change.PreviousValue, v) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func containsValue(values []interface{}, value interface{}) bool {
+	for _, v := range values {
+		if fmt.Sprint(v) == fmt.Sprint(value) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func sameValues(a []interface{}, b []interface{}) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if fmt.Sprint(a[i]) != fmt.Sprint(b[i]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (rs *ruleService) runAction(action RuleAction, property Property) error {
+	switch action.Type {
+	case RuleActionTypePostMessage:
+		return rs.postMessage(action.ChannelID, property, action.Message)
+	case RuleActionTypeMentionUser:
+		user, err := rs.api.User.Get(action.UserID)
+		if err != nil {
+			return errors.Wrapf(err, "failed to get user '%s'", action.UserID)
+		}
+		return rs.postMessage("", property, fmt.Sprintf("@%s %s", user.Username, action.Message))
+	case RuleActionTypeSetProperty:
+		return rs.setProperty(property, action.PropertyFieldID, action.Value)
+	case RuleActionTypeMovePost:
+		return rs.movePost(property, action.ChannelID)
+	default:
+		return errors.Errorf("unknown action type '%s'", action.Type)
+	}
+}
+
+// postMessage posts the message in the given channel, or in the thread of the object when
+// no channel is given.
+func (rs *ruleService) postMessage(channelID string, property Property, message string) error {
+	objectChannelID, rootID, err := objectThread(rs.api, property)
+	if err != nil {
+		return err
+	}
+
+	post := &model.Post{
+		UserId:    rs.botID,
+		ChannelId: objectChannelID,
+		RootId:    rootID,
+		Message:   message,
+	}
+
+	if channelID != "" && channelID != objectChannelID {
+		post.ChannelId = channelID
+		post.RootId = ""
+		if rootID != "" {
+			post.Message = fmt.Sprintf("%s\n%s", message, permalink(rs.api, objectChannelID, rootID))
+		}
+	}
+
+	return rs.api.Post.CreatePost(post)
+}
+
+func (rs *ruleService) setProperty(property Property, propertyFieldID string, value []interface{}) error {
+	properties, err := rs.propertyService.GetForObject(property.ObjectID)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return errors.Wrapf(err, "failed to get properties for object '%s'", property.ObjectID)
+	}
+
+	if existing := FindProperty(properties, propertyFieldID); existing != nil {
+		return rs.propertyService.UpdateValue(existing.ID, value)
+	}
+
+	_, err = rs.propertyService.Create(Property{
+		ObjectID:        property.ObjectID,
+		ObjectType:      property.ObjectType,
+		PropertyFieldID: propertyFieldID,
+		ChannelID:       property.ChannelID,
+		TeamID:          property.TeamID,
+		Value:           value,
+	})
+
+	return err
+}
+
+// movePost recreates the post and its properties in the given channel and deletes the
+// original. Only posts without replies can be moved.
+func (rs *ruleService) movePost(property Property, channelID string) error {
+	if property.ObjectType != PropertyObjectTypePost {
+		return errors.Errorf("only posts can be moved, got object type '%s'", property.ObjectType)
+	}
+
+	post, err := rs.api.Post.GetPost(property.ObjectID)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get post '%s'", property.ObjectID)
+	}
+
+	if post.ChannelId == channelID {
+		return nil
+	}
+
+	if post.RootId != "" {
+		return errors.Errorf("post '%s' is a reply and can't be moved", post.Id)
+	}
+
+	thread, err := rs.api.Post.GetPostThread(post.Id)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get thread of post '%s'", post.Id)
+	}
+	if len(thread.Posts) > 1 {
+		return errors.Errorf("post '%s' has replies and can't be moved", post.Id)
+	}
+
+	channel, err := rs.api.Channel.Get(channelID)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get channel '%s'", channelID)
+	}
+
+	properties, err := rs.propertyService.GetForObject(post.Id)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return errors.Wrapf(err, "failed to get properties for post '%s'", post.Id)
+	}
+
+	movedPost := &model.Post{
+		UserId:    post.UserId,
+		ChannelId: channel.Id,
+		Message:   post.Message,
+		Type:      post.Type,
+		Props:     post.GetProps(),
+	}
+
+	if len(post.FileIds) > 0 {
+		fileIDs, copyErr := rs.api.File.CopyInfos(post.FileIds, post.UserId)
+		if copyErr != nil {
+			return errors.Wrapf(copyErr, "failed to copy files of post '%s'", post.Id)
+		}
+		movedPost.FileIds = fileIDs
+	}
+
+	if err = rs.api.Post.CreatePost(movedPost); err != nil {
+		return errors.Wrapf(err, "failed to create moved post in channel '%s'", channel.Id)
+	}
+
+	// The copied properties are not changes, so they must not trigger rules again
+	defer rs.suppress(movedPost.Id)()
+
+	for _, p := range properties {
+		_, err = rs.propertyService.Create(Property{
+			ObjectID:        movedPost.Id,
+			ObjectType:      PropertyObjectTypePost,
+			PropertyFieldID: p.PropertyFieldID,
+			ChannelID:       channel.Id,
+			TeamID:          channel.TeamId,
+			Value:           p.Value,
+		})
+		if err != nil {
+			return errors.Wrapf(err, "failed to copy property '%s' to moved post", p.ID)
+		}
+
+		if err = rs.propertyService.Delete(p.ID); err != nil {
+			return errors.Wrapf(err, "failed to delete property '%s' of original post", p.ID)
+		}
+	}
+
+	return rs.api.Post.DeletePost(post.Id)
+}
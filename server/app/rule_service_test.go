@@ -0,0 +1,170 @@
+package app
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/plugin/plugintest"
+	"github.com/mattermost/mattermost/server/public/pluginapi"
+)
+
+func TestRuleValidate(t *testing.T) {
+	api := &plugintest.API{}
+	api.On("GetChannel", "channel1").Return(&model.Channel{Id: "channel1", TeamId: "team1"}, nil)
+	api.On("GetChannel", "other-team").Return(&model.Channel{Id: "other-team", TeamId: "team2"}, nil)
+	api.On("GetChannel", "read-only").Return(&model.Channel{Id: "read-only", TeamId: "team1"}, nil)
+	api.On("GetChannel", mock.Anything).Return(nil, model.NewAppError("GetChannel", "app.channel.get.existing.app_error", nil, "", http.StatusNotFound))
+	api.On("HasPermissionToChannel", "author", "read-only", model.PermissionCreatePost).Return(false)
+	api.On("HasPermissionToChannel", "author", mock.Anything, model.PermissionCreatePost).Return(true)
+
+	fieldService := &fakePropertyFieldService{fields: []PropertyField{
+		{ID: "status"},
+		{ID: "owner", TeamID: "team1"},
+		{ID: "other-team", TeamID: "team2"},
+	}}
+	rs := &ruleService{
+		api:                  pluginapi.NewClient(api, nil),
+		propertyFieldService: fieldService,
+	}
+
+	cases := []struct {
+		Name          string
+		TeamID        string
+		Action        RuleAction
+		ExpectedError string
+	}{
+		{
+			Name:   "message in the thread",
+			TeamID: "team1",
+			Action: RuleAction{Type: RuleActionTypePostMessage, Message: "Done"},
+		},
+		{
+			Name:   "message in a channel of the team",
+			TeamID: "team1",
+			Action: RuleAction{Type: RuleActionTypePostMessage, Message: "Done", ChannelID: "channel1"},
+		},
+		{
+			Name:          "message in a missing channel",
+			TeamID:        "team1",
+			Action:        RuleAction{Type: RuleActionTypePostMessage, Message: "Done", ChannelID: "missing"},
+			ExpectedError: "Channel 'missing' of action type 'post_message' does not exist",
+		},
+		{
+			Name:          "message in a channel of another team",
+			TeamID:        "team1",
+			Action:        RuleAction{Type: RuleActionTypePostMessage, Message: "Done", ChannelID: "other-team"},
+			ExpectedError: "Channel 'other-team' of action type 'post_message' should be in the team of the rule",
+		},
+		{
+			Name:   "message in a channel of any team without a team",
+			Action: RuleAction{Type: RuleActionTypePostMessage, Message: "Done", ChannelID: "other-team"},
+		},
+		{
+			Name:          "move to a channel the author can't post in",
+			TeamID:        "team1",
+			Action:        RuleAction{Type: RuleActionTypeMovePost, ChannelID: "read-only"},
+			ExpectedError: "Channel 'read-only' of action type 'move_post' should be a channel the author of the rule can post in",
+		},
+		{
+			Name:          "move without a channel",
+			TeamID:        "team1",
+			Action:        RuleAction{Type: RuleActionTypeMovePost},
+			ExpectedError: "ChannelID should not be blank for action type 'move_post'",
+		},
+		{
+			Name:   "set a field of the team",
+			TeamID: "team1",
+			Action: RuleAction{Type: RuleActionTypeSetProperty, PropertyFieldID: "owner", Value: []interface{}{"user1"}},
+		},
+		{
+			Name:   "set a field of all teams",
+			TeamID: "team1",
+			Action: RuleAction{Type: RuleActionTypeSetProperty, PropertyFieldID: "status", Value: []interface{}{"Done"}},
+		},
+		{
+			Name:          "set a missing field",
+			TeamID:        "team1",
+			Action:        RuleAction{Type: RuleActionTypeSetProperty, PropertyFieldID: "missing"},
+			ExpectedError: "Property field 'missing' of action type 'set_property' does not exist",
+		},
+		{
+			Name:          "set a field of another team",
+			TeamID:        "team1",
+			Action:        RuleAction{Type: RuleActionTypeSetProperty, PropertyFieldID: "other-team"},
+			ExpectedError: "Property field 'other-team' of action type 'set_property' should be a field of the team of the rule",
+		},
+		{
+			Name:          "unknown action",
+			TeamID:        "team1",
+			Action:        RuleAction{Type: "archive"},
+			ExpectedError: "Unknown action type 'archive'",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			err := rs.validate(Rule{TeamID: c.TeamID, PropertyFieldID: "status", UpdateBy: "author", Actions: []RuleAction{c.Action}})
+			if c.ExpectedError != "" {
+				assert.EqualError(t, err, c.ExpectedError)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestRuleMatches(t *testing.T) {
+	property := Property{TeamID: "team1", ChannelID: "channel1", Value: []interface{}{"Done", "Blocked"}}
+
+	cases := []struct {
+		Name          string
+		Rule          Rule
+		PreviousValue []interface{}
+		Expected      bool
+	}{
+		{
+			Name:     "any change",
+			Rule:     Rule{},
+			Expected: true,
+		},
+		{
+			Name:          "no change",
+			Rule:          Rule{},
+			PreviousValue: []interface{}{"Done", "Blocked"},
+			Expected:      false,
+		},
+		{
+			Name:          "value gained",
+			Rule:          Rule{Value: []interface{}{"Done"}},
+			PreviousValue: []interface{}{"Blocked"},
+			Expected:      true,
+		},
+		{
+			Name:          "value kept",
+			Rule:          Rule{Value: []interface{}{"Done"}},
+			PreviousValue: []interface{}{"Done"},
+			Expected:      false,
+		},
+		{
+			Name:     "other team",
+			Rule:     Rule{TeamID: "team2"},
+			Expected: false,
+		},
+		{
+			Name:     "other channel",
+			Rule:     Rule{TeamID: "team1", ChannelID: "channel2"},
+			Expected: false,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			change := PropertyChange{Property: property, PreviousValue: c.PreviousValue}
+			assert.Equal(t, c.Expected, ruleMatches(c.Rule, change))
+		})
+	}
+}
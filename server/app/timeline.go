@@ -0,0 +1,306 @@
+package app
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// maxTimelineObjects bounds the objects returned for a timeline window.
+	maxTimelineObjects = 500
+	// maxTimelineDependencyObjects bounds the objects whose dependencies are followed from the
+	// objects of a timeline window when looking for cycles.
+	maxTimelineDependencyObjects = 5000
+)
+
+// TimelineFormat places the objects of a timeline view on bars from a start to an end date field.
+type TimelineFormat struct {
+	StartFieldID string `json:"start_field_id"`
+	EndFieldID   string `json:"end_field_id"`
+	// DependencyFieldID is a relation field, or a text field of object ids, linking an object to
+	// the objects it depends on.
+	DependencyFieldID string `json:"dependency_field_id,omitempty"`
+}
+
+// TimelineData holds the objects of a timeline window with their bars and the dependencies
+// between them. Truncated is set when the window has more than maxTimelineObjects objects, the
+// ones starting last being left out.
+type TimelineData struct {
+	Objects      Objects              `json:"objects"`
+	Bars         []TimelineBar        `json:"bars"`
+	Dependencies []TimelineDependency `json:"dependencies"`
+	Truncated    bool                 `json:"truncated"`
+}
+
+// TimelineBar spans the days from the start to the end of an object, both "2006-01-02".
+type TimelineBar struct {
+	ObjectID string `json:"object_id"`
+	Start    string `json:"start"`
+	End      string `json:"end"`
+	Days     int    `json:"days"`
+}
+
+// TimelineDependency is an edge from an object to an object it depends on. Cyclic is set when
+// the edge is part of a dependency cycle.
+type TimelineDependency struct {
+	ObjectID    string `json:"object_id"`
+	DependsOnID string `json:"depends_on_id"`
+	Cyclic      bool   `json:"cyclic"`
+}
+
+// timelineBars returns the bars of the objects having a start date. Objects without an end, or
+// with an end before their start, span their start only.
+func timelineBars(properties map[string]PropertiesList, objectIDs []string, timeline TimelineFormat) []TimelineBar {
+	bars := []TimelineBar{}
+	for _, objectID := range objectIDs {
+		start, ok := propertyDay(properties[objectID], timeline.StartFieldID)
+		if !ok {
+			continue
+		}
+
+		end, ok := propertyDay(properties[objectID], timeline.EndFieldID)
+		if !ok || end.Before(start) {
+			end = start
+		}
+
+		bars = append(bars, TimelineBar{
+			ObjectID: objectID,
+			Start:    start.Format(dateLayout),
+			End:      end.Format(dateLayout),
+			Days:     int(end.Sub(start).Hours()/24) + 1,
+		})
+	}
+
+	return bars
+}
+
+// dependencyCycles returns, for each object part of a dependency cycle, the cycle it's part of.
+// Objects depending on each other, directly or not, share the same cycle.
+func dependencyCycles(dependencies map[string][]string) map[string]int {
+	objectIDs := make([]string, 0, len(dependencies))
+	for objectID := range dependencies {
+		objectIDs = append(objectIDs, objectID)
+	}
+	sort.Strings(objectIDs)
+
+	// Tarjan's strongly connected components
+	index := map[string]int{}
+	lowLink := map[string]int{}
+	onStack := map[string]bool{}
+	stack := []string{}
+	cycles := map[string]int{}
+	cycle := 0
+
+	var visit func(objectID string)
+	visit = func(objectID string) {
+		index[objectID] = len(index)
+		lowLink[objectID] = index[objectID]
+		stack = append(stack, objectID)
+		onStack[objectID] = true
+
+		selfDependent := false
+		for _, dependsOnID := range dependencies[objectID] {
+			if dependsOnID == objectID {
+				selfDependent = true
+			}
+			if _, visited := index[dependsOnID]; !visited {
+				visit(dependsOnID)
+				lowLink[objectID] = min(lowLink[objectID], lowLink[dependsOnID])
+			} else if onStack[dependsOnID] {
+				lowLink[objectID] = min(lowLink[objectID], index[dependsOnID])
+			}
+		}
+
+		if lowLink[objectID] != index[objectID] {
+			return
+		}
+
+		var component []string
+		for {
+			last := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[last] = false
+			component = append(component, last)
+			if last == objectID {
+				break
+			}
+		}
+
+		if len(component) > 1 || selfDependent {
+			for _, member := range component {
+				cycles[member] = cycle
+			}
+			cycle++
+		}
+	}
+
+	for _, objectID := range objectIDs {
+		if _, visited := index[objectID]; !visited {
+			visit(objectID)
+		}
+	}
+
+	return cycles
+}
+
+// timelineDependencies returns the dependencies between the objects of the bars, flagging those
+// part of a cycle of the whole dependency graph.
+func timelineDependencies(dependencies map[string][]string, bars []TimelineBar) []TimelineDependency {
+	onTimeline := make(map[string]bool, len(bars))
+	for _, bar := range bars {
+		onTimeline[bar.ObjectID] = true
+	}
+
+	cycles := dependencyCycles(dependencies)
+
+	edges := []TimelineDependency{}
+	for _, bar := range bars {
+		for _, dependsOnID := range dependencies[bar.ObjectID] {
+			if !onTimeline[dependsOnID] {
+				continue
+			}
+
+			cycle, inCycle := cycles[bar.ObjectID]
+			dependsOnCycle, dependsOnInCycle := cycles[dependsOnID]
+			edges = append(edges, TimelineDependency{
+				ObjectID:    bar.ObjectID,
+				DependsOnID: dependsOnID,
+				Cyclic:      inCycle && dependsOnInCycle && cycle == dependsOnCycle,
+			})
+		}
+	}
+
+	return edges
+}
+
+func (vs *viewService) GetTimeline(id string, from, to time.Time) (TimelineData, error) {
+	view, err := vs.store.Get(id)
+	if err != nil {
+		return TimelineData{}, errors.Wrap(err, "could not get view")
+	}
+
+	if view.Type != ViewTypeTimeline || view.Format.Timeline == nil {
+		return TimelineData{}, errors.Errorf("View '%s' is not a timeline", view.Title)
+	}
+	timeline := *view.Format.Timeline
+
+	if err = validateWindow(from, to); err != nil {
+		return TimelineData{}, err
+	}
+
+	ids, truncated, err := vs.queryDateRange(view.Query, timeline.StartFieldID, timeline.EndFieldID, from, to, maxTimelineObjects)
+	if err != nil {
+		return TimelineData{}, err
+	}
+
+	objects, err := vs.getObjectsByID(view.Query.ObjectType, ids)
+	if err != nil {
+		return TimelineData{}, err
+	}
+
+	bars := timelineBars(objects.Properties, ids, timeline)
+
+	dependencies, err := vs.getDependencies(timeline.DependencyFieldID, ids)
+	if err != nil {
+		return TimelineData{}, err
+	}
+
+	return TimelineData{
+		Objects:      objects,
+		Bars:         bars,
+		Dependencies: timelineDependencies(dependencies, bars),
+		Truncated:    truncated,
+	}, nil
+}
+
+// getDependencies returns the ids of the objects each object depends on, following the
+// dependencies from the given objects. Cycles are only made of objects depending on each other,
+// so the objects they reach are enough to find the cycles they are part of.
+func (vs *viewService) getDependencies(fieldID string, objectIDs []string) (map[string][]string, error) {
+	dependencies := map[string][]string{}
+	if fieldID == "" {
+		return dependencies, nil
+	}
+
+	visited := map[string]bool{}
+	for _, objectID := range objectIDs {
+		visited[objectID] = true
+	}
+
+	next := objectIDs
+	for len(next) > 0 {
+		properties, err := vs.propertyService.GetProperties(PropertyFilterOptions{PropertyFieldID: fieldID, ObjectIDs: next})
+		if err != nil && !errors.Is(err, ErrNotFound) {
+			return nil, errors.Wrap(err, "could not get dependencies")
+		}
+
+		next = []string{}
+		for _, property := range properties {
+			for _, value := range property.Value {
+				dependsOnID := fmt.Sprint(value)
+				dependencies[property.ObjectID] = append(dependencies[property.ObjectID], dependsOnID)
+
+				if !visited[dependsOnID] && len(visited) < maxTimelineDependencyObjects {
+					visited[dependsOnID] = true
+					next = append(next, dependsOnID)
+				}
+			}
+		}
+	}
+
+	return dependencies, nil
+}
+
+// validateDateRange rejects writes to the date fields of timeline views that aren't dates, or
+// that would end an object before it starts.
+func (vs *viewService) validateDateRange(property Property) error {
+	if property.PropertyFieldType != PropertyFieldTypeDate || len(property.Value) == 0 {
+		return nil
+	}
+
+	views, err := vs.store.GetByType(ViewTypeTimeline)
+	if err != nil {
+		// Failing to get the views shouldn't block writes
+		logrus.WithError(err).Warn("Failed to get timeline views to validate date range")
+		return nil
+	}
+
+	var properties PropertiesList
+	for _, view := range views {
+		timeline := view.Format.Timeline
+		if timeline == nil || (timeline.StartFieldID != property.PropertyFieldID && timeline.EndFieldID != property.PropertyFieldID) {
+			continue
+		}
+
+		if _, ok := ParseDateValue(property.Value[0]); !ok {
+			return errors.Errorf("Value of field '%s' should be a date", property.PropertyFieldName)
+		}
+
+		if properties == nil {
+			properties, err = vs.propertyService.GetForObject(property.ObjectID)
+			if err != nil && !errors.Is(err, ErrNotFound) {
+				logrus.WithError(err).WithField("object_id", property.ObjectID).Warn("Failed to get properties to validate date range")
+				return nil
+			}
+		}
+
+		// The value being written takes the place of the stored one
+		written := PropertiesList{property}
+		start, end := written, properties
+		if timeline.EndFieldID == property.PropertyFieldID {
+			start, end = properties, written
+		}
+
+		startDay, hasStart := propertyDay(start, timeline.StartFieldID)
+		endDay, hasEnd := propertyDay(end, timeline.EndFieldID)
+		if hasStart && hasEnd && endDay.Before(startDay) {
+			return errors.Errorf("The end date should not be before the start date of timeline '%s'", view.Title)
+		}
+	}
+
+	return nil
+}
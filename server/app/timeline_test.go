@@ -0,0 +1,158 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimelineBars(t *testing.T) {
+	timeline := TimelineFormat{StartFieldID: "start", EndFieldID: "end"}
+	value := func(fieldID string, v interface{}) Property {
+		return Property{PropertyFieldID: fieldID, Value: []interface{}{v}}
+	}
+
+	properties := map[string]PropertiesList{
+		"a": {value("start", "2024-03-01"), value("end", "2024-03-14")},
+		"b": {value("start", "2024-03-05")},
+		"c": {value("start", "2024-03-05"), value("end", "2024-03-01")},
+		"d": {value("end", "2024-03-01")},
+	}
+
+	assert.Equal(t, []TimelineBar{
+		{ObjectID: "a", Start: "2024-03-01", End: "2024-03-14", Days: 14},
+		{ObjectID: "b", Start: "2024-03-05", End: "2024-03-05", Days: 1},
+		{ObjectID: "c", Start: "2024-03-05", End: "2024-03-05", Days: 1},
+	}, timelineBars(properties, []string{"a", "b", "c", "d"}, timeline))
+}
+
+func TestTimelineDependencies(t *testing.T) {
+	bars := func(objectIDs ...string) []TimelineBar {
+		result := make([]TimelineBar, len(objectIDs))
+		for i, objectID := range objectIDs {
+			result[i] = TimelineBar{ObjectID: objectID}
+		}
+		return result
+	}
+
+	cases := []struct {
+		Name         string
+		Dependencies map[string][]string
+		Bars         []TimelineBar
+		Expected     []TimelineDependency
+	}{
+		{
+			Name:         "chain",
+			Dependencies: map[string][]string{"b": {"a"}, "c": {"b"}},
+			Bars:         bars("a", "b", "c"),
+			Expected: []TimelineDependency{
+				{ObjectID: "b", DependsOnID: "a"},
+				{ObjectID: "c", DependsOnID: "b"},
+			},
+		},
+		{
+			Name:         "cycle",
+			Dependencies: map[string][]string{"a": {"c"}, "b": {"a"}, "c": {"b"}, "d": {"c"}},
+			Bars:         bars("a", "b", "c", "d"),
+			Expected: []TimelineDependency{
+				{ObjectID: "a", DependsOnID: "c", Cyclic: true},
+				{ObjectID: "b", DependsOnID: "a", Cyclic: true},
+				{ObjectID: "c", DependsOnID: "b", Cyclic: true},
+				{ObjectID: "d", DependsOnID: "c"},
+			},
+		},
+		{
+			Name:         "self dependency",
+			Dependencies: map[string][]string{"a": {"a"}},
+			Bars:         bars("a"),
+			Expected:     []TimelineDependency{{ObjectID: "a", DependsOnID: "a", Cyclic: true}},
+		},
+		{
+			Name:         "cycle through an object outside the window",
+			Dependencies: map[string][]string{"a": {"x"}, "x": {"b"}, "b": {"a"}},
+			Bars:         bars("a", "b"),
+			Expected:     []TimelineDependency{{ObjectID: "b", DependsOnID: "a", Cyclic: true}},
+		},
+		{
+			Name:         "two separate cycles",
+			Dependencies: map[string][]string{"a": {"b"}, "b": {"a"}, "c": {"d", "a"}, "d": {"c"}},
+			Bars:         bars("a", "b", "c", "d"),
+			Expected: []TimelineDependency{
+				{ObjectID: "a", DependsOnID: "b", Cyclic: true},
+				{ObjectID: "b", DependsOnID: "a", Cyclic: true},
+				{ObjectID: "c", DependsOnID: "d", Cyclic: true},
+				{ObjectID: "c", DependsOnID: "a"},
+				{ObjectID: "d", DependsOnID: "c", Cyclic: true},
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			assert.Equal(t, c.Expected, timelineDependencies(c.Dependencies, c.Bars))
+		})
+	}
+}
+
+func TestGetDependencies(t *testing.T) {
+	dependsOn := func(objectID string, dependsOnIDs ...interface{}) []Property {
+		return []Property{{ObjectID: objectID, PropertyFieldID: "blocked_by", Value: dependsOnIDs}}
+	}
+	vs := &viewService{propertyService: &fakePropertyService{properties: map[string][]Property{
+		"a": dependsOn("a", "b"),
+		"b": dependsOn("b", "c"),
+		"c": dependsOn("c", "a"),
+		"d": dependsOn("d", "a"),
+	}}}
+
+	// Dependencies are followed from the objects of the window, not loaded for every object
+	dependencies, err := vs.getDependencies("blocked_by", []string{"a"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]string{"a": {"b"}, "b": {"c"}, "c": {"a"}}, dependencies)
+
+	dependencies, err = vs.getDependencies("", []string{"a"})
+	require.NoError(t, err)
+	assert.Empty(t, dependencies)
+}
+
+func TestValidateDateRange(t *testing.T) {
+	vs := &viewService{
+		store: &fakeViewStore{view: View{
+			ID:     "timeline",
+			Title:  "Roadmap",
+			Type:   ViewTypeTimeline,
+			Format: Format{Timeline: &TimelineFormat{StartFieldID: "start", EndFieldID: "end"}},
+		}},
+		propertyService: &fakePropertyService{properties: map[string][]Property{
+			"a": {{ObjectID: "a", PropertyFieldID: "start", Value: []interface{}{"2024-03-05"}}},
+		}},
+	}
+	date := func(fieldID string, value interface{}) Property {
+		return Property{ObjectID: "a", PropertyFieldID: fieldID, PropertyFieldName: fieldID, PropertyFieldType: PropertyFieldTypeDate, Value: []interface{}{value}}
+	}
+
+	cases := []struct {
+		Name     string
+		Property Property
+		Expected string
+	}{
+		{Name: "end after start", Property: date("end", "2024-03-07")},
+		{Name: "end before start", Property: date("end", "2024-03-01"), Expected: "The end date should not be before the start date of timeline 'Roadmap'"},
+		{Name: "timestamp", Property: date("start", "2024-03-01T10:00:00Z")},
+		{Name: "not a date", Property: date("end", "next week"), Expected: "Value of field 'end' should be a date"},
+		{Name: "not a string", Property: date("start", float64(3)), Expected: "Value of field 'start' should be a date"},
+		{Name: "other field", Property: date("due", "next week")},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			err := vs.validateDateRange(c.Property)
+			if c.Expected == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, c.Expected)
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package app
+
+// Token authenticates external tools writing properties without a Mattermost session. A token
+// belongs to a team and may only write the fields in FieldIDs, on objects of that team.
+type Token struct {
+	ID         string   `json:"id"`
+	TeamID     string   `json:"team_id"`
+	Name       string   `json:"name"`
+	FieldIDs   []string `json:"field_ids" db:"-"`
+	CreateAt   int64    `json:"create_at"`
+	CreateBy   string   `json:"create_by"`
+	LastUsedAt int64    `json:"last_used_at"`
+
+	// TokenHash is the SHA-256 of the secret, which is only returned once on creation.
+	TokenHash string `json:"-"`
+}
+
+// TokenWrite sets the value of a field, identified by id or name, on an object.
+type TokenWrite struct {
+	ObjectID string      `json:"object_id"`
+	Field    string      `json:"field"`
+	Value    interface{} `json:"value"`
+}
+
+// TokenWriteResult reports the outcome of one TokenWrite of a batch.
+type TokenWriteResult struct {
+	ObjectID   string `json:"object_id"`
+	Field      string `json:"field"`
+	PropertyID string `json:"property_id,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+type TokenStore interface {
+	Get(id string) (Token, error)
+	GetByHash(tokenHash string) (Token, error)
+	Create(token Token) (string, error)
+	GetForTeam(teamID string) ([]Token, error)
+	UpdateLastUsedAt(id string, lastUsedAt int64) error
+	Delete(id string) error
+}
+
+type TokenService interface {
+	Get(id string) (Token, error)
+	// Create stores the token and returns its id and secret.
+	Create(token Token) (string, string, error)
+	GetForTeam(teamID string) ([]Token, error)
+	Delete(id string) error
+
+	// Authenticate returns the token matching the secret.
+	Authenticate(secret string) (Token, error)
+
+	// Write applies a batch of writes on behalf of the token. A failing write doesn't stop the
+	// others, its error is reported in the results.
+	Write(token Token, writes []TokenWrite) []TokenWriteResult
+}
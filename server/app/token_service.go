@@ -0,0 +1,222 @@
+package app
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/pluginapi"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+const tokenSecretLength = 40
+
+type tokenService struct {
+	store                TokenStore
+	propertyService      PropertyService
+	propertyFieldService PropertyFieldService
+	api                  *pluginapi.Client
+}
+
+func NewTokenService(store TokenStore, propertyService PropertyService, propertyFieldService PropertyFieldService, api *pluginapi.Client) TokenService {
+	return &tokenService{
+		store:                store,
+		propertyService:      propertyService,
+		propertyFieldService: propertyFieldService,
+		api:                  api,
+	}
+}
+
+func (ts *tokenService) Get(id string) (Token, error) {
+	return ts.store.Get(id)
+}
+
+func (ts *tokenService) Create(token Token) (string, string, error) {
+	if token.TeamID == "" {
+		return "", "", errors.New("TeamID should not be blank")
+	}
+
+	if token.Name == "" {
+		return "", "", errors.New("Name should not be blank")
+	}
+
+	if len(token.FieldIDs) == 0 {
+		return "", "", errors.New("FieldIDs should not be empty")
+	}
+
+	for _, fieldID := range token.FieldIDs {
+		field, err := ts.propertyFieldService.Get(fieldID)
+		if err != nil {
+			if errors.Is(err, ErrNotFound) {
+				return "", "", errors.Errorf("Tried to create token with unknown property_field with id: '%s'", fieldID)
+			}
+			return "", "", err
+		}
+
+		if field.TeamID != "" && field.TeamID != token.TeamID {
+			return "", "", errors.Errorf("property_field '%s' belongs to another team", fieldID)
+		}
+	}
+
+	secret := model.NewRandomString(tokenSecretLength)
+	token.TokenHash = hashTokenSecret(secret)
+
+	id, err := ts.store.Create(token)
+	if err != nil {
+		return "", "", err
+	}
+
+	return id, secret, nil
+}
+
+func (ts *tokenService) GetForTeam(teamID string) ([]Token, error) {
+	return ts.store.GetForTeam(teamID)
+}
+
+func (ts *tokenService) Delete(id string) error {
+	return ts.store.Delete(id)
+}
+
+func (ts *tokenService) Authenticate(secret string) (Token, error) {
+	if secret == "" {
+		return Token{}, errors.Wrap(ErrNoPermissions, "token should not be blank")
+	}
+
+	token, err := ts.store.GetByHash(hashTokenSecret(secret))
+	if errors.Is(err, ErrNotFound) {
+		return Token{}, errors.Wrap(ErrNoPermissions, "invalid token")
+	} else if err != nil {
+		return Token{}, err
+	}
+
+	token.LastUsedAt = model.GetMillis()
+	if err = ts.store.UpdateLastUsedAt(token.ID, token.LastUsedAt); err != nil {
+		logrus.WithError(err).WithField("token_id", token.ID).Warn("Failed to update last use of token")
+	}
+
+	return token, nil
+}
+
+func (ts *tokenService) Write(token Token, writes []TokenWrite) []TokenWriteResult {
+	results := make([]TokenWriteResult, len(writes))
+	for i, write := range writes {
+		results[i] = TokenWriteResult{
+			ObjectID: write.ObjectID,
+			Field:    write.Field,
+		}
+
+		id, err := ts.write(token, write)
+		if err != nil {
+			results[i].Error = err.Error()
+			continue
+		}
+		results[i].PropertyID = id
+	}
+
+	return results
+}
+
+// write sets the value of a single field, returning the id of the property written.
+func (ts *tokenService) write(token Token, write TokenWrite) (string, error) {
+	if write.ObjectID == "" {
+		return "", errors.New("object_id should not be blank")
+	}
+
+	if write.Field == "" {
+		return "", errors.New("field should not be blank")
+	}
+
+	field, err := ts.findField(token, write.Field)
+	if err != nil {
+		return "", err
+	}
+
+	post, err := ts.api.Post.GetPost(write.ObjectID)
+	if err != nil {
+		return "", errors.Errorf("post '%s' not found", write.ObjectID)
+	}
+
+	channel, err := ts.api.Channel.Get(post.ChannelId)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to get channel '%s'", post.ChannelId)
+	}
+
+	if channel.TeamId != token.TeamID {
+		return "", errors.Errorf("post '%s' belongs to another team", post.Id)
+	}
+
+	value := tokenWriteValue(write.Value)
+
+	properties, err := ts.propertyService.GetForObject(post.Id)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return "", errors.Wrapf(err, "failed to get properties for post '%s'", post.Id)
+	}
+
+	if existing := FindProperty(properties, field.ID); existing != nil {
+		if err = ts.propertyService.UpdateValue(existing.ID, value); err != nil {
+			return "", err
+		}
+		return existing.ID, nil
+	}
+
+	return ts.propertyService.Create(Property{
+		ObjectID:        post.Id,
+		ObjectType:      PropertyObjectTypePost,
+		PropertyFieldID: field.ID,
+		ChannelID:       channel.Id,
+		TeamID:          channel.TeamId,
+		Value:           value,
+	})
+}
+
+// findField resolves the field by id or name and checks the token may write it.
+func (ts *tokenService) findField(token Token, idOrName string) (PropertyField, error) {
+	allowed := func(fieldID string) bool {
+		for _, id := range token.FieldIDs {
+			if id == fieldID {
+				return true
+			}
+		}
+		return false
+	}
+
+	if allowed(idOrName) {
+		return ts.propertyFieldService.Get(idOrName)
+	}
+
+	fields, err := ts.propertyFieldService.GetFields(PropertyFieldFilterOptions{
+		TeamID:     token.TeamID,
+		SearchTerm: idOrName,
+		PerPage:    100,
+	})
+	if err != nil {
+		return PropertyField{}, errors.Wrapf(err, "failed to find field '%s'", idOrName)
+	}
+
+	for _, field := range fields {
+		if strings.EqualFold(field.Name, idOrName) && allowed(field.ID) {
+			return field, nil
+		}
+	}
+
+	return PropertyField{}, errors.Errorf("field '%s' is not in the scope of the token", idOrName)
+}
+
+// tokenWriteValue wraps single values into the list stored for properties.
+func tokenWriteValue(value interface{}) []interface{} {
+	switch v := value.(type) {
+	case nil:
+		return []interface{}{}
+	case []interface{}:
+		return v
+	default:
+		return []interface{}{v}
+	}
+}
+
+func hashTokenSecret(secret string) string {
+	hash := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(hash[:])
+}
@@ -0,0 +1,233 @@
+package app
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/plugin/plugintest"
+	"github.com/mattermost/mattermost/server/public/pluginapi"
+)
+
+// fakeTokenStore keeps tokens in memory, other methods panic.
+type fakeTokenStore struct {
+	TokenStore
+	tokens []Token
+}
+
+func (s *fakeTokenStore) Create(token Token) (string, error) {
+	token.ID = model.NewId()
+	s.tokens = append(s.tokens, token)
+	return token.ID, nil
+}
+
+func (s *fakeTokenStore) GetByHash(tokenHash string) (Token, error) {
+	for _, token := range s.tokens {
+		if token.TokenHash == tokenHash {
+			return token, nil
+		}
+	}
+	return Token{}, ErrNotFound
+}
+
+func (s *fakeTokenStore) UpdateLastUsedAt(id string, lastUsedAt int64) error {
+	for i := range s.tokens {
+		if s.tokens[i].ID == id {
+			s.tokens[i].LastUsedAt = lastUsedAt
+			return nil
+		}
+	}
+	return ErrNotFound
+}
+
+var testTokenFields = []PropertyField{
+	{ID: "build", Name: "Build", TeamID: "team1"},
+	{ID: "status", Name: "Status"},
+	{ID: "secret", Name: "Secret", TeamID: "team1"},
+	{ID: "other", Name: "Other", TeamID: "team2"},
+}
+
+func TestTokenCreate(t *testing.T) {
+	cases := []struct {
+		Name     string
+		Token    Token
+		Expected string
+	}{
+		{
+			Name:  "valid",
+			Token: Token{TeamID: "team1", Name: "CI", FieldIDs: []string{"build", "status"}},
+		},
+		{
+			Name:     "blank team",
+			Token:    Token{Name: "CI", FieldIDs: []string{"build"}},
+			Expected: "TeamID should not be blank",
+		},
+		{
+			Name:     "blank name",
+			Token:    Token{TeamID: "team1", FieldIDs: []string{"build"}},
+			Expected: "Name should not be blank",
+		},
+		{
+			Name:     "no fields",
+			Token:    Token{TeamID: "team1", Name: "CI"},
+			Expected: "FieldIDs should not be empty",
+		},
+		{
+			Name:     "unknown field",
+			Token:    Token{TeamID: "team1", Name: "CI", FieldIDs: []string{"unknown"}},
+			Expected: "Tried to create token with unknown property_field with id: 'unknown'",
+		},
+		{
+			Name:     "field of another team",
+			Token:    Token{TeamID: "team1", Name: "CI", FieldIDs: []string{"build", "other"}},
+			Expected: "property_field 'other' belongs to another team",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			store := &fakeTokenStore{}
+			ts := NewTokenService(store, nil, &fakePropertyFieldService{fields: testTokenFields}, nil)
+
+			id, secret, err := ts.Create(c.Token)
+			if c.Expected != "" {
+				assert.EqualError(t, err, c.Expected)
+				assert.Empty(t, store.tokens)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Len(t, store.tokens, 1)
+			assert.Equal(t, id, store.tokens[0].ID)
+			assert.Len(t, secret, tokenSecretLength)
+			assert.Equal(t, hashTokenSecret(secret), store.tokens[0].TokenHash)
+			assert.NotContains(t, store.tokens[0].TokenHash, secret)
+		})
+	}
+}
+
+func TestTokenAuthenticate(t *testing.T) {
+	store := &fakeTokenStore{}
+	ts := NewTokenService(store, nil, &fakePropertyFieldService{fields: testTokenFields}, nil)
+
+	id, secret, err := ts.Create(Token{TeamID: "team1", Name: "CI", FieldIDs: []string{"build"}})
+	require.NoError(t, err)
+
+	cases := []struct {
+		Name     string
+		Secret   string
+		Expected string
+	}{
+		{
+			Name:   "valid secret",
+			Secret: secret,
+		},
+		{
+			Name:     "blank secret",
+			Secret:   "",
+			Expected: "token should not be blank: does not have permissions",
+		},
+		{
+			Name:     "invalid secret",
+			Secret:   secret + "x",
+			Expected: "invalid token: does not have permissions",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			token, err := ts.Authenticate(c.Secret)
+			if c.Expected != "" {
+				assert.ErrorIs(t, err, ErrNoPermissions)
+				assert.EqualError(t, err, c.Expected)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, id, token.ID)
+			assert.NotZero(t, token.LastUsedAt)
+			assert.Equal(t, token.LastUsedAt, store.tokens[0].LastUsedAt)
+		})
+	}
+}
+
+func TestTokenWrite(t *testing.T) {
+	api := &plugintest.API{}
+	api.On("GetPost", "post1").Return(&model.Post{Id: "post1", ChannelId: "channel1"}, nil)
+	api.On("GetPost", "post2").Return(&model.Post{Id: "post2", ChannelId: "channel2"}, nil)
+	api.On("GetPost", "missing").Return(nil, model.NewAppError("GetPost", "app.post.get.app_error", nil, "", http.StatusNotFound))
+	api.On("GetChannel", "channel1").Return(&model.Channel{Id: "channel1", TeamId: "team1"}, nil)
+	api.On("GetChannel", "channel2").Return(&model.Channel{Id: "channel2", TeamId: "team2"}, nil)
+
+	propertyService := &fakePropertyService{properties: map[string][]Property{
+		"post1": {{ID: "property1", ObjectID: "post1", PropertyFieldID: "status", Value: []interface{}{"Open"}}},
+	}}
+	ts := NewTokenService(&fakeTokenStore{}, propertyService, &fakePropertyFieldService{fields: testTokenFields}, pluginapi.NewClient(api, nil))
+	token := Token{ID: "token1", TeamID: "team1", FieldIDs: []string{"build", "status"}}
+
+	results := ts.Write(token, []TokenWrite{
+		{ObjectID: "post1", Field: "build", Value: "passed"},
+		{ObjectID: "post1", Field: "STATUS", Value: []interface{}{"Done"}},
+		{ObjectID: "post1", Field: "Secret", Value: "leaked"},
+		{ObjectID: "post2", Field: "build", Value: "passed"},
+		{ObjectID: "missing", Field: "build", Value: "passed"},
+		{ObjectID: "", Field: "build", Value: "passed"},
+		{ObjectID: "post1", Field: "", Value: "passed"},
+	})
+
+	require.Len(t, propertyService.created, 1)
+	createdID := propertyService.created[0].ID
+	assert.Equal(t, Property{
+		ID:              createdID,
+		ObjectID:        "post1",
+		ObjectType:      PropertyObjectTypePost,
+		PropertyFieldID: "build",
+		ChannelID:       "channel1",
+		TeamID:          "team1",
+		Value:           []interface{}{"passed"},
+	}, propertyService.created[0])
+	assert.Equal(t, []Property{{ID: "property1", Value: []interface{}{"Done"}}}, propertyService.updates)
+
+	assert.Equal(t, []TokenWriteResult{
+		{ObjectID: "post1", Field: "build", PropertyID: createdID},
+		{ObjectID: "post1", Field: "STATUS", PropertyID: "property1"},
+		{ObjectID: "post1", Field: "Secret", Error: "field 'Secret' is not in the scope of the token"},
+		{ObjectID: "post2", Field: "build", Error: "post 'post2' belongs to another team"},
+		{ObjectID: "missing", Field: "build", Error: "post 'missing' not found"},
+		{ObjectID: "", Field: "build", Error: "object_id should not be blank"},
+		{ObjectID: "post1", Field: "", Error: "field should not be blank"},
+	}, results)
+}
+
+func TestTokenWriteValue(t *testing.T) {
+	cases := []struct {
+		Name     string
+		Value    interface{}
+		Expected []interface{}
+	}{
+		{
+			Name:     "nil",
+			Value:    nil,
+			Expected: []interface{}{},
+		},
+		{
+			Name:     "single value",
+			Value:    "passed",
+			Expected: []interface{}{"passed"},
+		},
+		{
+			Name:     "list",
+			Value:    []interface{}{"a", float64(1)},
+			Expected: []interface{}{"a", float64(1)},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			assert.Equal(t, c.Expected, tokenWriteValue(c.Value))
+		})
+	}
+}
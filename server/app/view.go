@@ -1,6 +1,8 @@
 package app
 
 import (
+	"time"
+
 	"github.com/mattermost/mattermost/server/public/model"
 )
 
@@ -9,13 +11,18 @@ type View struct {
 	Title    string `json:"title"`
 	Type     string `json:"type"`
 	CreateAt int64  `json:"create_at"`
+	UpdateAt int64  `json:"update_at"`
 	Query    Query  `json:"query" db:"-"`
 	Format   Format `json:"format" db:"-"`
 }
 
 const (
-	ViewTypeList   = "list"
-	ViewTypeKanban = "kanban"
+	ViewTypeList     = "list"
+	ViewTypeKanban   = "kanban"
+	ViewTypeChart    = "chart"
+	ViewTypeTable    = "table"
+	ViewTypeCalendar = "calendar"
+	ViewTypeTimeline = "timeline"
 )
 
 type ViewMember struct {
@@ -23,31 +30,256 @@ type ViewMember struct {
 	UserID string `json:"user_id"`
 }
 type Query struct {
-	Includes  map[string][]string `json:"includes"`
-	Excludes  map[string][]string `json:"excludes"`
-	ChannelID string              `json:"channel_id"`
-	TeamID    string              `json:"team_id"`
+	Includes   map[string][]string `json:"includes"`
+	Excludes   map[string][]string `json:"excludes"`
+	ChannelID  string              `json:"channel_id"`
+	TeamID     string              `json:"team_id"`
+	ObjectType string              `json:"object_type"`
+
+	// Threads rolls matching replies up to their root posts, returning one object per thread.
+	Threads bool `json:"threads"`
+
+	// RelatedTo matches the objects linking to all of these objects. Object ids are only ever
+	// values of relation fields, so any field having the id matches.
+	RelatedTo []string `json:"related_to,omitempty"`
+
+	// Conditions are operators applied to fields, all of them having to match.
+	Conditions []Condition `json:"conditions,omitempty"`
+}
+
+// Condition compares the value of a field using an operator supported by the type of the field.
+type Condition struct {
+	FieldID  string `json:"field_id"`
+	Operator string `json:"operator"`
+	Value    string `json:"value,omitempty"`
+}
+
+const (
+	// OperatorIsChecked matches checkboxes which are checked.
+	OperatorIsChecked = "is_checked"
+	// OperatorDomainEquals matches URLs and email addresses of a domain, ignoring case.
+	OperatorDomainEquals   = "domain_equals"
+	OperatorGreaterOrEqual = "gte"
+	OperatorLessOrEqual    = "lte"
+)
+
+// Matches evaluates the query against an object in memory, with values and the type names of
+// their fields keyed by property field id. It mirrors the predicates the ViewStore applies in
+// SQL, where objects without any properties never match, and conditions on fields of unknown
+// types match nothing.
+func (q Query) Matches(objectType, channelID, teamID string, values map[string][]interface{}, types map[string]string) bool {
+	if len(values) == 0 {
+		return false
+	}
+
+	queryObjectType := q.ObjectType
+	if queryObjectType == "" {
+		queryObjectType = PropertyObjectTypePost
+	}
+	if objectType != queryObjectType {
+		return false
+	}
+
+	if q.ChannelID != "" && channelID != q.ChannelID {
+		return false
+	}
+
+	if q.TeamID != "" && teamID != q.TeamID {
+		return false
+	}
+
+	for id, fields := range q.Includes {
+		if !valuesMatch(values, id, fields) {
+			return false
+		}
+	}
+
+	for id, fields := range q.Excludes {
+		// Like in SQL, excluding options only matches objects having the field
+		if _, ok := values[id]; len(fields) > 0 && !ok {
+			return false
+		}
+		if valuesMatch(values, id, fields) {
+			return false
+		}
+	}
+
+	for _, objectID := range q.RelatedTo {
+		if !relatedTo(values, objectID) {
+			return false
+		}
+	}
+
+	for _, condition := range q.Conditions {
+		fieldType, ok := GetFieldType(types[condition.FieldID])
+		if !ok || !fieldType.Matches(condition, values[condition.FieldID]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// relatedTo returns true if any field has the object id among its values.
+func relatedTo(values map[string][]interface{}, objectID string) bool {
+	for fieldID := range values {
+		if valuesMatch(values, fieldID, []string{objectID}) {
+			return true
+		}
+	}
+	return false
+}
+
+// valuesMatch returns true if the field is set when no options are given, or if the field has
+// any of the options.
+func valuesMatch(values map[string][]interface{}, fieldID string, options []string) bool {
+	value, ok := values[fieldID]
+	if !ok {
+		return false
+	}
+
+	if len(options) == 0 {
+		return true
+	}
+
+	for _, v := range value {
+		s, isString := v.(string)
+		if !isString {
+			continue
+		}
+		for _, option := range options {
+			if s == option {
+				return true
+			}
+		}
+	}
+
+	return false
 }
 
 type Format struct {
 	Order          []string `json:"order"`
 	GroupByFieldID string   `json:"group_by_field_id"`
 	HiddenValueIDs []string `json:"hidden_value_ids"`
+
+	// ColumnOrders orders the objects of each column of kanban views, keyed by the value of the
+	// column and blank for the objects without a value. Objects not listed come last.
+	ColumnOrders map[string][]string `json:"column_orders,omitempty"`
+
+	// WIPLimits caps the objects of kanban columns, keyed by the value of the column and blank for
+	// the objects without a value. Zero means no limit.
+	WIPLimits map[string]int `json:"wip_limits,omitempty"`
+
+	// SwimlaneFieldID splits the columns of kanban views into rows, one per value of the field.
+	SwimlaneFieldID string `json:"swimlane_field_id,omitempty"`
+
+	// Chart is only used by chart views.
+	Chart *ChartFormat `json:"chart,omitempty"`
+
+	// Columns are the visible fields of table views, in order.
+	Columns []TableColumn `json:"columns,omitempty"`
+
+	// Calendar is only used by calendar views.
+	Calendar *CalendarFormat `json:"calendar,omitempty"`
+
+	// Timeline is only used by timeline views.
+	Timeline *TimelineFormat `json:"timeline,omitempty"`
 }
 
+// CalendarFormat places the objects of a calendar view on the days of a date field, or on the
+// days from a start to an end date field.
+type CalendarFormat struct {
+	StartFieldID string `json:"start_field_id"`
+	EndFieldID   string `json:"end_field_id,omitempty"`
+}
+
+// TableColumn is a field shown by a table view.
+type TableColumn struct {
+	FieldID string `json:"field_id"`
+	// Width is in pixels, the client picking one when zero.
+	Width  int  `json:"width,omitempty"`
+	Pinned bool `json:"pinned,omitempty"`
+	// Sort orders the objects by the first value of the field. Earlier columns take precedence.
+	Sort string `json:"sort,omitempty"`
+}
+
+const (
+	SortAscending  = "asc"
+	SortDescending = "desc"
+)
+
 type PropertiesList []Property
 
 type Objects struct {
 	Posts      []*model.Post             `json:"posts"`
+	Files      []*model.FileInfo         `json:"files"`
 	Properties map[string]PropertiesList `json:"properties"`
+
+	// ReplyCounts is keyed by root post id and only set for views in threads mode.
+	ReplyCounts map[string]int `json:"reply_counts,omitempty"`
+
+	// OverLimitValues are the values of the kanban columns holding more objects than their WIP
+	// limit. Only set for kanban views with limits.
+	OverLimitValues []string `json:"over_limit_values,omitempty"`
+}
+
+// AggregateOptions selects how the objects of a view are aggregated.
+type AggregateOptions struct {
+	// GroupByFieldIDs holds up to two fields, the second one splitting the groups of the first.
+	GroupByFieldIDs []string
+	// NumberFieldIDs are number fields whose values are summarized for every group.
+	NumberFieldIDs []string
+}
+
+// MaxAggregateGroupByFields is the number of fields objects can be grouped by at once.
+const MaxAggregateGroupByFields = 2
+
+type Aggregation struct {
+	GroupByFieldIDs []string           `json:"group_by_field_ids"`
+	Groups          []AggregationGroup `json:"groups"`
+}
+
+// AggregationGroup counts the objects having a value for each of the group by fields. Objects
+// with several values of a field are counted in the group of each value.
+type AggregationGroup struct {
+	// Values holds the value of each group by field, nil for objects without a value.
+	Values  []*string                    `json:"values"`
+	Count   int64                        `json:"count"`
+	Metrics map[string]AggregationMetric `json:"metrics,omitempty"`
+}
+
+// AggregationMetric summarizes the values of a number field over the objects of a group that
+// have one.
+type AggregationMetric struct {
+	Count int64   `json:"count"`
+	Sum   float64 `json:"sum"`
+	Avg   float64 `json:"avg"`
+	Min   float64 `json:"min"`
+	Max   float64 `json:"max"`
 }
 
 type ViewStore interface {
 	Create(view View) (string, error)
-	QueryObjects(query Query, page int, perPage int) ([]string, error)
+	// QueryObjects returns the ids of the objects matching the query, ordered by the columns
+	// having a sort.
+	QueryObjects(query Query, sort []TableColumn, page int, perPage int) ([]string, error)
+	QueryThreadRoots(query Query, page int, perPage int) ([]string, error)
+	// FilterObjects returns the ids of the given objects that match the query.
+	FilterObjects(query Query, objectIDs []string) ([]string, error)
+	// Aggregate groups the objects matching the query. The fields of the options are expected
+	// to exist, with NumberFieldIDs being number fields.
+	Aggregate(query Query, options AggregateOptions) (Aggregation, error)
+	// QueryDateRange returns the ids of the objects matching the query whose dates overlap the
+	// days from and to, both "2006-01-02". Objects without an end date, or with an end date before
+	// their start date, only span their start date.
+	QueryDateRange(query Query, startFieldID, endFieldID string, from, to string, limit int) ([]string, error)
 	Get(id string) (View, error)
 	GetForUser(userID string) ([]View, error)
+	GetByType(viewType string) ([]View, error)
 	Update(id string, title *string, query *Query, format *Format) error
+	// UpdateFormat replaces the format of the view if it wasn't updated since updateAt, returning
+	// its new update time. Fails with ErrConflict otherwise.
+	UpdateFormat(id string, format Format, updateAt int64) (int64, error)
 }
 
 type ViewMemberStore interface {
@@ -56,7 +288,27 @@ type ViewMemberStore interface {
 
 type ViewService interface {
 	Create(view View) (string, error)
+	Get(id string) (View, error)
 	GetObjectsForView(id string, page int, perPage int) (Objects, error)
+	// FilterObjectsInView returns the ids of the given objects that are part of the view.
+	FilterObjectsInView(id string, objectIDs []string) ([]string, error)
+	// AggregateView groups the objects of the view, by the group by field of its format when the
+	// options have no group by fields.
+	AggregateView(id string, options AggregateOptions) (Aggregation, error)
+	// GetBoard returns a page of the objects of a kanban view bucketed by column and swimlane,
+	// with the counts of each cell.
+	GetBoard(id string, page int, perPage int) (BoardData, error)
+	// GetChart returns the series to plot for a chart view.
+	GetChart(id string) (ChartData, error)
+	// GetCalendar returns the objects of a calendar view from one day to another, inclusive.
+	GetCalendar(id string, from, to time.Time) (CalendarData, error)
+	// GetTimeline returns the bars and dependencies of a timeline view from one day to another,
+	// inclusive.
+	GetTimeline(id string, from, to time.Time) (TimelineData, error)
+	// Move moves an object of a kanban view to a column and a position within it. The value and
+	// the order of the column are written separately, the value being put back when the order
+	// can't be saved.
+	Move(id string, move Move) (View, error)
 	AddUserToView(userID string, viewID string) error
 	GetForUser(userId string) ([]View, error)
 	Update(id string, title *string, query *Query, format *Format) error
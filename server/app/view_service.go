@@ -1,6 +1,10 @@
 package app
 
 import (
+	"fmt"
+	"slices"
+	"time"
+
 	"github.com/pkg/errors"
 
 	"github.com/mattermost/mattermost/server/public/model"
@@ -8,19 +12,25 @@ import (
 )
 
 type viewService struct {
-	store           ViewStore
-	memberStore     ViewMemberStore
-	propertyService PropertyService
-	api             *pluginapi.Client
+	store                ViewStore
+	memberStore          ViewMemberStore
+	propertyService      PropertyService
+	propertyFieldService PropertyFieldService
+	api                  *pluginapi.Client
 }
 
-func NewViewService(store ViewStore, memberStore ViewMemberStore, propertyService PropertyService, api *pluginapi.Client) ViewService {
-	return &viewService{
-		store:           store,
-		memberStore:     memberStore,
-		propertyService: propertyService,
-		api:             api,
+func NewViewService(store ViewStore, memberStore ViewMemberStore, propertyService PropertyService, propertyFieldService PropertyFieldService, api *pluginapi.Client) ViewService {
+	vs := &viewService{
+		store:                store,
+		memberStore:          memberStore,
+		propertyService:      propertyService,
+		propertyFieldService: propertyFieldService,
+		api:                  api,
 	}
+
+	propertyService.RegisterValidator(vs.validateDateRange)
+
+	return vs
 }
 
 func (vs *viewService) Create(view View) (string, error) {
@@ -28,12 +38,28 @@ func (vs *viewService) Create(view View) (string, error) {
 		return "", errors.New("Title should not be blank")
 	}
 
-	if view.Type != ViewTypeList && view.Type != ViewTypeKanban {
-		return "", errors.New("Type must be 'list' or 'kanban")
+	if view.Type != ViewTypeList && view.Type != ViewTypeKanban && view.Type != ViewTypeChart && view.Type != ViewTypeTable && view.Type != ViewTypeCalendar && view.Type != ViewTypeTimeline {
+		return "", errors.New("Type must be 'list', 'kanban', 'chart', 'table', 'calendar' or 'timeline'")
+	}
+
+	if err := vs.validateFormat(view.Type, view.Format); err != nil {
+		return "", err
+	}
+
+	if len(view.Query.Excludes) == 0 && len(view.Query.Includes) == 0 && len(view.Query.Conditions) == 0 && view.Query.ChannelID == "" {
+		return "", errors.New("Query must have Includes, Excludes, Conditions or ChannelID set")
+	}
+
+	if view.Query.ObjectType != "" && view.Query.ObjectType != PropertyObjectTypePost && view.Query.ObjectType != PropertyObjectTypeFile {
+		return "", errors.New("Query ObjectType must be 'post' or 'file'")
 	}
 
-	if len(view.Query.Excludes) == 0 && len(view.Query.Includes) == 0 && view.Query.ChannelID == "" {
-		return "", errors.New("Query must have Includes, Excludes or ChannelID set")
+	if view.Query.Threads && view.Query.ObjectType == PropertyObjectTypeFile {
+		return "", errors.New("Query Threads is only supported for posts")
+	}
+
+	if err := vs.validateQuery(view.Query); err != nil {
+		return "", err
 	}
 
 	id, err := vs.store.Create(view)
@@ -44,15 +70,97 @@ func (vs *viewService) Create(view View) (string, error) {
 	return id, nil
 }
 
+func (vs *viewService) Get(id string) (View, error) {
+	return vs.store.Get(id)
+}
+
 func (vs *viewService) GetObjectsForView(id string, page int, perPage int) (Objects, error) {
 	view, err := vs.store.Get(id)
 	if err != nil {
 		return Objects{}, errors.Wrap(err, "could not get view")
 	}
 
+	objects, err := vs.getObjectsForView(view, page, perPage)
+	if err != nil {
+		return Objects{}, err
+	}
+
+	if view.Type == ViewTypeKanban && hasWIPLimits(view.Format) {
+		columns, countErr := vs.store.Aggregate(view.Query, AggregateOptions{GroupByFieldIDs: []string{view.Format.GroupByFieldID}})
+		if countErr != nil {
+			return Objects{}, errors.Wrap(countErr, "could not count columns")
+		}
+		objects.OverLimitValues = overLimitValues(view.Format.WIPLimits, columns)
+	}
+
+	return objects, nil
+}
+
+// visibleFieldIDs returns the fields whose properties a view shows, the fields of the columns of
+// tables, or nil when it shows all of them.
+func visibleFieldIDs(view View) []string {
+	if view.Type != ViewTypeTable || len(view.Format.Columns) == 0 {
+		return nil
+	}
+
+	fieldIDs := make([]string, len(view.Format.Columns))
+	for i, column := range view.Format.Columns {
+		fieldIDs[i] = column.FieldID
+	}
+	return fieldIDs
+}
+
+// columnProperties returns the properties of the given fields, all of them when nil.
+func columnProperties(fieldIDs []string, properties PropertiesList) PropertiesList {
+	if fieldIDs == nil {
+		return properties
+	}
+
+	filtered := PropertiesList{}
+	for _, property := range properties {
+		if slices.Contains(fieldIDs, property.PropertyFieldID) {
+			filtered = append(filtered, property)
+		}
+	}
+
+	return filtered
+}
+
+// getPosts gets the posts with the given ids, in the same order, skipping the ones deleted since
+// they were queried.
+//
+// TODO: batch these
+func (vs *viewService) getPosts(ids []string) ([]*model.Post, error) {
+	posts := make([]*model.Post, 0, len(ids))
+	for _, id := range ids {
+		post, err := vs.api.Post.GetPost(id)
+		if errors.Is(err, pluginapi.ErrNotFound) {
+			continue
+		} else if err != nil {
+			return nil, errors.Wrapf(err, "could not get post with id '%s'", id)
+		}
+
+		if post.DeleteAt != 0 {
+			continue
+		}
+		posts = append(posts, post)
+	}
+
+	return posts, nil
+}
+
+func (vs *viewService) getObjectsForView(view View, page int, perPage int) (Objects, error) {
+	if view.Query.ObjectType == PropertyObjectTypeFile {
+		return vs.getFilesForView(view, page, perPage)
+	}
+
+	if view.Query.Threads {
+		return vs.getThreadsForView(view, page, perPage)
+	}
+
 	var posts []*model.Post
 
-	if view.Query.ChannelID != "" && len(view.Query.Excludes) == 0 && len(view.Query.Includes) == 0 {
+	if view.Query.ChannelID != "" && len(view.Query.Excludes) == 0 && len(view.Query.Includes) == 0 && len(view.Query.Conditions) == 0 {
 		postList, err := vs.api.Post.GetPostsForChannel(view.Query.ChannelID, page, perPage)
 		if err != nil {
 			return Objects{}, errors.Wrapf(err, "could not query objects for channel_id=%s", view.Query.ChannelID)
@@ -67,32 +175,247 @@ func (vs *viewService) GetObjectsForView(id string, page int, perPage int) (Obje
 			}
 		}
 	} else {
-		ids, err := vs.store.QueryObjects(view.Query, page, perPage)
+		ids, err := vs.store.QueryObjects(view.Query, view.Format.Columns, page, perPage)
 		if err != nil {
 			return Objects{}, errors.Wrap(err, "could not query objects")
 		}
 
-		posts, err = vs.api.Post.GetPostsById(ids)
+		posts, err = vs.getPosts(ids)
+		if err != nil {
+			return Objects{}, err
+		}
+	}
+
+	objects := Objects{Posts: posts, Files: []*model.FileInfo{}, Properties: map[string]PropertiesList{}}
+
+	// Tables only load the properties of their columns
+	properties, err := vs.propertyService.GetForPosts(posts, visibleFieldIDs(view))
+	if err != nil {
+		return Objects{}, errors.Wrap(err, "could not get properties for objects")
+	}
+	for postID, postProperties := range properties {
+		objects.Properties[postID] = postProperties
+	}
+
+	return objects, nil
+}
+
+func (vs *viewService) FilterObjectsInView(id string, objectIDs []string) ([]string, error) {
+	view, err := vs.store.Get(id)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get view")
+	}
+
+	return vs.store.FilterObjects(view.Query, objectIDs)
+}
+
+func (vs *viewService) AggregateView(id string, options AggregateOptions) (Aggregation, error) {
+	view, err := vs.store.Get(id)
+	if err != nil {
+		return Aggregation{}, errors.Wrap(err, "could not get view")
+	}
+
+	if len(options.GroupByFieldIDs) == 0 && view.Format.GroupByFieldID != "" {
+		options.GroupByFieldIDs = []string{view.Format.GroupByFieldID}
+	}
+
+	if len(options.GroupByFieldIDs) > MaxAggregateGroupByFields {
+		return Aggregation{}, errors.Errorf("Objects can be grouped by at most %d fields", MaxAggregateGroupByFields)
+	}
+
+	for _, fieldID := range options.GroupByFieldIDs {
+		if _, err = vs.propertyFieldService.Get(fieldID); err != nil {
+			return Aggregation{}, errors.Wrapf(err, "could not get group by field '%s'", fieldID)
+		}
+	}
+
+	for _, fieldID := range options.NumberFieldIDs {
+		field, fieldErr := vs.propertyFieldService.Get(fieldID)
+		if fieldErr != nil {
+			return Aggregation{}, errors.Wrapf(fieldErr, "could not get number field '%s'", fieldID)
+		}
+		if field.Type != PropertyFieldTypeNumber {
+			return Aggregation{}, errors.Errorf("Field '%s' should be of type '%s'", field.Name, PropertyFieldTypeNumber)
+		}
+	}
+
+	return vs.store.Aggregate(view.Query, options)
+}
+
+func (vs *viewService) getThreadsForView(view View, page int, perPage int) (Objects, error) {
+	var rootPosts []*model.Post
+
+	if view.Query.ChannelID != "" && len(view.Query.Excludes) == 0 && len(view.Query.Includes) == 0 && len(view.Query.Conditions) == 0 {
+		postList, err := vs.api.Post.GetPostsForChannel(view.Query.ChannelID, page, perPage)
+		if err != nil {
+			return Objects{}, errors.Wrapf(err, "could not query objects for channel_id=%s", view.Query.ChannelID)
+		}
+
+		rootPosts = []*model.Post{}
+		for _, post := range postList.ToSlice() {
+			if !post.IsSystemMessage() && post.RootId == "" {
+				rootPosts = append(rootPosts, post)
+			}
+		}
+	} else {
+		ids, err := vs.store.QueryThreadRoots(view.Query, page, perPage)
+		if err != nil {
+			return Objects{}, errors.Wrap(err, "could not query threads")
+		}
+
+		rootPosts, err = vs.getPosts(ids)
 		if err != nil {
-			//TODO: handle not found better
-			return Objects{}, errors.Wrap(err, "could not get posts")
+			return Objects{}, err
 		}
 	}
 
-	objects := Objects{Posts: posts, Properties: map[string]PropertiesList{}}
+	objects := Objects{
+		Posts:       rootPosts,
+		Files:       []*model.FileInfo{},
+		Properties:  map[string]PropertiesList{},
+		ReplyCounts: map[string]int{},
+	}
 
 	//TODO: batch these
-	for _, post := range posts {
-		properties, err := vs.propertyService.GetForObject(post.Id)
+	for _, rootPost := range rootPosts {
+		thread, err := vs.api.Post.GetPostThread(rootPost.Id)
+		if err != nil {
+			return Objects{}, errors.Wrapf(err, "could not get thread for post_id=%s", rootPost.Id)
+		}
+
+		// Collect the root post's properties first so they take precedence in the rollup
+		properties, err := vs.propertyService.GetForObject(rootPost.Id)
 		if err != nil && !errors.Is(err, ErrNotFound) {
 			return Objects{}, errors.Wrap(err, "could not get properties for object")
 		}
-		objects.Properties[post.Id] = properties
+
+		replyCount := 0
+		for _, post := range thread.ToSlice() {
+			if post.Id == rootPost.Id {
+				continue
+			}
+			replyCount++
+
+			replyProperties, replyErr := vs.propertyService.GetForObject(post.Id)
+			if replyErr != nil && !errors.Is(replyErr, ErrNotFound) {
+				return Objects{}, errors.Wrap(replyErr, "could not get properties for object")
+			}
+			properties = append(properties, replyProperties...)
+		}
+
+		objects.ReplyCounts[rootPost.Id] = replyCount
+		objects.Properties[rootPost.Id] = columnProperties(visibleFieldIDs(view), rollupThreadProperties(properties))
 	}
 
 	return objects, nil
 }
 
+// rollupThreadProperties merges the properties of all posts in a thread into one property
+// per field, whose value is the union of the values found across the thread.
+func rollupThreadProperties(properties []Property) PropertiesList {
+	rollup := PropertiesList{}
+	indexByField := map[string]int{}
+	seenValues := map[string]map[string]bool{}
+
+	for _, property := range properties {
+		index, ok := indexByField[property.PropertyFieldID]
+		if !ok {
+			index = len(rollup)
+			indexByField[property.PropertyFieldID] = index
+			seenValues[property.PropertyFieldID] = map[string]bool{}
+
+			merged := property
+			merged.Value = []interface{}{}
+			rollup = append(rollup, merged)
+		}
+
+		for _, value := range property.Value {
+			key := fmt.Sprint(value)
+			if seenValues[property.PropertyFieldID][key] {
+				continue
+			}
+			seenValues[property.PropertyFieldID][key] = true
+			rollup[index].Value = append(rollup[index].Value, value)
+		}
+	}
+
+	return rollup
+}
+
+func (vs *viewService) getFilesForView(view View, page int, perPage int) (Objects, error) {
+	var fileIDs []string
+
+	if view.Query.ChannelID != "" && len(view.Query.Excludes) == 0 && len(view.Query.Includes) == 0 && len(view.Query.Conditions) == 0 {
+		postList, err := vs.api.Post.GetPostsForChannel(view.Query.ChannelID, page, perPage)
+		if err != nil {
+			return Objects{}, errors.Wrapf(err, "could not query objects for channel_id=%s", view.Query.ChannelID)
+		}
+
+		for _, post := range postList.ToSlice() {
+			fileIDs = append(fileIDs, post.FileIds...)
+		}
+	} else {
+		ids, err := vs.store.QueryObjects(view.Query, view.Format.Columns, page, perPage)
+		if err != nil {
+			return Objects{}, errors.Wrap(err, "could not query objects")
+		}
+		fileIDs = ids
+	}
+
+	objects := Objects{Posts: []*model.Post{}, Files: []*model.FileInfo{}, Properties: map[string]PropertiesList{}}
+	existingIDs := make([]string, 0, len(fileIDs))
+
+	//TODO: batch these
+	for _, fileID := range fileIDs {
+		fileInfo, err := vs.api.File.GetInfo(fileID)
+		if err != nil {
+			return Objects{}, errors.Wrapf(err, "could not get file info for file_id=%s", fileID)
+		}
+
+		if fileInfo.DeleteAt != 0 {
+			continue
+		}
+
+		objects.Files = append(objects.Files, fileInfo)
+		objects.Properties[fileInfo.Id] = PropertiesList{}
+		existingIDs = append(existingIDs, fileInfo.Id)
+	}
+
+	// Tables only load the properties of their columns
+	properties, err := vs.propertyService.GetForObjects(existingIDs, visibleFieldIDs(view))
+	if err != nil {
+		return Objects{}, errors.Wrap(err, "could not get properties for objects")
+	}
+	for _, property := range properties {
+		objects.Properties[property.ObjectID] = append(objects.Properties[property.ObjectID], property)
+	}
+
+	return objects, nil
+}
+
+// TODO: batch these
+func (vs *viewService) addPropertiesForObject(objects Objects, objectID string) error {
+	properties, err := vs.propertyService.GetForObject(objectID)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return errors.Wrap(err, "could not get properties for object")
+	}
+	objects.Properties[objectID] = properties
+
+	return nil
+}
+
+// addPropertiesForPost is addPropertiesForObject for posts, adding the properties inherited from
+// the root of their thread.
+func (vs *viewService) addPropertiesForPost(objects Objects, post *model.Post) error {
+	properties, err := vs.propertyService.GetForPost(post)
+	if err != nil {
+		return errors.Wrap(err, "could not get properties for object")
+	}
+	objects.Properties[post.Id] = properties
+
+	return nil
+}
+
 func (vs *viewService) AddUserToView(userID string, viewID string) error {
 	return vs.memberStore.Create(ViewMember{UserID: userID, ViewID: viewID})
 }
@@ -102,5 +425,339 @@ func (vs *viewService) GetForUser(userID string) ([]View, error) {
 }
 
 func (vs *viewService) Update(id string, title *string, query *Query, format *Format) error {
+	if query != nil {
+		if err := vs.validateQuery(*query); err != nil {
+			return err
+		}
+	}
+
+	if format != nil {
+		view, err := vs.store.Get(id)
+		if err != nil {
+			return errors.Wrap(err, "could not get view")
+		}
+
+		if err = vs.validateFormat(view.Type, *format); err != nil {
+			return err
+		}
+	}
+
 	return vs.store.Update(id, title, query, format)
 }
+
+// validateQuery checks that the options of channel and team fields filtered on, such as "channel
+// field is one of", are existing channels and teams.
+func (vs *viewService) validateQuery(query Query) error {
+	for _, filters := range []map[string][]string{query.Includes, query.Excludes} {
+		for fieldID, options := range filters {
+			if len(options) == 0 {
+				continue
+			}
+
+			field, err := vs.propertyFieldService.Get(fieldID)
+			if errors.Is(err, ErrNotFound) {
+				continue
+			} else if err != nil {
+				return errors.Wrapf(err, "could not get query field '%s'", fieldID)
+			}
+			if !isReferenceField(field.Type) {
+				continue
+			}
+
+			for _, option := range options {
+				if err = referenceExists(vs.api, field, option); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	for _, condition := range query.Conditions {
+		if err := vs.validateCondition(condition); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// validateCondition checks that the type of the field of a condition supports its operator.
+func (vs *viewService) validateCondition(condition Condition) error {
+	field, err := vs.propertyFieldService.Get(condition.FieldID)
+	if err != nil {
+		return errors.Wrapf(err, "could not get condition field '%s'", condition.FieldID)
+	}
+
+	fieldType, ok := GetFieldType(field.Type)
+	if !ok || !slices.Contains(fieldType.Operators(), condition.Operator) {
+		return errors.Errorf("Operator '%s' is not supported by field '%s'", condition.Operator, field.Name)
+	}
+
+	switch condition.Operator {
+	case OperatorDomainEquals:
+		if condition.Value == "" {
+			return errors.Errorf("Domain of condition on field '%s' should not be blank", field.Name)
+		}
+	case OperatorGreaterOrEqual, OperatorLessOrEqual:
+		if _, ok = numberValue(condition.Value); !ok {
+			return errors.Errorf("Value of condition on field '%s' should be a number", field.Name)
+		}
+	}
+
+	return nil
+}
+
+// validateFormat checks the parts of the format used by the type of view.
+func (vs *viewService) validateFormat(viewType string, format Format) error {
+	switch viewType {
+	case ViewTypeKanban:
+		return vs.validateKanban(format)
+	case ViewTypeChart:
+		return vs.validateChart(format.Chart)
+	case ViewTypeTable:
+		return vs.validateColumns(format.Columns)
+	case ViewTypeCalendar:
+		return vs.validateCalendar(format.Calendar)
+	case ViewTypeTimeline:
+		return vs.validateTimeline(format.Timeline)
+	}
+
+	return nil
+}
+
+func (vs *viewService) validateColumns(columns []TableColumn) error {
+	seen := map[string]bool{}
+	for _, column := range columns {
+		if column.FieldID == "" {
+			return errors.New("Column FieldID should not be blank")
+		}
+		if seen[column.FieldID] {
+			return errors.Errorf("Field '%s' should only have one column", column.FieldID)
+		}
+		seen[column.FieldID] = true
+
+		if _, err := vs.propertyFieldService.Get(column.FieldID); err != nil {
+			return errors.Wrapf(err, "could not get column field '%s'", column.FieldID)
+		}
+
+		if column.Width < 0 {
+			return errors.New("Column Width should not be negative")
+		}
+
+		if column.Sort != "" && column.Sort != SortAscending && column.Sort != SortDescending {
+			return errors.Errorf("Column Sort should be '%s' or '%s'", SortAscending, SortDescending)
+		}
+	}
+
+	return nil
+}
+
+func (vs *viewService) validateKanban(format Format) error {
+	for value, limit := range format.WIPLimits {
+		if limit < 0 {
+			return errors.Errorf("WIP limit of column '%s' should not be negative", value)
+		}
+	}
+
+	if format.SwimlaneFieldID == "" {
+		return nil
+	}
+
+	if format.SwimlaneFieldID == format.GroupByFieldID {
+		return errors.New("Format SwimlaneFieldID should differ from GroupByFieldID")
+	}
+
+	if _, err := vs.propertyFieldService.Get(format.SwimlaneFieldID); err != nil {
+		return errors.Wrapf(err, "could not get swimlane field '%s'", format.SwimlaneFieldID)
+	}
+
+	return nil
+}
+
+func (vs *viewService) validateCalendar(calendar *CalendarFormat) error {
+	if calendar == nil {
+		return errors.New("Format Calendar should be set for calendar views")
+	}
+
+	if calendar.StartFieldID == "" {
+		return errors.New("Calendar StartFieldID should not be blank")
+	}
+
+	for _, fieldID := range []string{calendar.StartFieldID, calendar.EndFieldID} {
+		if fieldID == "" {
+			continue
+		}
+		field, err := vs.propertyFieldService.Get(fieldID)
+		if err != nil {
+			return errors.Wrapf(err, "could not get calendar field '%s'", fieldID)
+		}
+		if field.Type != PropertyFieldTypeDate {
+			return errors.Errorf("Calendar fields should be of type '%s'", PropertyFieldTypeDate)
+		}
+	}
+
+	if calendar.EndFieldID == calendar.StartFieldID {
+		return errors.New("Calendar EndFieldID should differ from StartFieldID")
+	}
+
+	return nil
+}
+
+func (vs *viewService) validateTimeline(timeline *TimelineFormat) error {
+	if timeline == nil {
+		return errors.New("Format Timeline should be set for timeline views")
+	}
+
+	if timeline.StartFieldID == "" || timeline.EndFieldID == "" {
+		return errors.New("Timeline StartFieldID and EndFieldID should not be blank")
+	}
+
+	if timeline.StartFieldID == timeline.EndFieldID {
+		return errors.New("Timeline EndFieldID should differ from StartFieldID")
+	}
+
+	for _, fieldID := range []string{timeline.StartFieldID, timeline.EndFieldID} {
+		field, err := vs.propertyFieldService.Get(fieldID)
+		if err != nil {
+			return errors.Wrapf(err, "could not get timeline field '%s'", fieldID)
+		}
+		if field.Type != PropertyFieldTypeDate {
+			return errors.Errorf("Timeline date fields should be of type '%s'", PropertyFieldTypeDate)
+		}
+	}
+
+	if timeline.DependencyFieldID != "" {
+		field, err := vs.propertyFieldService.Get(timeline.DependencyFieldID)
+		if err != nil {
+			return errors.Wrapf(err, "could not get dependency field '%s'", timeline.DependencyFieldID)
+		}
+		if field.Type != PropertyFieldTypeRelation && field.Type != PropertyFieldTypeText {
+			return errors.Errorf("Timeline DependencyFieldID should be a field of type '%s' or '%s'", PropertyFieldTypeRelation, PropertyFieldTypeText)
+		}
+	}
+
+	return nil
+}
+
+func (vs *viewService) validateChart(chart *ChartFormat) error {
+	if chart == nil {
+		return errors.New("Format Chart should be set for chart views")
+	}
+
+	if chart.Kind != ChartKindBar && chart.Kind != ChartKindPie && chart.Kind != ChartKindLine {
+		return errors.Errorf("Unknown chart kind '%s'", chart.Kind)
+	}
+
+	if chart.DimensionFieldID == "" {
+		return errors.New("Chart DimensionFieldID should not be blank")
+	}
+	if _, err := vs.propertyFieldService.Get(chart.DimensionFieldID); err != nil {
+		return errors.Wrapf(err, "could not get dimension field '%s'", chart.DimensionFieldID)
+	}
+
+	if chart.SeriesFieldID != "" {
+		if chart.Kind != ChartKindBar {
+			return errors.New("Chart SeriesFieldID is only supported by bar charts")
+		}
+		if _, err := vs.propertyFieldService.Get(chart.SeriesFieldID); err != nil {
+			return errors.Wrapf(err, "could not get series field '%s'", chart.SeriesFieldID)
+		}
+	}
+
+	switch chart.Measure {
+	case "", ChartMeasureCount:
+	case ChartMeasureSum, ChartMeasureAvg, ChartMeasureMin, ChartMeasureMax:
+		if chart.Kind == ChartKindLine {
+			return errors.New("Line charts can only plot counts")
+		}
+		field, err := vs.propertyFieldService.Get(chart.MeasureFieldID)
+		if err != nil {
+			return errors.Wrapf(err, "could not get measure field '%s'", chart.MeasureFieldID)
+		}
+		if field.Type != PropertyFieldTypeNumber {
+			return errors.Errorf("Chart MeasureFieldID should be a field of type '%s'", PropertyFieldTypeNumber)
+		}
+	default:
+		return errors.Errorf("Unknown chart measure '%s'", chart.Measure)
+	}
+
+	if chart.Days < 0 || chart.Days > maxChartDays {
+		return errors.Errorf("Chart Days should be between 0 and %d", maxChartDays)
+	}
+
+	return nil
+}
+
+func (vs *viewService) GetChart(id string) (ChartData, error) {
+	view, err := vs.store.Get(id)
+	if err != nil {
+		return ChartData{}, errors.Wrap(err, "could not get view")
+	}
+
+	if view.Type != ViewTypeChart || view.Format.Chart == nil {
+		return ChartData{}, errors.Errorf("View '%s' is not a chart", view.Title)
+	}
+	chart := *view.Format.Chart
+
+	dimension, err := vs.propertyFieldService.Get(chart.DimensionFieldID)
+	if err != nil {
+		return ChartData{}, errors.Wrapf(err, "could not get dimension field '%s'", chart.DimensionFieldID)
+	}
+
+	if chart.Kind == ChartKindLine {
+		return vs.getChartOverTime(view, chart, dimension)
+	}
+
+	options := AggregateOptions{GroupByFieldIDs: []string{chart.DimensionFieldID}}
+	var seriesOptions []interface{}
+	if chart.SeriesFieldID != "" {
+		options.GroupByFieldIDs = append(options.GroupByFieldIDs, chart.SeriesFieldID)
+		series, fieldErr := vs.propertyFieldService.Get(chart.SeriesFieldID)
+		if fieldErr != nil {
+			return ChartData{}, errors.Wrapf(fieldErr, "could not get series field '%s'", chart.SeriesFieldID)
+		}
+		seriesOptions = series.Values
+	}
+	if chart.Measure != "" && chart.Measure != ChartMeasureCount {
+		options.NumberFieldIDs = []string{chart.MeasureFieldID}
+	}
+
+	aggregation, err := vs.store.Aggregate(view.Query, options)
+	if err != nil {
+		return ChartData{}, err
+	}
+
+	return chartFromAggregation(chart, dimension.Values, seriesOptions, aggregation), nil
+}
+
+// getChartOverTime plots the history of the dimension field for the objects currently in the view.
+func (vs *viewService) getChartOverTime(view View, chart ChartFormat, dimension PropertyField) (ChartData, error) {
+	start, days := chartDays(chart, time.Now())
+	until := start.AddDate(0, 0, days).UnixMilli() - 1
+
+	history, err := vs.propertyService.GetHistoryForField(dimension.ID, start.UnixMilli(), until)
+	if err != nil {
+		return ChartData{}, err
+	}
+
+	objectIDs := []string{}
+	seen := map[string]bool{}
+	for _, entry := range history {
+		if !seen[entry.ObjectID] {
+			seen[entry.ObjectID] = true
+			objectIDs = append(objectIDs, entry.ObjectID)
+		}
+	}
+
+	inView, err := vs.store.FilterObjects(view.Query, objectIDs)
+	if err != nil {
+		return ChartData{}, errors.Wrap(err, "could not filter objects of view")
+	}
+
+	objects := make(map[string]bool, len(inView))
+	for _, objectID := range inView {
+		objects[objectID] = true
+	}
+
+	return chartOverTime(chart, dimension.Values, history, objects, start, days), nil
+}
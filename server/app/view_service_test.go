@@ -0,0 +1,465 @@
+package app
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/plugin/plugintest"
+	"github.com/mattermost/mattermost/server/public/pluginapi"
+)
+
+// fakePropertyService returns the properties of the objects it's given and records the values
+// set, other methods panic.
+type fakePropertyService struct {
+	PropertyService
+	properties map[string][]Property
+	updates    []Property
+	created    []Property
+	deleted    []string
+	computed   []Property
+	fieldIDs   []string
+
+	history      []PropertyHistoryEntry
+	historySince int64
+	historyUntil int64
+}
+
+func (s *fakePropertyService) GetHistoryForField(_ string, since int64, until int64) ([]PropertyHistoryEntry, error) {
+	s.historySince = since
+	s.historyUntil = until
+	return s.history, nil
+}
+
+func (s *fakePropertyService) Create(property Property) (string, error) {
+	property.ID = model.NewId()
+	s.created = append(s.created, property)
+	return property.ID, nil
+}
+
+func (s *fakePropertyService) Delete(id string) error {
+	s.deleted = append(s.deleted, id)
+	return nil
+}
+
+func (s *fakePropertyService) UpdateValue(id string, value []interface{}) error {
+	s.updates = append(s.updates, Property{ID: id, Value: value})
+	return nil
+}
+
+func (s *fakePropertyService) SetComputedValue(object PropertyObject, fieldID string, value []interface{}) error {
+	s.computed = append(s.computed, Property{ObjectID: object.ObjectID, PropertyFieldID: fieldID, Value: value})
+	return nil
+}
+
+func (s *fakePropertyService) GetForObject(objectID string) ([]Property, error) {
+	properties, ok := s.properties[objectID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return properties, nil
+}
+
+func (s *fakePropertyService) GetProperties(filter PropertyFilterOptions) ([]Property, error) {
+	filtered := []Property{}
+	for _, properties := range s.properties {
+		for _, property := range properties {
+			if property.PropertyFieldID == filter.PropertyFieldID &&
+				(filter.TeamID == "" || property.TeamID == filter.TeamID) &&
+				(len(filter.ObjectIDs) == 0 || slices.Contains(filter.ObjectIDs, property.ObjectID)) {
+				filtered = append(filtered, property)
+			}
+		}
+	}
+	if filter.PerPage == 0 {
+		return filtered, nil
+	}
+
+	slices.SortFunc(filtered, func(a, b Property) int { return strings.Compare(a.ID, b.ID) })
+	start := min(filter.Page*filter.PerPage, len(filtered))
+	end := min(start+filter.PerPage, len(filtered))
+	return filtered[start:end], nil
+}
+
+func (s *fakePropertyService) GetReferencing(objectID string) ([]Property, error) {
+	referencing := []Property{}
+	for _, properties := range s.properties {
+		for _, property := range properties {
+			if slices.Contains(property.Value, interface{}(objectID)) {
+				referencing = append(referencing, property)
+			}
+		}
+	}
+	return referencing, nil
+}
+
+func (s *fakePropertyService) GetForPost(post *model.Post) ([]Property, error) {
+	properties := s.properties[post.Id]
+	if post.RootId != "" {
+		for _, property := range s.properties[post.RootId] {
+			property.Inherited = true
+			properties = append(properties, property)
+		}
+	}
+	return properties, nil
+}
+
+func (s *fakePropertyService) GetForPosts(posts []*model.Post, fieldIDs []string) (map[string][]Property, error) {
+	s.fieldIDs = fieldIDs
+	byPost := map[string][]Property{}
+	for _, post := range posts {
+		properties, _ := s.GetForPost(post)
+		byPost[post.Id] = columnProperties(fieldIDs, properties)
+	}
+	return byPost, nil
+}
+
+// fakePropertyFieldService returns the fields it's given, other methods panic.
+type fakePropertyFieldService struct {
+	PropertyFieldService
+	fields []PropertyField
+}
+
+func (s *fakePropertyFieldService) Get(id string) (PropertyField, error) {
+	for _, field := range s.fields {
+		if field.ID == id {
+			return field, nil
+		}
+	}
+	return PropertyField{}, ErrNotFound
+}
+
+func (s *fakePropertyFieldService) GetFields(filter PropertyFieldFilterOptions) ([]PropertyField, error) {
+	fields := []PropertyField{}
+	for _, field := range s.fields {
+		if len(filter.Types) == 0 || slices.Contains(filter.Types, field.Type) {
+			fields = append(fields, field)
+		}
+	}
+
+	start := min(filter.Page*filter.PerPage, len(fields))
+	end := min(start+filter.PerPage, len(fields))
+	return fields[start:end], nil
+}
+
+// fakeViewStore holds a single view whose query matches the given objects, other methods panic.
+type fakeViewStore struct {
+	ViewStore
+	view           View
+	matching       []string
+	updateFormatAt int64
+	updateErr      error
+
+	aggregateOptions *AggregateOptions
+}
+
+func (s *fakeViewStore) Get(id string) (View, error) {
+	if id != s.view.ID {
+		return View{}, ErrNotFound
+	}
+	return s.view, nil
+}
+
+func (s *fakeViewStore) GetByType(viewType string) ([]View, error) {
+	if s.view.Type != viewType {
+		return []View{}, nil
+	}
+	return []View{s.view}, nil
+}
+
+func (s *fakeViewStore) FilterObjects(_ Query, objectIDs []string) ([]string, error) {
+	filtered := []string{}
+	for _, id := range objectIDs {
+		if slices.Contains(s.matching, id) {
+			filtered = append(filtered, id)
+		}
+	}
+	return filtered, nil
+}
+
+func (s *fakeViewStore) Aggregate(_ Query, options AggregateOptions) (Aggregation, error) {
+	s.aggregateOptions = &options
+	return Aggregation{GroupByFieldIDs: options.GroupByFieldIDs, Groups: []AggregationGroup{}}, nil
+}
+
+func (s *fakeViewStore) QueryObjects(_ Query, _ []TableColumn, _ int, _ int) ([]string, error) {
+	return s.matching, nil
+}
+
+func (s *fakeViewStore) QueryDateRange(_ Query, _, _ string, _, _ string, limit int) ([]string, error) {
+	return s.matching[:min(limit, len(s.matching))], nil
+}
+
+func (s *fakeViewStore) UpdateFormat(_ string, format Format, _ int64) (int64, error) {
+	if s.updateErr != nil {
+		return 0, s.updateErr
+	}
+	s.view.Format = format
+	return s.updateFormatAt, nil
+}
+
+func TestGetPosts(t *testing.T) {
+	api := &plugintest.API{}
+	defer api.AssertExpectations(t)
+
+	api.On("GetPost", "post1").Return(&model.Post{Id: "post1"}, nil)
+	api.On("GetPost", "post2").Return(&model.Post{Id: "post2"}, nil)
+	api.On("GetPost", "deleted").Return(&model.Post{Id: "deleted", DeleteAt: 1}, nil)
+	api.On("GetPost", "missing").Return(nil, model.NewAppError("GetPost", "app.post.get.app_error", nil, "", http.StatusNotFound))
+	api.On("GetPost", "failing").Return(nil, model.NewAppError("GetPost", "app.post.get.app_error", nil, "", http.StatusInternalServerError))
+
+	vs := &viewService{api: pluginapi.NewClient(api, nil)}
+
+	posts, err := vs.getPosts([]string{"post2", "missing", "deleted", "post1"})
+	require.NoError(t, err)
+	require.Len(t, posts, 2)
+	assert.Equal(t, "post2", posts[0].Id)
+	assert.Equal(t, "post1", posts[1].Id)
+
+	_, err = vs.getPosts([]string{"post1", "failing"})
+	assert.Error(t, err)
+}
+
+func TestGetObjectsForView(t *testing.T) {
+	status := Property{PropertyFieldID: "status", Value: []interface{}{"Open"}}
+	owner := Property{PropertyFieldID: "owner", Value: []interface{}{"user1"}}
+
+	cases := []struct {
+		Name             string
+		View             View
+		ExpectedFieldIDs []string
+		Expected         PropertiesList
+	}{
+		{
+			Name: "table loads the properties of its columns",
+			View: View{ID: "view1", Type: ViewTypeTable, Query: Query{TeamID: "team1"},
+				Format: Format{Columns: []TableColumn{{FieldID: "owner"}}}},
+			ExpectedFieldIDs: []string{"owner"},
+			Expected:         PropertiesList{owner},
+		},
+		{
+			Name:             "table without columns loads all properties",
+			View:             View{ID: "view1", Type: ViewTypeTable, Query: Query{TeamID: "team1"}},
+			ExpectedFieldIDs: nil,
+			Expected:         PropertiesList{status, owner},
+		},
+		{
+			Name: "kanban loads all properties",
+			View: View{ID: "view1", Type: ViewTypeKanban, Query: Query{TeamID: "team1"},
+				Format: Format{Columns: []TableColumn{{FieldID: "owner"}}}},
+			ExpectedFieldIDs: nil,
+			Expected:         PropertiesList{status, owner},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			api := &plugintest.API{}
+			defer api.AssertExpectations(t)
+			api.On("GetPost", "post1").Return(&model.Post{Id: "post1"}, nil)
+
+			propertyService := &fakePropertyService{properties: map[string][]Property{"post1": {status, owner}}}
+			vs := &viewService{
+				api:             pluginapi.NewClient(api, nil),
+				store:           &fakeViewStore{view: c.View, matching: []string{"post1"}},
+				propertyService: propertyService,
+			}
+
+			objects, err := vs.GetObjectsForView("view1", 0, 10)
+			require.NoError(t, err)
+			require.Len(t, objects.Posts, 1)
+			assert.Equal(t, c.ExpectedFieldIDs, propertyService.fieldIDs)
+			assert.Equal(t, c.Expected, objects.Properties["post1"])
+		})
+	}
+}
+
+func TestAddProperties(t *testing.T) {
+	status := Property{PropertyFieldID: "status", Value: []interface{}{"Open"}}
+	owner := Property{PropertyFieldID: "owner", Value: []interface{}{"user1"}}
+	vs := &viewService{propertyService: &fakePropertyService{properties: map[string][]Property{
+		"root": {status},
+		"file": {owner},
+	}}}
+
+	objects := Objects{Properties: map[string]PropertiesList{}}
+	require.NoError(t, vs.addPropertiesForObject(objects, "file"))
+	require.NoError(t, vs.addPropertiesForObject(objects, "unset"))
+	require.NoError(t, vs.addPropertiesForPost(objects, &model.Post{Id: "reply", RootId: "root"}))
+
+	assert.Equal(t, PropertiesList{owner}, objects.Properties["file"])
+	assert.Empty(t, objects.Properties["unset"])
+	require.Len(t, objects.Properties["reply"], 1)
+	assert.True(t, objects.Properties["reply"][0].Inherited)
+}
+
+func TestRollupThreadProperties(t *testing.T) {
+	cases := []struct {
+		Name       string
+		Properties []Property
+		Expected   PropertiesList
+	}{
+		{
+			Name:       "no properties",
+			Properties: nil,
+			Expected:   PropertiesList{},
+		},
+		{
+			Name: "values merged per field",
+			Properties: []Property{
+				{ID: "root-status", PropertyFieldID: "status", Value: []interface{}{"Open"}},
+				{ID: "reply-status", PropertyFieldID: "status", Value: []interface{}{"Blocked", "Open"}},
+				{ID: "reply-owner", PropertyFieldID: "owner", Value: []interface{}{"user1"}},
+			},
+			Expected: PropertiesList{
+				{ID: "root-status", PropertyFieldID: "status", Value: []interface{}{"Open", "Blocked"}},
+				{ID: "reply-owner", PropertyFieldID: "owner", Value: []interface{}{"user1"}},
+			},
+		},
+		{
+			Name: "duplicate numbers dropped",
+			Properties: []Property{
+				{ID: "root-points", PropertyFieldID: "points", Value: []interface{}{float64(3)}},
+				{ID: "reply-points", PropertyFieldID: "points", Value: []interface{}{float64(3), float64(5)}},
+			},
+			Expected: PropertiesList{
+				{ID: "root-points", PropertyFieldID: "points", Value: []interface{}{float64(3), float64(5)}},
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			assert.Equal(t, c.Expected, rollupThreadProperties(c.Properties))
+		})
+	}
+}
+
+func TestValidateQuery(t *testing.T) {
+	api := &plugintest.API{}
+	api.On("GetChannel", "channel1").Return(&model.Channel{Id: "channel1"}, nil)
+	api.On("GetChannel", "missing").Return(nil, model.NewAppError("GetChannel", "app.channel.get.existing.app_error", nil, "", http.StatusNotFound))
+	api.On("GetTeam", "missing").Return(nil, model.NewAppError("GetTeam", "app.team.get.find.app_error", nil, "", http.StatusNotFound))
+
+	vs := &viewService{
+		api: pluginapi.NewClient(api, nil),
+		propertyFieldService: &fakePropertyFieldService{fields: []PropertyField{
+			{ID: "channel", Name: "Customer", Type: PropertyFieldTypeChannel},
+			{ID: "team", Name: "Team", Type: PropertyFieldTypeTeam},
+			{ID: "status", Name: "Status", Type: PropertyFieldTypeSelect},
+		}},
+	}
+
+	cases := []struct {
+		Name     string
+		Query    Query
+		Expected string
+	}{
+		{
+			Name:  "channel field is one of existing channels",
+			Query: Query{Includes: map[string][]string{"channel": {"channel1"}}},
+		},
+		{
+			Name:     "channel field is one of a missing channel",
+			Query:    Query{Includes: map[string][]string{"channel": {"channel1", "missing"}}},
+			Expected: "Channel 'missing' referenced by field 'Customer' does not exist",
+		},
+		{
+			Name:     "team field is not a missing team",
+			Query:    Query{Excludes: map[string][]string{"team": {"missing"}}},
+			Expected: "Team 'missing' referenced by field 'Team' does not exist",
+		},
+		{
+			Name:  "other field types",
+			Query: Query{Includes: map[string][]string{"status": {"missing"}}},
+		},
+		{
+			Name:  "unknown fields",
+			Query: Query{Includes: map[string][]string{"unknown": {"missing"}}},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			err := vs.validateQuery(c.Query)
+			if c.Expected == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, c.Expected)
+			}
+		})
+	}
+}
+
+func TestAggregateView(t *testing.T) {
+	fieldService := &fakePropertyFieldService{fields: []PropertyField{
+		{ID: "status", Name: "Status", Type: PropertyFieldTypeSelect},
+		{ID: "owner", Name: "Owner", Type: PropertyFieldTypeUser},
+		{ID: "points", Name: "Points", Type: PropertyFieldTypeNumber},
+	}}
+
+	cases := []struct {
+		Name            string
+		Format          Format
+		Options         AggregateOptions
+		ExpectedOptions *AggregateOptions
+		Expected        string
+	}{
+		{
+			Name:            "group by fields of the options",
+			Format:          Format{GroupByFieldID: "status"},
+			Options:         AggregateOptions{GroupByFieldIDs: []string{"owner", "status"}, NumberFieldIDs: []string{"points"}},
+			ExpectedOptions: &AggregateOptions{GroupByFieldIDs: []string{"owner", "status"}, NumberFieldIDs: []string{"points"}},
+		},
+		{
+			Name:            "group by field of the format",
+			Format:          Format{GroupByFieldID: "status"},
+			Options:         AggregateOptions{NumberFieldIDs: []string{"points"}},
+			ExpectedOptions: &AggregateOptions{GroupByFieldIDs: []string{"status"}, NumberFieldIDs: []string{"points"}},
+		},
+		{
+			Name:            "no group by field",
+			ExpectedOptions: &AggregateOptions{},
+		},
+		{
+			Name:     "too many group by fields",
+			Options:  AggregateOptions{GroupByFieldIDs: []string{"status", "owner", "points"}},
+			Expected: "Objects can be grouped by at most 2 fields",
+		},
+		{
+			Name:     "unknown group by field",
+			Options:  AggregateOptions{GroupByFieldIDs: []string{"unknown"}},
+			Expected: "could not get group by field 'unknown': not found",
+		},
+		{
+			Name:     "unknown number field",
+			Options:  AggregateOptions{NumberFieldIDs: []string{"unknown"}},
+			Expected: "could not get number field 'unknown': not found",
+		},
+		{
+			Name:     "number field of another type",
+			Options:  AggregateOptions{NumberFieldIDs: []string{"status"}},
+			Expected: "Field 'Status' should be of type 'number'",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			store := &fakeViewStore{view: View{ID: "view1", Type: ViewTypeKanban, Format: c.Format}}
+			vs := &viewService{store: store, propertyFieldService: fieldService}
+
+			_, err := vs.AggregateView("view1", c.Options)
+			if c.Expected != "" {
+				assert.EqualError(t, err, c.Expected)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, c.ExpectedOptions, store.aggregateOptions)
+		})
+	}
+}
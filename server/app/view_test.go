@@ -0,0 +1,128 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueryMatches(t *testing.T) {
+	values := map[string][]interface{}{
+		"priority": {"urgent"},
+		"tags":     {"bug", "ui"},
+		"estimate": {float64(3)},
+		"fixed_in": {"release"},
+	}
+
+	cases := []struct {
+		Name       string
+		Query      Query
+		ObjectType string
+		Values     map[string][]interface{}
+		Types      map[string]string
+		Expected   bool
+	}{
+		{
+			Name:     "includes an option",
+			Query:    Query{Includes: map[string][]string{"priority": {"urgent"}}},
+			Values:   values,
+			Expected: true,
+		},
+		{
+			Name:     "includes any of the options",
+			Query:    Query{Includes: map[string][]string{"tags": {"backend", "ui"}}},
+			Values:   values,
+			Expected: true,
+		},
+		{
+			Name:     "includes a missing option",
+			Query:    Query{Includes: map[string][]string{"priority": {"low"}}},
+			Values:   values,
+			Expected: false,
+		},
+		{
+			Name:     "includes a field being set",
+			Query:    Query{Includes: map[string][]string{"tags": {}}},
+			Values:   values,
+			Expected: true,
+		},
+		{
+			Name:     "includes an unset field",
+			Query:    Query{Includes: map[string][]string{"owner": {}}},
+			Values:   values,
+			Expected: false,
+		},
+		{
+			Name:     "excludes an option",
+			Query:    Query{Excludes: map[string][]string{"tags": {"bug"}}},
+			Values:   values,
+			Expected: false,
+		},
+		{
+			Name:     "excludes an unset field",
+			Query:    Query{Excludes: map[string][]string{"owner": {}}},
+			Values:   values,
+			Expected: true,
+		},
+		{
+			Name:     "non string values never match an option",
+			Query:    Query{Includes: map[string][]string{"estimate": {"3"}}},
+			Values:   values,
+			Expected: false,
+		},
+		{
+			Name:     "related to an object",
+			Query:    Query{RelatedTo: []string{"release"}},
+			Values:   values,
+			Expected: true,
+		},
+		{
+			Name:     "related to all of the objects",
+			Query:    Query{RelatedTo: []string{"release", "other"}},
+			Values:   values,
+			Expected: false,
+		},
+		{
+			Name:     "other channel",
+			Query:    Query{ChannelID: "other", Excludes: map[string][]string{"owner": {}}},
+			Values:   values,
+			Expected: false,
+		},
+		{
+			Name:       "other object type",
+			Query:      Query{Excludes: map[string][]string{"owner": {}}},
+			ObjectType: PropertyObjectTypeFile,
+			Values:     values,
+			Expected:   false,
+		},
+		{
+			Name:     "condition on the type of the field",
+			Query:    Query{Conditions: []Condition{{FieldID: "estimate", Operator: OperatorGreaterOrEqual, Value: "2"}}},
+			Values:   values,
+			Types:    map[string]string{"estimate": PropertyFieldTypeNumber},
+			Expected: true,
+		},
+		{
+			Name:     "condition on a field of unknown type",
+			Query:    Query{Conditions: []Condition{{FieldID: "estimate", Operator: OperatorGreaterOrEqual, Value: "2"}}},
+			Values:   values,
+			Expected: false,
+		},
+		{
+			Name:     "object without properties",
+			Query:    Query{Excludes: map[string][]string{"owner": {}}},
+			Values:   map[string][]interface{}{},
+			Expected: false,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			objectType := c.ObjectType
+			if objectType == "" {
+				objectType = PropertyObjectTypePost
+			}
+			assert.Equal(t, c.Expected, c.Query.Matches(objectType, "channel", "team", c.Values, c.Types))
+		})
+	}
+}
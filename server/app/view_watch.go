@@ -0,0 +1,26 @@
+package app
+
+// ViewWatch subscribes a user to the objects entering or leaving a view.
+type ViewWatch struct {
+	ViewID   string `json:"view_id"`
+	UserID   string `json:"user_id"`
+	CreateAt int64  `json:"create_at"`
+}
+
+type ViewWatchStore interface {
+	// Create adds the watch, doing nothing if the user already watches the view.
+	Create(watch ViewWatch) error
+	GetForUser(userID string) ([]ViewWatch, error)
+	// GetForObject returns the watches of the views whose query may match objects of the team and
+	// channel, the views not filtering on either and the ones filtering on them.
+	GetForObject(teamID string, channelID string) ([]ViewWatch, error)
+	Delete(viewID string, userID string) error
+}
+
+type ViewWatchService interface {
+	Watch(viewID string, userID string) error
+	Unwatch(viewID string, userID string) error
+	GetForUser(userID string) ([]ViewWatch, error)
+	// Close waits for the watches being evaluated.
+	Close()
+}
@@ -0,0 +1,157 @@
+package app
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/pluginapi"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+type viewWatchService struct {
+	store           ViewWatchStore
+	viewService     ViewService
+	propertyService PropertyService
+	api             *pluginapi.Client
+	botID           string
+
+	evaluating sync.WaitGroup
+}
+
+func NewViewWatchService(store ViewWatchStore, viewService ViewService, propertyService PropertyService, api *pluginapi.Client, botID string) ViewWatchService {
+	ws := &viewWatchService{
+		store:           store,
+		viewService:     viewService,
+		propertyService: propertyService,
+		api:             api,
+		botID:           botID,
+	}
+
+	propertyService.RegisterChangeListener(ws.onPropertyChange)
+
+	return ws
+}
+
+func (ws *viewWatchService) Watch(viewID string, userID string) error {
+	if _, err := ws.viewService.Get(viewID); err != nil {
+		return err
+	}
+
+	return ws.store.Create(ViewWatch{ViewID: viewID, UserID: userID})
+}
+
+func (ws *viewWatchService) Unwatch(viewID string, userID string) error {
+	return ws.store.Delete(viewID, userID)
+}
+
+func (ws *viewWatchService) GetForUser(userID string) ([]ViewWatch, error) {
+	return ws.store.GetForUser(userID)
+}
+
+func (ws *viewWatchService) onPropertyChange(change PropertyChange) {
+	// Evaluated in the background so watches don't slow down property writes
+	ws.evaluating.Add(1)
+	go func() {
+		defer ws.evaluating.Done()
+		if err := ws.evaluate(change); err != nil {
+			logrus.WithError(err).WithField("property_id", change.Property.ID).Warn("Failed to evaluate view watches")
+		}
+	}()
+}
+
+func (ws *viewWatchService) Close() {
+	ws.evaluating.Wait()
+}
+
+// evaluate compares whether the changed object matched the query of each watched view before
+// and after the change, notifying the watchers of the views it entered or left.
+func (ws *viewWatchService) evaluate(change PropertyChange) error {
+	property := change.Property
+	watches, err := ws.store.GetForObject(property.TeamID, property.ChannelID)
+	if err != nil {
+		return errors.Wrap(err, "failed to get view watches")
+	}
+	if len(watches) == 0 {
+		return nil
+	}
+
+	properties, err := ws.propertyService.GetForObject(property.ObjectID)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return errors.Wrapf(err, "failed to get properties for object '%s'", property.ObjectID)
+	}
+
+	after := map[string][]interface{}{}
+	types := map[string]string{property.PropertyFieldID: property.PropertyFieldType}
+	for _, p := range properties {
+		after[p.PropertyFieldID] = p.Value
+		types[p.PropertyFieldID] = p.PropertyFieldType
+	}
+
+	before := make(map[string][]interface{}, len(after))
+	for fieldID, value := range after {
+		before[fieldID] = value
+	}
+	switch change.Type {
+	case PropertyChangeTypeCreated:
+		delete(before, property.PropertyFieldID)
+	case PropertyChangeTypeUpdated, PropertyChangeTypeDeleted:
+		before[property.PropertyFieldID] = change.PreviousValue
+	}
+
+	watchers := map[string][]string{}
+	for _, watch := range watches {
+		watchers[watch.ViewID] = append(watchers[watch.ViewID], watch.UserID)
+	}
+
+	for viewID, userIDs := range watchers {
+		view, viewErr := ws.viewService.Get(viewID)
+		if viewErr != nil {
+			logrus.WithError(viewErr).WithField("view_id", viewID).Warn("Failed to get watched view")
+			continue
+		}
+
+		wasIn := view.Query.Matches(property.ObjectType, property.ChannelID, property.TeamID, before, types)
+		isIn := view.Query.Matches(property.ObjectType, property.ChannelID, property.TeamID, after, types)
+		if wasIn == isIn {
+			continue
+		}
+
+		ws.notify(view, property, isIn, userIDs)
+	}
+
+	return nil
+}
+
+func (ws *viewWatchService) notify(view View, property Property, entered bool, userIDs []string) {
+	channelID, rootID, err := objectThread(ws.api, property)
+	if err != nil {
+		logrus.WithError(err).WithField("object_id", property.ObjectID).Warn("Failed to get thread of watched object")
+		return
+	}
+
+	link := ""
+	if rootID != "" {
+		link = " " + permalink(ws.api, channelID, rootID)
+	}
+
+	message := fmt.Sprintf("An item entered the view **%s**:%s", view.Title, link)
+	if !entered {
+		message = fmt.Sprintf("An item left the view **%s**:%s", view.Title, link)
+	}
+
+	for _, userID := range userIDs {
+		// Watching a view doesn't give access to objects of channels the user can't read
+		if !ws.api.User.HasPermissionToChannel(userID, channelID, model.PermissionReadChannelContent) {
+			continue
+		}
+
+		if err = ws.api.Post.DM(ws.botID, userID, &model.Post{Message: message}); err != nil {
+			logrus.WithError(err).WithFields(logrus.Fields{
+				"view_id": view.ID,
+				"user_id": userID,
+			}).Warn("Failed to notify view watcher")
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package app
+
+import (
+	"encoding/json"
+)
+
+// Webhook receives a signed JSON payload for every property change matching its filters. Blank
+// filters match everything, except that webhooks without a channel only receive the changes of
+// public channels.
+type Webhook struct {
+	ID              string `json:"id"`
+	TeamID          string `json:"team_id"`
+	ChannelID       string `json:"channel_id"`
+	PropertyFieldID string `json:"property_field_id"`
+	URL             string `json:"url"`
+	Secret          string `json:"secret,omitempty"`
+	CreateAt        int64  `json:"create_at"`
+	UpdateAt        int64  `json:"update_at"`
+	UpdateBy        string `json:"update_by"`
+}
+
+// Sanitize removes the secret so the webhook can be returned to clients.
+func (w *Webhook) Sanitize() {
+	w.Secret = ""
+}
+
+const (
+	WebhookDeliveryStatusPending   = "pending"
+	WebhookDeliveryStatusSucceeded = "succeeded"
+	WebhookDeliveryStatusFailed    = "failed"
+)
+
+// WebhookDelivery records the attempts to deliver one property change to a webhook.
+type WebhookDelivery struct {
+	ID         string          `json:"id"`
+	WebhookID  string          `json:"webhook_id"`
+	ChangeType string          `json:"change_type"`
+	PropertyID string          `json:"property_id"`
+	Payload    json.RawMessage `json:"payload"`
+	Status     string          `json:"status"`
+	Attempts   int             `json:"attempts"`
+	StatusCode int             `json:"status_code"`
+	Error      string          `json:"error"`
+	CreateAt   int64           `json:"create_at"`
+	UpdateAt   int64           `json:"update_at"`
+	// NextAttemptAt is when a pending delivery is sent next.
+	NextAttemptAt int64 `json:"next_attempt_at"`
+}
+
+// WebhookPayload is the body posted to the webhook URL.
+type WebhookPayload struct {
+	DeliveryID    string        `json:"delivery_id"`
+	Type          string        `json:"type"`
+	Property      Property      `json:"property"`
+	PreviousValue []interface{} `json:"previous_value"`
+	Timestamp     int64         `json:"timestamp"`
+}
+
+type WebhookFilterOptions struct {
+	TeamID  string
+	Page    int
+	PerPage int
+}
+
+type WebhookStore interface {
+	Get(id string) (Webhook, error)
+	Create(webhook Webhook) (string, error)
+	GetWebhooks(filter WebhookFilterOptions) ([]Webhook, error)
+	// GetForChange returns the webhooks whose filters match a change of the field in the channel.
+	GetForChange(teamID, channelID, propertyFieldID string) ([]Webhook, error)
+	Update(webhook Webhook) error
+	Delete(id string) error
+
+	CreateDelivery(delivery WebhookDelivery) (string, error)
+	UpdateDelivery(delivery WebhookDelivery) error
+	GetDeliveries(webhookID string, page, perPage int) ([]WebhookDelivery, error)
+	// GetDueDeliveries returns the pending deliveries to send by the given time, oldest first.
+	GetDueDeliveries(until int64, limit int) ([]WebhookDelivery, error)
+}
+
+type WebhookService interface {
+	Get(id string) (Webhook, error)
+	Create(webhook Webhook) (string, error)
+	GetWebhooks(filter WebhookFilterOptions) ([]Webhook, error)
+	Update(webhook Webhook) error
+	Delete(id string) error
+	GetDeliveries(webhookID string, page, perPage int) ([]WebhookDelivery, error)
+	// Run sends the pending deliveries which are due, retrying the failed ones with a backoff.
+	Run()
+}
@@ -0,0 +1,337 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/pluginapi"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// WebhookSignatureHeader holds the hex encoded HMAC-SHA256 of the body, keyed with the secret
+	// of the webhook.
+	WebhookSignatureHeader = "X-Properties-Signature"
+	// WebhookDeliveryHeader holds the id of the delivery, which stays the same across retries.
+	WebhookDeliveryHeader = "X-Properties-Delivery"
+
+	webhookMaxAttempts    = 5
+	webhookInitialBackoff = 30 * time.Second
+	webhookTimeout        = 10 * time.Second
+
+	// webhookDeliveriesPerRun bounds the deliveries sent by a run, the others waiting for the next.
+	webhookDeliveriesPerRun = 200
+)
+
+type webhookService struct {
+	store           WebhookStore
+	propertyService PropertyService
+	api             *pluginapi.Client
+	client          *http.Client
+}
+
+func NewWebhookService(store WebhookStore, propertyService PropertyService, api *pluginapi.Client) WebhookService {
+	ws := &webhookService{
+		store:           store,
+		propertyService: propertyService,
+		api:             api,
+	}
+	ws.client = newWebhookClient(ws.allowedInternalConnections)
+
+	propertyService.RegisterChangeListener(ws.onPropertyChange)
+
+	return ws
+}
+
+func (ws *webhookService) Get(id string) (Webhook, error) {
+	return ws.store.Get(id)
+}
+
+func (ws *webhookService) Create(webhook Webhook) (string, error) {
+	if err := ws.validate(webhook); err != nil {
+		return "", err
+	}
+
+	return ws.store.Create(webhook)
+}
+
+func (ws *webhookService) GetWebhooks(filter WebhookFilterOptions) ([]Webhook, error) {
+	return ws.store.GetWebhooks(filter)
+}
+
+func (ws *webhookService) Update(webhook Webhook) error {
+	if err := ws.validate(webhook); err != nil {
+		return err
+	}
+
+	return ws.store.Update(webhook)
+}
+
+func (ws *webhookService) Delete(id string) error {
+	return ws.store.Delete(id)
+}
+
+func (ws *webhookService) GetDeliveries(webhookID string, page, perPage int) ([]WebhookDelivery, error) {
+	return ws.store.GetDeliveries(webhookID, page, perPage)
+}
+
+// validate checks the webhook, and that its author can read the channel it's filtered on.
+func (ws *webhookService) validate(webhook Webhook) error {
+	if err := validateWebhook(webhook); err != nil {
+		return err
+	}
+
+	if webhook.ChannelID != "" && !ws.api.User.HasPermissionToChannel(webhook.UpdateBy, webhook.ChannelID, model.PermissionReadChannel) {
+		return errors.Errorf("Channel '%s' should be a channel you can read", webhook.ChannelID)
+	}
+
+	return nil
+}
+
+func validateWebhook(webhook Webhook) error {
+	if webhook.URL == "" {
+		return errors.New("URL should not be blank")
+	}
+
+	u, err := url.Parse(webhook.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.Errorf("URL '%s' is not a valid http(s) url", webhook.URL)
+	}
+
+	if webhook.Secret == "" {
+		return errors.New("Secret should not be blank")
+	}
+
+	return nil
+}
+
+func (ws *webhookService) onPropertyChange(change PropertyChange) {
+	property := change.Property
+	webhooks, err := ws.store.GetForChange(property.TeamID, property.ChannelID, property.PropertyFieldID)
+	if err != nil {
+		logrus.WithError(err).WithField("property_id", property.ID).Warn("Failed to get webhooks for property change")
+		return
+	}
+
+	checked, public := false, false
+	for _, webhook := range webhooks {
+		// Webhooks of whole teams would otherwise leak the properties of private channels to
+		// whoever receives them
+		if webhook.ChannelID == "" && property.ChannelID != "" {
+			if !checked {
+				checked, public = true, ws.isPublicChannel(property.ChannelID)
+			}
+			if !public {
+				continue
+			}
+		}
+
+		ws.enqueue(webhook, change)
+	}
+}
+
+// isPublicChannel returns whether the channel is open, treating channels which can't be found as
+// private.
+func (ws *webhookService) isPublicChannel(channelID string) bool {
+	channel, err := ws.api.Channel.Get(channelID)
+	if err != nil {
+		logrus.WithError(err).WithField("channel_id", channelID).Warn("Failed to get channel of property change")
+		return false
+	}
+	return channel.Type == model.ChannelTypeOpen
+}
+
+// enqueue records the delivery, sent by the next run.
+func (ws *webhookService) enqueue(webhook Webhook, change PropertyChange) {
+	delivery := WebhookDelivery{
+		ID:            model.NewId(),
+		WebhookID:     webhook.ID,
+		ChangeType:    change.Type,
+		PropertyID:    change.Property.ID,
+		Status:        WebhookDeliveryStatusPending,
+		NextAttemptAt: model.GetMillis(),
+	}
+
+	payload, err := json.Marshal(WebhookPayload{
+		DeliveryID:    delivery.ID,
+		Type:          change.Type,
+		Property:      change.Property,
+		PreviousValue: change.PreviousValue,
+		Timestamp:     model.GetMillis(),
+	})
+	if err != nil {
+		logrus.WithError(err).WithField("webhook_id", webhook.ID).Warn("Failed to marshal webhook payload")
+		return
+	}
+	delivery.Payload = payload
+
+	if _, err = ws.store.CreateDelivery(delivery); err != nil {
+		logrus.WithError(err).WithField("webhook_id", webhook.ID).Warn("Failed to store webhook delivery")
+	}
+}
+
+// Run sends the pending deliveries which are due, oldest first.
+func (ws *webhookService) Run() {
+	now := model.GetMillis()
+	deliveries, err := ws.store.GetDueDeliveries(now, webhookDeliveriesPerRun)
+	if err != nil {
+		logrus.WithError(err).Warn("Failed to get due webhook deliveries")
+		return
+	}
+
+	webhooks := map[string]*Webhook{}
+	for _, delivery := range deliveries {
+		webhook, ok := webhooks[delivery.WebhookID]
+		if !ok {
+			found, getErr := ws.store.Get(delivery.WebhookID)
+			if getErr != nil && !errors.Is(getErr, ErrNotFound) {
+				logrus.WithError(getErr).WithField("webhook_id", delivery.WebhookID).Warn("Failed to get webhook of delivery")
+				continue
+			} else if getErr == nil {
+				webhook = &found
+			}
+			webhooks[delivery.WebhookID] = webhook
+		}
+
+		if webhook == nil {
+			continue
+		}
+
+		delivery = ws.attempt(*webhook, delivery, now)
+		if err = ws.store.UpdateDelivery(delivery); err != nil {
+			logrus.WithError(err).WithField("delivery_id", delivery.ID).Warn("Failed to update webhook delivery")
+		}
+	}
+}
+
+// attempt posts the payload once, scheduling the next attempt with an exponential backoff when
+// the webhook doesn't accept it.
+func (ws *webhookService) attempt(webhook Webhook, delivery WebhookDelivery, now int64) WebhookDelivery {
+	delivery.Attempts++
+	statusCode, err := postWebhook(ws.client, webhook.URL, webhook.Secret, delivery.ID, delivery.Payload)
+	delivery.StatusCode = statusCode
+	delivery.Error = ""
+
+	switch {
+	case err == nil:
+		delivery.Status = WebhookDeliveryStatusSucceeded
+	case delivery.Attempts >= webhookMaxAttempts:
+		delivery.Error = err.Error()
+		delivery.Status = WebhookDeliveryStatusFailed
+	default:
+		delivery.Error = err.Error()
+		delivery.NextAttemptAt = now + (webhookInitialBackoff << (delivery.Attempts - 1)).Milliseconds()
+	}
+
+	return delivery
+}
+
+// allowedInternalConnections returns the hosts, addresses and CIDR ranges of
+// ServiceSettings.AllowedUntrustedInternalConnections.
+func (ws *webhookService) allowedInternalConnections() []string {
+	config := ws.api.Configuration.GetConfig()
+	if config == nil || config.ServiceSettings.AllowedUntrustedInternalConnections == nil {
+		return nil
+	}
+	return strings.Fields(strings.ReplaceAll(*config.ServiceSettings.AllowedUntrustedInternalConnections, ",", " "))
+}
+
+// newWebhookClient returns a client refusing to connect to internal addresses, which anyone
+// managing webhooks could otherwise reach, unless they are allowed.
+func newWebhookClient(allowed func() []string) *http.Client {
+	dialer := &net.Dialer{Timeout: webhookTimeout}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = func(ctx context.Context, network, address string) (net.Conn, error) {
+		host, port, err := net.SplitHostPort(address)
+		if err != nil {
+			return nil, err
+		}
+
+		allowedConnections := allowed()
+		if slices.Contains(allowedConnections, host) {
+			return dialer.DialContext(ctx, network, address)
+		}
+
+		addresses, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+		if err != nil {
+			return nil, err
+		}
+		if len(addresses) == 0 {
+			return nil, errors.Errorf("no address found for host '%s'", host)
+		}
+		for _, ip := range addresses {
+			if isInternalAddress(ip.IP) && !internalAddressAllowed(ip.IP, allowedConnections) {
+				return nil, errors.Errorf("connecting to internal address '%s' of host '%s' is not allowed", ip.IP, host)
+			}
+		}
+
+		// The checked address is dialed so the host can't resolve to another one in between
+		return dialer.DialContext(ctx, network, net.JoinHostPort(addresses[0].IP.String(), port))
+	}
+
+	return &http.Client{Timeout: webhookTimeout, Transport: transport}
+}
+
+func isInternalAddress(ip net.IP) bool {
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() || ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() || ip.IsMulticast()
+}
+
+// internalAddressAllowed returns whether the address is one of the allowed addresses or in one
+// of the allowed CIDR ranges.
+func internalAddressAllowed(ip net.IP, allowed []string) bool {
+	for _, entry := range allowed {
+		if _, network, err := net.ParseCIDR(entry); err == nil {
+			if network.Contains(ip) {
+				return true
+			}
+		} else if allowedIP := net.ParseIP(entry); allowedIP != nil && allowedIP.Equal(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// postWebhook sends a signed payload to the url. Responses other than 2xx are returned as errors.
+func postWebhook(client *http.Client, webhookURL, secret, deliveryID string, payload []byte) (int, error) {
+	req, err := http.NewRequest(http.MethodPost, webhookURL, bytes.NewReader(payload))
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to create request")
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookDeliveryHeader, deliveryID)
+	req.Header.Set(WebhookSignatureHeader, SignWebhookPayload(secret, payload))
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to post payload")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	return resp.StatusCode, nil
+}
+
+// SignWebhookPayload returns the signature of the payload as sent in WebhookSignatureHeader.
+func SignWebhookPayload(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,221 @@
+package app
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/plugin/plugintest"
+	"github.com/mattermost/mattermost/server/public/pluginapi"
+)
+
+func TestPostWebhook(t *testing.T) {
+	payload := []byte(`{"type":"updated"}`)
+
+	cases := []struct {
+		Name          string
+		ResponseCode  int
+		ExpectedError bool
+	}{
+		{
+			Name:         "accepted",
+			ResponseCode: http.StatusOK,
+		},
+		{
+			Name:          "rejected",
+			ResponseCode:  http.StatusInternalServerError,
+			ExpectedError: true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, err := io.ReadAll(r.Body)
+				require.NoError(t, err)
+				assert.Equal(t, payload, body)
+				assert.Equal(t, "delivery", r.Header.Get(WebhookDeliveryHeader))
+				assert.Equal(t, SignWebhookPayload("secret", body), r.Header.Get(WebhookSignatureHeader))
+				w.WriteHeader(c.ResponseCode)
+			}))
+			defer server.Close()
+
+			statusCode, err := postWebhook(server.Client(), server.URL, "secret", "delivery", payload)
+			assert.Equal(t, c.ResponseCode, statusCode)
+			if c.ExpectedError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestWebhookClient(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	cases := []struct {
+		Name          string
+		Allowed       []string
+		ExpectedError bool
+	}{
+		{
+			Name:          "internal address",
+			Allowed:       nil,
+			ExpectedError: true,
+		},
+		{
+			Name:    "allowed address",
+			Allowed: []string{"10.0.0.1", "127.0.0.1"},
+		},
+		{
+			Name:    "allowed range",
+			Allowed: []string{"127.0.0.0/8"},
+		},
+		{
+			Name:          "other range",
+			Allowed:       []string{"10.0.0.0/8"},
+			ExpectedError: true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			client := newWebhookClient(func() []string { return c.Allowed })
+
+			_, err := postWebhook(client, server.URL, "secret", "delivery", []byte("{}"))
+			if c.ExpectedError {
+				assert.ErrorContains(t, err, "internal address '127.0.0.1'")
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestWebhookAttempt(t *testing.T) {
+	responseCode := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(responseCode)
+	}))
+	defer server.Close()
+
+	ws := &webhookService{client: server.Client()}
+	webhook := Webhook{URL: server.URL, Secret: "secret"}
+	now := int64(1000000)
+
+	cases := []struct {
+		Name                  string
+		ResponseCode          int
+		Attempts              int
+		ExpectedStatus        string
+		ExpectedNextAttemptAt int64
+	}{
+		{
+			Name:                  "accepted",
+			ResponseCode:          http.StatusOK,
+			ExpectedStatus:        WebhookDeliveryStatusSucceeded,
+			ExpectedNextAttemptAt: now,
+		},
+		{
+			Name:                  "first failure",
+			ResponseCode:          http.StatusInternalServerError,
+			ExpectedStatus:        WebhookDeliveryStatusPending,
+			ExpectedNextAttemptAt: now + webhookInitialBackoff.Milliseconds(),
+		},
+		{
+			Name:                  "third failure",
+			ResponseCode:          http.StatusInternalServerError,
+			Attempts:              2,
+			ExpectedStatus:        WebhookDeliveryStatusPending,
+			ExpectedNextAttemptAt: now + 4*webhookInitialBackoff.Milliseconds(),
+		},
+		{
+			Name:                  "last failure",
+			ResponseCode:          http.StatusInternalServerError,
+			Attempts:              webhookMaxAttempts - 1,
+			ExpectedStatus:        WebhookDeliveryStatusFailed,
+			ExpectedNextAttemptAt: now,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			responseCode = c.ResponseCode
+			delivery := WebhookDelivery{Status: WebhookDeliveryStatusPending, Attempts: c.Attempts, NextAttemptAt: now}
+
+			delivery = ws.attempt(webhook, delivery, now)
+			assert.Equal(t, c.Attempts+1, delivery.Attempts)
+			assert.Equal(t, c.ResponseCode, delivery.StatusCode)
+			assert.Equal(t, c.ExpectedStatus, delivery.Status)
+			assert.Equal(t, c.ExpectedNextAttemptAt, delivery.NextAttemptAt)
+		})
+	}
+}
+
+// fakeWebhookStore matches every change with its webhooks and records the deliveries, other
+// methods panic.
+type fakeWebhookStore struct {
+	WebhookStore
+	webhooks   []Webhook
+	deliveries []WebhookDelivery
+}
+
+func (s *fakeWebhookStore) GetForChange(_, _, _ string) ([]Webhook, error) {
+	return s.webhooks, nil
+}
+
+func (s *fakeWebhookStore) CreateDelivery(delivery WebhookDelivery) (string, error) {
+	s.deliveries = append(s.deliveries, delivery)
+	return delivery.ID, nil
+}
+
+func TestWebhookOnPropertyChange(t *testing.T) {
+	api := &plugintest.API{}
+	api.On("GetChannel", "public").Return(&model.Channel{Id: "public", Type: model.ChannelTypeOpen}, nil)
+	api.On("GetChannel", "private").Return(&model.Channel{Id: "private", Type: model.ChannelTypePrivate}, nil)
+
+	cases := []struct {
+		Name      string
+		ChannelID string
+		Expected  []string
+	}{
+		{
+			Name:      "public channel",
+			ChannelID: "public",
+			Expected:  []string{"team", "channel"},
+		},
+		{
+			Name:      "private channel",
+			ChannelID: "private",
+			Expected:  []string{"channel"},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			store := &fakeWebhookStore{webhooks: []Webhook{
+				{ID: "team", TeamID: "team1"},
+				{ID: "channel", TeamID: "team1", ChannelID: c.ChannelID},
+			}}
+			ws := &webhookService{store: store, api: pluginapi.NewClient(api, nil)}
+
+			ws.onPropertyChange(PropertyChange{Type: PropertyChangeTypeUpdated, Property: Property{ID: "property1", TeamID: "team1", ChannelID: c.ChannelID}})
+
+			webhookIDs := []string{}
+			for _, delivery := range store.deliveries {
+				webhookIDs = append(webhookIDs, delivery.WebhookID)
+				assert.Equal(t, WebhookDeliveryStatusPending, delivery.Status)
+			}
+			assert.Equal(t, c.Expected, webhookIDs)
+		})
+	}
+}
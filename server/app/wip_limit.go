@@ -0,0 +1,81 @@
+package app
+
+import (
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+// hasWIPLimits tells whether any column of a kanban format has a WIP limit.
+func hasWIPLimits(format Format) bool {
+	if format.GroupByFieldID == "" {
+		return false
+	}
+
+	for _, limit := range format.WIPLimits {
+		if limit > 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// columnCounts returns the number of objects of each column of an aggregation grouped by the
+// group by field, keyed by the value of the column and blank for the objects without a value.
+func columnCounts(columns Aggregation) map[string]int64 {
+	counts := map[string]int64{}
+	for _, group := range columns.Groups {
+		value := ""
+		if group.Values[0] != nil {
+			value = *group.Values[0]
+		}
+		counts[value] += group.Count
+	}
+	return counts
+}
+
+// overLimitValues returns the values of the columns holding more objects than their limit, sorted.
+func overLimitValues(limits map[string]int, columns Aggregation) []string {
+	counts := columnCounts(columns)
+
+	values := []string{}
+	for value, limit := range limits {
+		if limit > 0 && counts[value] > int64(limit) {
+			values = append(values, value)
+		}
+	}
+	sort.Strings(values)
+
+	return values
+}
+
+// checkWIPLimit fails with ErrLimitExceeded when moving the object into the column of value
+// would exceed its limit. Objects already in the column can always be moved within it.
+func (vs *viewService) checkWIPLimit(view View, move Move) error {
+	limit := view.Format.WIPLimits[move.Value]
+	if limit <= 0 {
+		return nil
+	}
+
+	properties, err := vs.propertyService.GetForObject(move.ObjectID)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return errors.Wrap(err, "could not get properties of object")
+	}
+	for _, value := range propertyValues(properties, view.Format.GroupByFieldID) {
+		if (value == nil && move.Value == "") || (value != nil && *value == move.Value) {
+			return nil
+		}
+	}
+
+	columns, err := vs.store.Aggregate(view.Query, AggregateOptions{GroupByFieldIDs: []string{view.Format.GroupByFieldID}})
+	if err != nil {
+		return errors.Wrap(err, "could not count columns")
+	}
+
+	if count := columnCounts(columns)[move.Value]; count >= int64(limit) {
+		return errors.Wrapf(ErrLimitExceeded, "Column '%s' is at its WIP limit of %d", move.Value, limit)
+	}
+
+	return nil
+}
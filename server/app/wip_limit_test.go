@@ -0,0 +1,64 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOverLimitValues(t *testing.T) {
+	open, done := "open", "done"
+	columns := Aggregation{Groups: []AggregationGroup{
+		{Values: []*string{&open}, Count: 3},
+		{Values: []*string{&done}, Count: 2},
+		{Values: []*string{nil}, Count: 5},
+	}}
+
+	cases := []struct {
+		Name     string
+		Limits   map[string]int
+		Expected []string
+	}{
+		{
+			Name:     "no limits",
+			Limits:   nil,
+			Expected: []string{},
+		},
+		{
+			Name:     "under limit",
+			Limits:   map[string]int{"open": 4},
+			Expected: []string{},
+		},
+		{
+			Name:     "at limit",
+			Limits:   map[string]int{"open": 3},
+			Expected: []string{},
+		},
+		{
+			Name:     "over limit",
+			Limits:   map[string]int{"open": 2, "done": 1},
+			Expected: []string{"done", "open"},
+		},
+		{
+			Name:     "no limit",
+			Limits:   map[string]int{"open": 0},
+			Expected: []string{},
+		},
+		{
+			Name:     "without value",
+			Limits:   map[string]int{"": 4},
+			Expected: []string{""},
+		},
+		{
+			Name:     "empty column",
+			Limits:   map[string]int{"blocked": 1},
+			Expected: []string{},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			assert.Equal(t, c.Expected, overLimitValues(c.Limits, columns))
+		})
+	}
+}
@@ -0,0 +1,597 @@
+package command
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/jwilander/mattermost-plugin-properties/server/app"
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/plugin"
+	"github.com/mattermost/mattermost/server/public/pluginapi"
+	"github.com/pkg/errors"
+)
+
+const helpText = "###### Mattermost Properties Plugin - Slash Command Help\n" +
+	"* `/property set <field> <value> [permalink]` - Set a property on the last post, or on the post linked by the permalink. \n" +
+	"* `/property get [permalink]` - Show the properties of the last post, or of the post linked by the permalink. \n" +
+	"* `/property clear <field> [permalink]` - Remove a property from the last post, or from the post linked by the permalink. \n" +
+	"* `/property fields` - List the property fields available in this team. \n" +
+	"* `/property view <name>` - Show the posts in one of your views. \n" +
+	"\n" +
+	"Field names and values containing spaces can be wrapped in double quotes, e.g. `/property set \"Due date\" tomorrow`."
+
+const maxFieldsToList = 200
+const maxViewObjectsToList = 20
+const lastPostsToScan = 20
+
+// permalinkRegexp matches links of the form https://example.com/team/pl/postid
+var permalinkRegexp = regexp.MustCompile(`/pl/([a-z0-9]{26})/?$`)
+
+// Register is a function that allows the runner to register commands with the mattermost server.
+type Register func(*model.Command) error
+
+// RegisterCommands should be called by the plugin to register all necessary commands
+func RegisterCommands(registerFunc Register) error {
+	return registerFunc(getCommand())
+}
+
+func getCommand() *model.Command {
+	return &model.Command{
+		Trigger:          "property",
+		DisplayName:      "Properties",
+		Description:      "Read and set properties on posts",
+		AutoComplete:     true,
+		AutoCompleteDesc: "Available commands: set, get, clear, fields, view, help",
+		AutoCompleteHint: "[command]",
+		AutocompleteData: getAutocompleteData(),
+	}
+}
+
+func getAutocompleteData() *model.AutocompleteData {
+	command := model.NewAutocompleteData("property", "[command]",
+		"Available commands: set, get, clear, fields, view, help")
+
+	set := model.NewAutocompleteData("set", "<field> <value> [permalink]",
+		"Set a property on the last post, or on the post linked by the permalink")
+	set.AddDynamicListArgument("Name of the field", "/api/v0/command/autocomplete/fields", true)
+	set.AddDynamicListArgument("Value to set", "/api/v0/command/autocomplete/values", true)
+	command.AddCommand(set)
+
+	get := model.NewAutocompleteData("get", "[permalink]",
+		"Show the properties of the last post, or of the post linked by the permalink")
+	command.AddCommand(get)
+
+	clear := model.NewAutocompleteData("clear", "<field> [permalink]",
+		"Remove a property from the last post, or from the post linked by the permalink")
+	clear.AddDynamicListArgument("Name of the field", "/api/v0/command/autocomplete/fields", true)
+	command.AddCommand(clear)
+
+	fields := model.NewAutocompleteData("fields", "",
+		"List the property fields available in this team")
+	command.AddCommand(fields)
+
+	view := model.NewAutocompleteData("view", "<name>",
+		"Show the posts in one of your views")
+	view.AddDynamicListArgument("Name of the view", "/api/v0/command/autocomplete/views", true)
+	command.AddCommand(view)
+
+	help := model.NewAutocompleteData("help", "",
+		"Show help")
+	command.AddCommand(help)
+
+	return command
+}
+
+// Runner handles commands.
+type Runner struct {
+	context              *plugin.Context
+	args                 *model.CommandArgs
+	pluginAPI            *pluginapi.Client
+	propertyService      app.PropertyService
+	propertyFieldService app.PropertyFieldService
+	viewService          app.ViewService
+	permissions          *app.PermissionsService
+}
+
+// NewCommandRunner creates a command runner.
+func NewCommandRunner(ctx *plugin.Context,
+	args *model.CommandArgs,
+	api *pluginapi.Client,
+	propertyService app.PropertyService,
+	propertyFieldService app.PropertyFieldService,
+	viewService app.ViewService,
+	permissions *app.PermissionsService,
+) *Runner {
+	return &Runner{
+		context:              ctx,
+		args:                 args,
+		pluginAPI:            api,
+		propertyService:      propertyService,
+		propertyFieldService: propertyFieldService,
+		viewService:          viewService,
+		permissions:          permissions,
+	}
+}
+
+// Execute should be called by the plugin when a command invocation is received from the Mattermost server.
+func (r *Runner) Execute() (*model.CommandResponse, error) {
+	split := SplitArgs(r.args.Command)
+	if len(split) == 0 {
+		return r.respond(helpText), nil
+	}
+
+	command := split[0]
+	parameters := []string{}
+	cmd := ""
+	if len(split) > 1 {
+		cmd = split[1]
+	}
+	if len(split) > 2 {
+		parameters = split[2:]
+	}
+
+	if command != "/property" {
+		return r.respond(helpText), nil
+	}
+
+	switch cmd {
+	case "set":
+		return r.actionSet(parameters)
+	case "get":
+		return r.actionGet(parameters)
+	case "clear":
+		return r.actionClear(parameters)
+	case "fields":
+		return r.actionFields()
+	case "view":
+		return r.actionView(parameters)
+	default:
+		return r.respond(helpText), nil
+	}
+}
+
+func (r *Runner) respond(text string) *model.CommandResponse {
+	return &model.CommandResponse{
+		ResponseType: model.CommandResponseTypeEphemeral,
+		Text:         text,
+	}
+}
+
+func (r *Runner) actionSet(args []string) (*model.CommandResponse, error) {
+	if len(args) < 2 {
+		return r.respond("Please specify a field and a value, e.g. `/property set Status Done`."), nil
+	}
+
+	postArg, args := popPermalink(args)
+	if len(args) < 2 {
+		return r.respond("Please specify a field and a value, e.g. `/property set Status Done`."), nil
+	}
+
+	post, err := r.targetPost(postArg)
+	if err != nil {
+		return r.respond(err.Error()), nil
+	}
+
+	field, err := r.findField(args[0])
+	if err != nil {
+		return nil, err
+	}
+	if field == nil {
+		return r.respond(fmt.Sprintf("Unable to find a field named `%s`. Use `/property fields` to list the available fields.", args[0])), nil
+	}
+
+	value, err := r.parseValue(*field, strings.Join(args[1:], " "))
+	if err != nil {
+		return r.respond(err.Error()), nil
+	}
+
+	existing, err := r.findProperty(post.Id, field.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	property, err := r.propertyForPost(post, *field)
+	if err != nil {
+		return nil, err
+	}
+	property.Value = value
+
+	if err = r.permissions.PropertyCreate(r.args.UserId, property); err != nil {
+		return r.respond("You do not have permission to set properties on that post."), nil
+	}
+
+	if err = r.permissions.ReferencesRead(r.args.UserId, property); err != nil {
+		return r.respond(fmt.Sprintf("You do not have permission to reference that %s.", field.Type)), nil
+	}
+
+	if existing != nil {
+		err = r.propertyService.UpdateValue(existing.ID, value)
+	} else {
+		_, err = r.propertyService.Create(property)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to set property for field '%s'", field.ID)
+	}
+
+	return r.respond(fmt.Sprintf("Set **%s** to %s.", field.Name, r.formatValue(field.Type, value))), nil
+}
+
+func (r *Runner) actionGet(args []string) (*model.CommandResponse, error) {
+	postArg, _ := popPermalink(args)
+	post, err := r.targetPost(postArg)
+	if err != nil {
+		return r.respond(err.Error()), nil
+	}
+
+	properties, err := r.propertyService.GetForPost(post)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get properties for post '%s'", post.Id)
+	}
+
+	if len(properties) == 0 {
+		return r.respond("That post has no properties."), nil
+	}
+
+	return r.respond(r.formatProperties(properties)), nil
+}
+
+func (r *Runner) actionClear(args []string) (*model.CommandResponse, error) {
+	postArg, args := popPermalink(args)
+	if len(args) < 1 {
+		return r.respond("Please specify a field, e.g. `/property clear Status`."), nil
+	}
+
+	post, err := r.targetPost(postArg)
+	if err != nil {
+		return r.respond(err.Error()), nil
+	}
+
+	field, err := r.findField(args[0])
+	if err != nil {
+		return nil, err
+	}
+	if field == nil {
+		return r.respond(fmt.Sprintf("Unable to find a field named `%s`. Use `/property fields` to list the available fields.", args[0])), nil
+	}
+
+	existing, err := r.findProperty(post.Id, field.ID)
+	if err != nil {
+		return nil, err
+	}
+	if existing == nil {
+		return r.respond(fmt.Sprintf("That post has no **%s** property.", field.Name)), nil
+	}
+
+	if err = r.permissions.PropertyCreate(r.args.UserId, *existing); err != nil {
+		return r.respond("You do not have permission to clear properties on that post."), nil
+	}
+
+	if err = r.propertyService.Delete(existing.ID); err != nil {
+		return nil, errors.Wrapf(err, "failed to delete property '%s'", existing.ID)
+	}
+
+	return r.respond(fmt.Sprintf("Cleared **%s**.", field.Name)), nil
+}
+
+func (r *Runner) actionFields() (*model.CommandResponse, error) {
+	fields, err := r.propertyFieldService.GetFields(app.PropertyFieldFilterOptions{
+		TeamID:  r.args.TeamId,
+		PerPage: maxFieldsToList,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get fields")
+	}
+
+	if len(fields) == 0 {
+		return r.respond("There are no property fields in this team yet."), nil
+	}
+
+	var sb strings.Builder
+	sb.WriteString("###### Property fields\n")
+	for _, field := range fields {
+		sb.WriteString(fmt.Sprintf("* **%s** (%s)", field.Name, field.Type))
+		if len(field.Values) > 0 {
+			options := make([]string, len(field.Values))
+			for i, v := range field.Values {
+				options[i] = fmt.Sprint(v)
+			}
+			sb.WriteString(": " + strings.Join(options, ", "))
+		}
+		sb.WriteString("\n")
+	}
+
+	return r.respond(sb.String()), nil
+}
+
+func (r *Runner) actionView(args []string) (*model.CommandResponse, error) {
+	if len(args) < 1 {
+		return r.respond("Please specify the name of a view, e.g. `/property view Triage`."), nil
+	}
+	name := strings.Join(args, " ")
+
+	views, err := r.viewService.GetForUser(r.args.UserId)
+	if err != nil && !errors.Is(err, app.ErrNotFound) {
+		return nil, errors.Wrap(err, "failed to get views")
+	}
+
+	var view *app.View
+	for i := range views {
+		if strings.EqualFold(views[i].Title, name) {
+			view = &views[i]
+			break
+		}
+	}
+	if view == nil {
+		return r.respond(fmt.Sprintf("Unable to find a view named `%s`.", name)), nil
+	}
+
+	objects, err := r.viewService.GetObjectsForView(view.ID, 0, maxViewObjectsToList)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to query view '%s'", view.ID)
+	}
+
+	if len(objects.Posts) == 0 {
+		return r.respond(fmt.Sprintf("The view **%s** is empty.", view.Title)), nil
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("###### %s\n", view.Title))
+	for _, post := range objects.Posts {
+		sb.WriteString(fmt.Sprintf("* %s", r.permalink(post)))
+		if properties := objects.Properties[post.Id]; len(properties) > 0 {
+			sb.WriteString(" - " + strings.ReplaceAll(strings.TrimSpace(r.formatProperties(properties)), "\n", ", "))
+		}
+		sb.WriteString("\n")
+	}
+
+	return r.respond(sb.String()), nil
+}
+
+// targetPost returns the post linked by the permalink, or the last post of the current
+// channel (or thread) when no permalink is given. Linked posts the user can't read are reported
+// as not found.
+func (r *Runner) targetPost(permalink string) (*model.Post, error) {
+	if permalink != "" {
+		matches := permalinkRegexp.FindStringSubmatch(permalink)
+		if matches == nil {
+			return nil, errors.Errorf("`%s` is not a valid permalink.", permalink)
+		}
+
+		post, err := r.pluginAPI.Post.GetPost(matches[1])
+		if err != nil || !r.pluginAPI.User.HasPermissionToChannel(r.args.UserId, post.ChannelId, model.PermissionReadChannelContent) {
+			return nil, errors.New("Unable to find the linked post.")
+		}
+		return post, nil
+	}
+
+	var posts []*model.Post
+	if r.args.RootId != "" {
+		thread, err := r.pluginAPI.Post.GetPostThread(r.args.RootId)
+		if err != nil {
+			return nil, errors.New("Unable to find the last post of this thread.")
+		}
+		thread.SortByCreateAt()
+		posts = thread.ToSlice()
+	} else {
+		postList, err := r.pluginAPI.Post.GetPostsForChannel(r.args.ChannelId, 0, lastPostsToScan)
+		if err != nil {
+			return nil, errors.New("Unable to find the last post of this channel.")
+		}
+		posts = postList.ToSlice()
+	}
+
+	for _, post := range posts {
+		if !post.IsSystemMessage() {
+			return post, nil
+		}
+	}
+
+	return nil, errors.New("Unable to find a post to apply the command to.")
+}
+
+func (r *Runner) findField(name string) (*app.PropertyField, error) {
+	fields, err := r.propertyFieldService.GetFields(app.PropertyFieldFilterOptions{
+		TeamID:     r.args.TeamId,
+		SearchTerm: name,
+		PerPage:    maxFieldsToList,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get fields")
+	}
+
+	for i := range fields {
+		if strings.EqualFold(fields[i].Name, name) {
+			return &fields[i], nil
+		}
+	}
+
+	return nil, nil
+}
+
+func (r *Runner) findProperty(objectID, fieldID string) (*app.Property, error) {
+	properties, err := r.propertyService.GetForObject(objectID)
+	if err != nil && !errors.Is(err, app.ErrNotFound) {
+		return nil, errors.Wrapf(err, "failed to get properties for object '%s'", objectID)
+	}
+
+	return app.FindProperty(properties, fieldID), nil
+}
+
+func (r *Runner) propertyForPost(post *model.Post, field app.PropertyField) (app.Property, error) {
+	channel, err := r.pluginAPI.Channel.Get(post.ChannelId)
+	if err != nil {
+		return app.Property{}, errors.Wrapf(err, "failed to get channel '%s'", post.ChannelId)
+	}
+
+	return app.Property{
+		ObjectID:        post.Id,
+		ObjectType:      app.PropertyObjectTypePost,
+		PropertyFieldID: field.ID,
+		ChannelID:       channel.Id,
+		TeamID:          channel.TeamId,
+	}, nil
+}
+
+// parseValue converts the user input into the stored value for the given field.
+func (r *Runner) parseValue(field app.PropertyField, input string) ([]interface{}, error) {
+	input = strings.TrimSpace(input)
+
+	switch field.Type {
+	case app.PropertyFieldTypeSelect:
+		for _, option := range field.Values {
+			if strings.EqualFold(fmt.Sprint(option), input) {
+				return []interface{}{option}, nil
+			}
+		}
+		return nil, errors.Errorf("`%s` is not an option of **%s**.", input, field.Name)
+	case app.PropertyFieldTypeUser:
+		user, err := r.pluginAPI.User.GetByUsername(strings.TrimPrefix(input, "@"))
+		if err != nil {
+			return nil, errors.Errorf("Unable to find a user named `%s`.", input)
+		}
+		return []interface{}{user.Id}, nil
+	case app.PropertyFieldTypeChannel:
+		channel, err := r.pluginAPI.Channel.GetByName(r.args.TeamId, strings.TrimPrefix(input, "~"), false)
+		if err != nil {
+			return nil, errors.Errorf("Unable to find a channel named `%s`.", input)
+		}
+		return []interface{}{channel.Id}, nil
+	case app.PropertyFieldTypeTeam:
+		team, err := r.pluginAPI.Team.GetByName(input)
+		if err != nil {
+			return nil, errors.Errorf("Unable to find a team named `%s`.", input)
+		}
+		return []interface{}{team.Id}, nil
+	case app.PropertyFieldTypeDate:
+		if _, ok := app.ParseDateValue(input); !ok {
+			return nil, errors.Errorf("`%s` is not a date, use the format `YYYY-MM-DD`.", input)
+		}
+		return []interface{}{input}, nil
+	case app.PropertyFieldTypeCheckbox:
+		checked, err := strconv.ParseBool(input)
+		if err != nil {
+			return nil, errors.Errorf("`%s` is not `true` or `false`.", input)
+		}
+		return []interface{}{strconv.FormatBool(checked)}, nil
+	case app.PropertyFieldTypeNumber, app.PropertyFieldTypeRating:
+		number, err := strconv.ParseFloat(input, 64)
+		if err != nil {
+			return nil, errors.Errorf("`%s` is not a number.", input)
+		}
+		return []interface{}{number}, nil
+	default:
+		return []interface{}{input}, nil
+	}
+}
+
+func (r *Runner) formatValue(fieldType string, value []interface{}) string {
+	values := make([]string, len(value))
+	for i, v := range value {
+		values[i] = fmt.Sprint(v)
+		switch fieldType {
+		case app.PropertyFieldTypeUser:
+			if user, err := r.pluginAPI.User.Get(values[i]); err == nil {
+				values[i] = "@" + user.Username
+			}
+		case app.PropertyFieldTypeChannel:
+			// Don't leak the names of channels the user can't read
+			if !r.pluginAPI.User.HasPermissionToChannel(r.args.UserId, values[i], model.PermissionReadChannel) {
+				values[i] = "private channel"
+			} else if channel, err := r.pluginAPI.Channel.Get(values[i]); err == nil {
+				values[i] = "~" + channel.Name
+			}
+		case app.PropertyFieldTypeTeam:
+			if !r.pluginAPI.User.HasPermissionToTeam(r.args.UserId, values[i], model.PermissionViewTeam) {
+				values[i] = "private team"
+			} else if team, err := r.pluginAPI.Team.Get(values[i]); err == nil {
+				values[i] = team.Name
+			}
+		}
+	}
+
+	if len(values) == 0 {
+		return "_empty_"
+	}
+
+	return strings.Join(values, ", ")
+}
+
+func (r *Runner) formatProperties(properties []app.Property) string {
+	var sb strings.Builder
+	for _, property := range properties {
+		sb.WriteString(fmt.Sprintf("**%s**: %s\n", property.PropertyFieldName, r.formatValue(property.PropertyFieldType, property.Value)))
+	}
+	return sb.String()
+}
+
+func (r *Runner) permalink(post *model.Post) string {
+	channel, err := r.pluginAPI.Channel.Get(post.ChannelId)
+	if err != nil || channel.TeamId == "" {
+		return post.Id
+	}
+
+	team, err := r.pluginAPI.Team.Get(channel.TeamId)
+	if err != nil {
+		return post.Id
+	}
+
+	return fmt.Sprintf("%s/%s/pl/%s", r.args.SiteURL, team.Name, post.Id)
+}
+
+// popPermalink removes a trailing permalink from the arguments, if there is one.
+func popPermalink(args []string) (string, []string) {
+	if len(args) > 0 && permalinkRegexp.MatchString(args[len(args)-1]) {
+		return args[len(args)-1], args[:len(args)-1]
+	}
+
+	return "", args
+}
+
+// SplitArgs splits a command into its arguments, keeping double quoted arguments together.
+// Within an argument, \" and \\ stand for a double quote and a backslash, as written by QuoteArg.
+func SplitArgs(command string) []string {
+	args := []string{}
+	var current strings.Builder
+	inQuotes := false
+	hasArg := false
+
+	runes := []rune(command)
+	for i := 0; i < len(runes); i++ {
+		c := runes[i]
+		switch {
+		case c == '\\' && i+1 < len(runes) && (runes[i+1] == '"' || runes[i+1] == '\\'):
+			i++
+			current.WriteRune(runes[i])
+			hasArg = true
+		case c == '"':
+			inQuotes = !inQuotes
+			hasArg = true
+		case c == ' ' && !inQuotes:
+			if hasArg {
+				args = append(args, current.String())
+				current.Reset()
+				hasArg = false
+			}
+		default:
+			current.WriteRune(c)
+			hasArg = true
+		}
+	}
+
+	if hasArg {
+		args = append(args, current.String())
+	}
+
+	return args
+}
+
+// QuoteArg wraps arguments which SplitArgs would otherwise split or change in double quotes.
+func QuoteArg(arg string) string {
+	if arg != "" && !strings.ContainsAny(arg, ` "\`) {
+		return arg
+	}
+
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(arg) + `"`
+}
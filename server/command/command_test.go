@@ -0,0 +1,330 @@
+package command
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jwilander/mattermost-plugin-properties/server/app"
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/plugin/plugintest"
+	"github.com/mattermost/mattermost/server/public/pluginapi"
+)
+
+func TestSplitArgs(t *testing.T) {
+	cases := []struct {
+		Command  string
+		Expected []string
+	}{
+		{
+			Command:  "/property set Status Done",
+			Expected: []string{"/property", "set", "Status", "Done"},
+		},
+		{
+			Command:  `/property set "Due date"  "next week"`,
+			Expected: []string{"/property", "set", "Due date", "next week"},
+		},
+		{
+			Command:  `/property set Notes ""`,
+			Expected: []string{"/property", "set", "Notes", ""},
+		},
+		{
+			Command:  `/property set Notes "say \"hi\" to C:\\dir"`,
+			Expected: []string{"/property", "set", "Notes", `say "hi" to C:\dir`},
+		},
+		{
+			Command:  `/property set Path C:\dir`,
+			Expected: []string{"/property", "set", "Path", `C:\dir`},
+		},
+		{
+			Command:  "  ",
+			Expected: []string{},
+		},
+	}
+
+	for _, c := range cases {
+		assert.Equal(t, c.Expected, SplitArgs(c.Command))
+	}
+}
+
+func TestQuoteArg(t *testing.T) {
+	cases := []struct {
+		Arg      string
+		Expected string
+	}{
+		{
+			Arg:      "Status",
+			Expected: "Status",
+		},
+		{
+			Arg:      "Due date",
+			Expected: `"Due date"`,
+		},
+		{
+			Arg:      `The "big" one`,
+			Expected: `"The \"big\" one"`,
+		},
+		{
+			Arg:      `back\slash`,
+			Expected: `"back\\slash"`,
+		},
+		{
+			Arg:      "日本 語",
+			Expected: `"日本 語"`,
+		},
+		{
+			Arg:      "",
+			Expected: `""`,
+		},
+	}
+
+	for _, c := range cases {
+		quoted := QuoteArg(c.Arg)
+		assert.Equal(t, c.Expected, quoted)
+		assert.Equal(t, []string{"set", c.Arg}, SplitArgs("set "+quoted), "round trip of %s", quoted)
+	}
+}
+
+// fakePropertyService keeps properties in memory, other methods panic.
+type fakePropertyService struct {
+	app.PropertyService
+	properties []app.Property
+}
+
+func (s *fakePropertyService) GetForObject(objectID string) ([]app.Property, error) {
+	properties := []app.Property{}
+	for _, property := range s.properties {
+		if property.ObjectID == objectID {
+			properties = append(properties, property)
+		}
+	}
+	return properties, nil
+}
+
+func (s *fakePropertyService) GetForPost(post *model.Post) ([]app.Property, error) {
+	return s.GetForObject(post.Id)
+}
+
+func (s *fakePropertyService) Create(property app.Property) (string, error) {
+	property.ID = model.NewId()
+	s.properties = append(s.properties, property)
+	return property.ID, nil
+}
+
+func (s *fakePropertyService) UpdateValue(id string, value []interface{}) error {
+	for i := range s.properties {
+		if s.properties[i].ID == id {
+			s.properties[i].Value = value
+		}
+	}
+	return nil
+}
+
+// fakePropertyFieldService returns the fields it's given, other methods panic.
+type fakePropertyFieldService struct {
+	app.PropertyFieldService
+	fields []app.PropertyField
+}
+
+func (s *fakePropertyFieldService) Get(id string) (app.PropertyField, error) {
+	for _, field := range s.fields {
+		if field.ID == id {
+			return field, nil
+		}
+	}
+	return app.PropertyField{}, app.ErrNotFound
+}
+
+func (s *fakePropertyFieldService) GetFields(filter app.PropertyFieldFilterOptions) ([]app.PropertyField, error) {
+	fields := []app.PropertyField{}
+	for _, field := range s.fields {
+		if strings.Contains(strings.ToLower(field.Name), strings.ToLower(filter.SearchTerm)) {
+			fields = append(fields, field)
+		}
+	}
+	return fields, nil
+}
+
+var (
+	readablePostID   = model.NewId()
+	unreadablePostID = model.NewId()
+	status           = app.PropertyField{ID: "status", Name: "Status", Type: app.PropertyFieldTypeSelect, Values: []interface{}{"Open", "Done"}}
+)
+
+// newTestRunner returns a runner for the user, who can read and set properties on the readable
+// post, and neither on the unreadable post.
+func newTestRunner(propertyService *fakePropertyService) *Runner {
+	api := &plugintest.API{}
+	api.On("GetPost", readablePostID).Return(&model.Post{Id: readablePostID, ChannelId: "public"}, nil)
+	api.On("GetPost", unreadablePostID).Return(&model.Post{Id: unreadablePostID, ChannelId: "private"}, nil)
+	api.On("GetChannel", "public").Return(&model.Channel{Id: "public", TeamId: "team1"}, nil)
+	api.On("HasPermissionToChannel", "user", "public", mock.Anything).Return(true)
+	api.On("HasPermissionToChannel", "user", "private", mock.Anything).Return(false)
+
+	client := pluginapi.NewClient(api, nil)
+	fieldService := &fakePropertyFieldService{fields: []app.PropertyField{status}}
+
+	return NewCommandRunner(nil, &model.CommandArgs{UserId: "user", TeamId: "team1", ChannelId: "public"}, client,
+		propertyService, fieldService, nil, app.NewPermissionsService(propertyService, fieldService, client, nil))
+}
+
+func TestActionGet(t *testing.T) {
+	cases := []struct {
+		Name     string
+		PostID   string
+		Expected string
+	}{
+		{
+			Name:     "readable post",
+			PostID:   readablePostID,
+			Expected: "**Status**: Open\n",
+		},
+		{
+			Name:     "unreadable post",
+			PostID:   unreadablePostID,
+			Expected: "Unable to find the linked post.",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			propertyService := &fakePropertyService{properties: []app.Property{
+				{ID: "property1", ObjectID: readablePostID, PropertyFieldID: "status", PropertyFieldName: "Status", PropertyFieldType: app.PropertyFieldTypeSelect, Value: []interface{}{"Open"}},
+				{ID: "property2", ObjectID: unreadablePostID, PropertyFieldID: "status", PropertyFieldName: "Status", PropertyFieldType: app.PropertyFieldTypeSelect, Value: []interface{}{"Done"}},
+			}}
+
+			response, err := newTestRunner(propertyService).actionGet([]string{"https://example.com/team1/pl/" + c.PostID})
+			require.NoError(t, err)
+			assert.Equal(t, c.Expected, response.Text)
+		})
+	}
+}
+
+func TestActionSet(t *testing.T) {
+	cases := []struct {
+		Name          string
+		Args          []string
+		Existing      []app.Property
+		Expected      string
+		ExpectedValue []interface{}
+	}{
+		{
+			Name:          "new property",
+			Args:          []string{"status", "done", "https://example.com/team1/pl/" + readablePostID},
+			Expected:      "Set **Status** to Done.",
+			ExpectedValue: []interface{}{"Done"},
+		},
+		{
+			Name:          "existing property",
+			Args:          []string{"Status", "Done", "https://example.com/team1/pl/" + readablePostID},
+			Existing:      []app.Property{{ID: "property1", ObjectID: readablePostID, PropertyFieldID: "status", TeamID: "team1", Value: []interface{}{"Open"}}},
+			Expected:      "Set **Status** to Done.",
+			ExpectedValue: []interface{}{"Done"},
+		},
+		{
+			Name:     "not an option",
+			Args:     []string{"Status", "Closed", "https://example.com/team1/pl/" + readablePostID},
+			Expected: "`Closed` is not an option of **Status**.",
+		},
+		{
+			Name:     "unknown field",
+			Args:     []string{"Priority", "High", "https://example.com/team1/pl/" + readablePostID},
+			Expected: "Unable to find a field named `Priority`. Use `/property fields` to list the available fields.",
+		},
+		{
+			Name:     "unreadable post",
+			Args:     []string{"Status", "Done", "https://example.com/team1/pl/" + unreadablePostID},
+			Expected: "Unable to find the linked post.",
+		},
+		{
+			Name:     "missing value",
+			Args:     []string{"Status", "https://example.com/team1/pl/" + readablePostID},
+			Expected: "Please specify a field and a value, e.g. `/property set Status Done`.",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			propertyService := &fakePropertyService{properties: c.Existing}
+
+			response, err := newTestRunner(propertyService).actionSet(c.Args)
+			require.NoError(t, err)
+			assert.Equal(t, c.Expected, response.Text)
+
+			if c.ExpectedValue == nil {
+				assert.Equal(t, c.Existing, propertyService.properties)
+				return
+			}
+			require.Len(t, propertyService.properties, 1)
+			assert.Equal(t, "status", propertyService.properties[0].PropertyFieldID)
+			assert.Equal(t, "team1", propertyService.properties[0].TeamID)
+			assert.Equal(t, c.ExpectedValue, propertyService.properties[0].Value)
+		})
+	}
+}
+
+func TestFormatValue(t *testing.T) {
+	api := &plugintest.API{}
+	api.On("HasPermissionToChannel", "user", "public", model.PermissionReadChannel).Return(true)
+	api.On("HasPermissionToChannel", "user", "private", model.PermissionReadChannel).Return(false)
+	api.On("GetChannel", "public").Return(&model.Channel{Id: "public", Name: "town-square"}, nil)
+	api.On("HasPermissionToTeam", "user", "team1", model.PermissionViewTeam).Return(true)
+	api.On("HasPermissionToTeam", "user", "team2", model.PermissionViewTeam).Return(false)
+	api.On("GetTeam", "team1").Return(&model.Team{Id: "team1", Name: "engineering"}, nil)
+	defer api.AssertExpectations(t)
+
+	r := &Runner{args: &model.CommandArgs{UserId: "user"}, pluginAPI: pluginapi.NewClient(api, nil)}
+
+	cases := []struct {
+		Name      string
+		FieldType string
+		Value     []interface{}
+		Expected  string
+	}{
+		{
+			Name:      "empty",
+			FieldType: app.PropertyFieldTypeText,
+			Value:     []interface{}{},
+			Expected:  "_empty_",
+		},
+		{
+			Name:      "text",
+			FieldType: app.PropertyFieldTypeSelect,
+			Value:     []interface{}{"Open", "Done"},
+			Expected:  "Open, Done",
+		},
+		{
+			Name:      "readable channel",
+			FieldType: app.PropertyFieldTypeChannel,
+			Value:     []interface{}{"public"},
+			Expected:  "~town-square",
+		},
+		{
+			Name:      "private channel",
+			FieldType: app.PropertyFieldTypeChannel,
+			Value:     []interface{}{"public", "private"},
+			Expected:  "~town-square, private channel",
+		},
+		{
+			Name:      "readable team",
+			FieldType: app.PropertyFieldTypeTeam,
+			Value:     []interface{}{"team1"},
+			Expected:  "engineering",
+		},
+		{
+			Name:      "private team",
+			FieldType: app.PropertyFieldTypeTeam,
+			Value:     []interface{}{"team2"},
+			Expected:  "private team",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			assert.Equal(t, c.Expected, r.formatValue(c.FieldType, c.Value))
+		})
+	}
+}
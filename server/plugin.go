@@ -2,30 +2,65 @@ package main
 
 import (
 	"net/http"
+	"time"
 
 	root "github.com/jwilander/mattermost-plugin-properties"
 	"github.com/jwilander/mattermost-plugin-properties/server/api"
 	"github.com/jwilander/mattermost-plugin-properties/server/app"
+	"github.com/jwilander/mattermost-plugin-properties/server/command"
 	"github.com/jwilander/mattermost-plugin-properties/server/config"
 	"github.com/jwilander/mattermost-plugin-properties/server/sqlstore"
 
+	"github.com/mattermost/mattermost/server/public/model"
 	"github.com/mattermost/mattermost/server/public/plugin"
 	"github.com/mattermost/mattermost/server/public/pluginapi"
 	"github.com/mattermost/mattermost/server/public/pluginapi/cluster"
 	"github.com/pkg/errors"
 )
 
+// reminderInterval is how often due dates and SLAs are checked.
+const reminderInterval = 5 * time.Minute
+
+// digestInterval is how often due digests are posted, bounding how late a digest can be.
+const digestInterval = 5 * time.Minute
+
+// formulaInterval is how often the formulas changing over time are recomputed.
+const formulaInterval = time.Hour
+
+// rollupInterval is how often all rollups are recomputed, catching up with deleted objects.
+const rollupInterval = 24 * time.Hour
+
+// webhookInterval is how often pending webhook deliveries are sent, bounding how late they are.
+const webhookInterval = 15 * time.Second
+
 // Plugin implements the interface expected by the Mattermost server to communicate between the server and plugin processes.
 type Plugin struct {
 	plugin.MattermostPlugin
 
-	handler              *api.Handler
-	config               *config.ServiceImpl
-	pluginAPI            *pluginapi.Client
-	propertyService      app.PropertyService
-	propertyFieldService app.PropertyFieldService
-	viewService          app.ViewService
-	permissions          *app.PermissionsService
+	handler                *api.Handler
+	config                 *config.ServiceImpl
+	pluginAPI              *pluginapi.Client
+	propertyService        app.PropertyService
+	propertyFieldService   app.PropertyFieldService
+	viewService            app.ViewService
+	viewWatchService       app.ViewWatchService
+	ruleService            app.RuleService
+	patternService         app.PatternService
+	reactionMappingService app.ReactionMappingService
+	webhookService         app.WebhookService
+	tokenService           app.TokenService
+	reminderService        app.ReminderService
+	digestService          app.DigestService
+	formulaService         app.FormulaService
+	rollupService          app.RollupService
+	permissions            *app.PermissionsService
+	botID                  string
+
+	reminderJob *cluster.Job
+	digestJob   *cluster.Job
+	formulaJob  *cluster.Job
+	rollupJob   *cluster.Job
+	webhookJob  *cluster.Job
 }
 
 func (p *Plugin) OnActivate() error {
@@ -46,10 +81,39 @@ func (p *Plugin) OnActivate() error {
 	propertyStore := sqlstore.NewPropertyStore(apiClient, sqlStore)
 	viewStore := sqlstore.NewViewStore(apiClient, sqlStore)
 	viewMemberStore := sqlstore.NewViewMemberStore(apiClient, sqlStore)
+	viewWatchStore := sqlstore.NewViewWatchStore(apiClient, sqlStore)
+	ruleStore := sqlstore.NewRuleStore(apiClient, sqlStore)
+	patternStore := sqlstore.NewPatternStore(apiClient, sqlStore)
+	reactionMappingStore := sqlstore.NewReactionMappingStore(apiClient, sqlStore)
+	webhookStore := sqlstore.NewWebhookStore(apiClient, sqlStore)
+	tokenStore := sqlstore.NewTokenStore(apiClient, sqlStore)
+	reminderStore := sqlstore.NewReminderStore(apiClient, sqlStore)
+	digestStore := sqlstore.NewDigestStore(apiClient, sqlStore)
+
+	botID, err := pluginAPIClient.Bot.EnsureBot(&model.Bot{
+		Username:    "properties",
+		DisplayName: "Properties",
+		Description: "Posts the messages of the Properties plugin.",
+	})
+	if err != nil {
+		return errors.Wrapf(err, "failed to ensure bot")
+	}
+	p.botID = botID
 
 	p.propertyFieldService = app.NewPropertyFieldService(propertyFieldStore, pluginAPIClient)
 	p.propertyService = app.NewPropertyService(propertyStore, p.propertyFieldService, pluginAPIClient)
-	p.viewService = app.NewViewService(viewStore, viewMemberStore, p.propertyService, pluginAPIClient)
+	p.viewService = app.NewViewService(viewStore, viewMemberStore, p.propertyService, p.propertyFieldService, pluginAPIClient)
+	p.ruleService = app.NewRuleService(ruleStore, p.propertyService, p.propertyFieldService, pluginAPIClient, p.botID)
+	p.patternService = app.NewPatternService(patternStore, p.propertyService, p.propertyFieldService, pluginAPIClient, p.botID)
+	p.permissions = app.NewPermissionsService(p.propertyService, p.propertyFieldService, pluginAPIClient, p.config)
+	p.reactionMappingService = app.NewReactionMappingService(reactionMappingStore, p.propertyService, p.propertyFieldService, p.permissions, pluginAPIClient)
+	p.webhookService = app.NewWebhookService(webhookStore, p.propertyService, pluginAPIClient)
+	p.tokenService = app.NewTokenService(tokenStore, p.propertyService, p.propertyFieldService, pluginAPIClient)
+	p.reminderService = app.NewReminderService(reminderStore, p.propertyService, p.propertyFieldService, p.viewService, pluginAPIClient, p.botID)
+	p.viewWatchService = app.NewViewWatchService(viewWatchStore, p.viewService, p.propertyService, pluginAPIClient, p.botID)
+	p.digestService = app.NewDigestService(digestStore, p.viewService, p.propertyFieldService, pluginAPIClient, p.botID)
+	p.formulaService = app.NewFormulaService(propertyStore, p.propertyService, p.propertyFieldService, pluginAPIClient)
+	p.rollupService = app.NewRollupService(p.propertyService, p.propertyFieldService, pluginAPIClient)
 
 	mutex, err := cluster.NewMutex(p.API, "PROP_dbMutex")
 	if err != nil {
@@ -63,7 +127,6 @@ func (p *Plugin) OnActivate() error {
 	mutex.Unlock()
 
 	p.handler = api.NewHandler(pluginAPIClient, p.config)
-	p.permissions = app.NewPermissionsService(p.propertyService, p.propertyFieldService, pluginAPIClient, p.config)
 
 	api.NewPropertyHandler(
 		p.handler.APIRouter,
@@ -84,14 +147,197 @@ func (p *Plugin) OnActivate() error {
 	api.NewViewHandler(
 		p.handler.APIRouter,
 		p.viewService,
+		p.viewWatchService,
+		pluginAPIClient,
+		p.config,
+		p.permissions,
+	)
+
+	api.NewRuleHandler(
+		p.handler.APIRouter,
+		p.ruleService,
+		pluginAPIClient,
+		p.config,
+		p.permissions,
+	)
+
+	api.NewPatternHandler(
+		p.handler.APIRouter,
+		p.patternService,
+		pluginAPIClient,
+		p.config,
+		p.permissions,
+	)
+
+	api.NewReactionMappingHandler(
+		p.handler.APIRouter,
+		p.reactionMappingService,
 		pluginAPIClient,
 		p.config,
 		p.permissions,
 	)
 
+	api.NewWebhookHandler(
+		p.handler.APIRouter,
+		p.webhookService,
+		pluginAPIClient,
+		p.config,
+		p.permissions,
+	)
+
+	api.NewTokenHandler(
+		p.handler.APIRouter,
+		p.handler.ExternalRouter,
+		p.tokenService,
+		pluginAPIClient,
+		p.config,
+		p.permissions,
+	)
+
+	api.NewReminderHandler(
+		p.handler.APIRouter,
+		p.reminderService,
+		pluginAPIClient,
+		p.config,
+		p.permissions,
+	)
+
+	api.NewDigestHandler(
+		p.handler.APIRouter,
+		p.digestService,
+		pluginAPIClient,
+		p.config,
+		p.permissions,
+	)
+
+	api.NewCommandHandler(
+		p.handler.APIRouter,
+		p.propertyFieldService,
+		p.viewService,
+		pluginAPIClient,
+		p.config,
+	)
+
+	if err := command.RegisterCommands(p.API.RegisterCommand); err != nil {
+		return errors.Wrapf(err, "failed to register commands")
+	}
+
+	// Scheduled through the cluster so only one node sends the reminders
+	p.reminderJob, err = cluster.Schedule(p.API, "PROP_reminders", cluster.MakeWaitForRoundedInterval(reminderInterval), p.reminderService.Run)
+	if err != nil {
+		return errors.Wrapf(err, "failed to schedule reminders job")
+	}
+
+	p.digestJob, err = cluster.Schedule(p.API, "PROP_digests", cluster.MakeWaitForRoundedInterval(digestInterval), p.digestService.Run)
+	if err != nil {
+		return errors.Wrapf(err, "failed to schedule digests job")
+	}
+
+	p.formulaJob, err = cluster.Schedule(p.API, "PROP_formulas", cluster.MakeWaitForRoundedInterval(formulaInterval), p.formulaService.Run)
+	if err != nil {
+		return errors.Wrapf(err, "failed to schedule formulas job")
+	}
+
+	p.rollupJob, err = cluster.Schedule(p.API, "PROP_rollups", cluster.MakeWaitForRoundedInterval(rollupInterval), p.rollupService.Run)
+	if err != nil {
+		return errors.Wrapf(err, "failed to schedule rollups job")
+	}
+
+	p.webhookJob, err = cluster.Schedule(p.API, "PROP_webhooks", cluster.MakeWaitForRoundedInterval(webhookInterval), p.webhookService.Run)
+	if err != nil {
+		return errors.Wrapf(err, "failed to schedule webhooks job")
+	}
+
 	return nil
 }
 
+func (p *Plugin) OnDeactivate() error {
+	if p.reminderJob != nil {
+		if err := p.reminderJob.Close(); err != nil {
+			return errors.Wrapf(err, "failed to close reminders job")
+		}
+	}
+
+	if p.digestJob != nil {
+		if err := p.digestJob.Close(); err != nil {
+			return errors.Wrapf(err, "failed to close digests job")
+		}
+	}
+
+	if p.formulaJob != nil {
+		if err := p.formulaJob.Close(); err != nil {
+			return errors.Wrapf(err, "failed to close formulas job")
+		}
+	}
+
+	if p.rollupJob != nil {
+		if err := p.rollupJob.Close(); err != nil {
+			return errors.Wrapf(err, "failed to close rollups job")
+		}
+	}
+
+	if p.webhookJob != nil {
+		if err := p.webhookJob.Close(); err != nil {
+			return errors.Wrapf(err, "failed to close webhooks job")
+		}
+	}
+
+	// Computed values notify the view watches, so formulas and rollups are waited on first
+	if p.formulaService != nil {
+		p.formulaService.Close()
+	}
+
+	if p.rollupService != nil {
+		p.rollupService.Close()
+	}
+
+	if p.viewWatchService != nil {
+		p.viewWatchService.Close()
+	}
+
+	return nil
+}
+
+// ExecuteCommand executes a command that has been previously registered via the RegisterCommand.
+func (p *Plugin) ExecuteCommand(c *plugin.Context, args *model.CommandArgs) (*model.CommandResponse, *model.AppError) {
+	runner := command.NewCommandRunner(c, args, p.pluginAPI, p.propertyService, p.propertyFieldService, p.viewService, p.permissions)
+
+	response, err := runner.Execute()
+	if err != nil {
+		return nil, model.NewAppError("Properties.ExecuteCommand", "app.command.execute.error", nil, err.Error(), http.StatusInternalServerError)
+	}
+
+	return response, nil
+}
+
+// MessageHasBeenPosted sets the properties of the patterns matching the new post.
+func (p *Plugin) MessageHasBeenPosted(c *plugin.Context, post *model.Post) {
+	if err := p.patternService.ApplyToPost(post); err != nil {
+		p.API.LogWarn("Failed to apply patterns to post", "post_id", post.Id, "error", err.Error())
+	}
+}
+
+// MessageHasBeenDeleted recomputes the rollups the deleted post was counted in.
+func (p *Plugin) MessageHasBeenDeleted(c *plugin.Context, post *model.Post) {
+	if err := p.rollupService.ApplyPostDeleted(post); err != nil {
+		p.API.LogWarn("Failed to recompute rollups of deleted post", "post_id", post.Id, "error", err.Error())
+	}
+}
+
+// ReactionHasBeenAdded sets the properties mapped to the emoji of the new reaction.
+func (p *Plugin) ReactionHasBeenAdded(c *plugin.Context, reaction *model.Reaction) {
+	if err := p.reactionMappingService.ApplyReactionAdded(reaction); err != nil {
+		p.API.LogWarn("Failed to apply reaction mappings", "post_id", reaction.PostId, "error", err.Error())
+	}
+}
+
+// ReactionHasBeenRemoved clears the properties mapped to the emoji of the removed reaction.
+func (p *Plugin) ReactionHasBeenRemoved(c *plugin.Context, reaction *model.Reaction) {
+	if err := p.reactionMappingService.ApplyReactionRemoved(reaction); err != nil {
+		p.API.LogWarn("Failed to apply reaction mappings", "post_id", reaction.PostId, "error", err.Error())
+	}
+}
+
 func (p *Plugin) ServeHTTP(c *plugin.Context, w http.ResponseWriter, r *http.Request) {
 	p.handler.ServeHTTP(w, r)
 }
@@ -1,17 +1,18 @@
 package main
 
 import (
-	"io"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+
+	"github.com/jwilander/mattermost-plugin-properties/server/api"
 )
 
 func TestServeHTTP(t *testing.T) {
 	assert := assert.New(t)
-	plugin := Plugin{}
+	plugin := Plugin{handler: api.NewHandler(nil, nil)}
 	w := httptest.NewRecorder()
 	r := httptest.NewRequest(http.MethodGet, "/", nil)
 
@@ -20,9 +21,6 @@ func TestServeHTTP(t *testing.T) {
 	result := w.Result()
 	assert.NotNil(result)
 	defer result.Body.Close()
-	bodyBytes, err := io.ReadAll(result.Body)
-	assert.Nil(err)
-	bodyString := string(bodyBytes)
 
-	assert.Equal("Hello, world!", bodyString)
+	assert.Equal(http.StatusNotFound, result.StatusCode)
 }
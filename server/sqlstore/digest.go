@@ -0,0 +1,192 @@
+package sqlstore
+
+import (
+	"database/sql"
+
+	"github.com/jwilander/mattermost-plugin-properties/server/app"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/pkg/errors"
+)
+
+type digestStore struct {
+	pluginAPI    PluginAPIClient
+	store        *SQLStore
+	queryBuilder sq.StatementBuilderType
+	digestSelect sq.SelectBuilder
+}
+
+// Ensure digestStore implements app.DigestStore interface
+var _ app.DigestStore = (*digestStore)(nil)
+
+func NewDigestStore(pluginAPI PluginAPIClient, sqlStore *SQLStore) app.DigestStore {
+	digestSelect := sqlStore.builder.
+		Select(
+			"d.ID",
+			"d.ViewID",
+			"d.UserID",
+			"d.ChannelID",
+			"d.Frequency",
+			"d.Weekday",
+			"d.Time",
+			"d.Timezone",
+			"d.LastSentAt",
+			"d.NextRunAt",
+			"d.CreateAt",
+			"d.UpdateAt",
+		).
+		From("PROP_Digest d")
+
+	return &digestStore{
+		pluginAPI:    pluginAPI,
+		store:        sqlStore,
+		queryBuilder: sqlStore.builder,
+		digestSelect: digestSelect,
+	}
+}
+
+func (d *digestStore) Create(digest app.Digest) (string, error) {
+	if digest.ID != "" {
+		return "", errors.New("ID should be empty")
+	}
+	digest.ID = model.NewId()
+	digest.CreateAt = model.GetMillis()
+	digest.UpdateAt = digest.CreateAt
+
+	tx, err := d.store.db.Beginx()
+	if err != nil {
+		return "", errors.Wrap(err, "could not begin transaction")
+	}
+	defer d.store.finalizeTransaction(tx)
+
+	_, err = d.store.execBuilder(tx, sq.
+		Insert("PROP_Digest").
+		SetMap(map[string]interface{}{
+			"ID":         digest.ID,
+			"ViewID":     digest.ViewID,
+			"UserID":     digest.UserID,
+			"ChannelID":  digest.ChannelID,
+			"Frequency":  digest.Frequency,
+			"Weekday":    digest.Weekday,
+			"Time":       digest.Time,
+			"Timezone":   digest.Timezone,
+			"LastSentAt": 0,
+			"NextRunAt":  digest.NextRunAt,
+			"CreateAt":   digest.CreateAt,
+			"UpdateAt":   digest.UpdateAt,
+		}))
+	if err != nil {
+		return "", errors.Wrap(err, "failed to store new digest")
+	}
+
+	if err = tx.Commit(); err != nil {
+		return "", errors.Wrap(err, "could not commit transaction")
+	}
+
+	return digest.ID, nil
+}
+
+func (d *digestStore) Get(id string) (app.Digest, error) {
+	if id == "" {
+		return app.Digest{}, errors.New("id cannot be blank")
+	}
+
+	var digest app.Digest
+	err := d.store.getBuilder(d.store.db, &digest, d.digestSelect.Where(sq.Eq{"d.ID": id}))
+	if err == sql.ErrNoRows {
+		return app.Digest{}, errors.Wrapf(app.ErrNotFound, "no digest exists for id '%s'", id)
+	} else if err != nil {
+		return app.Digest{}, errors.Wrapf(err, "failed to get digest by id '%s'", id)
+	}
+
+	return digest, nil
+}
+
+func (d *digestStore) GetForUser(userID string) ([]app.Digest, error) {
+	return d.selectDigests(d.digestSelect.
+		Where(sq.Eq{"d.UserID": userID}).
+		OrderBy("d.CreateAt ASC"))
+}
+
+func (d *digestStore) GetDue(now int64) ([]app.Digest, error) {
+	return d.selectDigests(d.digestSelect.
+		Where(sq.LtOrEq{"d.NextRunAt": now}).
+		OrderBy("d.NextRunAt ASC"))
+}
+
+func (d *digestStore) selectDigests(query sq.SelectBuilder) ([]app.Digest, error) {
+	var digests []app.Digest
+	err := d.store.selectBuilder(d.store.db, &digests, query)
+	if err != nil && err != sql.ErrNoRows {
+		return []app.Digest{}, errors.Wrap(err, "failed to get digests")
+	}
+
+	if digests == nil {
+		digests = []app.Digest{}
+	}
+
+	return digests, nil
+}
+
+func (d *digestStore) Update(digest app.Digest) error {
+	digest.UpdateAt = model.GetMillis()
+
+	tx, err := d.store.db.Beginx()
+	if err != nil {
+		return errors.Wrap(err, "could not begin transaction")
+	}
+	defer d.store.finalizeTransaction(tx)
+
+	_, err = d.store.execBuilder(tx, sq.
+		Update("PROP_Digest").
+		SetMap(map[string]interface{}{
+			"ChannelID": digest.ChannelID,
+			"Frequency": digest.Frequency,
+			"Weekday":   digest.Weekday,
+			"Time":      digest.Time,
+			"Timezone":  digest.Timezone,
+			"NextRunAt": digest.NextRunAt,
+			"UpdateAt":  digest.UpdateAt,
+		}).
+		Where(sq.Eq{"ID": digest.ID}))
+	if err != nil {
+		return errors.Wrapf(err, "failed to update digest with id '%s'", digest.ID)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return errors.Wrap(err, "could not commit transaction")
+	}
+
+	return nil
+}
+
+func (d *digestStore) UpdateSent(id string, lastSentAt int64, nextRunAt int64) error {
+	_, err := d.store.execBuilder(d.store.db, sq.
+		Update("PROP_Digest").
+		SetMap(map[string]interface{}{
+			"LastSentAt": lastSentAt,
+			"NextRunAt":  nextRunAt,
+		}).
+		Where(sq.Eq{"ID": id}))
+	if err != nil {
+		return errors.Wrapf(err, "failed to update last sent time of digest with id '%s'", id)
+	}
+
+	return nil
+}
+
+func (d *digestStore) Delete(id string) error {
+	if id == "" {
+		return errors.New("id cannot be blank")
+	}
+
+	_, err := d.store.execBuilder(d.store.db, sq.
+		Delete("PROP_Digest").
+		Where(sq.Eq{"ID": id}))
+	if err != nil {
+		return errors.Wrapf(err, "failed to delete digest with id '%s'", id)
+	}
+
+	return nil
+}
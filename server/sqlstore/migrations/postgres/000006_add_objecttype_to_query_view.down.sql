@@ -0,0 +1,3 @@
+DROP VIEW IF EXISTS PROP_Property_Query_View;
+
+CREATE VIEW PROP_Property_Query_View AS SELECT ObjectID, TeamID, ChannelID, json_object_agg(PropertyFieldID, Value) AS Properties from PROP_Property GROUP BY ObjectID, TeamID, ChannelID;
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_PROP_rule_propertyfieldid;
+
+DROP TABLE IF EXISTS PROP_Rule;
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS PROP_Rule (
+    ID TEXT PRIMARY KEY,
+    TeamID TEXT NOT NULL,
+    ChannelID TEXT NOT NULL,
+    PropertyFieldID TEXT NOT NULL,
+    CreateAt BIGINT NOT NULL,
+    UpdateAt BIGINT NOT NULL,
+    UpdateBy TEXT NOT NULL,
+    Value JSON NOT NULL,
+    Actions JSON NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_PROP_rule_propertyfieldid ON PROP_Rule (PropertyFieldID);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_PROP_pattern_teamid_channelid;
+
+DROP TABLE IF EXISTS PROP_Pattern;
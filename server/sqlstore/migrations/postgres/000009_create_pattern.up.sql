@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS PROP_Pattern (
+    ID TEXT PRIMARY KEY,
+    TeamID TEXT NOT NULL,
+    ChannelID TEXT NOT NULL,
+    Type TEXT NOT NULL,
+    Match TEXT NOT NULL,
+    PropertyFieldID TEXT NOT NULL,
+    AttachmentField TEXT NOT NULL,
+    CreateAt BIGINT NOT NULL,
+    UpdateAt BIGINT NOT NULL,
+    UpdateBy TEXT NOT NULL,
+    Value JSON NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_PROP_pattern_teamid_channelid ON PROP_Pattern (TeamID, ChannelID);
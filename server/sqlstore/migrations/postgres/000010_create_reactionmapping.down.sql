@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_PROP_reactionmapping_channelid_emojiname;
+
+DROP TABLE IF EXISTS PROP_ReactionMapping;
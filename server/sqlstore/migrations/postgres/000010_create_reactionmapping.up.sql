@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS PROP_ReactionMapping (
+    ID TEXT PRIMARY KEY,
+    TeamID TEXT NOT NULL,
+    ChannelID TEXT NOT NULL,
+    EmojiName TEXT NOT NULL,
+    PropertyFieldID TEXT NOT NULL,
+    ValueFromReactor BOOLEAN NOT NULL DEFAULT FALSE,
+    CreateAt BIGINT NOT NULL,
+    UpdateAt BIGINT NOT NULL,
+    UpdateBy TEXT NOT NULL,
+    Value JSON NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_PROP_reactionmapping_channelid_emojiname ON PROP_ReactionMapping (ChannelID, EmojiName);
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_PROP_webhookdelivery_status_nextattemptat;
+DROP INDEX IF EXISTS idx_PROP_webhookdelivery_webhookid_createat;
+
+DROP TABLE IF EXISTS PROP_WebhookDelivery;
+
+DROP TABLE IF EXISTS PROP_Webhook;
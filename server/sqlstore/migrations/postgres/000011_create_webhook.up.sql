@@ -0,0 +1,29 @@
+CREATE TABLE IF NOT EXISTS PROP_Webhook (
+    ID TEXT PRIMARY KEY,
+    TeamID TEXT NOT NULL,
+    ChannelID TEXT NOT NULL,
+    PropertyFieldID TEXT NOT NULL,
+    URL TEXT NOT NULL,
+    Secret TEXT NOT NULL,
+    CreateAt BIGINT NOT NULL,
+    UpdateAt BIGINT NOT NULL,
+    UpdateBy TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS PROP_WebhookDelivery (
+    ID TEXT PRIMARY KEY,
+    WebhookID TEXT NOT NULL,
+    ChangeType TEXT NOT NULL,
+    PropertyID TEXT NOT NULL,
+    Payload JSON NOT NULL,
+    Status TEXT NOT NULL,
+    Attempts INTEGER NOT NULL,
+    StatusCode INTEGER NOT NULL,
+    Error TEXT NOT NULL,
+    NextAttemptAt BIGINT NOT NULL,
+    CreateAt BIGINT NOT NULL,
+    UpdateAt BIGINT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_PROP_webhookdelivery_webhookid_createat ON PROP_WebhookDelivery (WebhookID, CreateAt);
+CREATE INDEX IF NOT EXISTS idx_PROP_webhookdelivery_status_nextattemptat ON PROP_WebhookDelivery (Status, NextAttemptAt);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_PROP_token_teamid;
+DROP INDEX IF EXISTS idx_PROP_token_tokenhash;
+
+DROP TABLE IF EXISTS PROP_Token;
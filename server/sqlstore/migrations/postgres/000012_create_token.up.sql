@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS PROP_Token (
+    ID TEXT PRIMARY KEY,
+    TeamID TEXT NOT NULL,
+    Name TEXT NOT NULL,
+    FieldIDs JSON NOT NULL,
+    CreateAt BIGINT NOT NULL,
+    CreateBy TEXT NOT NULL,
+    LastUsedAt BIGINT NOT NULL,
+    TokenHash TEXT NOT NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_PROP_token_tokenhash ON PROP_Token (TokenHash);
+CREATE INDEX IF NOT EXISTS idx_PROP_token_teamid ON PROP_Token (TeamID);
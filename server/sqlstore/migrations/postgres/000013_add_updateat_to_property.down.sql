@@ -0,0 +1 @@
+ALTER TABLE PROP_Property DROP COLUMN IF EXISTS UpdateAt;
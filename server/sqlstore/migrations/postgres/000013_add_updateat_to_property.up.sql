@@ -0,0 +1 @@
+ALTER TABLE PROP_Property ADD COLUMN IF NOT EXISTS UpdateAt BIGINT NOT NULL DEFAULT 0;
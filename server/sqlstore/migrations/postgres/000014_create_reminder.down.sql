@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_PROP_property_propertyfieldid;
+
+DROP TABLE IF EXISTS PROP_ReminderSent;
+
+DROP TABLE IF EXISTS PROP_Reminder;
@@ -0,0 +1,25 @@
+CREATE TABLE IF NOT EXISTS PROP_Reminder (
+    ID TEXT PRIMARY KEY,
+    TeamID TEXT NOT NULL,
+    ViewID TEXT NOT NULL,
+    PropertyFieldID TEXT NOT NULL,
+    Type TEXT NOT NULL,
+    Duration BIGINT NOT NULL,
+    ChannelID TEXT NOT NULL,
+    UserFieldID TEXT NOT NULL,
+    CreateAt BIGINT NOT NULL,
+    UpdateAt BIGINT NOT NULL,
+    UpdateBy TEXT NOT NULL,
+    Values JSON NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS PROP_ReminderSent (
+    ReminderID TEXT NOT NULL,
+    PropertyID TEXT NOT NULL,
+    Notification TEXT NOT NULL,
+    PropertyUpdateAt BIGINT NOT NULL,
+    SentAt BIGINT NOT NULL,
+    PRIMARY KEY (ReminderID, PropertyID, Notification, PropertyUpdateAt)
+);
+
+CREATE INDEX IF NOT EXISTS idx_PROP_property_propertyfieldid ON PROP_Property (PropertyFieldID);
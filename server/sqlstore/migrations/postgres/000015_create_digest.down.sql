@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_PROP_digest_nextrunat;
+
+DROP INDEX IF EXISTS idx_PROP_digest_userid;
+
+DROP TABLE IF EXISTS PROP_Digest;
@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS PROP_Digest (
+    ID TEXT PRIMARY KEY,
+    ViewID TEXT NOT NULL,
+    UserID TEXT NOT NULL,
+    ChannelID TEXT NOT NULL,
+    Frequency TEXT NOT NULL,
+    Weekday INTEGER NOT NULL,
+    Time TEXT NOT NULL,
+    Timezone TEXT NOT NULL,
+    LastSentAt BIGINT NOT NULL,
+    NextRunAt BIGINT NOT NULL,
+    CreateAt BIGINT NOT NULL,
+    UpdateAt BIGINT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_PROP_digest_userid ON PROP_Digest (UserID);
+CREATE INDEX IF NOT EXISTS idx_PROP_digest_nextrunat ON PROP_Digest (NextRunAt);
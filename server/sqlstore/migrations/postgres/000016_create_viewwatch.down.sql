@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_PROP_viewwatch_userid;
+
+DROP TABLE IF EXISTS PROP_ViewWatch;
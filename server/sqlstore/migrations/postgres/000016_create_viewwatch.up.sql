@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS PROP_ViewWatch (
+    ViewID TEXT NOT NULL,
+    UserID TEXT NOT NULL,
+    CreateAt BIGINT NOT NULL,
+    PRIMARY KEY (ViewID, UserID)
+);
+
+CREATE INDEX IF NOT EXISTS idx_PROP_viewwatch_userid ON PROP_ViewWatch (UserID);
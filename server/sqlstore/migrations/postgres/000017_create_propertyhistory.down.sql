@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_PROP_propertyhistory_propertyfieldid_objectid_createat;
+DROP INDEX IF EXISTS idx_PROP_propertyhistory_propertyfieldid_createat;
+
+DROP TABLE IF EXISTS PROP_PropertyHistory;
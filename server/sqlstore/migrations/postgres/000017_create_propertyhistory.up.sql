@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS PROP_PropertyHistory (
+    PropertyID TEXT NOT NULL,
+    ObjectID TEXT NOT NULL,
+    PropertyFieldID TEXT NOT NULL,
+    Value JSON NOT NULL,
+    CreateAt BIGINT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_PROP_propertyhistory_propertyfieldid_createat ON PROP_PropertyHistory (PropertyFieldID, CreateAt);
+CREATE INDEX IF NOT EXISTS idx_PROP_propertyhistory_propertyfieldid_objectid_createat ON PROP_PropertyHistory (PropertyFieldID, ObjectID, CreateAt);
+
+-- Existing values are known from their last update on
+INSERT INTO PROP_PropertyHistory (PropertyID, ObjectID, PropertyFieldID, Value, CreateAt)
+    SELECT ID, ObjectID, PropertyFieldID, Value, UpdateAt FROM PROP_Property;
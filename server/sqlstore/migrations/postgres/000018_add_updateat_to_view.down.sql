@@ -0,0 +1 @@
+ALTER TABLE PROP_View DROP COLUMN IF EXISTS UpdateAt;
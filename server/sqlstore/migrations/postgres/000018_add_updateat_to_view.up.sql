@@ -0,0 +1,3 @@
+ALTER TABLE PROP_View ADD COLUMN IF NOT EXISTS UpdateAt BIGINT NOT NULL DEFAULT 0;
+
+UPDATE PROP_View SET UpdateAt = CreateAt WHERE UpdateAt = 0;
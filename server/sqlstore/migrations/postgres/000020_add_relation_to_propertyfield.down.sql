@@ -0,0 +1 @@
+ALTER TABLE PROP_PropertyField DROP COLUMN IF EXISTS Relation;
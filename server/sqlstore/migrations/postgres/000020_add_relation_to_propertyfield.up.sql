@@ -0,0 +1 @@
+ALTER TABLE PROP_PropertyField ADD COLUMN IF NOT EXISTS Relation JSON;
@@ -0,0 +1,265 @@
+package sqlstore
+
+import (
+	"database/sql"
+	"encoding/json"
+
+	"github.com/jwilander/mattermost-plugin-properties/server/app"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/pkg/errors"
+)
+
+type sqlPattern struct {
+	app.Pattern
+	ValueJSON json.RawMessage `db:"value"`
+}
+
+type patternStore struct {
+	pluginAPI     PluginAPIClient
+	store         *SQLStore
+	queryBuilder  sq.StatementBuilderType
+	patternSelect sq.SelectBuilder
+}
+
+// Ensure patternStore implements app.PatternStore interface
+var _ app.PatternStore = (*patternStore)(nil)
+
+func NewPatternStore(pluginAPI PluginAPIClient, sqlStore *SQLStore) app.PatternStore {
+	patternSelect := sqlStore.builder.
+		Select(
+			"pa.ID",
+			"pa.TeamID",
+			"pa.ChannelID",
+			"pa.Type",
+			"pa.Match",
+			"pa.PropertyFieldID",
+			"pa.AttachmentField",
+			"pa.CreateAt",
+			"pa.UpdateAt",
+			"pa.UpdateBy",
+			"pa.Value",
+		).
+		From("PROP_Pattern pa")
+
+	return &patternStore{
+		pluginAPI:     pluginAPI,
+		store:         sqlStore,
+		queryBuilder:  sqlStore.builder,
+		patternSelect: patternSelect,
+	}
+}
+
+func (p *patternStore) Create(pattern app.Pattern) (string, error) {
+	if pattern.ID != "" {
+		return "", errors.New("ID should be empty")
+	}
+	pattern.ID = model.NewId()
+	pattern.CreateAt = model.GetMillis()
+	pattern.UpdateAt = pattern.CreateAt
+
+	rawPattern, err := toSQLPattern(pattern)
+	if err != nil {
+		return "", err
+	}
+
+	tx, err := p.store.db.Beginx()
+	if err != nil {
+		return "", errors.Wrap(err, "could not begin transaction")
+	}
+	defer p.store.finalizeTransaction(tx)
+
+	_, err = p.store.execBuilder(tx, sq.
+		Insert("PROP_Pattern").
+		SetMap(map[string]interface{}{
+			"ID":              rawPattern.ID,
+			"TeamID":          rawPattern.TeamID,
+			"ChannelID":       rawPattern.ChannelID,
+			"Type":            rawPattern.Type,
+			"Match":           rawPattern.Match,
+			"PropertyFieldID": rawPattern.PropertyFieldID,
+			"AttachmentField": rawPattern.AttachmentField,
+			"CreateAt":        rawPattern.CreateAt,
+			"UpdateAt":        rawPattern.UpdateAt,
+			"UpdateBy":        rawPattern.UpdateBy,
+			"Value":           rawPattern.ValueJSON,
+		}))
+	if err != nil {
+		return "", errors.Wrap(err, "failed to store new pattern")
+	}
+
+	if err = tx.Commit(); err != nil {
+		return "", errors.Wrap(err, "could not commit transaction")
+	}
+
+	return rawPattern.ID, nil
+}
+
+func (p *patternStore) Get(id string) (app.Pattern, error) {
+	if id == "" {
+		return app.Pattern{}, errors.New("id cannot be blank")
+	}
+
+	var rawPattern sqlPattern
+	err := p.store.getBuilder(p.store.db, &rawPattern, p.patternSelect.Where(sq.Eq{"pa.ID": id}))
+	if err == sql.ErrNoRows {
+		return app.Pattern{}, errors.Wrapf(app.ErrNotFound, "no pattern exists for id '%s'", id)
+	} else if err != nil {
+		return app.Pattern{}, errors.Wrapf(err, "failed to get pattern by id '%s'", id)
+	}
+
+	return toPattern(rawPattern)
+}
+
+func (p *patternStore) GetPatterns(filter app.PatternFilterOptions) ([]app.Pattern, error) {
+	query := p.patternSelect
+
+	if filter.TeamID != "" {
+		query = query.Where(sq.Eq{"pa.TeamID": filter.TeamID})
+	}
+
+	if filter.ChannelID != "" {
+		query = query.Where(sq.Eq{"pa.ChannelID": filter.ChannelID})
+	}
+
+	page := filter.Page
+	perPage := filter.PerPage
+	if page < 0 {
+		page = 0
+	}
+	if perPage < 0 {
+		perPage = 0
+	}
+
+	query = query.
+		OrderBy("pa.CreateAt ASC").
+		Offset(uint64(page * perPage)).
+		Limit(uint64(perPage))
+
+	return p.selectPatterns(query)
+}
+
+// GetForChannel returns the patterns of the channel followed by those of its whole team.
+func (p *patternStore) GetForChannel(teamID string, channelID string) ([]app.Pattern, error) {
+	query := p.patternSelect.
+		Where(sq.Eq{"pa.TeamID": teamID}).
+		Where(sq.Or{
+			sq.Eq{"pa.ChannelID": channelID},
+			sq.Eq{"pa.ChannelID": ""},
+		}).
+		OrderBy("pa.ChannelID DESC", "pa.CreateAt ASC")
+
+	return p.selectPatterns(query)
+}
+
+func (p *patternStore) selectPatterns(query sq.SelectBuilder) ([]app.Pattern, error) {
+	var rawPatterns []sqlPattern
+	err := p.store.selectBuilder(p.store.db, &rawPatterns, query)
+	if err != nil && err != sql.ErrNoRows {
+		return []app.Pattern{}, errors.Wrap(err, "failed to get patterns")
+	}
+
+	patterns := make([]app.Pattern, len(rawPatterns))
+	for i, rawPattern := range rawPatterns {
+		patterns[i], err = toPattern(rawPattern)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return patterns, nil
+}
+
+func (p *patternStore) Update(pattern app.Pattern) error {
+	pattern.UpdateAt = model.GetMillis()
+
+	rawPattern, err := toSQLPattern(pattern)
+	if err != nil {
+		return err
+	}
+
+	tx, err := p.store.db.Beginx()
+	if err != nil {
+		return errors.Wrap(err, "could not begin transaction")
+	}
+	defer p.store.finalizeTransaction(tx)
+
+	_, err = p.store.execBuilder(tx, sq.
+		Update("PROP_Pattern").
+		SetMap(map[string]interface{}{
+			"ChannelID":       rawPattern.ChannelID,
+			"Type":            rawPattern.Type,
+			"Match":           rawPattern.Match,
+			"PropertyFieldID": rawPattern.PropertyFieldID,
+			"AttachmentField": rawPattern.AttachmentField,
+			"UpdateAt":        rawPattern.UpdateAt,
+			"UpdateBy":        rawPattern.UpdateBy,
+			"Value":           rawPattern.ValueJSON,
+		}).
+		Where(sq.Eq{"ID": rawPattern.ID}))
+	if err != nil {
+		return errors.Wrapf(err, "failed to update pattern with id '%s'", rawPattern.ID)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return errors.Wrap(err, "could not commit transaction")
+	}
+
+	return nil
+}
+
+func (p *patternStore) Delete(id string) error {
+	if id == "" {
+		return errors.New("id cannot be blank")
+	}
+
+	tx, err := p.store.db.Beginx()
+	if err != nil {
+		return errors.Wrap(err, "could not begin transaction")
+	}
+	defer p.store.finalizeTransaction(tx)
+
+	_, err = p.store.execBuilder(tx, sq.
+		Delete("PROP_Pattern").
+		Where(sq.Eq{"ID": id}))
+	if err != nil {
+		return errors.Wrapf(err, "failed to delete pattern with id '%s'", id)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return errors.Wrap(err, "could not commit transaction")
+	}
+
+	return nil
+}
+
+func toSQLPattern(pattern app.Pattern) (*sqlPattern, error) {
+	if pattern.Value == nil {
+		pattern.Value = []interface{}{}
+	}
+	valueJSON, err := json.Marshal(pattern.Value)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to marshal value json for pattern id: '%s'", pattern.ID)
+	}
+
+	if len(valueJSON) > maxJSONLength {
+		return nil, errors.Errorf("value json for pattern id '%s' is too long (max %d)", pattern.ID, maxJSONLength)
+	}
+
+	return &sqlPattern{
+		Pattern:   pattern,
+		ValueJSON: valueJSON,
+	}, nil
+}
+
+func toPattern(rawPattern sqlPattern) (app.Pattern, error) {
+	p := rawPattern.Pattern
+	if len(rawPattern.ValueJSON) > 0 {
+		if err := json.Unmarshal(rawPattern.ValueJSON, &p.Value); err != nil {
+			return app.Pattern{}, errors.Wrapf(err, "failed to unmarshal value json for pattern id: '%s'", rawPattern.ID)
+		}
+	}
+
+	return p, nil
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/jwilander/mattermost-plugin-properties/server/app"
 
 	sq "github.com/Masterminds/squirrel"
+	"github.com/jmoiron/sqlx"
 	"github.com/mattermost/mattermost/server/public/model"
 	"github.com/pkg/errors"
 )
@@ -37,9 +38,11 @@ func NewPropertyStore(pluginAPI PluginAPIClient, sqlStore *SQLStore) app.Propert
 			"p.TeamID",
 			"p.PropertyFieldID",
 			"p.Value",
+			"COALESCE(p.UpdateAt, 0) as UpdateAt",
 			"pf.Name as PropertyFieldName",
 			"pf.Type as PropertyFieldType",
 			"pf.Values as PropertyFieldValues",
+			"pf.InheritToReplies as PropertyFieldInheritToReplies",
 		).
 		From("PROP_Property p").
 		RightJoin("PROP_PropertyField pf ON p.PropertyFieldID = pf.ID")
@@ -57,6 +60,7 @@ func (p *propertyStore) Create(property app.Property) (id string, err error) {
 		return "", errors.New("ID should be empty")
 	}
 	property.ID = model.NewId()
+	property.UpdateAt = model.GetMillis()
 
 	rawProperty, err := toSQLProperty(property)
 	if err != nil {
@@ -79,11 +83,16 @@ func (p *propertyStore) Create(property app.Property) (id string, err error) {
 			"TeamID":          rawProperty.TeamID,
 			"PropertyFieldID": rawProperty.PropertyFieldID,
 			"Value":           rawProperty.ValueJSON,
+			"UpdateAt":        rawProperty.UpdateAt,
 		}))
 	if err != nil {
 		return "", errors.Wrap(err, "failed to store new property")
 	}
 
+	if err = p.recordHistory(tx, rawProperty.ID, false); err != nil {
+		return "", err
+	}
+
 	if err = tx.Commit(); err != nil {
 		return "", errors.Wrap(err, "could not commit transaction")
 	}
@@ -91,6 +100,32 @@ func (p *propertyStore) Create(property app.Property) (id string, err error) {
 	return rawProperty.ID, nil
 }
 
+func (p *propertyStore) Get(id string) (app.Property, error) {
+	if id == "" {
+		return app.Property{}, errors.New("id cannot be blank")
+	}
+
+	tx, err := p.store.db.Beginx()
+	if err != nil {
+		return app.Property{}, errors.Wrap(err, "could not begin transaction")
+	}
+	defer p.store.finalizeTransaction(tx)
+
+	var rawProperty sqlProperty
+	err = p.store.getBuilder(tx, &rawProperty, p.propertySelect.Where(sq.Eq{"p.ID": id}))
+	if err == sql.ErrNoRows {
+		return app.Property{}, errors.Wrapf(app.ErrNotFound, "no property exists for id '%s'", id)
+	} else if err != nil {
+		return app.Property{}, errors.Wrapf(err, "failed to get property by id '%s'", id)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return app.Property{}, errors.Wrap(err, "could not commit transaction")
+	}
+
+	return toProperty(rawProperty)
+}
+
 func (p *propertyStore) GetByObjectID(objectID string) ([]app.Property, error) {
 	if objectID == "" {
 		return []app.Property{}, errors.New("objectID cannot be blank")
@@ -125,6 +160,151 @@ func (p *propertyStore) GetByObjectID(objectID string) ([]app.Property, error) {
 	return properties, nil
 }
 
+func (p *propertyStore) GetByFieldID(propertyFieldID string) ([]app.Property, error) {
+	if propertyFieldID == "" {
+		return []app.Property{}, errors.New("propertyFieldID cannot be blank")
+	}
+
+	var rawProperties []sqlProperty
+	err := p.store.selectBuilder(p.store.db, &rawProperties, p.propertySelect.Where(sq.Eq{"p.PropertyFieldID": propertyFieldID}))
+	if err != nil && err != sql.ErrNoRows {
+		return []app.Property{}, errors.Wrapf(err, "failed to get properties by property_field_id '%s'", propertyFieldID)
+	}
+
+	properties := make([]app.Property, len(rawProperties))
+	for i, rp := range rawProperties {
+		properties[i], err = toProperty(rp)
+		if err != nil {
+			return []app.Property{}, err
+		}
+	}
+
+	return properties, nil
+}
+
+func (p *propertyStore) GetForObjects(objectIDs []string, fieldIDs []string) ([]app.Property, error) {
+	if len(objectIDs) == 0 {
+		return []app.Property{}, nil
+	}
+
+	query := p.propertySelect.Where(sq.Eq{"p.ObjectID": objectIDs})
+	if len(fieldIDs) > 0 {
+		query = query.Where(sq.Eq{"p.PropertyFieldID": fieldIDs})
+	}
+
+	var rawProperties []sqlProperty
+	err := p.store.selectBuilder(p.store.db, &rawProperties, query)
+	if err != nil && err != sql.ErrNoRows {
+		return []app.Property{}, errors.Wrap(err, "failed to get properties of objects")
+	}
+
+	properties := make([]app.Property, len(rawProperties))
+	for i, rp := range rawProperties {
+		properties[i], err = toProperty(rp)
+		if err != nil {
+			return []app.Property{}, err
+		}
+	}
+
+	return properties, nil
+}
+
+func (p *propertyStore) GetProperties(filter app.PropertyFilterOptions) ([]app.Property, error) {
+	if filter.PropertyFieldID == "" {
+		return []app.Property{}, errors.New("propertyFieldID cannot be blank")
+	}
+
+	query := p.propertySelect.Where(sq.Eq{"p.PropertyFieldID": filter.PropertyFieldID})
+	if filter.TeamID != "" {
+		query = query.Where(sq.Eq{"p.TeamID": filter.TeamID})
+	}
+	if len(filter.ObjectIDs) > 0 {
+		query = query.Where(sq.Eq{"p.ObjectID": filter.ObjectIDs})
+	}
+	// Dates are stored as "2006-01-02" or RFC 3339 times, both starting with the day
+	if filter.DateFrom != "" {
+		query = query.Where(sq.GtOrEq{"LEFT(p.Value->>0, 10)": filter.DateFrom})
+	}
+	if filter.DateTo != "" {
+		query = query.Where(sq.LtOrEq{"LEFT(p.Value->>0, 10)": filter.DateTo})
+	}
+	if filter.UpdatedBefore != 0 {
+		query = query.Where(sq.Lt{"p.UpdateAt": filter.UpdatedBefore})
+	}
+	if filter.PerPage > 0 {
+		page := filter.Page
+		if page < 0 {
+			page = 0
+		}
+		query = query.
+			OrderBy("p.ID").
+			Offset(uint64(page * filter.PerPage)).
+			Limit(uint64(filter.PerPage))
+	}
+
+	var rawProperties []sqlProperty
+	err := p.store.selectBuilder(p.store.db, &rawProperties, query)
+	if err != nil && err != sql.ErrNoRows {
+		return []app.Property{}, errors.Wrapf(err, "failed to get properties of property_field_id '%s'", filter.PropertyFieldID)
+	}
+
+	properties := make([]app.Property, len(rawProperties))
+	for i, rp := range rawProperties {
+		properties[i], err = toProperty(rp)
+		if err != nil {
+			return []app.Property{}, err
+		}
+	}
+
+	return properties, nil
+}
+
+func (p *propertyStore) GetReferencing(objectID string) ([]app.Property, error) {
+	if objectID == "" {
+		return []app.Property{}, errors.New("objectID cannot be blank")
+	}
+
+	var rawProperties []sqlProperty
+	err := p.store.selectBuilder(p.store.db, &rawProperties, p.propertySelect.
+		Where(sq.Eq{"pf.Type": app.PropertyFieldTypeRelation}).
+		Where(sq.Expr("p.Value::jsonb ?? ?", objectID)))
+	if err != nil && err != sql.ErrNoRows {
+		return []app.Property{}, errors.Wrapf(err, "failed to get properties referencing object_id '%s'", objectID)
+	}
+
+	properties := make([]app.Property, len(rawProperties))
+	for i, rp := range rawProperties {
+		properties[i], err = toProperty(rp)
+		if err != nil {
+			return []app.Property{}, err
+		}
+	}
+
+	return properties, nil
+}
+
+func (p *propertyStore) GetObjects(teamID string, page int, perPage int) ([]app.PropertyObject, error) {
+	query := sq.
+		Select("p.ObjectID", "p.ObjectType", "p.ChannelID", "p.TeamID").
+		Options("DISTINCT ON (p.ObjectID)").
+		From("PROP_Property p").
+		OrderBy("p.ObjectID").
+		Offset(uint64(page * perPage)).
+		Limit(uint64(perPage))
+
+	if teamID != "" {
+		query = query.Where(sq.Eq{"p.TeamID": teamID})
+	}
+
+	var objects []app.PropertyObject
+	err := p.store.selectBuilder(p.store.db, &objects, query)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, errors.Wrapf(err, "failed to get objects of team '%s'", teamID)
+	}
+
+	return objects, nil
+}
+
 func (p *propertyStore) UpdateValue(id string, value []interface{}) error {
 	tx, err := p.store.db.Beginx()
 	if err != nil {
@@ -141,7 +321,8 @@ func (p *propertyStore) UpdateValue(id string, value []interface{}) error {
 	_, err = p.store.execBuilder(tx, sq.
 		Update("PROP_Property").
 		SetMap(map[string]interface{}{
-			"Value": rawProperty.ValueJSON,
+			"Value":    rawProperty.ValueJSON,
+			"UpdateAt": model.GetMillis(),
 		}).
 		Where(sq.Eq{"ID": id}))
 
@@ -149,6 +330,10 @@ func (p *propertyStore) UpdateValue(id string, value []interface{}) error {
 		return errors.Wrapf(err, "failed to update value of property with id '%s'", id)
 	}
 
+	if err = p.recordHistory(tx, id, false); err != nil {
+		return err
+	}
+
 	if err = tx.Commit(); err != nil {
 		return errors.Wrap(err, "could not commit transaction")
 	}
@@ -163,6 +348,10 @@ func (p *propertyStore) Delete(id string) error {
 	}
 	defer p.store.finalizeTransaction(tx)
 
+	if err = p.recordHistory(tx, id, true); err != nil {
+		return err
+	}
+
 	_, err = p.store.execBuilder(tx, sq.
 		Delete("PROP_Property").
 		Where(sq.Eq{"ID": id}))
@@ -178,6 +367,76 @@ func (p *propertyStore) Delete(id string) error {
 	return nil
 }
 
+// recordHistory copies the current value of the property to its history, or an empty value when
+// the property is about to be deleted.
+func (p *propertyStore) recordHistory(tx *sqlx.Tx, id string, deleted bool) error {
+	value := "p.Value"
+	if deleted {
+		value = "'[]'::json"
+	}
+
+	_, err := p.store.execBuilder(tx, sq.
+		Insert("PROP_PropertyHistory").
+		Columns("PropertyID", "ObjectID", "PropertyFieldID", "Value", "CreateAt").
+		Select(sq.
+			Select("p.ID", "p.ObjectID", "p.PropertyFieldID", value).
+			Column("?", model.GetMillis()).
+			From("PROP_Property p").
+			Where(sq.Eq{"p.ID": id})))
+	if err != nil {
+		return errors.Wrapf(err, "failed to record history of property with id '%s'", id)
+	}
+
+	return nil
+}
+
+type sqlPropertyHistoryEntry struct {
+	app.PropertyHistoryEntry
+	ValueJSON json.RawMessage `db:"value"`
+}
+
+func (p *propertyStore) GetHistoryForField(propertyFieldID string, since int64, until int64) ([]app.PropertyHistoryEntry, error) {
+	historySelect := sq.
+		Select("h.PropertyID", "h.ObjectID", "h.PropertyFieldID", "h.Value", "h.CreateAt").
+		From("PROP_PropertyHistory h").
+		Where(sq.Eq{"h.PropertyFieldID": propertyFieldID})
+
+	// The last value of each object before the window is the value it starts with
+	var rawEntries []sqlPropertyHistoryEntry
+	err := p.store.selectBuilder(p.store.db, &rawEntries, sq.
+		Select("*").
+		FromSelect(historySelect.
+			Options("DISTINCT ON (h.ObjectID)").
+			Where(sq.Lt{"h.CreateAt": since}).
+			OrderBy("h.ObjectID", "h.CreateAt DESC"), "s").
+		OrderBy("s.CreateAt ASC"))
+	if err != nil && err != sql.ErrNoRows {
+		return nil, errors.Wrapf(err, "failed to get history of property_field_id '%s'", propertyFieldID)
+	}
+
+	var rawWindowEntries []sqlPropertyHistoryEntry
+	err = p.store.selectBuilder(p.store.db, &rawWindowEntries, historySelect.
+		Where(sq.GtOrEq{"h.CreateAt": since}).
+		Where(sq.LtOrEq{"h.CreateAt": until}).
+		OrderBy("h.CreateAt ASC"))
+	if err != nil && err != sql.ErrNoRows {
+		return nil, errors.Wrapf(err, "failed to get history of property_field_id '%s'", propertyFieldID)
+	}
+	rawEntries = append(rawEntries, rawWindowEntries...)
+
+	entries := make([]app.PropertyHistoryEntry, len(rawEntries))
+	for i, rawEntry := range rawEntries {
+		entries[i] = rawEntry.PropertyHistoryEntry
+		if len(rawEntry.ValueJSON) > 0 {
+			if err = json.Unmarshal(rawEntry.ValueJSON, &entries[i].Value); err != nil {
+				return nil, errors.Wrapf(err, "failed to unmarshal history value json for property id: '%s'", rawEntry.PropertyID)
+			}
+		}
+	}
+
+	return entries, nil
+}
+
 func toSQLProperty(property app.Property) (*sqlProperty, error) {
 	valueJSON, err := json.Marshal(property.Value)
 	if err != nil {
@@ -15,7 +15,9 @@ import (
 
 type sqlPropertyField struct {
 	app.PropertyField
-	ValuesJSON json.RawMessage `db:"values"`
+	ValuesJSON   json.RawMessage `db:"values"`
+	RelationJSON json.RawMessage `db:"relation"`
+	RollupJSON   json.RawMessage `db:"rollup"`
 }
 
 type propertyFieldStore struct {
@@ -37,6 +39,10 @@ func NewPropertyFieldStore(pluginAPI PluginAPIClient, sqlStore *SQLStore) app.Pr
 			"p.Name",
 			"p.Type",
 			"p.Values",
+			"p.InheritToReplies",
+			"p.Formula",
+			"p.Relation",
+			"p.Rollup",
 		).
 		From("PROP_PropertyField p")
 
@@ -76,6 +82,11 @@ func (p *propertyFieldStore) Create(propertyField app.PropertyField) (id string,
 			"Name":     rawPropertyField.Name,
 			"Type":     rawPropertyField.Type,
 			"Values":   rawPropertyField.ValuesJSON,
+
+			"InheritToReplies": rawPropertyField.InheritToReplies,
+			"Formula":          rawPropertyField.Formula,
+			"Relation":         rawPropertyField.RelationJSON,
+			"Rollup":           rawPropertyField.RollupJSON,
 		}))
 	if err != nil {
 		return "", errors.Wrap(err, "failed to store new propertyField")
@@ -125,6 +136,10 @@ func (p *propertyFieldStore) GetFields(filter app.PropertyFieldFilterOptions) ([
 			"p.Name",
 			"p.Type",
 			"p.Values",
+			"p.InheritToReplies",
+			"p.Formula",
+			"p.Relation",
+			"p.Rollup",
 		).
 		From("PROP_PropertyField AS p")
 
@@ -150,6 +165,10 @@ func (p *propertyFieldStore) GetFields(filter app.PropertyFieldFilterOptions) ([
 		}
 	}
 
+	if len(filter.Types) > 0 {
+		queryForResults = queryForResults.Where(sq.Eq{"p.Type": filter.Types})
+	}
+
 	page := filter.Page
 	perPage := filter.PerPage
 	if page < 0 {
@@ -159,7 +178,9 @@ func (p *propertyFieldStore) GetFields(filter app.PropertyFieldFilterOptions) ([
 		perPage = 0
 	}
 
+	// Keeps pages stable for the callers going through all of them
 	queryForResults = queryForResults.
+		OrderBy("p.ID").
 		Offset(uint64(page * perPage)).
 		Limit(uint64(perPage))
 
@@ -201,6 +222,11 @@ func (p *propertyFieldStore) Update(propertyField app.PropertyField) error {
 			"UpdateBy": rawPropertyField.UpdateBy,
 			"Name":     rawPropertyField.Name,
 			"Values":   rawPropertyField.ValuesJSON,
+
+			"InheritToReplies": rawPropertyField.InheritToReplies,
+			"Formula":          rawPropertyField.Formula,
+			"Relation":         rawPropertyField.RelationJSON,
+			"Rollup":           rawPropertyField.RollupJSON,
 		}).
 		Where(sq.Eq{"ID": rawPropertyField.ID}))
 
@@ -251,9 +277,27 @@ func toSQLPropertyField(propertyField app.PropertyField) (*sqlPropertyField, err
 		return nil, errors.Errorf("values json for property_field id '%s' is too long (max %d)", propertyField.ID, maxJSONLength)
 	}
 
+	var relationJSON json.RawMessage
+	if propertyField.Relation != nil {
+		relationJSON, err = json.Marshal(propertyField.Relation)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to marshal relation json for property_field id: '%s'", propertyField.ID)
+		}
+	}
+
+	var rollupJSON json.RawMessage
+	if propertyField.Rollup != nil {
+		rollupJSON, err = json.Marshal(propertyField.Rollup)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to marshal rollup json for property_field id: '%s'", propertyField.ID)
+		}
+	}
+
 	return &sqlPropertyField{
 		PropertyField: propertyField,
 		ValuesJSON:    valuesJSON,
+		RelationJSON:  relationJSON,
+		RollupJSON:    rollupJSON,
 	}, nil
 }
 
@@ -265,5 +309,17 @@ func toPropertyField(rawPropertyField sqlPropertyField) (app.PropertyField, erro
 		}
 	}
 
+	if len(rawPropertyField.RelationJSON) > 0 {
+		if err := json.Unmarshal(rawPropertyField.RelationJSON, &p.Relation); err != nil {
+			return app.PropertyField{}, errors.Wrapf(err, "failed to unmarshal relation json for property_field id: '%s'", rawPropertyField.ID)
+		}
+	}
+
+	if len(rawPropertyField.RollupJSON) > 0 {
+		if err := json.Unmarshal(rawPropertyField.RollupJSON, &p.Rollup); err != nil {
+			return app.PropertyField{}, errors.Wrapf(err, "failed to unmarshal rollup json for property_field id: '%s'", rawPropertyField.ID)
+		}
+	}
+
 	return p, nil
 }
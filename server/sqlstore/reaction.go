@@ -0,0 +1,238 @@
+package sqlstore
+
+import (
+	"database/sql"
+	"encoding/json"
+
+	"github.com/jwilander/mattermost-plugin-properties/server/app"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/pkg/errors"
+)
+
+type sqlReactionMapping struct {
+	app.ReactionMapping
+	ValueJSON json.RawMessage `db:"value"`
+}
+
+type reactionMappingStore struct {
+	pluginAPI     PluginAPIClient
+	store         *SQLStore
+	queryBuilder  sq.StatementBuilderType
+	mappingSelect sq.SelectBuilder
+}
+
+// Ensure reactionMappingStore implements app.ReactionMappingStore interface
+var _ app.ReactionMappingStore = (*reactionMappingStore)(nil)
+
+func NewReactionMappingStore(pluginAPI PluginAPIClient, sqlStore *SQLStore) app.ReactionMappingStore {
+	mappingSelect := sqlStore.builder.
+		Select(
+			"rm.ID",
+			"rm.TeamID",
+			"rm.ChannelID",
+			"rm.EmojiName",
+			"rm.PropertyFieldID",
+			"rm.ValueFromReactor",
+			"rm.CreateAt",
+			"rm.UpdateAt",
+			"rm.UpdateBy",
+			"rm.Value",
+		).
+		From("PROP_ReactionMapping rm")
+
+	return &reactionMappingStore{
+		pluginAPI:     pluginAPI,
+		store:         sqlStore,
+		queryBuilder:  sqlStore.builder,
+		mappingSelect: mappingSelect,
+	}
+}
+
+func (r *reactionMappingStore) Create(mapping app.ReactionMapping) (string, error) {
+	if mapping.ID != "" {
+		return "", errors.New("ID should be empty")
+	}
+	mapping.ID = model.NewId()
+	mapping.CreateAt = model.GetMillis()
+	mapping.UpdateAt = mapping.CreateAt
+
+	rawMapping, err := toSQLReactionMapping(mapping)
+	if err != nil {
+		return "", err
+	}
+
+	tx, err := r.store.db.Beginx()
+	if err != nil {
+		return "", errors.Wrap(err, "could not begin transaction")
+	}
+	defer r.store.finalizeTransaction(tx)
+
+	_, err = r.store.execBuilder(tx, sq.
+		Insert("PROP_ReactionMapping").
+		SetMap(map[string]interface{}{
+			"ID":               rawMapping.ID,
+			"TeamID":           rawMapping.TeamID,
+			"ChannelID":        rawMapping.ChannelID,
+			"EmojiName":        rawMapping.EmojiName,
+			"PropertyFieldID":  rawMapping.PropertyFieldID,
+			"ValueFromReactor": rawMapping.ValueFromReactor,
+			"CreateAt":         rawMapping.CreateAt,
+			"UpdateAt":         rawMapping.UpdateAt,
+			"UpdateBy":         rawMapping.UpdateBy,
+			"Value":            rawMapping.ValueJSON,
+		}))
+	if err != nil {
+		return "", errors.Wrap(err, "failed to store new reaction mapping")
+	}
+
+	if err = tx.Commit(); err != nil {
+		return "", errors.Wrap(err, "could not commit transaction")
+	}
+
+	return rawMapping.ID, nil
+}
+
+func (r *reactionMappingStore) Get(id string) (app.ReactionMapping, error) {
+	if id == "" {
+		return app.ReactionMapping{}, errors.New("id cannot be blank")
+	}
+
+	var rawMapping sqlReactionMapping
+	err := r.store.getBuilder(r.store.db, &rawMapping, r.mappingSelect.Where(sq.Eq{"rm.ID": id}))
+	if err == sql.ErrNoRows {
+		return app.ReactionMapping{}, errors.Wrapf(app.ErrNotFound, "no reaction mapping exists for id '%s'", id)
+	} else if err != nil {
+		return app.ReactionMapping{}, errors.Wrapf(err, "failed to get reaction mapping by id '%s'", id)
+	}
+
+	return toReactionMapping(rawMapping)
+}
+
+func (r *reactionMappingStore) GetForChannel(channelID string) ([]app.ReactionMapping, error) {
+	query := r.mappingSelect.
+		Where(sq.Eq{"rm.ChannelID": channelID}).
+		OrderBy("rm.CreateAt ASC")
+
+	return r.selectMappings(query)
+}
+
+func (r *reactionMappingStore) GetForEmoji(channelID string, emojiName string) ([]app.ReactionMapping, error) {
+	query := r.mappingSelect.
+		Where(sq.Eq{"rm.ChannelID": channelID}).
+		Where(sq.Eq{"rm.EmojiName": emojiName}).
+		OrderBy("rm.CreateAt ASC")
+
+	return r.selectMappings(query)
+}
+
+func (r *reactionMappingStore) selectMappings(query sq.SelectBuilder) ([]app.ReactionMapping, error) {
+	var rawMappings []sqlReactionMapping
+	err := r.store.selectBuilder(r.store.db, &rawMappings, query)
+	if err != nil && err != sql.ErrNoRows {
+		return []app.ReactionMapping{}, errors.Wrap(err, "failed to get reaction mappings")
+	}
+
+	mappings := make([]app.ReactionMapping, len(rawMappings))
+	for i, rawMapping := range rawMappings {
+		mappings[i], err = toReactionMapping(rawMapping)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return mappings, nil
+}
+
+func (r *reactionMappingStore) Update(mapping app.ReactionMapping) error {
+	mapping.UpdateAt = model.GetMillis()
+
+	rawMapping, err := toSQLReactionMapping(mapping)
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.store.db.Beginx()
+	if err != nil {
+		return errors.Wrap(err, "could not begin transaction")
+	}
+	defer r.store.finalizeTransaction(tx)
+
+	_, err = r.store.execBuilder(tx, sq.
+		Update("PROP_ReactionMapping").
+		SetMap(map[string]interface{}{
+			"ChannelID":        rawMapping.ChannelID,
+			"EmojiName":        rawMapping.EmojiName,
+			"PropertyFieldID":  rawMapping.PropertyFieldID,
+			"ValueFromReactor": rawMapping.ValueFromReactor,
+			"UpdateAt":         rawMapping.UpdateAt,
+			"UpdateBy":         rawMapping.UpdateBy,
+			"Value":            rawMapping.ValueJSON,
+		}).
+		Where(sq.Eq{"ID": rawMapping.ID}))
+	if err != nil {
+		return errors.Wrapf(err, "failed to update reaction mapping with id '%s'", rawMapping.ID)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return errors.Wrap(err, "could not commit transaction")
+	}
+
+	return nil
+}
+
+func (r *reactionMappingStore) Delete(id string) error {
+	if id == "" {
+		return errors.New("id cannot be blank")
+	}
+
+	tx, err := r.store.db.Beginx()
+	if err != nil {
+		return errors.Wrap(err, "could not begin transaction")
+	}
+	defer r.store.finalizeTransaction(tx)
+
+	_, err = r.store.execBuilder(tx, sq.
+		Delete("PROP_ReactionMapping").
+		Where(sq.Eq{"ID": id}))
+	if err != nil {
+		return errors.Wrapf(err, "failed to delete reaction mapping with id '%s'", id)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return errors.Wrap(err, "could not commit transaction")
+	}
+
+	return nil
+}
+
+func toSQLReactionMapping(mapping app.ReactionMapping) (*sqlReactionMapping, error) {
+	if mapping.Value == nil {
+		mapping.Value = []interface{}{}
+	}
+	valueJSON, err := json.Marshal(mapping.Value)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to marshal value json for reaction mapping id: '%s'", mapping.ID)
+	}
+
+	if len(valueJSON) > maxJSONLength {
+		return nil, errors.Errorf("value json for reaction mapping id '%s' is too long (max %d)", mapping.ID, maxJSONLength)
+	}
+
+	return &sqlReactionMapping{
+		ReactionMapping: mapping,
+		ValueJSON:       valueJSON,
+	}, nil
+}
+
+func toReactionMapping(rawMapping sqlReactionMapping) (app.ReactionMapping, error) {
+	m := rawMapping.ReactionMapping
+	if len(rawMapping.ValueJSON) > 0 {
+		if err := json.Unmarshal(rawMapping.ValueJSON, &m.Value); err != nil {
+			return app.ReactionMapping{}, errors.Wrapf(err, "failed to unmarshal value json for reaction mapping id: '%s'", rawMapping.ID)
+		}
+	}
+
+	return m, nil
+}
@@ -0,0 +1,281 @@
+package sqlstore
+
+import (
+	"database/sql"
+	"encoding/json"
+
+	"github.com/jwilander/mattermost-plugin-properties/server/app"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/pkg/errors"
+)
+
+type sqlReminder struct {
+	app.Reminder
+	ValuesJSON json.RawMessage `db:"values"`
+}
+
+type reminderStore struct {
+	pluginAPI      PluginAPIClient
+	store          *SQLStore
+	queryBuilder   sq.StatementBuilderType
+	reminderSelect sq.SelectBuilder
+}
+
+// Ensure reminderStore implements app.ReminderStore interface
+var _ app.ReminderStore = (*reminderStore)(nil)
+
+func NewReminderStore(pluginAPI PluginAPIClient, sqlStore *SQLStore) app.ReminderStore {
+	reminderSelect := sqlStore.builder.
+		Select(
+			"r.ID",
+			"r.TeamID",
+			"r.ViewID",
+			"r.PropertyFieldID",
+			"r.Type",
+			"r.Duration",
+			"r.ChannelID",
+			"r.UserFieldID",
+			"r.CreateAt",
+			"r.UpdateAt",
+			"r.UpdateBy",
+			"r.Values",
+		).
+		From("PROP_Reminder r")
+
+	return &reminderStore{
+		pluginAPI:      pluginAPI,
+		store:          sqlStore,
+		queryBuilder:   sqlStore.builder,
+		reminderSelect: reminderSelect,
+	}
+}
+
+func (r *reminderStore) Create(reminder app.Reminder) (string, error) {
+	if reminder.ID != "" {
+		return "", errors.New("ID should be empty")
+	}
+	reminder.ID = model.NewId()
+	reminder.CreateAt = model.GetMillis()
+	reminder.UpdateAt = reminder.CreateAt
+
+	rawReminder, err := toSQLReminder(reminder)
+	if err != nil {
+		return "", err
+	}
+
+	tx, err := r.store.db.Beginx()
+	if err != nil {
+		return "", errors.Wrap(err, "could not begin transaction")
+	}
+	defer r.store.finalizeTransaction(tx)
+
+	_, err = r.store.execBuilder(tx, sq.
+		Insert("PROP_Reminder").
+		SetMap(map[string]interface{}{
+			"ID":              rawReminder.ID,
+			"TeamID":          rawReminder.TeamID,
+			"ViewID":          rawReminder.ViewID,
+			"PropertyFieldID": rawReminder.PropertyFieldID,
+			"Type":            rawReminder.Type,
+			"Duration":        rawReminder.Duration,
+			"ChannelID":       rawReminder.ChannelID,
+			"UserFieldID":     rawReminder.UserFieldID,
+			"CreateAt":        rawReminder.CreateAt,
+			"UpdateAt":        rawReminder.UpdateAt,
+			"UpdateBy":        rawReminder.UpdateBy,
+			"Values":          rawReminder.ValuesJSON,
+		}))
+	if err != nil {
+		return "", errors.Wrap(err, "failed to store new reminder")
+	}
+
+	if err = tx.Commit(); err != nil {
+		return "", errors.Wrap(err, "could not commit transaction")
+	}
+
+	return rawReminder.ID, nil
+}
+
+func (r *reminderStore) Get(id string) (app.Reminder, error) {
+	if id == "" {
+		return app.Reminder{}, errors.New("id cannot be blank")
+	}
+
+	var rawReminder sqlReminder
+	err := r.store.getBuilder(r.store.db, &rawReminder, r.reminderSelect.Where(sq.Eq{"r.ID": id}))
+	if err == sql.ErrNoRows {
+		return app.Reminder{}, errors.Wrapf(app.ErrNotFound, "no reminder exists for id '%s'", id)
+	} else if err != nil {
+		return app.Reminder{}, errors.Wrapf(err, "failed to get reminder by id '%s'", id)
+	}
+
+	return toReminder(rawReminder)
+}
+
+func (r *reminderStore) GetReminders(filter app.ReminderFilterOptions) ([]app.Reminder, error) {
+	query := r.reminderSelect
+
+	if filter.TeamID != "" {
+		query = query.Where(sq.Eq{"r.TeamID": filter.TeamID})
+	}
+
+	if filter.PropertyFieldID != "" {
+		query = query.Where(sq.Eq{"r.PropertyFieldID": filter.PropertyFieldID})
+	}
+
+	page := filter.Page
+	perPage := filter.PerPage
+	if page < 0 {
+		page = 0
+	}
+	if perPage < 0 {
+		perPage = 0
+	}
+
+	query = query.
+		OrderBy("r.CreateAt ASC").
+		Offset(uint64(page * perPage)).
+		Limit(uint64(perPage))
+
+	var rawReminders []sqlReminder
+	err := r.store.selectBuilder(r.store.db, &rawReminders, query)
+	if err != nil && err != sql.ErrNoRows {
+		return []app.Reminder{}, errors.Wrap(err, "failed to get reminders")
+	}
+
+	reminders := make([]app.Reminder, len(rawReminders))
+	for i, rawReminder := range rawReminders {
+		reminders[i], err = toReminder(rawReminder)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return reminders, nil
+}
+
+func (r *reminderStore) Update(reminder app.Reminder) error {
+	reminder.UpdateAt = model.GetMillis()
+
+	rawReminder, err := toSQLReminder(reminder)
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.store.db.Beginx()
+	if err != nil {
+		return errors.Wrap(err, "could not begin transaction")
+	}
+	defer r.store.finalizeTransaction(tx)
+
+	_, err = r.store.execBuilder(tx, sq.
+		Update("PROP_Reminder").
+		SetMap(map[string]interface{}{
+			"ViewID":          rawReminder.ViewID,
+			"PropertyFieldID": rawReminder.PropertyFieldID,
+			"Type":            rawReminder.Type,
+			"Duration":        rawReminder.Duration,
+			"ChannelID":       rawReminder.ChannelID,
+			"UserFieldID":     rawReminder.UserFieldID,
+			"UpdateAt":        rawReminder.UpdateAt,
+			"UpdateBy":        rawReminder.UpdateBy,
+			"Values":          rawReminder.ValuesJSON,
+		}).
+		Where(sq.Eq{"ID": rawReminder.ID}))
+	if err != nil {
+		return errors.Wrapf(err, "failed to update reminder with id '%s'", rawReminder.ID)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return errors.Wrap(err, "could not commit transaction")
+	}
+
+	return nil
+}
+
+func (r *reminderStore) Delete(id string) error {
+	if id == "" {
+		return errors.New("id cannot be blank")
+	}
+
+	tx, err := r.store.db.Beginx()
+	if err != nil {
+		return errors.Wrap(err, "could not begin transaction")
+	}
+	defer r.store.finalizeTransaction(tx)
+
+	_, err = r.store.execBuilder(tx, sq.
+		Delete("PROP_ReminderSent").
+		Where(sq.Eq{"ReminderID": id}))
+	if err != nil {
+		return errors.Wrapf(err, "failed to delete sent notifications of reminder with id '%s'", id)
+	}
+
+	_, err = r.store.execBuilder(tx, sq.
+		Delete("PROP_Reminder").
+		Where(sq.Eq{"ID": id}))
+	if err != nil {
+		return errors.Wrapf(err, "failed to delete reminder with id '%s'", id)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return errors.Wrap(err, "could not commit transaction")
+	}
+
+	return nil
+}
+
+func (r *reminderStore) MarkSent(reminderID, propertyID, notification string, propertyUpdateAt int64) (bool, error) {
+	result, err := r.store.execBuilder(r.store.db, sq.
+		Insert("PROP_ReminderSent").
+		SetMap(map[string]interface{}{
+			"ReminderID":       reminderID,
+			"PropertyID":       propertyID,
+			"Notification":     notification,
+			"PropertyUpdateAt": propertyUpdateAt,
+			"SentAt":           model.GetMillis(),
+		}).
+		Suffix("ON CONFLICT DO NOTHING"))
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to mark reminder '%s' as sent for property '%s'", reminderID, propertyID)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, errors.Wrap(err, "failed to get affected rows")
+	}
+
+	return rows == 1, nil
+}
+
+func toSQLReminder(reminder app.Reminder) (*sqlReminder, error) {
+	if reminder.Values == nil {
+		reminder.Values = []interface{}{}
+	}
+	valuesJSON, err := json.Marshal(reminder.Values)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to marshal values json for reminder id: '%s'", reminder.ID)
+	}
+
+	if len(valuesJSON) > maxJSONLength {
+		return nil, errors.Errorf("values json for reminder id '%s' is too long (max %d)", reminder.ID, maxJSONLength)
+	}
+
+	return &sqlReminder{
+		Reminder:   reminder,
+		ValuesJSON: valuesJSON,
+	}, nil
+}
+
+func toReminder(rawReminder sqlReminder) (app.Reminder, error) {
+	r := rawReminder.Reminder
+	if len(rawReminder.ValuesJSON) > 0 {
+		if err := json.Unmarshal(rawReminder.ValuesJSON, &r.Values); err != nil {
+			return app.Reminder{}, errors.Wrapf(err, "failed to unmarshal values json for reminder id: '%s'", rawReminder.ID)
+		}
+	}
+
+	return r, nil
+}
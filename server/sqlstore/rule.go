@@ -0,0 +1,262 @@
+package sqlstore
+
+import (
+	"database/sql"
+	"encoding/json"
+
+	"github.com/jwilander/mattermost-plugin-properties/server/app"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/pkg/errors"
+)
+
+type sqlRule struct {
+	app.Rule
+	ValueJSON   json.RawMessage `db:"value"`
+	ActionsJSON json.RawMessage `db:"actions"`
+}
+
+type ruleStore struct {
+	pluginAPI    PluginAPIClient
+	store        *SQLStore
+	queryBuilder sq.StatementBuilderType
+	ruleSelect   sq.SelectBuilder
+}
+
+// Ensure ruleStore implements app.RuleStore interface
+var _ app.RuleStore = (*ruleStore)(nil)
+
+func NewRuleStore(pluginAPI PluginAPIClient, sqlStore *SQLStore) app.RuleStore {
+	ruleSelect := sqlStore.builder.
+		Select(
+			"r.ID",
+			"r.TeamID",
+			"r.ChannelID",
+			"r.PropertyFieldID",
+			"r.CreateAt",
+			"r.UpdateAt",
+			"r.UpdateBy",
+			"r.Value",
+			"r.Actions",
+		).
+		From("PROP_Rule r")
+
+	return &ruleStore{
+		pluginAPI:    pluginAPI,
+		store:        sqlStore,
+		queryBuilder: sqlStore.builder,
+		ruleSelect:   ruleSelect,
+	}
+}
+
+func (r *ruleStore) Create(rule app.Rule) (string, error) {
+	if rule.ID != "" {
+		return "", errors.New("ID should be empty")
+	}
+	rule.ID = model.NewId()
+	rule.CreateAt = model.GetMillis()
+	rule.UpdateAt = rule.CreateAt
+
+	rawRule, err := toSQLRule(rule)
+	if err != nil {
+		return "", err
+	}
+
+	tx, err := r.store.db.Beginx()
+	if err != nil {
+		return "", errors.Wrap(err, "could not begin transaction")
+	}
+	defer r.store.finalizeTransaction(tx)
+
+	_, err = r.store.execBuilder(tx, sq.
+		Insert("PROP_Rule").
+		SetMap(map[string]interface{}{
+			"ID":              rawRule.ID,
+			"TeamID":          rawRule.TeamID,
+			"ChannelID":       rawRule.ChannelID,
+			"PropertyFieldID": rawRule.PropertyFieldID,
+			"CreateAt":        rawRule.CreateAt,
+			"UpdateAt":        rawRule.UpdateAt,
+			"UpdateBy":        rawRule.UpdateBy,
+			"Value":           rawRule.ValueJSON,
+			"Actions":         rawRule.ActionsJSON,
+		}))
+	if err != nil {
+		return "", errors.Wrap(err, "failed to store new rule")
+	}
+
+	if err = tx.Commit(); err != nil {
+		return "", errors.Wrap(err, "could not commit transaction")
+	}
+
+	return rawRule.ID, nil
+}
+
+func (r *ruleStore) Get(id string) (app.Rule, error) {
+	if id == "" {
+		return app.Rule{}, errors.New("id cannot be blank")
+	}
+
+	var rawRule sqlRule
+	err := r.store.getBuilder(r.store.db, &rawRule, r.ruleSelect.Where(sq.Eq{"r.ID": id}))
+	if err == sql.ErrNoRows {
+		return app.Rule{}, errors.Wrapf(app.ErrNotFound, "no rule exists for id '%s'", id)
+	} else if err != nil {
+		return app.Rule{}, errors.Wrapf(err, "failed to get rule by id '%s'", id)
+	}
+
+	return toRule(rawRule)
+}
+
+func (r *ruleStore) GetRules(filter app.RuleFilterOptions) ([]app.Rule, error) {
+	query := r.ruleSelect
+
+	if filter.TeamID != "" {
+		query = query.Where(sq.Eq{"r.TeamID": filter.TeamID})
+	}
+
+	if filter.PropertyFieldID != "" {
+		query = query.Where(sq.Eq{"r.PropertyFieldID": filter.PropertyFieldID})
+	}
+
+	page := filter.Page
+	perPage := filter.PerPage
+	if page < 0 {
+		page = 0
+	}
+	if perPage < 0 {
+		perPage = 0
+	}
+
+	query = query.
+		OrderBy("r.CreateAt ASC").
+		Offset(uint64(page * perPage)).
+		Limit(uint64(perPage))
+
+	var rawRules []sqlRule
+	err := r.store.selectBuilder(r.store.db, &rawRules, query)
+	if err != nil && err != sql.ErrNoRows {
+		return []app.Rule{}, errors.Wrap(err, "failed to get rules")
+	}
+
+	rules := make([]app.Rule, len(rawRules))
+	for i, rawRule := range rawRules {
+		rules[i], err = toRule(rawRule)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return rules, nil
+}
+
+func (r *ruleStore) Update(rule app.Rule) error {
+	rule.UpdateAt = model.GetMillis()
+
+	rawRule, err := toSQLRule(rule)
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.store.db.Beginx()
+	if err != nil {
+		return errors.Wrap(err, "could not begin transaction")
+	}
+	defer r.store.finalizeTransaction(tx)
+
+	_, err = r.store.execBuilder(tx, sq.
+		Update("PROP_Rule").
+		SetMap(map[string]interface{}{
+			"ChannelID":       rawRule.ChannelID,
+			"PropertyFieldID": rawRule.PropertyFieldID,
+			"UpdateAt":        rawRule.UpdateAt,
+			"UpdateBy":        rawRule.UpdateBy,
+			"Value":           rawRule.ValueJSON,
+			"Actions":         rawRule.ActionsJSON,
+		}).
+		Where(sq.Eq{"ID": rawRule.ID}))
+	if err != nil {
+		return errors.Wrapf(err, "failed to update rule with id '%s'", rawRule.ID)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return errors.Wrap(err, "could not commit transaction")
+	}
+
+	return nil
+}
+
+func (r *ruleStore) Delete(id string) error {
+	if id == "" {
+		return errors.New("id cannot be blank")
+	}
+
+	tx, err := r.store.db.Beginx()
+	if err != nil {
+		return errors.Wrap(err, "could not begin transaction")
+	}
+	defer r.store.finalizeTransaction(tx)
+
+	_, err = r.store.execBuilder(tx, sq.
+		Delete("PROP_Rule").
+		Where(sq.Eq{"ID": id}))
+	if err != nil {
+		return errors.Wrapf(err, "failed to delete rule with id '%s'", id)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return errors.Wrap(err, "could not commit transaction")
+	}
+
+	return nil
+}
+
+func toSQLRule(rule app.Rule) (*sqlRule, error) {
+	if rule.Value == nil {
+		rule.Value = []interface{}{}
+	}
+	valueJSON, err := json.Marshal(rule.Value)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to marshal value json for rule id: '%s'", rule.ID)
+	}
+
+	if len(valueJSON) > maxJSONLength {
+		return nil, errors.Errorf("value json for rule id '%s' is too long (max %d)", rule.ID, maxJSONLength)
+	}
+
+	if rule.Actions == nil {
+		rule.Actions = []app.RuleAction{}
+	}
+	actionsJSON, err := json.Marshal(rule.Actions)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to marshal actions json for rule id: '%s'", rule.ID)
+	}
+
+	if len(actionsJSON) > maxJSONLength {
+		return nil, errors.Errorf("actions json for rule id '%s' is too long (max %d)", rule.ID, maxJSONLength)
+	}
+
+	return &sqlRule{
+		Rule:        rule,
+		ValueJSON:   valueJSON,
+		ActionsJSON: actionsJSON,
+	}, nil
+}
+
+func toRule(rawRule sqlRule) (app.Rule, error) {
+	r := rawRule.Rule
+	if len(rawRule.ValueJSON) > 0 {
+		if err := json.Unmarshal(rawRule.ValueJSON, &r.Value); err != nil {
+			return app.Rule{}, errors.Wrapf(err, "failed to unmarshal value json for rule id: '%s'", rawRule.ID)
+		}
+	}
+
+	if len(rawRule.ActionsJSON) > 0 {
+		if err := json.Unmarshal(rawRule.ActionsJSON, &r.Actions); err != nil {
+			return app.Rule{}, errors.Wrapf(err, "failed to unmarshal actions json for rule id: '%s'", rawRule.ID)
+		}
+	}
+
+	return r, nil
+}
@@ -0,0 +1,207 @@
+package sqlstore
+
+import (
+	"database/sql"
+	"encoding/json"
+
+	"github.com/jwilander/mattermost-plugin-properties/server/app"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/pkg/errors"
+)
+
+type sqlToken struct {
+	app.Token
+	FieldIDsJSON json.RawMessage `db:"fieldids"`
+}
+
+type tokenStore struct {
+	pluginAPI    PluginAPIClient
+	store        *SQLStore
+	queryBuilder sq.StatementBuilderType
+	tokenSelect  sq.SelectBuilder
+}
+
+// Ensure tokenStore implements app.TokenStore interface
+var _ app.TokenStore = (*tokenStore)(nil)
+
+func NewTokenStore(pluginAPI PluginAPIClient, sqlStore *SQLStore) app.TokenStore {
+	tokenSelect := sqlStore.builder.
+		Select(
+			"t.ID",
+			"t.TeamID",
+			"t.Name",
+			"t.FieldIDs",
+			"t.CreateAt",
+			"t.CreateBy",
+			"t.LastUsedAt",
+			"t.TokenHash",
+		).
+		From("PROP_Token t")
+
+	return &tokenStore{
+		pluginAPI:    pluginAPI,
+		store:        sqlStore,
+		queryBuilder: sqlStore.builder,
+		tokenSelect:  tokenSelect,
+	}
+}
+
+func (t *tokenStore) Create(token app.Token) (string, error) {
+	if token.ID != "" {
+		return "", errors.New("ID should be empty")
+	}
+	token.ID = model.NewId()
+	token.CreateAt = model.GetMillis()
+
+	rawToken, err := toSQLToken(token)
+	if err != nil {
+		return "", err
+	}
+
+	tx, err := t.store.db.Beginx()
+	if err != nil {
+		return "", errors.Wrap(err, "could not begin transaction")
+	}
+	defer t.store.finalizeTransaction(tx)
+
+	_, err = t.store.execBuilder(tx, sq.
+		Insert("PROP_Token").
+		SetMap(map[string]interface{}{
+			"ID":         rawToken.ID,
+			"TeamID":     rawToken.TeamID,
+			"Name":       rawToken.Name,
+			"FieldIDs":   rawToken.FieldIDsJSON,
+			"CreateAt":   rawToken.CreateAt,
+			"CreateBy":   rawToken.CreateBy,
+			"LastUsedAt": rawToken.LastUsedAt,
+			"TokenHash":  rawToken.TokenHash,
+		}))
+	if err != nil {
+		return "", errors.Wrap(err, "failed to store new token")
+	}
+
+	if err = tx.Commit(); err != nil {
+		return "", errors.Wrap(err, "could not commit transaction")
+	}
+
+	return rawToken.ID, nil
+}
+
+func (t *tokenStore) Get(id string) (app.Token, error) {
+	if id == "" {
+		return app.Token{}, errors.New("id cannot be blank")
+	}
+
+	return t.getToken(t.tokenSelect.Where(sq.Eq{"t.ID": id}), "id", id)
+}
+
+func (t *tokenStore) GetByHash(tokenHash string) (app.Token, error) {
+	if tokenHash == "" {
+		return app.Token{}, errors.New("tokenHash cannot be blank")
+	}
+
+	return t.getToken(t.tokenSelect.Where(sq.Eq{"t.TokenHash": tokenHash}), "hash", tokenHash)
+}
+
+func (t *tokenStore) getToken(query sq.SelectBuilder, key, value string) (app.Token, error) {
+	var rawToken sqlToken
+	err := t.store.getBuilder(t.store.db, &rawToken, query)
+	if err == sql.ErrNoRows {
+		return app.Token{}, errors.Wrapf(app.ErrNotFound, "no token exists for %s '%s'", key, value)
+	} else if err != nil {
+		return app.Token{}, errors.Wrapf(err, "failed to get token by %s '%s'", key, value)
+	}
+
+	return toToken(rawToken)
+}
+
+func (t *tokenStore) GetForTeam(teamID string) ([]app.Token, error) {
+	query := t.tokenSelect.
+		Where(sq.Eq{"t.TeamID": teamID}).
+		OrderBy("t.CreateAt ASC")
+
+	var rawTokens []sqlToken
+	err := t.store.selectBuilder(t.store.db, &rawTokens, query)
+	if err != nil && err != sql.ErrNoRows {
+		return []app.Token{}, errors.Wrapf(err, "failed to get tokens for team '%s'", teamID)
+	}
+
+	tokens := make([]app.Token, len(rawTokens))
+	for i, rawToken := range rawTokens {
+		tokens[i], err = toToken(rawToken)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return tokens, nil
+}
+
+func (t *tokenStore) UpdateLastUsedAt(id string, lastUsedAt int64) error {
+	_, err := t.store.execBuilder(t.store.db, sq.
+		Update("PROP_Token").
+		Set("LastUsedAt", lastUsedAt).
+		Where(sq.Eq{"ID": id}))
+	if err != nil {
+		return errors.Wrapf(err, "failed to update last use of token with id '%s'", id)
+	}
+
+	return nil
+}
+
+func (t *tokenStore) Delete(id string) error {
+	if id == "" {
+		return errors.New("id cannot be blank")
+	}
+
+	tx, err := t.store.db.Beginx()
+	if err != nil {
+		return errors.Wrap(err, "could not begin transaction")
+	}
+	defer t.store.finalizeTransaction(tx)
+
+	_, err = t.store.execBuilder(tx, sq.
+		Delete("PROP_Token").
+		Where(sq.Eq{"ID": id}))
+	if err != nil {
+		return errors.Wrapf(err, "failed to delete token with id '%s'", id)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return errors.Wrap(err, "could not commit transaction")
+	}
+
+	return nil
+}
+
+func toSQLToken(token app.Token) (*sqlToken, error) {
+	if token.FieldIDs == nil {
+		token.FieldIDs = []string{}
+	}
+	fieldIDsJSON, err := json.Marshal(token.FieldIDs)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to marshal field ids json for token id: '%s'", token.ID)
+	}
+
+	if len(fieldIDsJSON) > maxJSONLength {
+		return nil, errors.Errorf("field ids json for token id '%s' is too long (max %d)", token.ID, maxJSONLength)
+	}
+
+	return &sqlToken{
+		Token:        token,
+		FieldIDsJSON: fieldIDsJSON,
+	}, nil
+}
+
+func toToken(rawToken sqlToken) (app.Token, error) {
+	t := rawToken.Token
+	if len(rawToken.FieldIDsJSON) > 0 {
+		if err := json.Unmarshal(rawToken.FieldIDsJSON, &t.FieldIDs); err != nil {
+			return app.Token{}, errors.Wrapf(err, "failed to unmarshal field ids json for token id: '%s'", rawToken.ID)
+		}
+	}
+
+	return t, nil
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/jwilander/mattermost-plugin-properties/server/app"
 
 	sq "github.com/Masterminds/squirrel"
+	"github.com/jmoiron/sqlx"
 	"github.com/mattermost/mattermost/server/public/model"
 	"github.com/pkg/errors"
 )
@@ -35,6 +36,7 @@ func NewViewStore(pluginAPI PluginAPIClient, sqlStore *SQLStore) app.ViewStore {
 			"v.Title",
 			"v.Type",
 			"v.CreateAt",
+			"v.UpdateAt",
 			"v.Query",
 			"v.Format",
 		).
@@ -54,6 +56,7 @@ func (p *viewStore) Create(view app.View) (id string, err error) {
 	}
 	view.ID = model.NewId()
 	view.CreateAt = model.GetMillis()
+	view.UpdateAt = view.CreateAt
 
 	rawView, err := toSQLView(view)
 	if err != nil {
@@ -73,6 +76,7 @@ func (p *viewStore) Create(view app.View) (id string, err error) {
 			"Title":    rawView.Title,
 			"Type":     rawView.Type,
 			"CreateAt": rawView.CreateAt,
+			"UpdateAt": rawView.UpdateAt,
 			"Query":    rawView.QueryJSON,
 			"Format":   rawView.FormatJSON,
 		}))
@@ -162,6 +166,24 @@ func (p *viewStore) GetForUser(userID string) ([]app.View, error) {
 	return views, nil
 }
 
+func (p *viewStore) GetByType(viewType string) ([]app.View, error) {
+	var rawViews []sqlView
+	err := p.store.selectBuilder(p.store.db, &rawViews, p.viewSelect.Where(sq.Eq{"v.Type": viewType}))
+	if err != nil && err != sql.ErrNoRows {
+		return []app.View{}, errors.Wrapf(err, "failed to get views of type '%s'", viewType)
+	}
+
+	views := make([]app.View, len(rawViews))
+	for i, rawView := range rawViews {
+		views[i], err = toView(rawView)
+		if err != nil {
+			return []app.View{}, err
+		}
+	}
+
+	return views, nil
+}
+
 func (p *viewStore) Update(id string, title *string, query *app.Query, format *app.Format) error {
 	if id == "" {
 		return errors.New("ID must be set")
@@ -192,7 +214,7 @@ func (p *viewStore) Update(id string, title *string, query *app.Query, format *a
 		return err
 	}
 
-	toUpdate := map[string]interface{}{}
+	toUpdate := map[string]interface{}{"UpdateAt": model.GetMillis()}
 	if title != nil {
 		toUpdate["Title"] = *title
 	}
@@ -219,8 +241,92 @@ func (p *viewStore) Update(id string, title *string, query *app.Query, format *a
 	return nil
 }
 
-func (p *viewStore) QueryObjects(query app.Query, page int, perPage int) ([]string, error) {
-	if len(query.Includes) == 0 && len(query.Excludes) == 0 {
+func (p *viewStore) UpdateFormat(id string, format app.Format, updateAt int64) (int64, error) {
+	rawView, err := toSQLView(app.View{ID: id, Format: format})
+	if err != nil {
+		return 0, err
+	}
+
+	newUpdateAt := model.GetMillis()
+	if newUpdateAt <= updateAt {
+		newUpdateAt = updateAt + 1
+	}
+
+	result, err := p.store.execBuilder(p.store.db, sq.
+		Update("PROP_View").
+		SetMap(map[string]interface{}{
+			"Format":   rawView.FormatJSON,
+			"UpdateAt": newUpdateAt,
+		}).
+		Where(sq.Eq{"ID": id, "UpdateAt": updateAt}))
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to update format of view with id '%s'", id)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to update format of view with id '%s'", id)
+	}
+	if rows == 0 {
+		return 0, errors.Wrapf(app.ErrConflict, "view with id '%s' was changed since %d", id, updateAt)
+	}
+
+	return newUpdateAt, nil
+}
+
+func (p *viewStore) QueryObjects(query app.Query, sort []app.TableColumn, page int, perPage int) ([]string, error) {
+	if len(query.Includes) == 0 && len(query.Excludes) == 0 && len(query.Conditions) == 0 {
+		return []string{}, errors.New("Fields must have at least one value")
+	}
+
+	tx, err := p.store.db.Beginx()
+	if err != nil {
+		return []string{}, errors.Wrap(err, "could not begin transaction")
+	}
+	defer p.store.finalizeTransaction(tx)
+
+	fieldTypes, err := p.getFieldTypes(tx, query, sort)
+	if err != nil {
+		return []string{}, err
+	}
+
+	if page < 0 {
+		page = 0
+	}
+	if perPage < 0 {
+		perPage = 0
+	}
+
+	//TODO: consider view performance
+	q := sq.
+		Select(
+			"p.ObjectID",
+		).
+		From("PROP_Property_Query_View p").
+		Where(queryObjectsWhere(query, fieldTypes)).
+		Offset(uint64(page * perPage)).
+		Limit(uint64(perPage))
+
+	q = orderObjects(q, sort, fieldTypes)
+
+	var ids []string
+	err = p.store.selectBuilder(tx, &ids, q)
+
+	if err == sql.ErrNoRows {
+		return []string{}, errors.Wrap(app.ErrNotFound, "no objects exist for query")
+	} else if err != nil {
+		return []string{}, errors.Wrap(err, "failed to get objects by query")
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, errors.Wrap(err, "could not commit transaction")
+	}
+
+	return ids, nil
+}
+
+func (p *viewStore) QueryThreadRoots(query app.Query, page int, perPage int) ([]string, error) {
+	if len(query.Includes) == 0 && len(query.Excludes) == 0 && len(query.Conditions) == 0 {
 		return []string{}, errors.New("Fields must have at least one value")
 	}
 
@@ -230,6 +336,284 @@ func (p *viewStore) QueryObjects(query app.Query, page int, perPage int) ([]stri
 	}
 	defer p.store.finalizeTransaction(tx)
 
+	fieldTypes, err := p.getFieldTypes(tx, query, nil)
+	if err != nil {
+		return []string{}, err
+	}
+
+	var ids []string
+	err = p.store.selectBuilder(tx, &ids, threadRootsSelect(query, fieldTypes, page, perPage))
+
+	if err == sql.ErrNoRows {
+		return []string{}, errors.Wrap(app.ErrNotFound, "no threads exist for query")
+	} else if err != nil {
+		return []string{}, errors.Wrap(err, "failed to get threads by query")
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, errors.Wrap(err, "could not commit transaction")
+	}
+
+	return ids, nil
+}
+
+// threadRootsSelect selects a page of the root posts of the threads having a post matching the
+// query, a matching reply standing in for its root post. Roots are ordered by id so pages don't
+// overlap.
+func threadRootsSelect(query app.Query, fieldTypes map[string]app.FieldType, page int, perPage int) sq.SelectBuilder {
+	if page < 0 {
+		page = 0
+	}
+	if perPage < 0 {
+		perPage = 0
+	}
+
+	return sq.
+		Select(
+			"DISTINCT COALESCE(NULLIF(po.RootId, ''), p.ObjectID) AS RootID",
+		).
+		From("PROP_Property_Query_View p").
+		LeftJoin("Posts po ON po.Id = p.ObjectID").
+		Where(queryObjectsWhere(query, fieldTypes)).
+		OrderBy("RootID").
+		Offset(uint64(page * perPage)).
+		Limit(uint64(perPage))
+}
+
+func (p *viewStore) FilterObjects(query app.Query, objectIDs []string) ([]string, error) {
+	if len(objectIDs) == 0 {
+		return []string{}, nil
+	}
+
+	fieldTypes, err := p.getFieldTypes(p.store.db, query, nil)
+	if err != nil {
+		return []string{}, err
+	}
+
+	q := sq.
+		Select(
+			"p.ObjectID",
+		).
+		From("PROP_Property_Query_View p").
+		Where(queryObjectsWhere(query, fieldTypes)).
+		Where(sq.Eq{"p.ObjectID": objectIDs})
+
+	var ids []string
+	err = p.store.selectBuilder(p.store.db, &ids, q)
+	if err != nil && err != sql.ErrNoRows {
+		return []string{}, errors.Wrap(err, "failed to filter objects by query")
+	}
+
+	return ids, nil
+}
+
+func (p *viewStore) QueryDateRange(query app.Query, startFieldID, endFieldID string, from, to string, limit int) ([]string, error) {
+	if limit < 0 {
+		limit = 0
+	}
+
+	fieldTypes, err := p.getFieldTypes(p.store.db, query, nil)
+	if err != nil {
+		return []string{}, err
+	}
+
+	// Comparing the day part of the values keeps both dates and timestamps in range. Objects
+	// without an end, or with an end before their start, span their start only.
+	start := "LEFT(p.Properties->?->>0, 10)"
+	end := start
+	endArgs := []interface{}{startFieldID}
+	if endFieldID != "" {
+		end = "GREATEST(LEFT(p.Properties->?->>0, 10), " + start + ")"
+		endArgs = []interface{}{endFieldID, startFieldID}
+	}
+
+	q := sq.
+		Select(
+			"p.ObjectID",
+		).
+		From("PROP_Property_Query_View p").
+		Where(queryObjectsWhere(query, fieldTypes)).
+		Where(sq.Expr(start+" <= ?", startFieldID, to)).
+		Where(sq.Expr(end+" >= ?", append(endArgs, from)...)).
+		OrderByClause(start, startFieldID).
+		OrderBy("p.ObjectID").
+		Limit(uint64(limit))
+
+	var ids []string
+	err = p.store.selectBuilder(p.store.db, &ids, q)
+	if err != nil && err != sql.ErrNoRows {
+		return []string{}, errors.Wrap(err, "failed to get objects by date range")
+	}
+
+	return ids, nil
+}
+
+// orderObjects orders the objects by the first value of the fields of the columns having a sort.
+func orderObjects(q sq.SelectBuilder, sort []app.TableColumn, fieldTypes map[string]app.FieldType) sq.SelectBuilder {
+	sorted := false
+	for _, column := range sort {
+		var direction string
+		switch column.Sort {
+		case app.SortAscending:
+			direction = "ASC"
+		case app.SortDescending:
+			direction = "DESC"
+		default:
+			continue
+		}
+		sorted = true
+
+		// Without a type, numbers are compared as such and other values as text
+		key := app.SQLExpr{
+			SQL:  "CASE WHEN json_typeof(p.Properties->?->0) = 'number' THEN (p.Properties->?->>0)::float8 END",
+			Args: []interface{}{column.FieldID, column.FieldID},
+		}
+		if fieldType, ok := fieldTypes[column.FieldID]; ok {
+			key = fieldType.SortKey(column.FieldID)
+		}
+		q = q.OrderByClause(fmt.Sprintf("%s %s NULLS LAST", key.SQL, direction), key.Args...)
+
+		// The text of the values breaks ties between values the key doesn't order
+		if text := "p.Properties->?->>0"; key.SQL != text {
+			q = q.OrderByClause(fmt.Sprintf("%s %s NULLS LAST", text, direction), column.FieldID)
+		}
+	}
+
+	// Keeps pages stable across objects with the same values
+	if sorted {
+		q = q.OrderBy("p.ObjectID")
+	}
+
+	return q
+}
+
+// sqlAggregationRow is a group of an aggregation query, the metric columns only being set when
+// a number field is summarized.
+type sqlAggregationRow struct {
+	Value1      sql.NullString
+	Value2      sql.NullString
+	Count       int64
+	MetricCount int64
+	Sum         sql.NullFloat64
+	Avg         sql.NullFloat64
+	Min         sql.NullFloat64
+	Max         sql.NullFloat64
+}
+
+func (p *viewStore) Aggregate(query app.Query, options app.AggregateOptions) (app.Aggregation, error) {
+	if len(options.GroupByFieldIDs) > app.MaxAggregateGroupByFields {
+		return app.Aggregation{}, errors.Errorf("cannot group by more than %d fields", app.MaxAggregateGroupByFields)
+	}
+
+	fieldTypes, err := p.getFieldTypes(p.store.db, query, nil)
+	if err != nil {
+		return app.Aggregation{}, err
+	}
+
+	var rows []sqlAggregationRow
+	err = p.store.selectBuilder(p.store.db, &rows, aggregateSelect(query, fieldTypes, options.GroupByFieldIDs, ""))
+	if err != nil && err != sql.ErrNoRows {
+		return app.Aggregation{}, errors.Wrap(err, "failed to aggregate objects")
+	}
+
+	aggregation := app.Aggregation{
+		GroupByFieldIDs: options.GroupByFieldIDs,
+		Groups:          make([]app.AggregationGroup, len(rows)),
+	}
+	if aggregation.GroupByFieldIDs == nil {
+		aggregation.GroupByFieldIDs = []string{}
+	}
+
+	groups := make(map[[2]sql.NullString]int, len(rows))
+	for i, row := range rows {
+		groups[[2]sql.NullString{row.Value1, row.Value2}] = i
+
+		values := make([]*string, len(options.GroupByFieldIDs))
+		for j, value := range []sql.NullString{row.Value1, row.Value2}[:len(values)] {
+			if value.Valid {
+				v := value.String
+				values[j] = &v
+			}
+		}
+
+		aggregation.Groups[i] = app.AggregationGroup{
+			Values: values,
+			Count:  row.Count,
+		}
+	}
+
+	for _, fieldID := range options.NumberFieldIDs {
+		var metricRows []sqlAggregationRow
+		err = p.store.selectBuilder(p.store.db, &metricRows, aggregateSelect(query, fieldTypes, options.GroupByFieldIDs, fieldID))
+		if err != nil && err != sql.ErrNoRows {
+			return app.Aggregation{}, errors.Wrapf(err, "failed to aggregate number field '%s'", fieldID)
+		}
+
+		for _, row := range metricRows {
+			i, ok := groups[[2]sql.NullString{row.Value1, row.Value2}]
+			if !ok || row.MetricCount == 0 {
+				continue
+			}
+
+			if aggregation.Groups[i].Metrics == nil {
+				aggregation.Groups[i].Metrics = map[string]app.AggregationMetric{}
+			}
+			aggregation.Groups[i].Metrics[fieldID] = app.AggregationMetric{
+				Count: row.MetricCount,
+				Sum:   row.Sum.Float64,
+				Avg:   row.Avg.Float64,
+				Min:   row.Min.Float64,
+				Max:   row.Max.Float64,
+			}
+		}
+	}
+
+	return aggregation, nil
+}
+
+// aggregateSelect groups the objects matching the query by the values of up to two fields, an
+// object being part of the group of each of its values. When numberFieldID is set the number
+// values of the field are summarized for each group.
+func aggregateSelect(query app.Query, fieldTypes map[string]app.FieldType, groupByFieldIDs []string, numberFieldID string) sq.SelectBuilder {
+	q := sq.Select().From("PROP_Property_Query_View p")
+
+	for i := 0; i < app.MaxAggregateGroupByFields; i++ {
+		if i >= len(groupByFieldIDs) {
+			q = q.Column(fmt.Sprintf("NULL::text AS Value%d", i+1))
+			continue
+		}
+
+		q = q.
+			Column(fmt.Sprintf("g%d.value AS Value%d", i+1, i+1)).
+			LeftJoin(fmt.Sprintf("LATERAL json_array_elements_text(p.Properties->?) g%d(value) ON true", i+1), groupByFieldIDs[i])
+	}
+
+	q = q.Column("COUNT(*) AS Count")
+
+	if numberFieldID == "" {
+		q = q.Columns(
+			"0 AS MetricCount",
+			"NULL::float8 AS Sum",
+			"NULL::float8 AS Avg",
+			"NULL::float8 AS Min",
+			"NULL::float8 AS Max",
+		)
+	} else {
+		// Only the first value of the field counts, and only when it's a JSON number
+		number := "CASE WHEN json_typeof(p.Properties->?->0) = 'number' THEN (p.Properties->?->>0)::float8 END"
+		for _, column := range []string{"COUNT(%s) AS MetricCount", "SUM(%s) AS Sum", "AVG(%s) AS Avg", "MIN(%s) AS Min", "MAX(%s) AS Max"} {
+			q = q.Column(sq.Expr(fmt.Sprintf(column, number), numberFieldID, numberFieldID))
+		}
+	}
+
+	return q.
+		Where(queryObjectsWhere(query, fieldTypes)).
+		GroupBy("Value1", "Value2").
+		OrderBy("Value1 NULLS LAST", "Value2 NULLS LAST")
+}
+
+// queryObjectsWhere builds the predicates of a view query against PROP_Property_Query_View.
+func queryObjectsWhere(query app.Query, fieldTypes map[string]app.FieldType) sq.And {
 	where := sq.And{}
 	for id, fields := range query.Includes {
 		if len(fields) == 0 {
@@ -261,6 +645,7 @@ func (p *viewStore) QueryObjects(query app.Query, page int, perPage int) ([]stri
 			continue
 		}
 
+		// Objects without the field don't match, the predicate being NULL for them
 		if len(fields) == 1 {
 			where = append(where, sq.Expr("NOT(p.Properties::jsonb->? ?? ?)", id, fields[0]))
 			continue
@@ -279,6 +664,26 @@ func (p *viewStore) QueryObjects(query app.Query, page int, perPage int) ([]stri
 		where = append(where, sq.Expr(fieldsList, fieldsInterface...))
 	}
 
+	for _, objectID := range query.RelatedTo {
+		where = append(where, sq.Expr("EXISTS (SELECT 1 FROM json_each(p.Properties) r WHERE r.value::jsonb ?? ?)", objectID))
+	}
+
+	// Conditions on deleted fields or using unsupported operators match nothing
+	for _, condition := range query.Conditions {
+		fieldType, ok := fieldTypes[condition.FieldID]
+		if !ok {
+			where = append(where, sq.Expr("FALSE"))
+			continue
+		}
+
+		predicate, err := fieldType.Predicate(condition)
+		if err != nil {
+			where = append(where, sq.Expr("FALSE"))
+			continue
+		}
+		where = append(where, sq.Expr(predicate.SQL, predicate.Args...))
+	}
+
 	if query.ChannelID != "" {
 		where = append(where, sq.Eq{"p.ChannelID": query.ChannelID})
 	}
@@ -287,40 +692,49 @@ func (p *viewStore) QueryObjects(query app.Query, page int, perPage int) ([]stri
 		where = append(where, sq.Eq{"p.TeamID": query.TeamID})
 	}
 
-	if page < 0 {
-		page = 0
-	}
-	if perPage < 0 {
-		perPage = 0
+	objectType := query.ObjectType
+	if objectType == "" {
+		objectType = app.PropertyObjectTypePost
 	}
+	where = append(where, sq.Eq{"p.ObjectType": objectType})
 
-	//TODO: handle different object types & consider view performance
-	q := sq.
-		Select(
-			"p.ObjectID",
-		).
-		From("PROP_Property_Query_View p").
-		Where(where).
-		Offset(uint64(page * perPage)).
-		Limit(uint64(perPage))
+	return where
+}
 
-	str, _, _ := q.ToSql()
-	fmt.Println(str)
+// getFieldTypes returns the types of the fields the conditions of a query and the sorted columns
+// apply to, keyed by field id. Deleted fields and unknown types are left out.
+func (p *viewStore) getFieldTypes(q sqlx.Queryer, query app.Query, sort []app.TableColumn) (map[string]app.FieldType, error) {
+	fieldIDs := []string{}
+	for _, condition := range query.Conditions {
+		fieldIDs = append(fieldIDs, condition.FieldID)
+	}
+	for _, column := range sort {
+		if column.Sort != "" {
+			fieldIDs = append(fieldIDs, column.FieldID)
+		}
+	}
 
-	var ids []string
-	err = p.store.selectBuilder(tx, &ids, q)
+	fieldTypes := map[string]app.FieldType{}
+	if len(fieldIDs) == 0 {
+		return fieldTypes, nil
+	}
 
-	if err == sql.ErrNoRows {
-		return []string{}, errors.Wrap(app.ErrNotFound, "no objects exist for query")
-	} else if err != nil {
-		return []string{}, errors.Wrap(err, "failed to get objects by query")
+	var rows []struct {
+		ID   string
+		Type string
+	}
+	b := sq.Select("ID", "Type").From("PROP_PropertyField").Where(sq.Eq{"ID": fieldIDs})
+	if err := p.store.selectBuilder(q, &rows, b); err != nil && err != sql.ErrNoRows {
+		return nil, errors.Wrap(err, "failed to get field types")
 	}
 
-	if err = tx.Commit(); err != nil {
-		return nil, errors.Wrap(err, "could not commit transaction")
+	for _, row := range rows {
+		if fieldType, ok := app.GetFieldType(row.Type); ok {
+			fieldTypes[row.ID] = fieldType
+		}
 	}
 
-	return ids, nil
+	return fieldTypes, nil
 }
 
 func toSQLView(view app.View) (*sqlView, error) {
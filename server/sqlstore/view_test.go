@@ -0,0 +1,292 @@
+package sqlstore
+
+import (
+	"fmt"
+	"testing"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jwilander/mattermost-plugin-properties/server/app"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrderObjects(t *testing.T) {
+	number := "CASE WHEN json_typeof(p.Properties->?->0) = 'number' THEN (p.Properties->?->>0)::float8 END"
+	text := "p.Properties->?->>0"
+
+	typeOf := func(name string) app.FieldType {
+		fieldType, ok := app.GetFieldType(name)
+		require.True(t, ok)
+		return fieldType
+	}
+
+	cases := []struct {
+		Name      string
+		Sort      []app.TableColumn
+		Types     map[string]app.FieldType
+		Expected  string
+		Arguments []interface{}
+	}{
+		{
+			Name:      "untyped",
+			Sort:      []app.TableColumn{{FieldID: "f", Sort: app.SortAscending}},
+			Expected:  "ORDER BY " + number + " ASC NULLS LAST, " + text + " ASC NULLS LAST, p.ObjectID",
+			Arguments: []interface{}{"f", "f", "f"},
+		},
+		{
+			Name:      "number",
+			Sort:      []app.TableColumn{{FieldID: "f", Sort: app.SortDescending}},
+			Types:     map[string]app.FieldType{"f": typeOf(app.PropertyFieldTypeNumber)},
+			Expected:  "ORDER BY " + number + " DESC NULLS LAST, " + text + " DESC NULLS LAST, p.ObjectID",
+			Arguments: []interface{}{"f", "f", "f"},
+		},
+		{
+			Name:      "formula",
+			Sort:      []app.TableColumn{{FieldID: "f", Sort: app.SortAscending}},
+			Types:     map[string]app.FieldType{"f": typeOf(app.PropertyFieldTypeFormula)},
+			Expected:  "ORDER BY " + number + " ASC NULLS LAST, " + text + " ASC NULLS LAST, p.ObjectID",
+			Arguments: []interface{}{"f", "f", "f"},
+		},
+		{
+			Name:      "text",
+			Sort:      []app.TableColumn{{FieldID: "f", Sort: app.SortAscending}},
+			Types:     map[string]app.FieldType{"f": typeOf(app.PropertyFieldTypeText)},
+			Expected:  "ORDER BY " + text + " ASC NULLS LAST, p.ObjectID",
+			Arguments: []interface{}{"f"},
+		},
+		{
+			Name:      "unsorted",
+			Sort:      []app.TableColumn{{FieldID: "f"}},
+			Expected:  "",
+			Arguments: nil,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			query, args, err := orderObjects(sq.Select("p.ObjectID").From("PROP_Property_Query_View p"), c.Sort, c.Types).ToSql()
+			require.NoError(t, err)
+
+			expected := "SELECT p.ObjectID FROM PROP_Property_Query_View p"
+			if c.Expected != "" {
+				expected += " " + c.Expected
+			}
+			assert.Equal(t, expected, query)
+			assert.Equal(t, c.Arguments, args)
+		})
+	}
+}
+
+// TestQueryObjectsWhere runs each query through the SQL predicates and app.Query.Matches, which
+// evaluates views in memory and should agree with them.
+func TestQueryObjectsWhere(t *testing.T) {
+	values := map[string][]interface{}{
+		"tags":     {"bug", "ui"},
+		"estimate": {float64(3)},
+		"done":     {"true"},
+	}
+	types := map[string]string{
+		"tags":     app.PropertyFieldTypeSelect,
+		"estimate": app.PropertyFieldTypeNumber,
+		"done":     app.PropertyFieldTypeCheckbox,
+	}
+	fieldTypes := map[string]app.FieldType{}
+	for fieldID, name := range types {
+		fieldTypes[fieldID], _ = app.GetFieldType(name)
+	}
+
+	cases := []struct {
+		Name         string
+		Query        app.Query
+		ExpectedSQL  string
+		ExpectedArgs []interface{}
+		// Expected is whether the object having the values above matches, in SQL and in memory.
+		Expected bool
+	}{
+		{
+			Name:         "includes an option",
+			Query:        app.Query{Includes: map[string][]string{"tags": {"ui"}}},
+			ExpectedSQL:  "p.Properties::jsonb->? ?? ?",
+			ExpectedArgs: []interface{}{"tags", "ui"},
+			Expected:     true,
+		},
+		{
+			Name:         "includes any of the options",
+			Query:        app.Query{Includes: map[string][]string{"tags": {"backend", "frontend"}}},
+			ExpectedSQL:  "p.Properties::jsonb->? ??| array[? , ?]",
+			ExpectedArgs: []interface{}{"tags", "backend", "frontend"},
+			Expected:     false,
+		},
+		{
+			Name:         "includes an unset field",
+			Query:        app.Query{Includes: map[string][]string{"owner": {}}},
+			ExpectedSQL:  "p.Properties::jsonb ?? ?",
+			ExpectedArgs: []interface{}{"owner"},
+			Expected:     false,
+		},
+		{
+			Name:         "excludes an option",
+			Query:        app.Query{Excludes: map[string][]string{"tags": {"bug"}}},
+			ExpectedSQL:  "NOT(p.Properties::jsonb->? ?? ?)",
+			ExpectedArgs: []interface{}{"tags", "bug"},
+			Expected:     false,
+		},
+		{
+			// Saved views rely on objects without the field not matching
+			Name:         "excludes an option of an unset field",
+			Query:        app.Query{Excludes: map[string][]string{"owner": {"jo"}}},
+			ExpectedSQL:  "NOT(p.Properties::jsonb->? ?? ?)",
+			ExpectedArgs: []interface{}{"owner", "jo"},
+			Expected:     false,
+		},
+		{
+			Name:         "excludes options of an unset field",
+			Query:        app.Query{Excludes: map[string][]string{"owner": {"jo", "sam"}}},
+			ExpectedSQL:  "NOT(p.Properties::jsonb->? ??| array[? , ?])",
+			ExpectedArgs: []interface{}{"owner", "jo", "sam"},
+			Expected:     false,
+		},
+		{
+			Name:         "excludes an unset field",
+			Query:        app.Query{Excludes: map[string][]string{"owner": {}}},
+			ExpectedSQL:  "NOT(p.Properties::jsonb ?? ?)",
+			ExpectedArgs: []interface{}{"owner"},
+			Expected:     true,
+		},
+		{
+			Name:         "number condition",
+			Query:        app.Query{Conditions: []app.Condition{{FieldID: "estimate", Operator: app.OperatorLessOrEqual, Value: "2"}}},
+			ExpectedSQL:  "CASE WHEN json_typeof(p.Properties->?->0) = 'number' THEN (p.Properties->?->>0)::float8 END <= ?",
+			ExpectedArgs: []interface{}{"estimate", "estimate", float64(2)},
+			Expected:     false,
+		},
+		{
+			Name:         "checkbox condition",
+			Query:        app.Query{Conditions: []app.Condition{{FieldID: "done", Operator: app.OperatorIsChecked}}},
+			ExpectedSQL:  "p.Properties->?->>0 = 'true'",
+			ExpectedArgs: []interface{}{"done"},
+			Expected:     true,
+		},
+		{
+			Name:         "condition on a field of unknown type",
+			Query:        app.Query{Conditions: []app.Condition{{FieldID: "owner", Operator: app.OperatorIsChecked}}},
+			ExpectedSQL:  "FALSE",
+			ExpectedArgs: []interface{}{},
+			Expected:     false,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			where, args, err := queryObjectsWhere(c.Query, fieldTypes).ToSql()
+			require.NoError(t, err)
+			assert.Equal(t, "("+c.ExpectedSQL+" AND p.ObjectType = ?)", where)
+			assert.Equal(t, append(c.ExpectedArgs, app.PropertyObjectTypePost), args)
+
+			assert.Equal(t, c.Expected, c.Query.Matches(app.PropertyObjectTypePost, "channel", "team", values, types))
+		})
+	}
+}
+
+func TestAggregateSelect(t *testing.T) {
+	number := "CASE WHEN json_typeof(p.Properties->?->0) = 'number' THEN (p.Properties->?->>0)::float8 END"
+	counts := "COUNT(*) AS Count, 0 AS MetricCount, NULL::float8 AS Sum, NULL::float8 AS Avg, NULL::float8 AS Min, NULL::float8 AS Max"
+	metrics := "COUNT(*) AS Count, COUNT(" + number + ") AS MetricCount, SUM(" + number + ") AS Sum, AVG(" + number + ") AS Avg, MIN(" + number + ") AS Min, MAX(" + number + ") AS Max"
+	metricArgs := []interface{}{"points", "points", "points", "points", "points", "points", "points", "points", "points", "points"}
+	join := " LEFT JOIN LATERAL json_array_elements_text(p.Properties->?) g%[1]d(value) ON true"
+
+	cases := []struct {
+		Name            string
+		GroupByFieldIDs []string
+		NumberFieldID   string
+		ExpectedColumns string
+		ExpectedJoins   string
+		ExpectedArgs    []interface{}
+	}{
+		{
+			Name:            "count of all objects",
+			ExpectedColumns: "NULL::text AS Value1, NULL::text AS Value2, " + counts,
+			ExpectedArgs:    []interface{}{},
+		},
+		{
+			Name:            "count per value",
+			GroupByFieldIDs: []string{"status"},
+			ExpectedColumns: "g1.value AS Value1, NULL::text AS Value2, " + counts,
+			ExpectedJoins:   fmt.Sprintf(join, 1),
+			ExpectedArgs:    []interface{}{"status"},
+		},
+		{
+			Name:            "count per pair of values",
+			GroupByFieldIDs: []string{"status", "owner"},
+			ExpectedColumns: "g1.value AS Value1, g2.value AS Value2, " + counts,
+			ExpectedJoins:   fmt.Sprintf(join, 1) + fmt.Sprintf(join, 2),
+			ExpectedArgs:    []interface{}{"status", "owner"},
+		},
+		{
+			Name:            "metrics of all objects",
+			NumberFieldID:   "points",
+			ExpectedColumns: "NULL::text AS Value1, NULL::text AS Value2, " + metrics,
+			ExpectedArgs:    metricArgs,
+		},
+		{
+			Name:            "metrics per value",
+			GroupByFieldIDs: []string{"status"},
+			NumberFieldID:   "points",
+			ExpectedColumns: "g1.value AS Value1, NULL::text AS Value2, " + metrics,
+			ExpectedJoins:   fmt.Sprintf(join, 1),
+			ExpectedArgs:    append(append([]interface{}{}, metricArgs...), "status"),
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			query, args, err := aggregateSelect(app.Query{TeamID: "team1"}, nil, c.GroupByFieldIDs, c.NumberFieldID).ToSql()
+			require.NoError(t, err)
+
+			expected := "SELECT " + c.ExpectedColumns + " FROM PROP_Property_Query_View p" + c.ExpectedJoins +
+				" WHERE (p.TeamID = ? AND p.ObjectType = ?) GROUP BY Value1, Value2 ORDER BY Value1 NULLS LAST, Value2 NULLS LAST"
+			assert.Equal(t, expected, query)
+			assert.Equal(t, append(c.ExpectedArgs, "team1", app.PropertyObjectTypePost), args)
+		})
+	}
+}
+
+func TestThreadRootsSelect(t *testing.T) {
+	cases := []struct {
+		Name     string
+		Page     int
+		PerPage  int
+		Expected string
+	}{
+		{
+			Name:     "first page",
+			Page:     0,
+			PerPage:  20,
+			Expected: "LIMIT 20 OFFSET 0",
+		},
+		{
+			Name:     "later page",
+			Page:     2,
+			PerPage:  20,
+			Expected: "LIMIT 20 OFFSET 40",
+		},
+		{
+			Name:     "negative page",
+			Page:     -1,
+			PerPage:  20,
+			Expected: "LIMIT 20 OFFSET 0",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			query, args, err := threadRootsSelect(app.Query{TeamID: "team1"}, nil, c.Page, c.PerPage).ToSql()
+			require.NoError(t, err)
+
+			expected := "SELECT DISTINCT COALESCE(NULLIF(po.RootId, ''), p.ObjectID) AS RootID FROM PROP_Property_Query_View p" +
+				" LEFT JOIN Posts po ON po.Id = p.ObjectID WHERE (p.TeamID = ? AND p.ObjectType = ?) ORDER BY RootID " + c.Expected
+			assert.Equal(t, expected, query)
+			assert.Equal(t, []interface{}{"team1", app.PropertyObjectTypePost}, args)
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package sqlstore
+
+import (
+	"database/sql"
+
+	"github.com/jwilander/mattermost-plugin-properties/server/app"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/pkg/errors"
+)
+
+type viewWatchStore struct {
+	pluginAPI       PluginAPIClient
+	store           *SQLStore
+	queryBuilder    sq.StatementBuilderType
+	viewWatchSelect sq.SelectBuilder
+}
+
+// Ensure viewWatchStore implements app.ViewWatchStore interface
+var _ app.ViewWatchStore = (*viewWatchStore)(nil)
+
+func NewViewWatchStore(pluginAPI PluginAPIClient, sqlStore *SQLStore) app.ViewWatchStore {
+	viewWatchSelect := sqlStore.builder.
+		Select(
+			"w.ViewID",
+			"w.UserID",
+			"w.CreateAt",
+		).
+		From("PROP_ViewWatch w")
+
+	return &viewWatchStore{
+		pluginAPI:       pluginAPI,
+		store:           sqlStore,
+		queryBuilder:    sqlStore.builder,
+		viewWatchSelect: viewWatchSelect,
+	}
+}
+
+func (w *viewWatchStore) Create(watch app.ViewWatch) error {
+	_, err := w.store.execBuilder(w.store.db, sq.
+		Insert("PROP_ViewWatch").
+		SetMap(map[string]interface{}{
+			"ViewID":   watch.ViewID,
+			"UserID":   watch.UserID,
+			"CreateAt": model.GetMillis(),
+		}).
+		Suffix("ON CONFLICT DO NOTHING"))
+	if err != nil {
+		return errors.Wrapf(err, "failed to store watch of view '%s'", watch.ViewID)
+	}
+
+	return nil
+}
+
+func (w *viewWatchStore) GetForUser(userID string) ([]app.ViewWatch, error) {
+	return w.selectWatches(w.viewWatchSelect.Where(sq.Eq{"w.UserID": userID}))
+}
+
+func (w *viewWatchStore) GetForObject(teamID string, channelID string) ([]app.ViewWatch, error) {
+	return w.selectWatches(w.viewWatchSelect.
+		Join("PROP_View v ON v.ID = w.ViewID").
+		Where(sq.Eq{"COALESCE(v.Query->>'team_id', '')": []string{"", teamID}}).
+		Where(sq.Eq{"COALESCE(v.Query->>'channel_id', '')": []string{"", channelID}}))
+}
+
+func (w *viewWatchStore) selectWatches(query sq.SelectBuilder) ([]app.ViewWatch, error) {
+	var watches []app.ViewWatch
+	err := w.store.selectBuilder(w.store.db, &watches, query.OrderBy("w.CreateAt ASC"))
+	if err != nil && err != sql.ErrNoRows {
+		return []app.ViewWatch{}, errors.Wrap(err, "failed to get view watches")
+	}
+
+	if watches == nil {
+		watches = []app.ViewWatch{}
+	}
+
+	return watches, nil
+}
+
+func (w *viewWatchStore) Delete(viewID string, userID string) error {
+	_, err := w.store.execBuilder(w.store.db, sq.
+		Delete("PROP_ViewWatch").
+		Where(sq.Eq{"ViewID": viewID, "UserID": userID}))
+	if err != nil {
+		return errors.Wrapf(err, "failed to delete watch of view '%s'", viewID)
+	}
+
+	return nil
+}
@@ -0,0 +1,319 @@
+package sqlstore
+
+import (
+	"database/sql"
+
+	"github.com/jwilander/mattermost-plugin-properties/server/app"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/pkg/errors"
+)
+
+type webhookStore struct {
+	pluginAPI      PluginAPIClient
+	store          *SQLStore
+	queryBuilder   sq.StatementBuilderType
+	webhookSelect  sq.SelectBuilder
+	deliverySelect sq.SelectBuilder
+}
+
+// Ensure webhookStore implements app.WebhookStore interface
+var _ app.WebhookStore = (*webhookStore)(nil)
+
+func NewWebhookStore(pluginAPI PluginAPIClient, sqlStore *SQLStore) app.WebhookStore {
+	webhookSelect := sqlStore.builder.
+		Select(
+			"w.ID",
+			"w.TeamID",
+			"w.ChannelID",
+			"w.PropertyFieldID",
+			"w.URL",
+			"w.Secret",
+			"w.CreateAt",
+			"w.UpdateAt",
+			"w.UpdateBy",
+		).
+		From("PROP_Webhook w")
+
+	deliverySelect := sqlStore.builder.
+		Select(
+			"d.ID",
+			"d.WebhookID",
+			"d.ChangeType",
+			"d.PropertyID",
+			"d.Payload",
+			"d.Status",
+			"d.Attempts",
+			"d.StatusCode",
+			"d.Error",
+			"d.CreateAt",
+			"d.UpdateAt",
+			"d.NextAttemptAt",
+		).
+		From("PROP_WebhookDelivery d")
+
+	return &webhookStore{
+		pluginAPI:      pluginAPI,
+		store:          sqlStore,
+		queryBuilder:   sqlStore.builder,
+		webhookSelect:  webhookSelect,
+		deliverySelect: deliverySelect,
+	}
+}
+
+func (w *webhookStore) Create(webhook app.Webhook) (string, error) {
+	if webhook.ID != "" {
+		return "", errors.New("ID should be empty")
+	}
+	webhook.ID = model.NewId()
+	webhook.CreateAt = model.GetMillis()
+	webhook.UpdateAt = webhook.CreateAt
+
+	tx, err := w.store.db.Beginx()
+	if err != nil {
+		return "", errors.Wrap(err, "could not begin transaction")
+	}
+	defer w.store.finalizeTransaction(tx)
+
+	_, err = w.store.execBuilder(tx, sq.
+		Insert("PROP_Webhook").
+		SetMap(map[string]interface{}{
+			"ID":              webhook.ID,
+			"TeamID":          webhook.TeamID,
+			"ChannelID":       webhook.ChannelID,
+			"PropertyFieldID": webhook.PropertyFieldID,
+			"URL":             webhook.URL,
+			"Secret":          webhook.Secret,
+			"CreateAt":        webhook.CreateAt,
+			"UpdateAt":        webhook.UpdateAt,
+			"UpdateBy":        webhook.UpdateBy,
+		}))
+	if err != nil {
+		return "", errors.Wrap(err, "failed to store new webhook")
+	}
+
+	if err = tx.Commit(); err != nil {
+		return "", errors.Wrap(err, "could not commit transaction")
+	}
+
+	return webhook.ID, nil
+}
+
+func (w *webhookStore) Get(id string) (app.Webhook, error) {
+	if id == "" {
+		return app.Webhook{}, errors.New("id cannot be blank")
+	}
+
+	var webhook app.Webhook
+	err := w.store.getBuilder(w.store.db, &webhook, w.webhookSelect.Where(sq.Eq{"w.ID": id}))
+	if err == sql.ErrNoRows {
+		return app.Webhook{}, errors.Wrapf(app.ErrNotFound, "no webhook exists for id '%s'", id)
+	} else if err != nil {
+		return app.Webhook{}, errors.Wrapf(err, "failed to get webhook by id '%s'", id)
+	}
+
+	return webhook, nil
+}
+
+func (w *webhookStore) GetWebhooks(filter app.WebhookFilterOptions) ([]app.Webhook, error) {
+	query := w.webhookSelect
+
+	if filter.TeamID != "" {
+		query = query.Where(sq.Eq{"w.TeamID": filter.TeamID})
+	}
+
+	page := filter.Page
+	perPage := filter.PerPage
+	if page < 0 {
+		page = 0
+	}
+	if perPage < 0 {
+		perPage = 0
+	}
+
+	query = query.
+		OrderBy("w.CreateAt ASC").
+		Offset(uint64(page * perPage)).
+		Limit(uint64(perPage))
+
+	var webhooks []app.Webhook
+	err := w.store.selectBuilder(w.store.db, &webhooks, query)
+	if err != nil && err != sql.ErrNoRows {
+		return []app.Webhook{}, errors.Wrap(err, "failed to get webhooks")
+	}
+
+	return webhooks, nil
+}
+
+func (w *webhookStore) GetForChange(teamID, channelID, propertyFieldID string) ([]app.Webhook, error) {
+	query := w.webhookSelect.
+		Where(sq.Or{sq.Eq{"w.TeamID": ""}, sq.Eq{"w.TeamID": teamID}}).
+		Where(sq.Or{sq.Eq{"w.ChannelID": ""}, sq.Eq{"w.ChannelID": channelID}}).
+		Where(sq.Or{sq.Eq{"w.PropertyFieldID": ""}, sq.Eq{"w.PropertyFieldID": propertyFieldID}})
+
+	var webhooks []app.Webhook
+	err := w.store.selectBuilder(w.store.db, &webhooks, query)
+	if err != nil && err != sql.ErrNoRows {
+		return []app.Webhook{}, errors.Wrap(err, "failed to get webhooks for change")
+	}
+
+	return webhooks, nil
+}
+
+func (w *webhookStore) Update(webhook app.Webhook) error {
+	webhook.UpdateAt = model.GetMillis()
+
+	tx, err := w.store.db.Beginx()
+	if err != nil {
+		return errors.Wrap(err, "could not begin transaction")
+	}
+	defer w.store.finalizeTransaction(tx)
+
+	_, err = w.store.execBuilder(tx, sq.
+		Update("PROP_Webhook").
+		SetMap(map[string]interface{}{
+			"ChannelID":       webhook.ChannelID,
+			"PropertyFieldID": webhook.PropertyFieldID,
+			"URL":             webhook.URL,
+			"Secret":          webhook.Secret,
+			"UpdateAt":        webhook.UpdateAt,
+			"UpdateBy":        webhook.UpdateBy,
+		}).
+		Where(sq.Eq{"ID": webhook.ID}))
+	if err != nil {
+		return errors.Wrapf(err, "failed to update webhook with id '%s'", webhook.ID)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return errors.Wrap(err, "could not commit transaction")
+	}
+
+	return nil
+}
+
+func (w *webhookStore) Delete(id string) error {
+	if id == "" {
+		return errors.New("id cannot be blank")
+	}
+
+	tx, err := w.store.db.Beginx()
+	if err != nil {
+		return errors.Wrap(err, "could not begin transaction")
+	}
+	defer w.store.finalizeTransaction(tx)
+
+	_, err = w.store.execBuilder(tx, sq.
+		Delete("PROP_WebhookDelivery").
+		Where(sq.Eq{"WebhookID": id}))
+	if err != nil {
+		return errors.Wrapf(err, "failed to delete deliveries of webhook with id '%s'", id)
+	}
+
+	_, err = w.store.execBuilder(tx, sq.
+		Delete("PROP_Webhook").
+		Where(sq.Eq{"ID": id}))
+	if err != nil {
+		return errors.Wrapf(err, "failed to delete webhook with id '%s'", id)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return errors.Wrap(err, "could not commit transaction")
+	}
+
+	return nil
+}
+
+func (w *webhookStore) CreateDelivery(delivery app.WebhookDelivery) (string, error) {
+	if delivery.ID == "" {
+		delivery.ID = model.NewId()
+	}
+	delivery.CreateAt = model.GetMillis()
+	delivery.UpdateAt = delivery.CreateAt
+
+	if len(delivery.Payload) > maxJSONLength {
+		return "", errors.Errorf("payload json for webhook delivery id '%s' is too long (max %d)", delivery.ID, maxJSONLength)
+	}
+
+	_, err := w.store.execBuilder(w.store.db, sq.
+		Insert("PROP_WebhookDelivery").
+		SetMap(map[string]interface{}{
+			"ID":            delivery.ID,
+			"WebhookID":     delivery.WebhookID,
+			"ChangeType":    delivery.ChangeType,
+			"PropertyID":    delivery.PropertyID,
+			"Payload":       delivery.Payload,
+			"Status":        delivery.Status,
+			"Attempts":      delivery.Attempts,
+			"StatusCode":    delivery.StatusCode,
+			"Error":         delivery.Error,
+			"CreateAt":      delivery.CreateAt,
+			"UpdateAt":      delivery.UpdateAt,
+			"NextAttemptAt": delivery.NextAttemptAt,
+		}))
+	if err != nil {
+		return "", errors.Wrap(err, "failed to store new webhook delivery")
+	}
+
+	return delivery.ID, nil
+}
+
+func (w *webhookStore) UpdateDelivery(delivery app.WebhookDelivery) error {
+	delivery.UpdateAt = model.GetMillis()
+
+	_, err := w.store.execBuilder(w.store.db, sq.
+		Update("PROP_WebhookDelivery").
+		SetMap(map[string]interface{}{
+			"Status":        delivery.Status,
+			"Attempts":      delivery.Attempts,
+			"StatusCode":    delivery.StatusCode,
+			"Error":         delivery.Error,
+			"UpdateAt":      delivery.UpdateAt,
+			"NextAttemptAt": delivery.NextAttemptAt,
+		}).
+		Where(sq.Eq{"ID": delivery.ID}))
+	if err != nil {
+		return errors.Wrapf(err, "failed to update webhook delivery with id '%s'", delivery.ID)
+	}
+
+	return nil
+}
+
+func (w *webhookStore) GetDeliveries(webhookID string, page, perPage int) ([]app.WebhookDelivery, error) {
+	if page < 0 {
+		page = 0
+	}
+	if perPage < 0 {
+		perPage = 0
+	}
+
+	query := w.deliverySelect.
+		Where(sq.Eq{"d.WebhookID": webhookID}).
+		OrderBy("d.CreateAt DESC").
+		Offset(uint64(page * perPage)).
+		Limit(uint64(perPage))
+
+	var deliveries []app.WebhookDelivery
+	err := w.store.selectBuilder(w.store.db, &deliveries, query)
+	if err != nil && err != sql.ErrNoRows {
+		return []app.WebhookDelivery{}, errors.Wrapf(err, "failed to get deliveries of webhook '%s'", webhookID)
+	}
+
+	return deliveries, nil
+}
+
+func (w *webhookStore) GetDueDeliveries(until int64, limit int) ([]app.WebhookDelivery, error) {
+	query := w.deliverySelect.
+		Where(sq.Eq{"d.Status": app.WebhookDeliveryStatusPending}).
+		Where(sq.LtOrEq{"d.NextAttemptAt": until}).
+		OrderBy("d.NextAttemptAt").
+		Limit(uint64(limit))
+
+	var deliveries []app.WebhookDelivery
+	err := w.store.selectBuilder(w.store.db, &deliveries, query)
+	if err != nil && err != sql.ErrNoRows {
+		return []app.WebhookDelivery{}, errors.Wrap(err, "failed to get due webhook deliveries")
+	}
+
+	return deliveries, nil
+}
@@ -5,7 +5,7 @@ import {Client4} from 'mattermost-redux/client';
 import {ClientError} from '@mattermost/client';
 
 import {manifest} from './manifest';
-import {Property, PropertyField, View, ViewFormat, ViewQuery, ViewQueryResults} from './types/property';
+import {Aggregation, BoardData, CalendarData, ChartData, Property, PropertyField, RelationSettings, RollupSettings, TimelineData, View, ViewFormat, ViewMove, ViewQuery, ViewQueryResults} from './types/property';
 
 let siteURL = '';
 let basePath = '';
@@ -36,8 +36,16 @@ export async function createProperty(object_id: string, object_type: string, pro
     return data as {id: string};
 }
 
-export async function fetchPropertiesForObject(objectID: string) {
-    const data = await doGet(`${apiUrl}/property/object/${objectID}`);
+export async function fetchPropertiesForObject(objectID: string, objectType?: string) {
+    const query = objectType ? `?object_type=${objectType}` : '';
+    const data = await doGet(`${apiUrl}/property/object/${objectID}${query}`);
+
+    return data as Property[];
+}
+
+// fetchReferencingProperties fetches the properties of relation fields linking to the object.
+export async function fetchReferencingProperties(objectID: string) {
+    const data = await doGet(`${apiUrl}/property/object/${objectID}/referencing`);
 
     return data as Property[];
 }
@@ -50,8 +58,8 @@ export async function deleteProperty(id: string) {
     await doDelete(`${apiUrl}/property/${id}`);
 }
 
-export async function createPropertyField(name: string, type: string, values: string[] | null | undefined) {
-    const data = await doPost(`${apiUrl}/field`, JSON.stringify({name, type, values}));
+export async function createPropertyField(name: string, type: string, values: string[] | null | undefined, formula?: string, relation?: RelationSettings, rollup?: RollupSettings) {
+    const data = await doPost(`${apiUrl}/field`, JSON.stringify({name, type, values, formula, relation, rollup}));
     return data as {id: string};
 }
 
@@ -65,8 +73,8 @@ export async function fetchPropertyFieldsForTerm(term: string) {
     return data as PropertyField[];
 }
 
-export async function updatePropertyField(id: string, type: string, name: string, values: string[] | null | undefined) {
-    await doPut(`${apiUrl}/field/${id}`, JSON.stringify({name, type, values}));
+export async function updatePropertyField(id: string, type: string, name: string, values: string[] | null | undefined, formula?: string, relation?: RelationSettings, rollup?: RollupSettings) {
+    await doPut(`${apiUrl}/field/${id}`, JSON.stringify({name, type, values, formula, relation, rollup}));
 }
 
 export async function fetchObjectsForView(id: string) {
@@ -75,6 +83,60 @@ export async function fetchObjectsForView(id: string) {
     return data as ViewQueryResults;
 }
 
+export async function fetchBoardForView(id: string) {
+    const data = await doGet(`${apiUrl}/view/${id}/board`);
+
+    return data as BoardData;
+}
+
+export async function fetchViewAggregation(id: string, groupBy: string[] = [], numberFields: string[] = []) {
+    const params = new URLSearchParams();
+    if (groupBy.length > 0) {
+        params.set('group_by', groupBy.join(','));
+    }
+    if (numberFields.length > 0) {
+        params.set('number_fields', numberFields.join(','));
+    }
+    const query = params.toString() ? `?${params.toString()}` : '';
+    const data = await doGet(`${apiUrl}/view/${id}/aggregate${query}`);
+
+    return data as Aggregation;
+}
+
+export async function fetchChartForView(id: string) {
+    const data = await doGet(`${apiUrl}/view/${id}/chart`);
+
+    return data as ChartData;
+}
+
+// fetchCalendarForView fetches the objects of a calendar view from one day to another, both
+// formatted as YYYY-MM-DD.
+export async function fetchCalendarForView(id: string, from: string, to: string) {
+    const data = await doGet(`${apiUrl}/view/${id}/calendar?from=${from}&to=${to}`);
+
+    return data as CalendarData;
+}
+
+// fetchTimelineForView fetches the bars and dependencies of a timeline view from one day to
+// another, both formatted as YYYY-MM-DD.
+export async function fetchTimelineForView(id: string, from: string, to: string) {
+    const data = await doGet(`${apiUrl}/view/${id}/timeline?from=${from}&to=${to}`);
+
+    return data as TimelineData;
+}
+
+export async function fetchView(id: string) {
+    const data = await doGet(`${apiUrl}/view/${id}`);
+
+    return data as View;
+}
+
+export async function moveObjectInView(id: string, move: ViewMove) {
+    const data = await doPost(`${apiUrl}/view/${id}/move`, JSON.stringify(move));
+
+    return data as View;
+}
+
 export async function fetchViewsForUser(userID: string) {
     const data = await doGet(`${apiUrl}/view/user/${userID}`);
 
@@ -0,0 +1,172 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+import React, {useEffect, useState} from 'react';
+import styled from 'styled-components';
+
+import {fetchCalendarForView} from 'src/client';
+import {CalendarData} from 'src/types/property';
+import {formatDay} from 'src/utils';
+
+type CalendarProps = {
+    id: string;
+}
+
+const weekdays = ['Sun', 'Mon', 'Tue', 'Wed', 'Thu', 'Fri', 'Sat'];
+
+const maxItemsPerDay = 3;
+
+// monthGrid returns the days shown for a month, whole weeks from the Sunday before its first day.
+const monthGrid = (month: Date) => {
+    const first = new Date(month.getFullYear(), month.getMonth(), 1);
+    const start = new Date(first.getFullYear(), first.getMonth(), 1 - first.getDay());
+    const last = new Date(month.getFullYear(), month.getMonth() + 1, 0);
+    const weeks = Math.ceil((first.getDay() + last.getDate()) / 7);
+
+    return Array.from({length: weeks * 7}, (_, i) => new Date(start.getFullYear(), start.getMonth(), start.getDate() + i));
+};
+
+const Calendar = ({id}: CalendarProps) => {
+    const [month, setMonth] = useState(() => {
+        const now = new Date();
+        return new Date(now.getFullYear(), now.getMonth(), 1);
+    });
+    const [data, setData] = useState<CalendarData | null>(null);
+
+    const days = monthGrid(month);
+
+    useEffect(() => {
+        fetchCalendarForView(id, formatDay(days[0]), formatDay(days[days.length - 1])).then(setData).catch(() => setData(null));
+    }, [id, month]);
+
+    const titles: Record<string, string> = {};
+    data?.objects.posts.forEach((p) => {
+        titles[p.id] = p.message;
+    });
+    data?.objects.files.forEach((f) => {
+        titles[f.id] = f.name;
+    });
+
+    const changeMonth = (delta: number) => {
+        setMonth(new Date(month.getFullYear(), month.getMonth() + delta, 1));
+    };
+
+    return (
+        <CalendarContainer>
+            <Toolbar>
+                <NavButton onClick={() => changeMonth(-1)}>{'‹'}</NavButton>
+                <MonthTitle>{month.toLocaleDateString(undefined, {month: 'long', year: 'numeric'})}</MonthTitle>
+                <NavButton onClick={() => changeMonth(1)}>{'›'}</NavButton>
+            </Toolbar>
+            {data?.truncated && (
+                <Truncated>{'Only the first items of this month are shown. Narrow the view to see them all.'}</Truncated>
+            )}
+            <Grid>
+                {weekdays.map((d) => (
+                    <Weekday key={d}>{d}</Weekday>
+                ))}
+                {days.map((day) => {
+                    const key = formatDay(day);
+                    const objectIDs = data?.days[key] || [];
+                    return (
+                        <Day
+                            key={key}
+                            outside={day.getMonth() !== month.getMonth()}
+                        >
+                            <DayNumber>{day.getDate()}</DayNumber>
+                            {objectIDs.slice(0, maxItemsPerDay).map((objectID) => (
+                                <Item
+                                    key={objectID}
+                                    title={titles[objectID]}
+                                >
+                                    {titles[objectID] || objectID}
+                                </Item>
+                            ))}
+                            {objectIDs.length > maxItemsPerDay && (
+                                <More>{`+${objectIDs.length - maxItemsPerDay} more`}</More>
+                            )}
+                        </Day>
+                    );
+                })}
+            </Grid>
+        </CalendarContainer>
+    );
+};
+
+const CalendarContainer = styled.div`
+    color: var(--center-channel-color);
+`;
+
+const Toolbar = styled.div`
+    display: flex;
+    align-items: center;
+    margin-bottom: 12px;
+`;
+
+const NavButton = styled.button`
+    border: none;
+    background: none;
+    font-size: 18px;
+    color: rgba(var(--center-channel-color-rgb), 0.64);
+    cursor: pointer;
+`;
+
+const MonthTitle = styled.div`
+    min-width: 160px;
+    text-align: center;
+    font-weight: 600;
+`;
+
+const Truncated = styled.div`
+    margin-bottom: 8px;
+    font-size: 12px;
+    color: rgba(var(--center-channel-color-rgb), 0.64);
+`;
+
+const Grid = styled.div`
+    display: grid;
+    grid-template-columns: repeat(7, 1fr);
+    border-top: 1px solid rgba(var(--center-channel-color-rgb), 0.16);
+    border-left: 1px solid rgba(var(--center-channel-color-rgb), 0.16);
+`;
+
+const Weekday = styled.div`
+    padding: 4px 8px;
+    font-size: 12px;
+    font-weight: 600;
+    color: rgba(var(--center-channel-color-rgb), 0.64);
+    border-right: 1px solid rgba(var(--center-channel-color-rgb), 0.16);
+    border-bottom: 1px solid rgba(var(--center-channel-color-rgb), 0.16);
+`;
+
+const Day = styled.div<{outside: boolean}>`
+    min-height: 96px;
+    padding: 4px;
+    overflow: hidden;
+    border-right: 1px solid rgba(var(--center-channel-color-rgb), 0.16);
+    border-bottom: 1px solid rgba(var(--center-channel-color-rgb), 0.16);
+    opacity: ${(props) => (props.outside ? 0.56 : 1)};
+`;
+
+const DayNumber = styled.div`
+    font-size: 12px;
+    margin-bottom: 4px;
+`;
+
+const Item = styled.div`
+    margin-bottom: 2px;
+    padding: 1px 4px;
+    font-size: 12px;
+    border-radius: 2px;
+    overflow: hidden;
+    text-overflow: ellipsis;
+    white-space: nowrap;
+    background: rgba(var(--button-bg-rgb), 0.12);
+`;
+
+const More = styled.div`
+    font-size: 11px;
+    color: rgba(var(--center-channel-color-rgb), 0.64);
+`;
+
+export default Calendar;
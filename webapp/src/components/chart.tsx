@@ -0,0 +1,172 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+import React, {useEffect, useState} from 'react';
+import styled from 'styled-components';
+
+import {fetchChartForView} from 'src/client';
+import {ChartData} from 'src/types/property';
+
+type ChartProps = {
+    id: string;
+}
+
+const colors = ['#1c58d9', '#3db887', '#ffbc1f', '#d24b4e', '#7a5ce6', '#ff8800', '#27a3b5'];
+
+const chartHeight = 200;
+
+const Chart = ({id}: ChartProps) => {
+    const [data, setData] = useState<ChartData | null>(null);
+
+    useEffect(() => {
+        fetchChartForView(id).then(setData).catch(() => setData(null));
+    }, [id]);
+
+    if (!data) {
+        return null;
+    }
+
+    const max = Math.max(1, ...data.series.flatMap((s) => s.values));
+
+    return (
+        <ChartContainer>
+            {data.kind === 'line' ? (
+                <LineChart
+                    data={data}
+                    max={max}
+                />
+            ) : (
+                <BarChart
+                    data={data}
+                    max={max}
+                    share={data.kind === 'pie'}
+                />
+            )}
+            <Legend>
+                {data.series.map((s, i) => (
+                    <LegendItem key={s.name}>
+                        <Swatch style={{background: colors[i % colors.length]}}/>
+                        {s.name}
+                    </LegendItem>
+                ))}
+            </Legend>
+        </ChartContainer>
+    );
+};
+
+// BarChart plots a bar per label and series. Pie charts are shown as shares of the total.
+const BarChart = ({data, max, share}: {data: ChartData, max: number, share: boolean}) => {
+    const total = data.series.reduce((sum, s) => sum + s.values.reduce((a, b) => a + b, 0), 0) || 1;
+
+    return (
+        <div>
+            {data.labels.map((label, l) => (
+                <BarRow key={label}>
+                    <BarLabel>{label}</BarLabel>
+                    <div>
+                        {data.series.map((s, i) => (
+                            <Bar
+                                key={s.name}
+                                style={{
+                                    width: `${(share ? s.values[l] / total : s.values[l] / max) * 100}%`,
+                                    background: colors[i % colors.length],
+                                }}
+                                title={`${s.name}: ${s.values[l]}`}
+                            />
+                        ))}
+                    </div>
+                    <BarValue>
+                        {share ? `${Math.round((data.series.reduce((sum, s) => sum + s.values[l], 0) / total) * 100)}%` : data.series.map((s) => s.values[l]).join(' / ')}
+                    </BarValue>
+                </BarRow>
+            ))}
+        </div>
+    );
+};
+
+const LineChart = ({data, max}: {data: ChartData, max: number}) => {
+    const width = Math.max(1, data.labels.length - 1);
+
+    return (
+        <div>
+            <svg
+                viewBox={`0 0 ${width} ${chartHeight}`}
+                preserveAspectRatio='none'
+                width='100%'
+                height={chartHeight}
+            >
+                {data.series.map((s, i) => (
+                    <polyline
+                        key={s.name}
+                        fill='none'
+                        stroke={colors[i % colors.length]}
+                        strokeWidth={2}
+                        vectorEffect='non-scaling-stroke'
+                        points={s.values.map((v, x) => `${x},${chartHeight - ((v / max) * chartHeight)}`).join(' ')}
+                    />
+                ))}
+            </svg>
+            <Axis>
+                <span>{data.labels[0]}</span>
+                <span>{data.labels[data.labels.length - 1]}</span>
+            </Axis>
+        </div>
+    );
+};
+
+const ChartContainer = styled.div`
+    max-width: 800px;
+    color: var(--center-channel-color);
+`;
+
+const BarRow = styled.div`
+    display: grid;
+    grid-template-columns: 160px 1fr 80px;
+    align-items: center;
+    margin-bottom: 8px;
+`;
+
+const BarLabel = styled.div`
+    overflow: hidden;
+    text-overflow: ellipsis;
+    white-space: nowrap;
+`;
+
+const Bar = styled.div`
+    height: 12px;
+    margin: 2px 0;
+    border-radius: 2px;
+`;
+
+const BarValue = styled.div`
+    padding-left: 8px;
+    color: rgba(var(--center-channel-color-rgb), 0.64);
+`;
+
+const Axis = styled.div`
+    display: flex;
+    justify-content: space-between;
+    font-size: 12px;
+    color: rgba(var(--center-channel-color-rgb), 0.64);
+`;
+
+const Legend = styled.div`
+    display: flex;
+    flex-wrap: wrap;
+    margin-top: 16px;
+`;
+
+const LegendItem = styled.div`
+    display: flex;
+    align-items: center;
+    margin-right: 16px;
+`;
+
+const Swatch = styled.span`
+    width: 10px;
+    height: 10px;
+    margin-right: 6px;
+    border-radius: 2px;
+`;
+
+export default Chart;
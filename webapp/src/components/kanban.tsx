@@ -1,16 +1,16 @@
 // Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
 // See LICENSE.txt for license information.
 
-import React, {useMemo} from 'react';
+import React, {useEffect, useMemo, useState} from 'react';
 import {useSelector, useDispatch} from 'react-redux';
 import styled from 'styled-components';
 import withScrolling, {createHorizontalStrength, createVerticalStrength} from 'react-dnd-scrolling';
 
-import {ObjectWithProperties, Property, ViewFormat} from 'src/types/property';
-import {getPropertyField} from 'src/selectors';
+import {Aggregation, BoardData, ObjectWithProperties, Property, PropertyField, ViewFormat} from 'src/types/property';
+import {getPropertyField, getView} from 'src/selectors';
 import KanbanColumnHeader from 'src/components/kanban_column_header';
-import {createProperty, deleteProperty, updatePropertyValue} from 'src/client';
-import {deletedProperty, receivedProperty, receivedPropertyValue} from 'src/actions';
+import {createProperty, deleteProperty, fetchBoardForView, fetchPropertiesForObject, fetchView, fetchViewAggregation, moveObjectInView, patchView, updatePropertyValue} from 'src/client';
+import {deletedProperty, receivedPropertiesForObject, receivedProperty, receivedPropertyValue, receivedView} from 'src/actions';
 
 import KanbanColumn from './kanban_column';
 
@@ -50,6 +50,34 @@ const BoardBody = styled.div`
     padding-left: 1px;
 `;
 
+const Lane = styled.div`
+    width: max-content;
+    margin-bottom: 16px;
+`;
+
+const LaneHeader = styled.div`
+    padding: 8px 0;
+    font-weight: 600;
+    color: var(--center-channel-color);
+    border-bottom: 1px solid rgba(var(--center-channel-color-rgb), 0.16);
+`;
+
+const LaneCount = styled.span`
+    margin-left: 8px;
+    font-weight: 400;
+    color: rgba(var(--center-channel-color-rgb), 0.64);
+`;
+
+// orderColumn sorts the objects of a column by its order, the objects it doesn't list last.
+const orderColumn = (objs: ObjectWithProperties[], order: string[] = []) => {
+    const positions = {} as Record<string, number>;
+    order.forEach((objectID, i) => {
+        positions[objectID] = i;
+    });
+    const position = (o: ObjectWithProperties) => (o.id in positions ? positions[o.id] : order.length);
+    return [...objs].sort((a, b) => position(a) - position(b));
+};
+
 const getObjectsByGroupByField = (fieldID: string, vals: string[], objs: ObjectWithProperties[]) => {
     const result = {} as Record<string, ObjectWithProperties[]>;
     vals.forEach((v) => {
@@ -78,21 +106,62 @@ const Kanban = ({id, objects, format}: KanbanProps) => {
     const groupByField = useSelector(getPropertyField(format.group_by_field_id));
     const groupByFieldValues = groupByField.values || emptyValues;
     const values = useMemo(() => ([...groupByFieldValues, '']), [groupByFieldValues]);
-    const objectsByValue = useMemo(() => getObjectsByGroupByField(format.group_by_field_id, values, objects), [format.group_by_field_id, values, objects]);
+    const view = useSelector(getView(id));
+    const objectsByValue = useMemo(() => {
+        const byValue = getObjectsByGroupByField(format.group_by_field_id, values, objects);
+        Object.keys(byValue).forEach((v) => {
+            byValue[v] = orderColumn(byValue[v], format.column_orders?.[v]);
+        });
+        return byValue;
+    }, [format.group_by_field_id, format.column_orders, values, objects]);
 
-    const onDropToColumn = (value: string, object: ObjectWithProperties) => {
-        const property = object.properties.find((p) => p.property_field_id === groupByField.id);
+    // Counts cover the whole view, while only the first page of objects is loaded
+    const [aggregation, setAggregation] = useState<Aggregation | null>(null);
+    useEffect(() => {
+        fetchViewAggregation(id).then(setAggregation).catch(() => setAggregation(null));
+    }, [id, format.group_by_field_id, objects]);
+    const countsByValue = useMemo(() => {
+        const counts = {} as Record<string, number>;
+        aggregation?.groups.forEach((g) => {
+            counts[g.values[0] || ''] = g.count;
+        });
+        return counts;
+    }, [aggregation]);
+
+    // Boards with swimlanes are bucketed by the server, per column and lane
+    const swimlaneField = useSelector(getPropertyField(format.swimlane_field_id || ''));
+    const [board, setBoard] = useState<BoardData | null>(null);
+    useEffect(() => {
+        if (!format.swimlane_field_id) {
+            setBoard(null);
+            return;
+        }
+        fetchBoardForView(id).then(setBoard).catch(() => setBoard(null));
+    }, [id, format.group_by_field_id, format.swimlane_field_id, objects]);
+    const objectsByID = useMemo(() => {
+        const result = {} as Record<string, ObjectWithProperties>;
+        objects.forEach((o) => {
+            result[o.id] = o;
+        });
+        return result;
+    }, [objects]);
+
+    const setFieldValue = (field: PropertyField, value: string, object: ObjectWithProperties) => {
+        const property = object.properties.find((p) => p.property_field_id === field.id);
         if (property == null) {
-            createProperty(object.id, object.type, groupByField.id, [value]).then(
+            if (!value) {
+                return;
+            }
+            createProperty(object.id, object.type, field.id, [value]).then(
                 () => {
                     dispatch(receivedProperty({
                         id,
                         object_id: object.id,
                         object_type: object.type,
-                        property_field_id: groupByField.id,
-                        property_field_name: groupByField.name,
-                        property_field_type: groupByField.type,
-                        property_field_values: groupByField.values,
+                        property_field_id: field.id,
+                        property_field_name: field.name,
+                        property_field_type: field.type,
+                        property_field_values: field.values,
                         value: [value],
                     } as Property));
                 },
@@ -116,50 +185,49 @@ const Kanban = ({id, objects, format}: KanbanProps) => {
         );
     };
 
-    const onDropToCard = (srcObject: ObjectWithProperties, dstObject: ObjectWithProperties) => {
-        const dstProperty = dstObject.properties.find((p) => p.property_field_id === groupByField.id);
-        const srcProperty = srcObject.properties.find((p) => p.property_field_id === groupByField.id);
-        if (dstProperty == null && srcProperty == null) {
-            return;
-        }
+    const setLimit = async (value: string, limit: number) => {
+        const newFormat = {...format, wip_limits: {...format.wip_limits, [value]: limit}};
+        await patchView(id, null, null, newFormat);
 
-        if (dstProperty && srcProperty == null) {
-            createProperty(srcObject.id, srcObject.type, groupByField.id, dstProperty.value).then(
-                () => {
-                    dispatch(receivedProperty({
-                        id,
-                        object_id: srcObject.id,
-                        object_type: srcObject.type,
-                        property_field_id: groupByField.id,
-                        property_field_name: groupByField.name,
-                        property_field_type: groupByField.type,
-                        property_field_values: groupByField.values,
-                        value: dstProperty.value,
-                    } as Property));
-                },
-            );
-            return;
-        }
+        dispatch(receivedView({...view, format: newFormat}));
+    };
 
-        if (srcProperty && dstProperty == null) {
-            deleteProperty(srcProperty.id).then(
-                () => {
-                    dispatch(deletedProperty(srcProperty.id, srcObject.id));
-                },
-            );
-            return;
+    // Moves go through the server so the value and the order of the column change together,
+    // failing when someone else changed the board first or the column is at its WIP limit
+    const moveObject = async (object: ObjectWithProperties, value: string, position: number) => {
+        const move = {object_id: object.id, object_type: object.type, value, position, update_at: view.update_at};
+        try {
+            dispatch(receivedView(await moveObjectInView(id, move)));
+        } catch (e: any) {
+            const force = e.status_code === 422 && window.confirm(`${e.message}. Move it anyway?`);
+            const moved = force ? await moveObjectInView(id, {...move, force: true}).catch(() => null) : null;
+            dispatch(receivedView(moved || await fetchView(id)));
         }
 
-        //TODO: find better way to make typescript know these are not null, previous checks should be sufficient
-        if (dstProperty == null || srcProperty == null) {
-            return;
+        const properties = await fetchPropertiesForObject(object.id, object.type);
+        dispatch(receivedPropertiesForObject(object.id, properties));
+    };
+
+    const onDropToColumn = (value: string, object: ObjectWithProperties) => {
+        moveObject(object, value, (objectsByValue[value] || []).length);
+    };
+
+    const onDropToCell = (value: string, laneValue: string, object: ObjectWithProperties) => {
+        moveObject(object, value, (objectsByValue[value] || []).length);
+
+        const laneProperty = object.properties.find((p) => p.property_field_id === swimlaneField.id);
+        if ((laneProperty?.value[0] || '') !== laneValue) {
+            setFieldValue(swimlaneField, laneValue, object);
         }
+    };
 
-        updatePropertyValue(srcProperty.id, dstProperty.value).then(
-            () => {
-                dispatch(receivedPropertyValue(srcProperty.id, srcObject.id, dstProperty.value));
-            },
-        );
+    // Cards dropped on a card take its place in its column
+    const onDropToCard = (srcObject: ObjectWithProperties, dstObject: ObjectWithProperties) => {
+        const dstProperty = dstObject.properties.find((p) => p.property_field_id === groupByField.id);
+        const value = dstProperty?.value[0] || '';
+        const position = (objectsByValue[value] || []).findIndex((o) => o.id === dstObject.id);
+
+        moveObject(srcObject, value, Math.max(position, 0));
     };
 
     return (
@@ -173,19 +241,48 @@ const Kanban = ({id, objects, format}: KanbanProps) => {
                     <KanbanColumnHeader
                         key={`column-header-${id}-${v}`}
                         value={v || `No ${groupByField.name}`}
+                        count={aggregation ? countsByValue[v] || 0 : undefined}
+                        limit={format.wip_limits?.[v]}
+                        onSetLimit={(limit: number) => setLimit(v, limit)}
                     />
                 ))}
             </BoardHeader>
-            <BoardBody className='KanbanBoardBody'>
-                {values.map((v) => (
-                    <KanbanColumn
-                        key={`column-${id}-${v}`}
-                        objects={objectsByValue[v]}
-                        onDrop={(object: ObjectWithProperties) => onDropToColumn(v, object)}
-                        onDropToCard={onDropToCard}
-                    />
-                ))}
-            </BoardBody>
+            {board ? board.lanes.filter((lane) => lane.count > 0).map((lane) => {
+                const laneValue = lane.value || '';
+                return (
+                    <Lane key={`lane-${id}-${laneValue}`}>
+                        <LaneHeader>
+                            {lane.value || `No ${swimlaneField.name}`}
+                            <LaneCount>{lane.count}</LaneCount>
+                        </LaneHeader>
+                        <BoardBody className='KanbanBoardBody'>
+                            {values.map((v) => {
+                                const column = board.columns.findIndex((c) => (c.value || '') === v);
+                                const cell = column === -1 ? null : lane.cells[column];
+                                return (
+                                    <KanbanColumn
+                                        key={`column-${id}-${laneValue}-${v}`}
+                                        objects={(cell?.object_ids || []).map((objectID) => objectsByID[objectID]).filter((o) => o != null)}
+                                        onDrop={(object: ObjectWithProperties) => onDropToCell(v, laneValue, object)}
+                                        onDropToCard={onDropToCard}
+                                    />
+                                );
+                            })}
+                        </BoardBody>
+                    </Lane>
+                );
+            }) : (
+                <BoardBody className='KanbanBoardBody'>
+                    {values.map((v) => (
+                        <KanbanColumn
+                            key={`column-${id}-${v}`}
+                            objects={objectsByValue[v]}
+                            onDrop={(object: ObjectWithProperties) => onDropToColumn(v, object)}
+                            onDropToCard={onDropToCard}
+                        />
+                    ))}
+                </BoardBody>
+            )}
         </Board>
     );
 };
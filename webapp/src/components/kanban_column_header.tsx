@@ -6,6 +6,9 @@ import styled from 'styled-components';
 
 type KanbanProps = {
     value: string;
+    count?: number;
+    limit?: number;
+    onSetLimit?: (limit: number) => void;
 }
 
 const ColumnHeader = styled.div`
@@ -37,12 +40,58 @@ const Label = styled.span`
     }
 `;
 
-const KanbanColumnHeader = ({value}: KanbanProps) => {
+const Count = styled.span`
+    color: rgba(var(--center-channel-color-rgb), 0.64);
+
+    &.over-limit {
+        color: var(--error-text);
+        font-weight: 600;
+    }
+`;
+
+const LimitButton = styled.button`
+    margin-left: 8px;
+    padding: 0;
+    border: none;
+    background: none;
+    font-size: 12px;
+    color: rgba(var(--center-channel-color-rgb), 0.48);
+
+    &:hover {
+        color: rgba(var(--center-channel-color-rgb), 0.72);
+    }
+`;
+
+const KanbanColumnHeader = ({value, count, limit, onSetLimit}: KanbanProps) => {
+    const overLimit = Boolean(limit) && count !== undefined && count > (limit || 0);
+
+    const setLimit = () => {
+        const input = window.prompt(`WIP limit for ${value}, 0 for no limit`, String(limit || 0));
+        if (input === null) {
+            return;
+        }
+        const newLimit = parseInt(input, 10);
+        if (isNaN(newLimit) || newLimit < 0) {
+            return;
+        }
+        onSetLimit?.(newLimit);
+    };
+
     return (
         <ColumnHeader className='KanbanColumnHeader'>
             <Label>
                 {value}
             </Label>
+            {count !== undefined && (
+                <Count className={overLimit ? 'over-limit' : ''}>
+                    {limit ? `${count}/${limit}` : count}
+                </Count>
+            )}
+            {onSetLimit && (
+                <LimitButton onClick={setLimit}>
+                    {'Limit'}
+                </LimitButton>
+            )}
         </ColumnHeader>
     );
 };
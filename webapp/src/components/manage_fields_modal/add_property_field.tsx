@@ -67,6 +67,50 @@ const options = [
         label: 'User',
         value: 'user',
     },
+    {
+        label: 'Date',
+        value: 'date',
+    },
+    {
+        label: 'Number',
+        value: 'number',
+    },
+    {
+        label: 'Formula',
+        value: 'formula',
+    },
+    {
+        label: 'Relation',
+        value: 'relation',
+    },
+    {
+        label: 'Rollup',
+        value: 'rollup',
+    },
+    {
+        label: 'Channel',
+        value: 'channel',
+    },
+    {
+        label: 'Team',
+        value: 'team',
+    },
+    {
+        label: 'Checkbox',
+        value: 'checkbox',
+    },
+    {
+        label: 'URL',
+        value: 'url',
+    },
+    {
+        label: 'Email',
+        value: 'email',
+    },
+    {
+        label: 'Rating',
+        value: 'rating',
+    },
 ];
 
 const components = {DropdownIndicator: null, IndicatorSeparator: null};
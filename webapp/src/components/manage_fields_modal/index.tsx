@@ -4,7 +4,7 @@ import {IntlProvider} from 'react-intl';
 
 import {useDispatch} from 'react-redux';
 
-import {PropertyField} from 'src/types/property';
+import {PropertyField, RelationSettings, RollupSettings} from 'src/types/property';
 import GenericModal from 'src/widgets/generic_modal';
 import {createPropertyField, deletePropertyField, fetchPropertyFieldsForTerm, updatePropertyField} from 'src/client';
 import Editable from 'src/widgets/editable';
@@ -72,8 +72,8 @@ const ManageFieldsModal = (modalProps: ManageFieldsModalProps) => {
         });
 
         //TODO: look into batching this
-        const updateRequests = fieldsToUpdate.map((f) => updatePropertyField(f.id, f.type, f.name, f.values));
-        const createRequests = fieldsToCreate.map((f) => createPropertyField(f.name, f.type, f.values));
+        const updateRequests = fieldsToUpdate.map((f) => updatePropertyField(f.id, f.type, f.name, f.values, f.formula, f.relation, f.rollup));
+        const createRequests = fieldsToCreate.map((f) => createPropertyField(f.name, f.type, f.values, f.formula, f.relation, f.rollup));
         const deleteRequests = toDeleteIDs.map((id) => deletePropertyField(id));
         await Promise.all(updateRequests);
         fieldsToUpdate.forEach((f) => dispatch(receivedPropertyField(f)));
@@ -92,7 +92,7 @@ const ManageFieldsModal = (modalProps: ManageFieldsModalProps) => {
         }
     }
 
-    const onFieldChange = (id: string, key: string, newValue: string | string[]) => {
+    const onFieldChange = (id: string, key: string, newValue: string | string[] | RelationSettings | RollupSettings) => {
         const newFields = [...tempFields];
         const index = newFields.findIndex((f) => f.id === id);
         const newField = {...newFields[index], [key]: newValue};
@@ -101,7 +101,9 @@ const ManageFieldsModal = (modalProps: ManageFieldsModalProps) => {
     };
 
     const onAddField = (type: string) => {
-        setTempFields([...tempFields, {id: '', name: '', type, values: null}]);
+        const relation = type === 'relation' ? {object_type: 'post'} : undefined;
+        const rollup = type === 'rollup' ? {relation_field_id: '', function: 'count'} : undefined;
+        setTempFields([...tempFields, {id: '', name: '', type, values: null, relation, rollup}]);
     };
 
     const onDeleteField = (id: string) => {
@@ -135,7 +137,7 @@ const ManageFieldsModal = (modalProps: ManageFieldsModalProps) => {
                         <FieldRow>
                             <TitleLabel>{'Name'}</TitleLabel>
                             <TitleLabel>{'Type'}</TitleLabel>
-                            <TitleLabel>{'Values'}</TitleLabel>
+                            <TitleLabel>{'Values / Formula'}</TitleLabel>
                             <TitleLabel>{'Actions'}</TitleLabel>
                         </FieldRow>
                         {toRenderFields.map((f) => (
@@ -147,13 +149,96 @@ const ManageFieldsModal = (modalProps: ManageFieldsModalProps) => {
                                     />
                                 </td>
                                 <Label>{f.type}</Label>
-                                {f.values || f.type === 'select' ? (
+                                {f.type === 'formula' && (
+                                    <td>
+                                        <Editable
+                                            value={f.formula || ''}
+                                            placeholderText='days_since(create_at)'
+                                            onChange={(newValue) => onFieldChange(f.id, 'formula', newValue)}
+                                        />
+                                    </td>
+                                )}
+                                {f.type === 'rollup' && (
+                                    <td>
+                                        <select
+                                            value={f.rollup?.relation_field_id || ''}
+                                            onChange={(e) => onFieldChange(f.id, 'rollup', {function: 'count', ...f.rollup, relation_field_id: e.target.value})}
+                                        >
+                                            <option value=''>{'Relation...'}</option>
+                                            {toRenderFields.filter((r) => r.type === 'relation' && r.id).map((r) => (
+                                                <option
+                                                    key={r.id}
+                                                    value={r.id}
+                                                >
+                                                    {r.name}
+                                                </option>
+                                            ))}
+                                        </select>
+                                        <select
+                                            value={f.rollup?.function || 'count'}
+                                            onChange={(e) => onFieldChange(f.id, 'rollup', {relation_field_id: '', ...f.rollup, function: e.target.value})}
+                                        >
+                                            <option value='count'>{'Count'}</option>
+                                            <option value='sum'>{'Sum'}</option>
+                                            <option value='min'>{'Min'}</option>
+                                            <option value='max'>{'Max'}</option>
+                                            <option value='percent'>{'Percent complete'}</option>
+                                        </select>
+                                        <select
+                                            value={f.rollup?.field_id || ''}
+                                            onChange={(e) => onFieldChange(f.id, 'rollup', {relation_field_id: '', function: 'count', ...f.rollup, field_id: e.target.value})}
+                                        >
+                                            <option value=''>{'No field'}</option>
+                                            {toRenderFields.filter((r) => r.id && r.type !== 'formula' && r.type !== 'rollup').map((r) => (
+                                                <option
+                                                    key={r.id}
+                                                    value={r.id}
+                                                >
+                                                    {r.name}
+                                                </option>
+                                            ))}
+                                        </select>
+                                        {(f.rollup?.function === 'count' || f.rollup?.function === 'percent') && (
+                                            <Editable
+                                                value={f.rollup?.value || ''}
+                                                placeholderText='Done'
+                                                onChange={(newValue) => onFieldChange(f.id, 'rollup', {relation_field_id: '', function: 'count', ...f.rollup, value: newValue})}
+                                            />
+                                        )}
+                                    </td>
+                                )}
+                                {f.type === 'relation' && (
+                                    <td>
+                                        <select
+                                            value={f.relation?.object_type || 'post'}
+                                            onChange={(e) => onFieldChange(f.id, 'relation', {...f.relation, object_type: e.target.value})}
+                                        >
+                                            <option value='post'>{'Posts'}</option>
+                                            <option value='channel'>{'Channels'}</option>
+                                        </select>
+                                        <select
+                                            value={f.relation?.back_link_field_id || ''}
+                                            onChange={(e) => onFieldChange(f.id, 'relation', {object_type: 'post', ...f.relation, back_link_field_id: e.target.value})}
+                                        >
+                                            <option value=''>{'No back-link'}</option>
+                                            {toRenderFields.filter((b) => b.type === 'relation' && b.id).map((b) => (
+                                                <option
+                                                    key={b.id}
+                                                    value={b.id}
+                                                >
+                                                    {`Back-link: ${b.name}`}
+                                                </option>
+                                            ))}
+                                        </select>
+                                    </td>
+                                )}
+                                {f.type !== 'formula' && f.type !== 'relation' && f.type !== 'rollup' && (f.values || f.type === 'select' ? (
                                     <td>
                                         <Editable
                                             value={(f.values || []).join(',')}
                                             onChange={(newValue) => onFieldChange(f.id, 'values', newValue.trim().split(','))}
                                         />
-                                    </td>) : <Label>{'-'}</Label>}
+                                    </td>) : <Label>{'-'}</Label>)}
                                 <td>
                                     <IconButton
                                         onClick={() => onDeleteField(f.id)}
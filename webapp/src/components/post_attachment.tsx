@@ -43,7 +43,7 @@ const PostAttachment = ({postId}: PostAttachmentProps) => {
     const properties = useSelector<GlobalState, Property[]>(getPropertiesForObject(postId));
 
     useEffect(() => {
-        fetchPropertiesForObject(postId).
+        fetchPropertiesForObject(postId, 'post').
             then((res) => dispatch(receivedPropertiesForObject(postId, res || [])));
     }, [dispatch, postId]);
 
@@ -0,0 +1,71 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+import React from 'react';
+import {useSelector, useDispatch} from 'react-redux';
+import styled from 'styled-components';
+
+import {View} from 'src/types/property';
+import FieldSelect, {FieldOption} from 'src/components/field_select';
+import {getPropertyField} from 'src/selectors';
+import {patchView} from '@/client';
+import {receivedView} from '@/actions';
+
+interface SwimlanesProps {
+    view: View;
+}
+
+export default function Swimlanes({view}: SwimlanesProps) {
+    const dispatch = useDispatch();
+    const format = view.format;
+    const swimlaneField = useSelector(getPropertyField(format.swimlane_field_id || ''));
+
+    const setSwimlaneField = async (fieldID: string) => {
+        const newFormat = {...format, swimlane_field_id: fieldID};
+        await patchView(view.id, null, null, newFormat);
+
+        dispatch(receivedView({...view, format: newFormat}));
+    };
+
+    const filterLaneFields = (option: FieldOption) => option.field.id !== format.group_by_field_id && (option.field.type === 'select' || option.field.type === 'user');
+
+    return (
+        <Container>
+            <FieldSelect
+                buttonText={
+                    <Button>
+                        {swimlaneField?.name ? `Swimlanes: ${swimlaneField.name}` : 'Swimlanes: <None>'}
+                    </Button>
+                }
+                filter={filterLaneFields}
+                onSelectedChange={(value: FieldOption) => setSwimlaneField(value.field.id)}
+            />
+            {swimlaneField?.name && (
+                <Clear
+                    onClick={() => setSwimlaneField('')}
+                    title='Remove swimlanes'
+                >
+                    {'×'}
+                </Clear>
+            )}
+        </Container>
+    );
+}
+
+const Container = styled.div`
+    display: flex;
+    align-items: center;
+    margin-top: 15px;
+`;
+
+const Button = styled.div`
+    padding: 10px;
+`;
+
+const Clear = styled.button`
+    border: none;
+    background: none;
+    font-size: 16px;
+    color: rgba(var(--center-channel-color-rgb), 0.64);
+    cursor: pointer;
+`;
@@ -0,0 +1,155 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+import React from 'react';
+import {useDispatch, useSelector} from 'react-redux';
+import styled from 'styled-components';
+
+import {ObjectWithProperties, PropertyTypeEnum, SortEnum, TableColumn, View} from 'src/types/property';
+import {getPropertyFields} from 'src/selectors';
+import {patchView} from 'src/client';
+import {receivedView} from 'src/actions';
+import registry from 'src/properties';
+
+type TableProps = {
+    view: View;
+    objects: ObjectWithProperties[];
+}
+
+const defaultColumnWidth = 160;
+const contentColumnWidth = 320;
+
+const nextSort = (sort?: SortEnum): SortEnum | undefined => {
+    switch (sort) {
+    case 'asc':
+        return 'desc';
+    case 'desc':
+        return undefined;
+    default:
+        return 'asc';
+    }
+};
+
+const Table = ({view, objects}: TableProps) => {
+    const dispatch = useDispatch();
+    const fields = useSelector(getPropertyFields);
+    const columns = view.format.columns || [];
+
+    // Pinned columns stay on the left, after the content column
+    const ordered = [...columns.filter((c) => c.pinned), ...columns.filter((c) => !c.pinned)];
+    const offsets = [] as number[];
+    let left = contentColumnWidth;
+    ordered.forEach((c) => {
+        offsets.push(left);
+        left += c.width || defaultColumnWidth;
+    });
+
+    const onSort = async (column: TableColumn) => {
+        const newColumns = columns.map((c) => (c.field_id === column.field_id ? {...c, sort: nextSort(c.sort)} : c));
+        const newFormat = {...view.format, columns: newColumns};
+        await patchView(view.id, null, null, newFormat);
+
+        dispatch(receivedView({...view, format: newFormat}));
+    };
+
+    return (
+        <Container>
+            <Grid>
+                <thead>
+                    <tr>
+                        <HeaderCell
+                            style={{width: contentColumnWidth, left: 0}}
+                            className='pinned'
+                        >
+                            {'Content'}
+                        </HeaderCell>
+                        {ordered.map((c, i) => (
+                            <HeaderCell
+                                key={c.field_id}
+                                style={{width: c.width || defaultColumnWidth, left: c.pinned ? offsets[i] : undefined}}
+                                className={c.pinned ? 'pinned' : ''}
+                                onClick={() => onSort(c)}
+                            >
+                                {fields[c.field_id]?.name || 'Unknown'}
+                                {c.sort === 'asc' && ' ▲'}
+                                {c.sort === 'desc' && ' ▼'}
+                            </HeaderCell>
+                        ))}
+                    </tr>
+                </thead>
+                <tbody>
+                    {objects.map((o) => (
+                        <tr key={o.id}>
+                            <Cell
+                                style={{left: 0}}
+                                className='pinned'
+                            >
+                                {o.content}
+                            </Cell>
+                            {ordered.map((c, i) => {
+                                const property = o.properties?.find((p) => p.property_field_id === c.field_id);
+                                const field = fields[c.field_id];
+                                const Editor = registry.get((field?.type || 'unknown') as PropertyTypeEnum).Editor;
+
+                                return (
+                                    <Cell
+                                        key={c.field_id}
+                                        style={{left: c.pinned ? offsets[i] : undefined}}
+                                        className={c.pinned ? 'pinned' : ''}
+                                    >
+                                        {property && (
+                                            <Editor
+                                                id={property.id}
+                                                value={property.value}
+                                                possibleValues={field?.values || null}
+                                                name={field?.name || ''}
+                                                showEmptyPlaceholder={false}
+                                                readOnly={true}
+                                                onChange={() => null}
+                                            />
+                                        )}
+                                    </Cell>
+                                );
+                            })}
+                        </tr>
+                    ))}
+                </tbody>
+            </Grid>
+        </Container>
+    );
+};
+
+const Container = styled.div`
+    overflow: auto;
+    height: 100%;
+`;
+
+const Grid = styled.table`
+    table-layout: fixed;
+    border-collapse: collapse;
+    color: var(--center-channel-color);
+
+    .pinned {
+        position: sticky;
+        z-index: 1;
+        background: rgb(var(--center-channel-bg-rgb));
+    }
+`;
+
+const HeaderCell = styled.th`
+    padding: 8px;
+    font-weight: 600;
+    text-align: left;
+    cursor: pointer;
+    border-bottom: 1px solid rgba(var(--center-channel-color-rgb), 0.16);
+`;
+
+const Cell = styled.td`
+    padding: 8px;
+    overflow: hidden;
+    text-overflow: ellipsis;
+    white-space: nowrap;
+    border-bottom: 1px solid rgba(var(--center-channel-color-rgb), 0.08);
+`;
+
+export default Table;
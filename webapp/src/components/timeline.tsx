@@ -0,0 +1,178 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+import React, {useEffect, useState} from 'react';
+import styled from 'styled-components';
+
+import {fetchTimelineForView} from 'src/client';
+import {TimelineData} from 'src/types/property';
+import {formatDay} from 'src/utils';
+
+type TimelineProps = {
+    id: string;
+}
+
+const windowDays = 84;
+const stepDays = 28;
+const dayMillis = 24 * 60 * 60 * 1000;
+
+const addDays = (date: Date, days: number) => new Date(date.getFullYear(), date.getMonth(), date.getDate() + days);
+
+// daysBetween counts the days from one YYYY-MM-DD day to another.
+const daysBetween = (from: string, to: string) => Math.round((Date.parse(to) - Date.parse(from)) / dayMillis);
+
+const Timeline = ({id}: TimelineProps) => {
+    // The window starts on the Monday two weeks before today
+    const [start, setStart] = useState(() => {
+        const now = new Date();
+        return addDays(now, -((now.getDay() + 6) % 7) - 14);
+    });
+    const [data, setData] = useState<TimelineData | null>(null);
+
+    const from = formatDay(start);
+    const to = formatDay(addDays(start, windowDays - 1));
+
+    useEffect(() => {
+        fetchTimelineForView(id, from, to).then(setData).catch(() => setData(null));
+    }, [id, from]);
+
+    const titles: Record<string, string> = {};
+    data?.objects.posts.forEach((p) => {
+        titles[p.id] = p.message;
+    });
+    data?.objects.files.forEach((f) => {
+        titles[f.id] = f.name;
+    });
+
+    const weeks = Array.from({length: windowDays / 7}, (_, i) => addDays(start, i * 7));
+
+    return (
+        <TimelineContainer>
+            <Toolbar>
+                <NavButton onClick={() => setStart(addDays(start, -stepDays))}>{'‹'}</NavButton>
+                <Range>{`${from} – ${to}`}</Range>
+                <NavButton onClick={() => setStart(addDays(start, stepDays))}>{'›'}</NavButton>
+            </Toolbar>
+            {data?.truncated && (
+                <Truncated>{'Only the first items of this window are shown. Narrow the view to see them all.'}</Truncated>
+            )}
+            <Row>
+                <Label/>
+                <Track>
+                    {weeks.map((week) => (
+                        <Week key={formatDay(week)}>{week.toLocaleDateString(undefined, {month: 'short', day: 'numeric'})}</Week>
+                    ))}
+                </Track>
+            </Row>
+            {data?.bars.map((bar) => {
+                const offset = Math.max(0, daysBetween(from, bar.start));
+                const length = Math.min(windowDays, daysBetween(from, bar.end) + 1) - offset;
+                const dependencies = data.dependencies.filter((d) => d.object_id === bar.object_id);
+
+                return (
+                    <Row key={bar.object_id}>
+                        <Label title={titles[bar.object_id]}>
+                            {titles[bar.object_id] || bar.object_id}
+                            {dependencies.map((d) => (
+                                <Dependency
+                                    key={d.depends_on_id}
+                                    cyclic={d.cyclic}
+                                    title={d.cyclic ? 'Part of a dependency cycle' : undefined}
+                                >
+                                    {`after ${titles[d.depends_on_id] || d.depends_on_id}`}
+                                </Dependency>
+                            ))}
+                        </Label>
+                        <Track>
+                            <Bar
+                                style={{
+                                    left: `${(offset / windowDays) * 100}%`,
+                                    width: `${(Math.max(length, 0) / windowDays) * 100}%`,
+                                }}
+                                title={`${bar.start} – ${bar.end} (${bar.days}d)`}
+                            />
+                        </Track>
+                    </Row>
+                );
+            })}
+        </TimelineContainer>
+    );
+};
+
+const TimelineContainer = styled.div`
+    color: var(--center-channel-color);
+`;
+
+const Toolbar = styled.div`
+    display: flex;
+    align-items: center;
+    margin-bottom: 12px;
+`;
+
+const NavButton = styled.button`
+    border: none;
+    background: none;
+    font-size: 18px;
+    color: rgba(var(--center-channel-color-rgb), 0.64);
+    cursor: pointer;
+`;
+
+const Range = styled.div`
+    min-width: 200px;
+    text-align: center;
+    font-weight: 600;
+`;
+
+const Truncated = styled.div`
+    margin-bottom: 8px;
+    font-size: 12px;
+    color: rgba(var(--center-channel-color-rgb), 0.64);
+`;
+
+const Row = styled.div`
+    display: grid;
+    grid-template-columns: 240px 1fr;
+    align-items: center;
+    min-height: 32px;
+    border-bottom: 1px solid rgba(var(--center-channel-color-rgb), 0.08);
+`;
+
+const Label = styled.div`
+    padding-right: 8px;
+    overflow: hidden;
+    text-overflow: ellipsis;
+    white-space: nowrap;
+`;
+
+const Dependency = styled.div<{cyclic: boolean}>`
+    font-size: 11px;
+    overflow: hidden;
+    text-overflow: ellipsis;
+    color: ${(props) => (props.cyclic ? 'var(--error-text)' : 'rgba(var(--center-channel-color-rgb), 0.64)')};
+`;
+
+const Track = styled.div`
+    position: relative;
+    display: flex;
+    height: 100%;
+    min-height: 20px;
+`;
+
+const Week = styled.div`
+    flex: 1;
+    font-size: 11px;
+    color: rgba(var(--center-channel-color-rgb), 0.64);
+    border-left: 1px solid rgba(var(--center-channel-color-rgb), 0.08);
+    padding-left: 4px;
+`;
+
+const Bar = styled.div`
+    position: absolute;
+    top: 6px;
+    height: 20px;
+    border-radius: 4px;
+    background: var(--button-bg);
+    opacity: 0.8;
+`;
+
+export default Timeline;
@@ -16,10 +16,15 @@ import {getObjectsWithPropertiesForView, getPropertyFields, getView} from 'src/s
 
 import List from 'src/components/list';
 import Kanban from 'src/components/kanban';
+import Chart from 'src/components/chart';
+import Table from 'src/components/table';
+import Calendar from 'src/components/calendar';
+import Timeline from 'src/components/timeline';
 import {receivedObjectsForView, receivedPropertiesForObject} from '@/actions';
 import {ReceivedPropertiesForObject} from '@/types/actions';
 
 import GroupBy from './group_by';
+import Swimlanes from './swimlanes';
 
 const ViewContainer = styled.div`
     padding: 50px;
@@ -38,9 +43,10 @@ const View = ({id}: ViewProps) => {
     const fields = useSelector(getPropertyFields);
     const objects = useSelector(getObjectsWithPropertiesForView(id));
 
+    // Tables are sorted by the server, so changing their columns reloads the objects
     useEffect(() => {
         getPostsForView(id);
-    }, [id]);
+    }, [id, format.columns]);
 
     async function getPostsForView(viewID: string) {
         if (!viewID) {
@@ -75,6 +81,9 @@ const View = ({id}: ViewProps) => {
         if (query.excludes) {
             lines = lines.concat(Object.keys(query.excludes).map((fid) => `${fields[fid] ? fields[fid].name : 'unknown'} excludes ${query.excludes[fid].length ? query.excludes[fid] : 'any'}`));
         }
+        if (query.conditions) {
+            lines = lines.concat(query.conditions.map((c) => `${fields[c.field_id] ? fields[c.field_id].name : 'unknown'} ${c.operator} ${c.value || ''}`.trim()));
+        }
         return lines.join(' && ');
     }, [query, fields]);
 
@@ -91,26 +100,47 @@ const View = ({id}: ViewProps) => {
                         </HeaderTitle>
                         <HeaderRight>
                             {type === 'kanban' ? (
-                                <GroupBy
-                                    view={view}
-                                />
+                                <>
+                                    <GroupBy
+                                        view={view}
+                                    />
+                                    <Swimlanes
+                                        view={view}
+                                    />
+                                </>
                             ) : null
                             }
                         </HeaderRight>
                     </Header>
                     <ObjectContainer>
-                        {type === 'list' ? (
+                        {type === 'list' && (
                             <List
                                 id={id}
                                 posts={posts}
                             />
-                        ) : (
+                        )}
+                        {type === 'kanban' && (
                             <Kanban
                                 id={id}
                                 objects={objects}
                                 format={format}
                             />
                         )}
+                        {type === 'chart' && (
+                            <Chart id={id}/>
+                        )}
+                        {type === 'table' && (
+                            <Table
+                                view={view}
+                                objects={objects}
+                            />
+                        )}
+                        {type === 'calendar' && (
+                            <Calendar id={id}/>
+                        )}
+                        {type === 'timeline' && (
+                            <Timeline id={id}/>
+                        )}
                     </ObjectContainer>
                 </ViewContainer>
             </DndProvider>
@@ -0,0 +1,46 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+import React from 'react';
+import {useSelector} from 'react-redux';
+
+import {getMyChannels} from 'mattermost-redux/selectors/entities/channels';
+
+import Label from 'src/widgets/label';
+import {PropertyProps} from 'src/properties/types';
+
+// Channels are picked among the ones of the current user, the server checking the user can read
+// the channel before saving.
+const ChannelProperty = (props: PropertyProps): JSX.Element => {
+    const channels = useSelector(getMyChannels);
+    const value = Array.isArray(props.value) ? props.value[0] : props.value;
+    const channel = channels.find((c) => c.id === value);
+
+    if (props.readOnly) {
+        return (
+            <Label empty={!value}>
+                <span>{channel ? `~${channel.display_name}` : value || 'Empty'}</span>
+            </Label>
+        );
+    }
+
+    return (
+        <select
+            value={value || ''}
+            onChange={(e) => props.onChange(e.target.value ? [e.target.value] : [])}
+        >
+            <option value=''>{'Empty'}</option>
+            {value && !channel && <option value={value}>{value}</option>}
+            {channels.map((c) => (
+                <option
+                    key={c.id}
+                    value={c.id}
+                >
+                    {`~${c.display_name}`}
+                </option>
+            ))}
+        </select>
+    );
+};
+
+export default ChannelProperty;
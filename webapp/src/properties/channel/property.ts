@@ -0,0 +1,14 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+import {PropertyType} from 'src/properties/types';
+import {PropertyTypeEnum} from 'src/types/property';
+
+import ChannelProperty from './channel';
+
+export default class ChannelPropertyType extends PropertyType {
+    Editor = ChannelProperty;
+    name = 'Channel';
+    type = 'channel' as PropertyTypeEnum;
+    displayName = 'Channel';
+}
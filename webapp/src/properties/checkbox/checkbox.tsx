@@ -0,0 +1,23 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+import React from 'react';
+
+import {PropertyProps} from 'src/properties/types';
+
+// Checkboxes are stored as 'true' or 'false', like the bools computed by formulas.
+const CheckboxProperty = (props: PropertyProps): JSX.Element => {
+    const value = Array.isArray(props.value) ? props.value[0] : props.value;
+
+    return (
+        <input
+            type='checkbox'
+            title={props.name}
+            checked={value === 'true'}
+            disabled={props.readOnly}
+            onChange={(e) => props.onChange([String(e.target.checked)])}
+        />
+    );
+};
+
+export default CheckboxProperty;
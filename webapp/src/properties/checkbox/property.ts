@@ -0,0 +1,14 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+import {PropertyType} from 'src/properties/types';
+import {PropertyTypeEnum} from 'src/types/property';
+
+import CheckboxProperty from './checkbox';
+
+export default class CheckboxPropertyType extends PropertyType {
+    Editor = CheckboxProperty;
+    name = 'Checkbox';
+    type = 'checkbox' as PropertyTypeEnum;
+    displayName = 'Checkbox';
+}
@@ -0,0 +1,37 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+import React, {useCallback} from 'react';
+import styled from 'styled-components';
+
+import {PropertyProps} from 'src/properties/types';
+
+// Dates are stored as YYYY-MM-DD strings, the format of the date input.
+const DateProperty = (props: PropertyProps): JSX.Element => {
+    const {onChange} = props;
+    const value = (Array.isArray(props.value) ? props.value[0] : props.value) || '';
+
+    const onDateChange = useCallback((e: React.ChangeEvent<HTMLInputElement>) => {
+        onChange(e.target.value ? [e.target.value] : []);
+    }, [onChange]);
+
+    if (props.readOnly) {
+        return <div>{value}</div>;
+    }
+
+    return (
+        <DateInput
+            type='date'
+            value={value.substring(0, 10)}
+            onChange={onDateChange}
+        />
+    );
+};
+
+const DateInput = styled.input`
+    border: none;
+    background: transparent;
+    color: var(--center-channel-color);
+`;
+
+export default DateProperty;
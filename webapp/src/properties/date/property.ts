@@ -0,0 +1,14 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+import {PropertyType} from 'src/properties/types';
+import {PropertyTypeEnum} from 'src/types/property';
+
+import DateProperty from './date';
+
+export default class DatePropertyType extends PropertyType {
+    Editor = DateProperty;
+    name = 'Date';
+    type = 'date' as PropertyTypeEnum;
+    displayName = 'Date';
+}
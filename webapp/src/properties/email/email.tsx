@@ -0,0 +1,43 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+import React, {useEffect, useState} from 'react';
+import styled from 'styled-components';
+
+import Editable from 'src/widgets/editable';
+import {PropertyProps} from 'src/properties/types';
+
+// Email addresses are checked by the server, which only accepts bare addresses.
+const EmailProperty = (props: PropertyProps): JSX.Element => {
+    const {onChange} = props;
+    const stored = (Array.isArray(props.value) ? props.value[0] : props.value) || '';
+    const [value, setValue] = useState(stored);
+
+    useEffect(() => {
+        setValue(stored);
+    }, [stored]);
+
+    if (props.readOnly) {
+        return (
+            <Link href={`mailto:${stored}`}>
+                {stored}
+            </Link>
+        );
+    }
+
+    return (
+        <Editable
+            placeholderText='name@example.com'
+            value={value}
+            onChange={setValue}
+            onSave={() => onChange(value.trim() ? [value.trim()] : [])}
+            onCancel={() => setValue(stored)}
+        />
+    );
+};
+
+const Link = styled.a`
+    display: block;
+`;
+
+export default EmailProperty;
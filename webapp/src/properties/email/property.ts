@@ -0,0 +1,14 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+import {PropertyType} from 'src/properties/types';
+import {PropertyTypeEnum} from 'src/types/property';
+
+import EmailProperty from './email';
+
+export default class EmailPropertyType extends PropertyType {
+    Editor = EmailProperty;
+    name = 'Email';
+    type = 'email' as PropertyTypeEnum;
+    displayName = 'Email';
+}
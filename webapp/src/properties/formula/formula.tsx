@@ -0,0 +1,25 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+import React from 'react';
+import styled from 'styled-components';
+
+import {PropertyProps} from 'src/properties/types';
+
+// Formula values are computed by the server, so they are only ever displayed.
+const FormulaProperty = (props: PropertyProps): JSX.Element => {
+    const stored = Array.isArray(props.value) ? props.value[0] : props.value;
+    const value = stored === undefined || stored === null ? '' : String(stored);
+
+    if (!value && props.showEmptyPlaceholder) {
+        return <Empty>{'Empty'}</Empty>;
+    }
+
+    return <div title={props.name}>{value}</div>;
+};
+
+const Empty = styled.div`
+    color: rgba(var(--center-channel-color-rgb), 0.48);
+`;
+
+export default FormulaProperty;
@@ -0,0 +1,14 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+import {PropertyType} from 'src/properties/types';
+import {PropertyTypeEnum} from 'src/types/property';
+
+import FormulaProperty from './formula';
+
+export default class FormulaPropertyType extends PropertyType {
+    Editor = FormulaProperty;
+    name = 'Formula';
+    type = 'formula' as PropertyTypeEnum;
+    displayName = 'Formula';
+}
@@ -7,6 +7,17 @@ import {PropertyType} from 'src/properties/types';
 import SelectProperty from 'src/properties/select/property';
 import TextProperty from 'src/properties/text/property';
 import UserProperty from 'src/properties/user/property';
+import DatePropertyType from 'src/properties/date/property';
+import NumberPropertyType from 'src/properties/number/property';
+import FormulaPropertyType from 'src/properties/formula/property';
+import RelationPropertyType from 'src/properties/relation/property';
+import RollupPropertyType from 'src/properties/rollup/property';
+import ChannelPropertyType from 'src/properties/channel/property';
+import TeamPropertyType from 'src/properties/team/property';
+import CheckboxPropertyType from 'src/properties/checkbox/property';
+import URLPropertyType from 'src/properties/url/property';
+import EmailPropertyType from 'src/properties/email/property';
+import RatingPropertyType from 'src/properties/rating/property';
 import UnknownProperty from 'src/properties/unknown/property';
 
 class PropertiesRegistry {
@@ -37,5 +48,16 @@ const registry = new PropertiesRegistry();
 registry.register(new TextProperty());
 registry.register(new SelectProperty());
 registry.register(new UserProperty());
+registry.register(new DatePropertyType());
+registry.register(new NumberPropertyType());
+registry.register(new FormulaPropertyType());
+registry.register(new RelationPropertyType());
+registry.register(new RollupPropertyType());
+registry.register(new ChannelPropertyType());
+registry.register(new TeamPropertyType());
+registry.register(new CheckboxPropertyType());
+registry.register(new URLPropertyType());
+registry.register(new EmailPropertyType());
+registry.register(new RatingPropertyType());
 
 export default registry;
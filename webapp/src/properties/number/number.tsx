@@ -0,0 +1,52 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+import React, {useCallback, useEffect, useState} from 'react';
+import styled from 'styled-components';
+
+import {PropertyProps} from 'src/properties/types';
+
+// Numbers are stored as JSON numbers so they can be summed and compared on the server.
+const NumberProperty = (props: PropertyProps): JSX.Element => {
+    const {onChange} = props;
+    const stored = Array.isArray(props.value) ? props.value[0] : props.value;
+    const [value, setValue] = useState(stored === undefined || stored === null ? '' : String(stored));
+
+    useEffect(() => {
+        setValue(stored === undefined || stored === null ? '' : String(stored));
+    }, [stored]);
+
+    const onBlur = useCallback(() => {
+        if (value === '') {
+            onChange([]);
+            return;
+        }
+
+        const number = Number(value);
+        if (!isNaN(number)) {
+            // Values are typed as strings throughout the webapp, but the server keeps the JSON type
+            onChange([number] as unknown as string[]);
+        }
+    }, [onChange, value]);
+
+    if (props.readOnly) {
+        return <div>{value}</div>;
+    }
+
+    return (
+        <NumberInput
+            type='number'
+            value={value}
+            onChange={(e) => setValue(e.target.value)}
+            onBlur={onBlur}
+        />
+    );
+};
+
+const NumberInput = styled.input`
+    border: none;
+    background: transparent;
+    color: var(--center-channel-color);
+`;
+
+export default NumberProperty;
@@ -0,0 +1,14 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+import {PropertyType} from 'src/properties/types';
+import {PropertyTypeEnum} from 'src/types/property';
+
+import NumberProperty from './number';
+
+export default class NumberPropertyType extends PropertyType {
+    Editor = NumberProperty;
+    name = 'Number';
+    type = 'number' as PropertyTypeEnum;
+    displayName = 'Number';
+}
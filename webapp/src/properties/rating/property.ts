@@ -0,0 +1,14 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+import {PropertyType} from 'src/properties/types';
+import {PropertyTypeEnum} from 'src/types/property';
+
+import RatingProperty from './rating';
+
+export default class RatingPropertyType extends PropertyType {
+    Editor = RatingProperty;
+    name = 'Rating';
+    type = 'rating' as PropertyTypeEnum;
+    displayName = 'Rating';
+}
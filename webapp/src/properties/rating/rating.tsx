@@ -0,0 +1,45 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+import React from 'react';
+import styled from 'styled-components';
+
+import {PropertyProps} from 'src/properties/types';
+
+const maxRating = 5;
+
+// Ratings are stored as JSON numbers from 1 to 5 so views can filter on them, clicking the
+// current rating again clearing it.
+const RatingProperty = (props: PropertyProps): JSX.Element => {
+    const stored = Array.isArray(props.value) ? props.value[0] : props.value;
+    const rating = Number(stored) || 0;
+
+    const onClick = (star: number) => {
+        if (props.readOnly) {
+            return;
+        }
+
+        // Values are typed as strings throughout the webapp, but the server keeps the JSON type
+        props.onChange((star === rating ? [] : [star]) as unknown as string[]);
+    };
+
+    return (
+        <div title={props.name}>
+            {[...Array(maxRating).keys()].map((i) => (
+                <Star
+                    key={i}
+                    className={i < rating ? 'icon-star' : 'icon-star-outline'}
+                    readOnly={props.readOnly}
+                    onClick={() => onClick(i + 1)}
+                />
+            ))}
+        </div>
+    );
+};
+
+const Star = styled.i<{readOnly: boolean}>`
+    cursor: ${({readOnly}) => (readOnly ? 'default' : 'pointer')};
+    color: var(--center-channel-color);
+`;
+
+export default RatingProperty;
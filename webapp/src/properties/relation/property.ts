@@ -0,0 +1,14 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+import {PropertyType} from 'src/properties/types';
+import {PropertyTypeEnum} from 'src/types/property';
+
+import RelationProperty from './relation';
+
+export default class RelationPropertyType extends PropertyType {
+    Editor = RelationProperty;
+    name = 'Relation';
+    type = 'relation' as PropertyTypeEnum;
+    displayName = 'Relation';
+}
@@ -0,0 +1,55 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+import React, {useEffect, useState} from 'react';
+import styled from 'styled-components';
+
+import Editable from 'src/widgets/editable';
+import {PropertyProps} from 'src/properties/types';
+
+// Relations are edited as comma separated object ids, which the server checks exist and are
+// readable before saving.
+const RelationProperty = (props: PropertyProps): JSX.Element => {
+    const {onChange} = props;
+    const ids = Array.isArray(props.value) ? props.value : [props.value].filter(Boolean);
+    const [value, setValue] = useState(ids.join(', '));
+
+    useEffect(() => {
+        setValue(ids.join(', '));
+    }, [ids.join(',')]);
+
+    const save = () => {
+        onChange(value.split(',').map((id) => id.trim()).filter(Boolean));
+    };
+
+    if (props.readOnly) {
+        return (
+            <div>
+                {ids.map((id) => (
+                    <Link
+                        key={id}
+                        href={`/_redirect/pl/${id}`}
+                    >
+                        {id}
+                    </Link>
+                ))}
+            </div>
+        );
+    }
+
+    return (
+        <Editable
+            placeholderText='Empty'
+            value={value}
+            onChange={setValue}
+            onSave={save}
+            onCancel={() => setValue(ids.join(', '))}
+        />
+    );
+};
+
+const Link = styled.a`
+    display: block;
+`;
+
+export default RelationProperty;
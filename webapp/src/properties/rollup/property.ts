@@ -0,0 +1,14 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+import {PropertyType} from 'src/properties/types';
+import {PropertyTypeEnum} from 'src/types/property';
+
+import RollupProperty from './rollup';
+
+export default class RollupPropertyType extends PropertyType {
+    Editor = RollupProperty;
+    name = 'Rollup';
+    type = 'rollup' as PropertyTypeEnum;
+    displayName = 'Rollup';
+}
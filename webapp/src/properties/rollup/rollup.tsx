@@ -0,0 +1,29 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+import React from 'react';
+import styled from 'styled-components';
+
+import {PropertyProps} from 'src/properties/types';
+
+// Rollup values are computed by the server. Percent complete rollups store the percentage
+// followed by the number of done and related objects, shown as "7/10 (70%)".
+const RollupProperty = (props: PropertyProps): JSX.Element => {
+    const values = Array.isArray(props.value) ? props.value : [props.value];
+    let value = values[0] === undefined || values[0] === null ? '' : String(values[0]);
+    if (values.length === 3) {
+        value = `${values[1]}/${values[2]} (${values[0]}%)`;
+    }
+
+    if (!value && props.showEmptyPlaceholder) {
+        return <Empty>{'Empty'}</Empty>;
+    }
+
+    return <div title={props.name}>{value}</div>;
+};
+
+const Empty = styled.div`
+    color: rgba(var(--center-channel-color-rgb), 0.48);
+`;
+
+export default RollupProperty;
@@ -0,0 +1,14 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+import {PropertyType} from 'src/properties/types';
+import {PropertyTypeEnum} from 'src/types/property';
+
+import TeamProperty from './team';
+
+export default class TeamPropertyType extends PropertyType {
+    Editor = TeamProperty;
+    name = 'Team';
+    type = 'team' as PropertyTypeEnum;
+    displayName = 'Team';
+}
@@ -0,0 +1,46 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+import React from 'react';
+import {useSelector} from 'react-redux';
+
+import {getMyTeams} from 'mattermost-redux/selectors/entities/teams';
+
+import Label from 'src/widgets/label';
+import {PropertyProps} from 'src/properties/types';
+
+// Teams are picked among the ones of the current user, the server checking the user can view
+// the team before saving.
+const TeamProperty = (props: PropertyProps): JSX.Element => {
+    const teams = useSelector(getMyTeams);
+    const value = Array.isArray(props.value) ? props.value[0] : props.value;
+    const team = teams.find((t) => t.id === value);
+
+    if (props.readOnly) {
+        return (
+            <Label empty={!value}>
+                <span>{team ? team.display_name : value || 'Empty'}</span>
+            </Label>
+        );
+    }
+
+    return (
+        <select
+            value={value || ''}
+            onChange={(e) => props.onChange(e.target.value ? [e.target.value] : [])}
+        >
+            <option value=''>{'Empty'}</option>
+            {value && !team && <option value={value}>{value}</option>}
+            {teams.map((t) => (
+                <option
+                    key={t.id}
+                    value={t.id}
+                >
+                    {t.display_name}
+                </option>
+            ))}
+        </select>
+    );
+};
+
+export default TeamProperty;
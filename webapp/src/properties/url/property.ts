@@ -0,0 +1,14 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+import {PropertyType} from 'src/properties/types';
+import {PropertyTypeEnum} from 'src/types/property';
+
+import URLProperty from './url';
+
+export default class URLPropertyType extends PropertyType {
+    Editor = URLProperty;
+    name = 'URL';
+    type = 'url' as PropertyTypeEnum;
+    displayName = 'URL';
+}
@@ -1,9 +1,10 @@
 // Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
 // See LICENSE.txt for license information.
 
+import {FileInfo} from '@mattermost/types/lib/files';
 import {Post} from '@mattermost/types/lib/posts';
 
-export type PropertyTypeEnum = 'text' | 'select' | 'user' | 'unknown';
+export type PropertyTypeEnum = 'text' | 'select' | 'user' | 'date' | 'number' | 'formula' | 'relation' | 'rollup' | 'channel' | 'team' | 'checkbox' | 'url' | 'email' | 'rating' | 'unknown';
 export interface Property {
     id: string;
     object_id: string;
@@ -12,7 +13,10 @@ export interface Property {
     readonly property_field_name: string;
     readonly property_field_type: PropertyTypeEnum;
     readonly property_field_values: string[] | null | undefined;
+    readonly property_field_inherit_to_replies?: boolean;
+    readonly inherited?: boolean;
     value: string[];
+    readonly update_at?: number;
 }
 
 export interface ObjectWithoutProperties {
@@ -33,6 +37,22 @@ export interface PropertyField {
     type: string;
     name: string;
     values: string[] | null | undefined;
+    inherit_to_replies?: boolean;
+    formula?: string;
+    relation?: RelationSettings;
+    rollup?: RollupSettings;
+}
+
+export interface RelationSettings {
+    object_type: string;
+    back_link_field_id?: string;
+}
+
+export interface RollupSettings {
+    relation_field_id: string;
+    field_id?: string;
+    function: string;
+    value?: string;
 }
 
 export interface ViewQuery {
@@ -40,15 +60,65 @@ export interface ViewQuery {
     excludes: Record<string, string[]>;
     channel_id: string;
     team_id: string;
+    object_type?: string;
+    threads?: boolean;
+    related_to?: string[];
+    conditions?: Condition[];
+}
+
+export type ConditionOperatorEnum = 'is_checked' | 'domain_equals' | 'gte' | 'lte';
+
+export interface Condition {
+    field_id: string;
+    operator: ConditionOperatorEnum;
+    value?: string;
+}
+
+export type ChartKindEnum = 'bar' | 'pie' | 'line';
+
+export interface ChartFormat {
+    kind: ChartKindEnum;
+    dimension_field_id: string;
+    series_field_id?: string;
+    measure: string;
+    measure_field_id?: string;
+    days?: number;
+}
+
+export type SortEnum = 'asc' | 'desc';
+
+export interface TableColumn {
+    field_id: string;
+    width?: number;
+    pinned?: boolean;
+    sort?: SortEnum;
+}
+
+export interface CalendarFormat {
+    start_field_id: string;
+    end_field_id?: string;
+}
+
+export interface TimelineFormat {
+    start_field_id: string;
+    end_field_id: string;
+    dependency_field_id?: string;
 }
 
 export interface ViewFormat {
     order: string[];
     group_by_field_id: string;
     hidden_value_ids: string[];
+    column_orders?: Record<string, string[]>;
+    wip_limits?: Record<string, number>;
+    swimlane_field_id?: string;
+    chart?: ChartFormat;
+    columns?: TableColumn[];
+    calendar?: CalendarFormat;
+    timeline?: TimelineFormat;
 }
 
-export type ViewTypeEnum = 'list' | 'kanban';
+export type ViewTypeEnum = 'list' | 'kanban' | 'chart' | 'table' | 'calendar' | 'timeline';
 
 export interface View {
     id: string;
@@ -57,9 +127,104 @@ export interface View {
     query: ViewQuery;
     format: ViewFormat;
     create_at: number;
+    update_at: number;
+}
+
+export interface ViewMove {
+    object_id: string;
+    object_type: string;
+    value: string;
+    position: number;
+    update_at: number;
+    force?: boolean;
+}
+
+export interface AggregationMetric {
+    count: number;
+    sum: number;
+    avg: number;
+    min: number;
+    max: number;
+}
+
+export interface AggregationGroup {
+    values: Array<string | null>;
+    count: number;
+    metrics?: Record<string, AggregationMetric>;
+}
+
+export interface Aggregation {
+    group_by_field_ids: string[];
+    groups: AggregationGroup[];
+}
+
+export interface ChartSeries {
+    name: string;
+    values: number[];
+}
+
+export interface ChartData {
+    kind: ChartKindEnum;
+    labels: string[];
+    series: ChartSeries[];
+}
+
+export interface BoardColumn {
+    value: string | null;
+    count: number;
+    limit?: number;
+    over_limit?: boolean;
+}
+
+export interface BoardCell {
+    count: number;
+    object_ids: string[];
+}
+
+export interface BoardLane {
+    value: string | null;
+    count: number;
+    cells: BoardCell[];
+}
+
+export interface BoardData {
+    group_by_field_id: string;
+    swimlane_field_id?: string;
+    columns: BoardColumn[];
+    lanes: BoardLane[];
+    objects: ViewQueryResults;
 }
 
 export interface ViewQueryResults {
     posts: Post[];
+    files: FileInfo[];
     properties: Record<string, Property[]>;
+    reply_counts?: Record<string, number>;
+    over_limit_values?: string[];
+}
+
+export interface CalendarData {
+    objects: ViewQueryResults;
+    days: Record<string, string[]>;
+    truncated: boolean;
+}
+
+export interface TimelineBar {
+    object_id: string;
+    start: string;
+    end: string;
+    days: number;
+}
+
+export interface TimelineDependency {
+    object_id: string;
+    depends_on_id: string;
+    cyclic: boolean;
+}
+
+export interface TimelineData {
+    objects: ViewQueryResults;
+    bars: TimelineBar[];
+    dependencies: TimelineDependency[];
+    truncated: boolean;
 }
@@ -4,3 +4,10 @@
 export function generateClassName(conditions: Record<string, boolean>): string {
     return Object.entries(conditions).map(([className, condition]) => (condition ? className : '')).filter((className) => className !== '').join(' ');
 }
+
+// formatDay formats a day as YYYY-MM-DD, which is how the server keys days.
+export function formatDay(date: Date): string {
+    const month = String(date.getMonth() + 1).padStart(2, '0');
+    const day = String(date.getDate()).padStart(2, '0');
+    return `${date.getFullYear()}-${month}-${day}`;
+}